@@ -42,6 +42,14 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := v.Unmarshal(&BrevityApp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	expandJWTKeys(&BrevityApp.JWT)
+	applyJWTFallbacks(&BrevityApp.JWT)
+
+	sources, err := loadSources(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config sources: %w", err)
+	}
+	BrevityApp.sources = sources
 
 	if BrevityApp.App.Environment == "development" {
 		v.WatchConfig()
@@ -51,6 +59,11 @@ func LoadConfig(configPath string) (*Config, error) {
 			if err := v.Unmarshal(&BrevityApp); err != nil {
 				log.Printf("Error reloading config: %v", err)
 			} else {
+				expandJWTKeys(&BrevityApp.JWT)
+				applyJWTFallbacks(&BrevityApp.JWT)
+				if sources, err := loadSources(configPath); err == nil {
+					BrevityApp.sources = sources
+				}
 				log.Println("Config reloaded successfully")
 			}
 		})
@@ -77,12 +90,69 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.sqlite.journal_mode", "WAL")
 	v.SetDefault("database.sqlite.cache_size", -2000)
 
+	v.SetDefault("jwt.algorithm", "HS256")
 	v.SetDefault("jwt.access_token_expiry", "15m")
 	v.SetDefault("jwt.refresh_token_expiry", "168h")
 	v.SetDefault("jwt.reset_token_secret", "default_reset_secret_change_in_production")
+	v.SetDefault("jwt.verification_token_secret", "default_verification_secret_change_in_production")
+	v.SetDefault("jwt.magic_link_expiry", "15m")
 	v.SetDefault("jwt.issuer", "brevity-service")
 	v.SetDefault("jwt.secure_cookie", false)
 
+	v.SetDefault("verification.allow_unverified_login", true)
+	v.SetDefault("verification.blocked_actions", []string{"custom_code", "subscription", "promo_code", "bulk_url"})
+	v.SetDefault("verification.unverified_url_limit", 3)
+	v.SetDefault("verification.resend_cooldown", "1m")
+	v.SetDefault("verification.resend_requests", 5)
+	v.SetDefault("verification.resend_window", "1h")
+
+	v.SetDefault("privacy.deletion_grace_period", "720h")
+	v.SetDefault("privacy.export_expiry", "168h")
+	v.SetDefault("privacy.purge_interval", "1h")
+
+	v.SetDefault("credits.expiry_interval", "1h")
+	v.SetDefault("credits.low_threshold", 5)
+
+	v.SetDefault("referral.referrer_credits", 10)
+	v.SetDefault("referral.referee_credits", 5)
+	v.SetDefault("referral.monthly_cap", 10)
+
+	v.SetDefault("invoice.prefix", "INV")
+	v.SetDefault("invoice.seller_name", "Brevity")
+	v.SetDefault("invoice.tax_rate", 0)
+	v.SetDefault("invoice.tax_label", "Tax")
+	v.SetDefault("invoice.interval", "1m")
+
+	v.SetDefault("trial.length", "336h")
+	v.SetDefault("trial.reminder_before", "72h")
+
+	v.SetDefault("audit.retention", "8760h")
+	v.SetDefault("audit.admin_retention", "0")
+	v.SetDefault("audit.purge_interval", "24h")
+
+	v.SetDefault("webhooks.max_attempts", 8)
+	v.SetDefault("webhooks.retry_base", "30s")
+	v.SetDefault("webhooks.timeout", "10s")
+	v.SetDefault("webhooks.disable_after", 5)
+	v.SetDefault("webhooks.delivery_interval", "10s")
+	v.SetDefault("webhooks.expiry_check_interval", "5m")
+	v.SetDefault("webhooks.allow_private_targets", false)
+
+	v.SetDefault("bulk.sync_limit", 100)
+	v.SetDefault("bulk.max_rows", 5000)
+	v.SetDefault("bulk.worker_interval", "30s")
+
+	v.SetDefault("exports.worker_interval", "5m")
+	v.SetDefault("exports.max_email_size", 10485760) // 10MB
+
+	v.SetDefault("payment.provider", "fake")
+	v.SetDefault("payment.stripe.api_base", "https://api.stripe.com")
+	v.SetDefault("payment.webhook_max_attempts", 5)
+	v.SetDefault("payment.webhook_retry_interval", "1m")
+	v.SetDefault("payment.renewal_interval", "15m")
+	v.SetDefault("payment.renewal_retry_interval", "24h")
+	v.SetDefault("payment.grace_period", "168h")
+
 	v.SetDefault("logger.level", "debug")
 	v.SetDefault("logger.format", "console")
 	v.SetDefault("logger.file_path", "./logs/brevity.log")
@@ -118,11 +188,15 @@ func envVariables(v *viper.Viper) {
 		"database.sqlite.journal_mode",
 		"database.sqlite.cache_size",
 
+		"jwt.algorithm",
+		"jwt.active_key_id",
 		"jwt.access_token_secret",
 		"jwt.access_token_expiry",
 		"jwt.refresh_token_secret",
 		"jwt.refresh_token_expiry",
 		"jwt.reset_token_secret",
+		"jwt.verification_token_secret",
+		"jwt.magic_link_expiry",
 		"jwt.issuer",
 		"jwt.secure_cookie",
 
@@ -143,6 +217,67 @@ func envVariables(v *viper.Viper) {
 		"storage.max_avatar_size",
 		"storage.upload_dir",
 
+		"verification.allow_unverified_login",
+		"verification.blocked_actions",
+		"verification.unverified_url_limit",
+		"verification.resend_cooldown",
+		"verification.resend_requests",
+		"verification.resend_window",
+
+		"privacy.deletion_grace_period",
+		"privacy.export_expiry",
+		"privacy.purge_interval",
+
+		"credits.expiry_interval",
+		"credits.low_threshold",
+
+		"referral.referrer_credits",
+		"referral.referee_credits",
+		"referral.monthly_cap",
+
+		"invoice.prefix",
+		"invoice.seller_name",
+		"invoice.seller_address",
+		"invoice.seller_tax_id",
+		"invoice.tax_rate",
+		"invoice.tax_label",
+		"invoice.interval",
+
+		"trial.length",
+		"trial.reminder_before",
+
+		"audit.retention",
+		"audit.admin_retention",
+		"audit.purge_interval",
+
+		"webhooks.max_attempts",
+		"webhooks.retry_base",
+		"webhooks.timeout",
+		"webhooks.disable_after",
+		"webhooks.delivery_interval",
+		"webhooks.expiry_check_interval",
+		"webhooks.allow_private_targets",
+
+		"bulk.sync_limit",
+		"bulk.max_rows",
+		"bulk.worker_interval",
+
+		"exports.worker_interval",
+		"exports.max_email_size",
+
+		"payment.provider",
+		"payment.prices.basic",
+		"payment.prices.pro",
+		"payment.prices.enterprise",
+		"payment.stripe.secret_key",
+		"payment.stripe.api_base",
+		"payment.webhook_secret",
+		"payment.webhook_max_attempts",
+		"payment.webhook_retry_interval",
+		"payment.renewal_interval",
+		"payment.renewal_retry_interval",
+		"payment.grace_period",
+
 		"logger.level",
 		"logger.format",
 		"logger.file_path",
@@ -165,6 +300,33 @@ func envVariables(v *viper.Viper) {
 	}
 }
 
+// expandJWTKeys resolves environment references inside the jwt.keys list,
+// which envVariables cannot reach because it only handles scalar keys.
+func expandJWTKeys(cfg *JWTConfig) {
+	keys := make([]JWTKeyConfig, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		k.ID = os.ExpandEnv(k.ID)
+		k.Algorithm = os.ExpandEnv(k.Algorithm)
+		k.PrivateKeyPath = os.ExpandEnv(k.PrivateKeyPath)
+		k.PublicKeyPath = os.ExpandEnv(k.PublicKeyPath)
+		if k.ID == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	cfg.Keys = keys
+}
+
+// applyJWTFallbacks fills in secrets added after a deployment was set up.
+// An unset JWT_VERIFICATION_SECRET expands to "" over the default, so
+// verification tokens fall back to the access secret they were signed with
+// before they had their own.
+func applyJWTFallbacks(cfg *JWTConfig) {
+	if cfg.VerificationTokenSecret == "" {
+		cfg.VerificationTokenSecret = cfg.AccessTokenSecret
+	}
+}
+
 func GetConfigPath() string {
 	path := []string{
 		"configs/app.yaml",
@@ -13,6 +13,21 @@ type Config struct {
 	CORS       CORSConfig       `mapstructure:"cors"`
 	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`
 	Storage    StorageConfig    `mapstructure:"storage"`
+
+	Verification VerificationConfig `mapstructure:"verification"`
+	Privacy      PrivacyConfig      `mapstructure:"privacy"`
+	Credits      CreditsConfig      `mapstructure:"credits"`
+	Referral     ReferralConfig     `mapstructure:"referral"`
+	Invoice      InvoiceConfig      `mapstructure:"invoice"`
+	Trial        TrialConfig        `mapstructure:"trial"`
+	Audit        AuditConfig        `mapstructure:"audit"`
+	Webhooks     WebhooksConfig     `mapstructure:"webhooks"`
+	Bulk         BulkConfig         `mapstructure:"bulk"`
+	Exports      ExportsConfig      `mapstructure:"exports"`
+	Payment      PaymentConfig      `mapstructure:"payment"`
+
+	// sources records where each setting came from; see Diff
+	sources map[string]settingSource
 }
 
 type AppConfig struct {
@@ -47,13 +62,123 @@ type SQLiteConfig struct {
 }
 
 type JWTConfig struct {
-	AccessTokenSecret  string        `mapstructure:"access_token_secret"`
-	AccessTokenExpiry  time.Duration `mapstructure:"access_token_expiry"`
-	RefreshTokenSecret string        `mapstructure:"refresh_token_secret"`
-	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry"`
-	ResetTokenSecret   string        `mapstructure:"reset_token_secret"`
-	Issuer             string        `mapstructure:"issuer"`
-	SecureCookie       bool          `mapstructure:"secure_cookie"`
+	Algorithm               string         `mapstructure:"algorithm"`     // HS256|RS256|EdDSA
+	ActiveKeyID             string         `mapstructure:"active_key_id"` // kid used for signing new tokens
+	Keys                    []JWTKeyConfig `mapstructure:"keys"`
+	AccessTokenSecret       string         `mapstructure:"access_token_secret" secret:"true"`
+	AccessTokenExpiry       time.Duration  `mapstructure:"access_token_expiry"`
+	RefreshTokenSecret      string         `mapstructure:"refresh_token_secret" secret:"true"`
+	RefreshTokenExpiry      time.Duration  `mapstructure:"refresh_token_expiry"`
+	ResetTokenSecret        string         `mapstructure:"reset_token_secret" secret:"true"`
+	VerificationTokenSecret string         `mapstructure:"verification_token_secret" secret:"true"`
+	MagicLinkExpiry         time.Duration  `mapstructure:"magic_link_expiry"`
+	Issuer                  string         `mapstructure:"issuer"`
+	SecureCookie            bool           `mapstructure:"secure_cookie"`
+}
+
+// JWTKeyConfig describes one asymmetric key pair. Keys without a private key
+// are only used to verify tokens signed before a rotation.
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	PublicKeyPath  string `mapstructure:"public_key_path"`
+}
+
+// VerificationConfig controls what unverified accounts may do and how often
+// they can ask for a new verification email.
+type VerificationConfig struct {
+	AllowUnverifiedLogin bool          `mapstructure:"allow_unverified_login"`
+	BlockedActions       []string      `mapstructure:"blocked_actions"`      // e.g. custom_code, subscription, promo_code, bulk_url
+	UnverifiedURLLimit   int           `mapstructure:"unverified_url_limit"` // 0 disables the limit
+	ResendCooldown       time.Duration `mapstructure:"resend_cooldown"`
+	ResendRequests       int           `mapstructure:"resend_requests"` // per IP per window
+	ResendWindow         time.Duration `mapstructure:"resend_window"`
+}
+
+// PrivacyConfig governs personal data exports and the two-phase account
+// deletion: accounts are soft deleted first and purged once the grace period ends.
+type PrivacyConfig struct {
+	DeletionGracePeriod time.Duration `mapstructure:"deletion_grace_period"`
+	ExportExpiry        time.Duration `mapstructure:"export_expiry"`
+	PurgeInterval       time.Duration `mapstructure:"purge_interval"`
+}
+
+// CreditsConfig controls the background job that writes off expired credits.
+// A credits.low event is sent when the remaining balance drops to
+// LowThreshold.
+type CreditsConfig struct {
+	ExpiryInterval time.Duration `mapstructure:"expiry_interval"`
+	LowThreshold   int           `mapstructure:"low_threshold"`
+}
+
+// ReferralConfig sets the credits paid out when a referred user verifies
+// their email. MonthlyCap limits how many referrals a referrer is paid for
+// per calendar month; 0 means no limit.
+type ReferralConfig struct {
+	ReferrerCredits int `mapstructure:"referrer_credits"`
+	RefereeCredits  int `mapstructure:"referee_credits"`
+	MonthlyCap      int `mapstructure:"monthly_cap"`
+}
+
+// InvoiceConfig sets the seller details and tax printed on invoices. Prices
+// include tax at TaxRate percent; 0 leaves the tax line off. Interval is how
+// often the worker issues invoices for new payments.
+type InvoiceConfig struct {
+	Prefix        string        `mapstructure:"prefix"`
+	SellerName    string        `mapstructure:"seller_name"`
+	SellerAddress string        `mapstructure:"seller_address"` // lines separated by "\n"
+	SellerTaxID   string        `mapstructure:"seller_tax_id"`
+	TaxRate       float64       `mapstructure:"tax_rate"`
+	TaxLabel      string        `mapstructure:"tax_label"`
+	Interval      time.Duration `mapstructure:"interval"`
+}
+
+// TrialConfig sets how long a free trial of a paid plan lasts and how long
+// before it ends the user is reminded.
+type TrialConfig struct {
+	Length         time.Duration `mapstructure:"length"`
+	ReminderBefore time.Duration `mapstructure:"reminder_before"`
+}
+
+// AuditConfig sets how long audit events are kept. Admin actions have their
+// own retention so they can outlive routine events; 0 keeps events forever.
+// PurgeInterval is how often expired events are removed.
+type AuditConfig struct {
+	Retention      time.Duration `mapstructure:"retention"`
+	AdminRetention time.Duration `mapstructure:"admin_retention"`
+	PurgeInterval  time.Duration `mapstructure:"purge_interval"`
+}
+
+// WebhooksConfig controls outbound webhook deliveries. A failed delivery is
+// retried after RetryBase, doubling each time, until MaxAttempts; an
+// endpoint is disabled after DisableAfter deliveries in a row have failed.
+// ExpiryCheckInterval is how often newly expired links are announced.
+type WebhooksConfig struct {
+	MaxAttempts         int           `mapstructure:"max_attempts"`
+	RetryBase           time.Duration `mapstructure:"retry_base"`
+	Timeout             time.Duration `mapstructure:"timeout"`
+	DisableAfter        int           `mapstructure:"disable_after"`
+	DeliveryInterval    time.Duration `mapstructure:"delivery_interval"`
+	ExpiryCheckInterval time.Duration `mapstructure:"expiry_check_interval"`
+	AllowPrivateTargets bool          `mapstructure:"allow_private_targets"` // for local testing only
+}
+
+// BulkConfig limits bulk link creation. Requests of up to SyncLimit rows are
+// answered straight away; larger ones, up to MaxRows, are queued for the
+// import worker, which runs every WorkerInterval.
+type BulkConfig struct {
+	SyncLimit      int           `mapstructure:"sync_limit"`
+	MaxRows        int           `mapstructure:"max_rows"`
+	WorkerInterval time.Duration `mapstructure:"worker_interval"`
+}
+
+// ExportsConfig controls scheduled exports. WorkerInterval is how often due
+// schedules are run; an export bigger than MaxEmailSize bytes can't be
+// emailed and has to be delivered to storage.
+type ExportsConfig struct {
+	WorkerInterval time.Duration `mapstructure:"worker_interval"`
+	MaxEmailSize   int64         `mapstructure:"max_email_size"`
 }
 
 type EmailConfig struct {
@@ -65,7 +190,7 @@ type SMTPConfig struct {
 	Host      string `mapstructure:"host"`
 	Port      int    `mapstructure:"port"`
 	Username  string `mapstructure:"username"`
-	Password  string `mapstructure:"password"`
+	Password  string `mapstructure:"password" secret:"true"`
 	FromEmail string `mapstructure:"from_email"`
 	FromName  string `mapstructure:"from_name"`
 	UseTLS    bool   `mapstructure:"use_tls"`
@@ -74,7 +199,7 @@ type SMTPConfig struct {
 type CloudinaryConfig struct {
 	CloudName string `mapstructure:"cloud_name"`
 	APIKey    string `mapstructure:"api_key"`
-	APISecret string `mapstructure:"api_secret"`
+	APISecret string `mapstructure:"api_secret" secret:"true"`
 	Folder    string `mapstructure:"folder"`
 }
 
@@ -101,3 +226,36 @@ type RateLimitConfig struct {
 	Requests int    `mapstructure:"requests"`
 	Window   string `mapstructure:"window"`
 }
+
+// PaymentConfig selects the payment gateway. Provider is "stripe" or "fake";
+// the fake gateway never charges anything and is meant for tests and local runs.
+type PaymentConfig struct {
+	Provider string       `mapstructure:"provider"`
+	Prices   PlanPrices   `mapstructure:"prices"`
+	Stripe   StripeConfig `mapstructure:"stripe"`
+
+	// Webhook events are verified with WebhookSecret and retried with backoff
+	// until WebhookMaxAttempts, after which they are dead-lettered.
+	WebhookSecret        string        `mapstructure:"webhook_secret" secret:"true"`
+	WebhookMaxAttempts   int           `mapstructure:"webhook_max_attempts"`
+	WebhookRetryInterval time.Duration `mapstructure:"webhook_retry_interval"`
+
+	// The renewal scheduler runs every RenewalInterval. A failed renewal is
+	// retried every RenewalRetryInterval until GracePeriod has passed, after
+	// which the subscription is downgraded to the free plan.
+	RenewalInterval      time.Duration `mapstructure:"renewal_interval"`
+	RenewalRetryInterval time.Duration `mapstructure:"renewal_retry_interval"`
+	GracePeriod          time.Duration `mapstructure:"grace_period"`
+}
+
+// PlanPrices maps subscription plans to the provider's price IDs.
+type PlanPrices struct {
+	Basic      string `mapstructure:"basic"`
+	Pro        string `mapstructure:"pro"`
+	Enterprise string `mapstructure:"enterprise"`
+}
+
+type StripeConfig struct {
+	SecretKey string `mapstructure:"secret_key" secret:"true"`
+	APIBase   string `mapstructure:"api_base"`
+}
@@ -0,0 +1,83 @@
+package configs
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RedactedValue replaces secrets that are set. Unset secrets are shown as an
+// empty string, so it's still visible whether they were configured.
+const RedactedValue = "[REDACTED]"
+
+// Redact returns the settings keyed by their config names, with every field
+// tagged secret:"true" replaced by RedactedValue.
+func Redact(cfg *Config) map[string]interface{} {
+	return redactStruct(reflect.ValueOf(cfg).Elem())
+}
+
+func redactStruct(v reflect.Value) map[string]interface{} {
+	out := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := configName(field)
+		if name == "" {
+			continue
+		}
+		out[name] = redactValue(v.Field(i), field.Tag.Get("secret") == "true")
+	}
+	return out
+}
+
+func redactValue(v reflect.Value, secret bool) interface{} {
+	if secret {
+		if v.IsZero() {
+			return ""
+		}
+		return RedactedValue
+	}
+
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Struct:
+		return redactStruct(v)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = redactStruct(v.Index(i))
+		}
+		return items
+	}
+	return v.Interface()
+}
+
+// flatten walks cfg like Redact and returns the leaf settings keyed by their
+// full dotted name, e.g. "jwt.access_token_secret".
+func flatten(prefix string, values map[string]interface{}, out map[string]interface{}) {
+	for name, value := range values {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = value
+	}
+}
+
+// configName is the field's mapstructure name; unexported and skipped fields
+// have none.
+func configName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,123 @@
+package configs
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Source is where a setting's value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default" // the built-in default from setDefaults
+	SourceFile    Source = "file"    // a literal value in the YAML file
+	SourceEnv     Source = "env"     // an environment variable
+	SourceUnset   Source = "unset"   // nothing set it, or it points at an unset variable
+)
+
+// Setting is one entry of the config diff.
+type Setting struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source Source      `json:"source"`
+	EnvVar string      `json:"env_var,omitempty"` // the variables it was read from
+}
+
+var envRef = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
+
+// Diff lists every setting with its redacted value and where it came from,
+// sorted by key.
+func (c *Config) Diff() []Setting {
+	values := make(map[string]interface{})
+	flatten("", Redact(c), values)
+
+	settings := make([]Setting, 0, len(values))
+	for key, value := range values {
+		setting := Setting{Key: key, Value: value, Source: SourceUnset}
+		if src, ok := c.sources[key]; ok {
+			setting.Source = src.source
+			setting.EnvVar = src.envVar
+		}
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+type settingSource struct {
+	source Source
+	envVar string
+}
+
+// loadSources works out where each setting came from. It reads the defaults
+// and the YAML file on their own so values can be told apart from the
+// merged result, and looks at the environment as it was at load time.
+func loadSources(configPath string) (map[string]settingSource, error) {
+	defaults := viper.New()
+	setDefaults(defaults)
+
+	file := viper.New()
+	file.SetConfigFile(configPath)
+	file.SetConfigType("yaml")
+	if err := file.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for _, key := range append(defaults.AllKeys(), file.AllKeys()...) {
+		keys[key] = true
+	}
+	// Lists of tables, like jwt.keys, are listed by viper one level down
+	for key := range keys {
+		if i := strings.LastIndex(key, "."); i > 0 && file.IsSet(key[:i]) {
+			if _, isList := file.Get(key[:i]).([]interface{}); isList {
+				keys[key[:i]] = true
+			}
+		}
+	}
+
+	sources := make(map[string]settingSource, len(keys))
+	for key := range keys {
+		sources[key] = sourceOf(key, defaults, file)
+	}
+	return sources, nil
+}
+
+func sourceOf(key string, defaults, file *viper.Viper) settingSource {
+	// Matches viper's AutomaticEnv lookup with the "." to "_" key replacer
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if _, ok := os.LookupEnv(name); ok {
+		return settingSource{source: SourceEnv, envVar: name}
+	}
+
+	if file.IsSet(key) {
+		raw, ok := file.Get(key).(string)
+		if !ok {
+			return settingSource{source: SourceFile}
+		}
+		refs := envRef.FindAllStringSubmatch(raw, -1)
+		if len(refs) == 0 {
+			return settingSource{source: SourceFile}
+		}
+
+		names := make([]string, len(refs))
+		for i, ref := range refs {
+			names[i] = ref[1] + ref[2]
+		}
+		// envVariables replaces the reference with its expansion even when
+		// that is empty, which also hides the default
+		if os.ExpandEnv(raw) == "" {
+			return settingSource{source: SourceUnset, envVar: strings.Join(names, ",")}
+		}
+		return settingSource{source: SourceEnv, envVar: strings.Join(names, ",")}
+	}
+
+	if defaults.IsSet(key) {
+		return settingSource{source: SourceDefault}
+	}
+	return settingSource{source: SourceUnset}
+}
@@ -1,13 +1,18 @@
 package app
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/imraushankr/bervity/server/src/configs"
 	"github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/database"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/email"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/events"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/payment"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/storage"
 	"github.com/imraushankr/bervity/server/src/internal/repository"
 	"github.com/imraushankr/bervity/server/src/internal/routes"
@@ -16,14 +21,22 @@ import (
 
 func SetupRouter(cfg *configs.Config, db *database.DB, log logger.Logger) (*gin.Engine, error) {
 	router := gin.Default()
+	router.Use(middleware.RequestID())
 
 	// Initialize core services
-	authService := auth.NewAuth(&cfg.JWT)
+	authService, err := auth.NewAuth(&cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
 	emailService := email.NewEmailService(&cfg.Email, log)
 	storageProvider, err := storage.NewStorage(cfg)
 	if err != nil {
 		return nil, err
 	}
+	paymentGateway, err := payment.NewGateway(&cfg.Payment, log)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db.DB, log)
@@ -31,12 +44,45 @@ func SetupRouter(cfg *configs.Config, db *database.DB, log logger.Logger) (*gin.
 	urlRepo := repository.NewURLRepository(db.DB, log)
 	creditRepo := repository.NewCreditRepository(db.DB, log)
 	subRepo := repository.NewSubscriptionRepository(db.DB, log)
+	privacyRepo := repository.NewPrivacyRepository(db.DB, log)
+	paymentEventRepo := repository.NewPaymentEventRepository(db.DB, log)
+	planRepo := repository.NewPlanRepository(db.DB, log)
+	promoRepo := repository.NewPromoCodeRepository(db.DB, log)
+	referralRepo := repository.NewReferralRepository(db.DB, log)
+	invoiceRepo := repository.NewInvoiceRepository(db.DB, log)
+	trialRepo := repository.NewTrialRepository(db.DB, log)
+	workspaceRepo := repository.NewWorkspaceRepository(db.DB, log)
+	adminRepo := repository.NewAdminRepository(db.DB, log)
+	auditRepo := repository.NewAuditRepository(db.DB, log)
+	webhookRepo := repository.NewWebhookRepository(db.DB, log)
+	urlImportRepo := repository.NewURLImportRepository(db.DB, log)
+	exportScheduleRepo := repository.NewExportScheduleRepository(db.DB, log)
+	folderRepo := repository.NewFolderRepository(db.DB, log)
+	tagRepo := repository.NewTagRepository(db.DB, log)
+
+	// Event bus: link, click and credit events for outbound webhooks
+	bus := events.NewBus(log)
+	webhookEndpointSvc := services.NewWebhookService(webhookRepo, bus, &cfg.Webhooks, log)
+	go webhookEndpointSvc.RunDeliveryWorker(context.Background())
+
+	// Audit log: who did what, kept for the configured retention
+	auditSvc := services.NewAuditService(auditRepo, &cfg.Audit, log)
+	go auditSvc.RunRetentionWorker(context.Background())
+
+	// Workspace roles decide who may act on shared links, credits and
+	// subscriptions
+	permissionSvc := services.NewPermissionService(workspaceRepo, log)
+
+	// Referrals are recorded at signup and paid out on email verification
+	referralSvc := services.NewReferralService(referralRepo, userRepo, &cfg.Referral, log)
 
 	// Initialize services with proper configuration
 	authSvc := services.NewAuthService(
 		authRepo,
 		authService,
 		emailService,
+		referralSvc,
+		auditSvc,
 		cfg,
 		log,
 	)
@@ -55,34 +101,167 @@ func SetupRouter(cfg *configs.Config, db *database.DB, log logger.Logger) (*gin.
 		urlRepo,
 		creditRepo,
 		nil, // analytics repo if available
+		permissionSvc,
+		auditSvc,
+		bus,
 		log,
 		cfg.App.BaseURL,
 		cfg.App.AnonURLLimit, // Anonymous user limit (5)
 		cfg.App.AuthURLLimit, // Authenticated user free limit (15)
+		cfg.Webhooks.ExpiryCheckInterval,
+	)
+	go urlSvc.RunExpiryWorker(context.Background())
+
+	// Bulk link creation shares the authenticated user free limit
+	urlImportSvc := services.NewURLImportService(
+		urlImportRepo,
+		permissionSvc,
+		auditSvc,
+		bus,
+		&cfg.Bulk,
+		log,
+		cfg.App.BaseURL,
+		cfg.App.AuthURLLimit,
 	)
+	go urlImportSvc.RunImportWorker(context.Background())
+
+	exportSvc := services.NewExportService(
+		urlRepo,
+		exportScheduleRepo,
+		userRepo,
+		permissionSvc,
+		storageProvider,
+		emailService,
+		&cfg.Exports,
+		log,
+		cfg.App.BaseURL,
+	)
+	go exportSvc.RunScheduleWorker(context.Background())
+
+	folderSvc := services.NewFolderService(folderRepo, urlRepo, permissionSvc, log, cfg.App.BaseURL)
+	tagSvc := services.NewTagService(tagRepo, urlRepo, permissionSvc, log, cfg.App.BaseURL)
 
 	// Credit service with authenticated user free limit
 	creditSvc := services.NewCreditService(
 		creditRepo,
+		promoRepo,
+		subRepo,
+		permissionSvc,
+		auditSvc,
+		bus,
 		log,
 		cfg.App.AuthURLLimit, // Authenticated user free limit (15)
+		cfg.Credits.ExpiryInterval,
+		cfg.Credits.LowThreshold,
 	)
+	go creditSvc.RunExpiryWorker(context.Background())
 
 	// Subscription service
 	subSvc := services.NewSubscriptionService(
 		subRepo,
+		planRepo,
+		promoRepo,
 		creditRepo,
+		userRepo,
+		trialRepo,
+		permissionSvc,
+		auditSvc,
+		paymentGateway,
 		log,
 		cfg,
 	)
 
+	// Workspaces: shared links, credits and subscriptions for teams
+	workspaceSvc := services.NewWorkspaceService(
+		workspaceRepo,
+		userRepo,
+		permissionSvc,
+		emailService,
+		cfg,
+		log,
+	)
+
+	// Plan catalog and promo codes, managed through the admin API
+	planSvc := services.NewPlanService(planRepo, log)
+	promoSvc := services.NewPromoCodeService(promoRepo, planRepo, paymentGateway, log)
+
+	// Admin API: user, link, credit and subscription management with an
+	// audit log
+	adminSvc := services.NewAdminService(adminRepo, planRepo, creditRepo, cfg.App.BaseURL, log)
+
+	// Privacy service: data exports and two-phase account deletion
+	privacySvc := services.NewPrivacyService(
+		privacyRepo,
+		storageProvider,
+		&cfg.Privacy,
+		log,
+	)
+	go privacySvc.RunPurgeWorker(context.Background())
+
+	// Payment webhooks: signed ingestion with retries for failed events
+	webhookSvc := services.NewPaymentWebhookService(
+		paymentEventRepo,
+		subRepo,
+		planRepo,
+		creditRepo,
+		paymentGateway,
+		&cfg.Payment,
+		log,
+	)
+	go webhookSvc.RunRetryWorker(context.Background())
+
+	// Subscription scheduler: renewals, grace periods, expiry and trials
+	scheduler := services.NewSubscriptionScheduler(
+		subRepo,
+		planRepo,
+		creditRepo,
+		userRepo,
+		trialRepo,
+		paymentGateway,
+		emailService,
+		&cfg.Payment,
+		&cfg.Trial,
+		log,
+	)
+	go scheduler.RunScheduler(context.Background())
+
+	// Invoices: issued for every paid payment, stored as PDF and emailed
+	invoiceSvc := services.NewInvoiceService(
+		invoiceRepo,
+		userRepo,
+		storageProvider,
+		emailService,
+		&cfg.Invoice,
+		log,
+	)
+	go invoiceSvc.RunInvoiceWorker(context.Background())
+
+	// Access tokens stop working once their account is scheduled for deletion
+	authService.SetAccountCheck(middleware.ActiveAccountCheck(userRepo))
+
+	verificationPolicy := middleware.NewVerificationPolicy(userRepo, urlRepo, &cfg.Verification, log)
+
 	// Initialize handlers
 	authHandler := v1.NewAuthHandler(authSvc, cfg, log)
 	userHandler := v1.NewUserHandler(userSvc, log)
 	healthHandler := v1.NewHealthHandler(cfg)
-	urlHandler := v1.NewURLHandler(urlSvc, log)
+	urlHandler := v1.NewURLHandler(urlSvc, urlImportSvc, log)
 	creditHandler := v1.NewCreditHandler(creditSvc, log)
 	subHandler := v1.NewSubscriptionHandler(subSvc, log)
+	wellKnownHandler := v1.NewWellKnownHandler(authService)
+	privacyHandler := v1.NewPrivacyHandler(privacySvc, log)
+	webhookHandler := v1.NewWebhookHandler(webhookSvc, log)
+	planHandler := v1.NewPlanHandler(planSvc, log)
+	promoHandler := v1.NewPromoCodeHandler(promoSvc, log)
+	referralHandler := v1.NewReferralHandler(referralSvc, log)
+	invoiceHandler := v1.NewInvoiceHandler(invoiceSvc, log)
+	workspaceHandler := v1.NewWorkspaceHandler(workspaceSvc, log)
+	adminHandler := v1.NewAdminHandler(adminSvc, log)
+	auditHandler := v1.NewAuditHandler(auditSvc, log)
+	webhookEndpointHandler := v1.NewWebhookEndpointHandler(webhookEndpointSvc, log)
+	exportHandler := v1.NewExportHandler(exportSvc, log)
+	folderHandler := v1.NewFolderHandler(folderSvc, log)
+	tagHandler := v1.NewTagHandler(tagSvc, log)
 
 	// Setup routes with all required parameters
 	routes.SetupRoutes(
@@ -93,8 +272,23 @@ func SetupRouter(cfg *configs.Config, db *database.DB, log logger.Logger) (*gin.
 		urlHandler,
 		creditHandler,
 		subHandler,
+		wellKnownHandler,
+		privacyHandler,
+		webhookHandler,
+		planHandler,
+		promoHandler,
+		referralHandler,
+		invoiceHandler,
+		workspaceHandler,
+		adminHandler,
+		auditHandler,
+		webhookEndpointHandler,
+		exportHandler,
+		folderHandler,
+		tagHandler,
 		authService, 
 		urlRepo, // Add this line to pass the URL repository
+		verificationPolicy,
 		cfg,
 		log,
 	)
@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+// AdminHandler serves the admin endpoints for users, links, credits and
+// subscriptions. Every change is recorded in the audit log.
+type AdminHandler struct {
+	service interfaces.AdminService
+	log     logger.Logger
+}
+
+func NewAdminHandler(service interfaces.AdminService, log logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *AdminHandler) SearchUsers(c *gin.Context) {
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	users, meta, err := h.service.SearchUsers(c.Request.Context(), &models.UserSearchFilter{
+		Query:  c.Query("q"),
+		Role:   models.Role(c.Query("role")),
+		Status: c.Query("status"),
+		Page:   page,
+	})
+	if err != nil {
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to search users", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Users retrieved successfully", users, meta)
+}
+
+func (h *AdminHandler) SetUserStatus(c *gin.Context) {
+	var req models.SetUserStatusRequest
+	if !bindAdminRequest(c, &req, req.Validate) {
+		return
+	}
+
+	user, err := h.service.SetUserStatus(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.userError(c, err, "Failed to change user status")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "User status updated successfully", user)
+}
+
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	var req models.SetUserRoleRequest
+	if !bindAdminRequest(c, &req, req.Validate) {
+		return
+	}
+
+	user, err := h.service.SetUserRole(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.userError(c, err, "Failed to change user role")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "User role updated successfully", user)
+}
+
+func (h *AdminHandler) GrantCredits(c *gin.Context) {
+	var req models.GrantCreditsRequest
+	if !bindAdminRequest(c, &req, req.Validate) {
+		return
+	}
+
+	balance, err := h.service.GrantCredits(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.userError(c, err, "Failed to grant credits")
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Credits granted successfully", balance)
+}
+
+func (h *AdminHandler) AdjustCredits(c *gin.Context) {
+	var req models.AdjustCreditsRequest
+	if !bindAdminRequest(c, &req, req.Validate) {
+		return
+	}
+
+	balance, err := h.service.AdjustCredits(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.userError(c, err, "Failed to adjust credits")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Credits adjusted successfully", balance)
+}
+
+func (h *AdminHandler) OverrideSubscription(c *gin.Context) {
+	var req models.OverrideSubscriptionRequest
+	if !bindAdminRequest(c, &req, req.Validate) {
+		return
+	}
+
+	sub, err := h.service.OverrideSubscription(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.userError(c, err, "Failed to override subscription")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subscription overridden successfully", sub)
+}
+
+func (h *AdminHandler) TakeDownURL(c *gin.Context) {
+	var req models.TakeDownURLRequest
+	if !bindAdminRequest(c, &req, req.Validate) {
+		return
+	}
+
+	url, err := h.service.TakeDownURL(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.urlError(c, err, "Failed to take down URL")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "URL taken down successfully", url)
+}
+
+func (h *AdminHandler) RestoreURL(c *gin.Context) {
+	var req models.RestoreURLRequest
+	if !bindAdminRequest(c, &req, req.Validate) {
+		return
+	}
+
+	url, err := h.service.RestoreURL(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.urlError(c, err, "Failed to restore URL")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "URL restored successfully", url)
+}
+
+func (h *AdminHandler) userError(c *gin.Context, err error, msg string) {
+	switch err {
+	case models.ErrUserNotFound:
+		utils.Error(c, http.StatusNotFound, err.Error(), err)
+	case models.ErrInvalidInput, models.ErrInvalidPlan, models.ErrCannotModifySelf:
+		utils.Error(c, http.StatusBadRequest, err.Error(), err)
+	case models.ErrInsufficientCredits, models.ErrPaidSubscriptionOverride, models.ErrSubscriptionTrialing:
+		utils.Error(c, http.StatusConflict, err.Error(), err)
+	default:
+		h.log.Error("admin action failed", logger.ErrorField(err), logger.String("action", msg))
+		utils.Error(c, http.StatusInternalServerError, msg, err)
+	}
+}
+
+func (h *AdminHandler) urlError(c *gin.Context, err error, msg string) {
+	switch err {
+	case models.ErrURLNotFound:
+		utils.Error(c, http.StatusNotFound, err.Error(), err)
+	case models.ErrURLTakenDown, models.ErrURLNotTakenDown:
+		utils.Error(c, http.StatusConflict, err.Error(), err)
+	default:
+		h.log.Error("admin action failed", logger.ErrorField(err), logger.String("action", msg))
+		utils.Error(c, http.StatusInternalServerError, msg, err)
+	}
+}
+
+// bindAdminRequest binds and validates the JSON body, answering with the
+// validation errors when either fails.
+func bindAdminRequest(c *gin.Context, req interface{}, validate func() error) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return false
+	}
+	if err := validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return false
+	}
+	return true
+}
+
+// pageParams reads the limit and cursor of a list request. It answers 400
+// and reports false if either is malformed.
+func pageParams(c *gin.Context) (pagination.Params, bool) {
+	p, err := pagination.Parse(c.Query("limit"), c.Query("cursor"))
+	switch err {
+	case nil:
+		return p, true
+	case pagination.ErrInvalidLimit:
+		utils.Error(c, http.StatusBadRequest, "Invalid limit parameter", err)
+	default:
+		utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+	}
+	return p, false
+}
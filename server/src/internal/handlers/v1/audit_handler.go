@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type AuditHandler struct {
+	service interfaces.AuditService
+	log     logger.Logger
+}
+
+func NewAuditHandler(service interfaces.AuditService, log logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// GetMyEvents lists the events the current user performed or that target
+// their account.
+func (h *AuditHandler) GetMyEvents(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	events, page, err := h.service.ListUserEvents(c.Request.Context(), c.GetString("user_id"), filter)
+	if err != nil {
+		auditListError(c, err)
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Audit events retrieved successfully", events, page)
+}
+
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+	filter.ActorID = c.Query("actor_id")
+	filter.TargetType = c.Query("target_type")
+	filter.TargetID = c.Query("target_id")
+
+	events, page, err := h.service.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		auditListError(c, err)
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Audit events retrieved successfully", events, page)
+}
+
+func auditListError(c *gin.Context, err error) {
+	switch err {
+	case pagination.ErrInvalidCursor:
+		utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+	default:
+		utils.Error(c, http.StatusInternalServerError, "Failed to get audit events", err)
+	}
+}
+
+// auditFilter reads the query parameters both audit endpoints accept
+func auditFilter(c *gin.Context) (*models.AuditEventFilter, bool) {
+	page, ok := pageParams(c)
+	if !ok {
+		return nil, false
+	}
+
+	filter := &models.AuditEventFilter{
+		Action: models.AuditAction(c.Query("action")),
+		Page:   page,
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "Invalid from date", err)
+			return nil, false
+		}
+		filter.From = &t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "Invalid to date", err)
+			return nil, false
+		}
+		filter.To = &t
+	}
+	return filter, true
+}
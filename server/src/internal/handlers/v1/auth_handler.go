@@ -32,7 +32,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.Register(c.Request.Context(), &req)
+	if ref := c.Query("ref"); ref != "" {
+		req.ReferralCode = ref
+	}
+
+	user, err := h.service.Register(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
 		switch err {
 		case models.ErrEmailAlreadyExists, models.ErrUsernameAlreadyExists:
@@ -59,7 +63,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		switch err {
 		case models.ErrInvalidCredentials:
 			utils.Error(c, http.StatusUnauthorized, "Login failed", err)
-		case models.ErrUserNotVerified:
+		case models.ErrUserNotVerified, models.ErrAccountSuspended:
 			utils.Error(c, http.StatusForbidden, "Login failed", err)
 		default:
 			utils.Error(c, http.StatusInternalServerError, "Login failed", err)
@@ -67,6 +71,76 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	h.setAuthCookies(c, resp)
+	utils.Success(c, http.StatusOK, "Login successful", resp)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	c.SetCookie("access_token", "", -1, "/", "", h.cfg.JWT.SecureCookie, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", h.cfg.JWT.SecureCookie, true)
+	c.SetCookie("logged_in", "", -1, "/", "", h.cfg.JWT.SecureCookie, false)
+	utils.Success(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req models.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	if err := h.service.RequestMagicLink(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "Failed to send sign-in link", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "If an account exists, a sign-in link has been sent", nil)
+}
+
+// ConfirmMagicLink renders the page the emailed link opens. It doesn't check
+// or use the token.
+func (h *AuthHandler) ConfirmMagicLink(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.Error(c, http.StatusBadRequest, "Login failed", models.ErrInvalidMagicLink)
+		return
+	}
+
+	renderConfirmPage(c, h.log, confirmPage{
+		Title:   "Sign in",
+		Message: "Continue to sign in to your account.",
+		Button:  "Sign in",
+		Action:  "verify",
+		Token:   token,
+	})
+}
+
+func (h *AuthHandler) ConsumeMagicLink(c *gin.Context) {
+	var req models.ConsumeMagicLinkRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	resp, err := h.service.LoginWithMagicLink(c.Request.Context(), req.Token)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidMagicLink:
+			utils.Error(c, http.StatusUnauthorized, "Login failed", err)
+		case models.ErrAccountSuspended:
+			utils.Error(c, http.StatusForbidden, "Login failed", err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Login failed", err)
+		}
+		return
+	}
+
+	h.setAuthCookies(c, resp)
+	utils.Success(c, http.StatusOK, "Login successful", resp)
+}
+
+// setAuthCookies issues the session cookies shared by every sign-in method.
+func (h *AuthHandler) setAuthCookies(c *gin.Context, resp *models.LoginResponse) {
 	c.SetSameSite(http.SameSiteLaxMode)
 	c.SetCookie(
 		"access_token",
@@ -77,6 +151,17 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		h.cfg.JWT.SecureCookie,
 		true,
 	)
+	if resp.RefreshToken != "" {
+		c.SetCookie(
+			"refresh_token",
+			resp.RefreshToken,
+			int(h.cfg.JWT.RefreshTokenExpiry.Seconds()),
+			"/",
+			"",
+			h.cfg.JWT.SecureCookie,
+			true,
+		)
+	}
 	c.SetCookie(
 		"logged_in",
 		"true",
@@ -86,14 +171,6 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		h.cfg.JWT.SecureCookie,
 		false,
 	)
-
-	utils.Success(c, http.StatusOK, "Login successful", resp)
-}
-
-func (h *AuthHandler) Logout(c *gin.Context) {
-	c.SetCookie("access_token", "", -1, "/", "", h.cfg.JWT.SecureCookie, true)
-	c.SetCookie("logged_in", "", -1, "/", "", h.cfg.JWT.SecureCookie, false)
-	utils.Success(c, http.StatusOK, "Logged out successfully", nil)
 }
 
 func (h *AuthHandler) VerifyEmail(c *gin.Context) {
@@ -110,6 +187,21 @@ func (h *AuthHandler) VerifyEmail(c *gin.Context) {
 	utils.Success(c, http.StatusOK, "Email verified successfully", nil)
 }
 
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	if err := h.service.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "Failed to resend verification email", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "If the account is awaiting verification, a new link has been sent", nil)
+}
+
 func (h *AuthHandler) InitiatePasswordReset(c *gin.Context) {
 	var req models.PasswordResetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -155,6 +247,10 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 	resp, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
+		if err == models.ErrAccountSuspended {
+			utils.Error(c, http.StatusForbidden, "Token refresh failed", err)
+			return
+		}
 		utils.Error(c, http.StatusUnauthorized, "Token refresh failed", err)
 		return
 	}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// confirmPage asks the user to confirm an action from an emailed link. Mail
+// scanners and link prefetchers open links in emails, so opening the link
+// must not use up its single-use token; the button posts it back instead.
+type confirmPage struct {
+	Title   string
+	Message string
+	Button  string
+	Action  string // relative, so the form posts to the same path without the query
+	Token   string
+}
+
+var confirmPageTemplate = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<meta name="referrer" content="no-referrer">
+<title>{{.Title}}</title>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<p>{{.Message}}</p>
+<button type="submit">{{.Button}}</button>
+</form>
+</body>
+</html>
+`))
+
+func renderConfirmPage(c *gin.Context, log logger.Logger, page confirmPage) {
+	// Keep the token out of caches and Referer headers
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := confirmPageTemplate.Execute(c.Writer, page); err != nil {
+		log.Error("failed to render confirm page", logger.ErrorField(err))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 	"github.com/imraushankr/bervity/server/src/internal/utils"
 )
 
@@ -50,7 +51,8 @@ func (h *CreditHandler) ApplyPromoCode(c *gin.Context) {
 	credit, err := h.creditService.ApplyPromoCode(ctx, userID, req.Code)
 	if err != nil {
 		switch err {
-		case models.ErrInvalidInput, models.ErrPromoCodeInvalid, models.ErrPromoCodeAlreadyUsed:
+		case models.ErrInvalidInput, models.ErrPromoCodeInvalid, models.ErrPromoCodeAlreadyUsed,
+			models.ErrPromoCodeExpired, models.ErrPromoCodeExhausted, models.ErrPromoCodeNotApplicable:
 			utils.Error(c, http.StatusBadRequest, err.Error(), err)
 		default:
 			h.log.Error("failed to apply promo code", logger.ErrorField(err))
@@ -66,12 +68,94 @@ func (h *CreditHandler) GetUsage(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetString("user_id")
 
-	usage, err := h.creditService.GetCreditUsage(ctx, userID)
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	usage, meta, err := h.creditService.GetCreditUsage(ctx, userID, page)
 	if err != nil {
-		h.log.Error("failed to get credit usage", logger.ErrorField(err))
-		utils.Error(c, http.StatusInternalServerError, "Failed to get credit usage", err)
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		default:
+			h.log.Error("failed to get credit usage", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get credit usage", err)
+		}
 		return
 	}
 
-	utils.Success(c, http.StatusOK, "Credit usage retrieved successfully", usage)
-}
\ No newline at end of file
+	utils.List(c, http.StatusOK, "Credit usage retrieved successfully", usage, meta)
+}
+
+func (h *CreditHandler) GetLedger(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	entries, meta, err := h.creditService.GetCreditLedger(ctx, userID, page)
+	if err != nil {
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		default:
+			h.log.Error("failed to get credit ledger", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get credit ledger", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Credit ledger retrieved successfully", entries, meta)
+}
+func (h *CreditHandler) GetWorkspaceBalance(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	balance, err := h.creditService.GetWorkspaceCreditBalance(ctx, c.Param("id"), userID)
+	if err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to get workspace credit balance", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get credit balance", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Credit balance retrieved successfully", balance)
+}
+
+func (h *CreditHandler) GetWorkspaceLedger(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	entries, meta, err := h.creditService.GetWorkspaceCreditLedger(ctx, c.Param("id"), userID, page)
+	if err != nil {
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to get workspace credit ledger", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get credit ledger", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Credit ledger retrieved successfully", entries, meta)
+}
@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/export"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type ExportHandler struct {
+	service interfaces.ExportService
+	log     logger.Logger
+}
+
+func NewExportHandler(service interfaces.ExportService, log logger.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// ExportLinks streams the user's links, or a workspace's with workspace_id
+func (h *ExportHandler) ExportLinks(c *gin.Context) {
+	h.export(c, models.URLExportLinks, "", c.Query("workspace_id"))
+}
+
+// ExportClicks streams the raw clicks on one link
+func (h *ExportHandler) ExportClicks(c *gin.Context) {
+	h.export(c, models.URLExportClicks, c.Param("id"), "")
+}
+
+func (h *ExportHandler) export(c *gin.Context, kind models.URLExportKind, urlID, workspaceID string) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "Invalid format parameter", models.ErrUnknownExportFormat)
+		return
+	}
+	columns, err := kind.SelectColumns(c.Query("fields"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	from, ok := optionalTime(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := optionalTime(c, "to")
+	if !ok {
+		return
+	}
+
+	q := &models.URLExportQuery{
+		Kind:        kind,
+		Format:      string(format),
+		Columns:     columns,
+		URLID:       urlID,
+		WorkspaceID: workspaceID,
+		From:        from,
+		To:          to,
+	}
+	filename := fmt.Sprintf("brevity-%s-%s.%s", kind, time.Now().UTC().Format("20060102-150405"), format.Extension())
+	out := &exportResponse{c: c, contentType: format.ContentType(), filename: filename}
+
+	err = h.service.Export(c.Request.Context(), c.GetString("user_id"), q, out)
+	if err == nil {
+		out.start()
+		return
+	}
+	if out.started {
+		// The status line has gone out; all that can be done is to drop the
+		// connection so the client sees the download is incomplete
+		h.log.Error("export failed while streaming", logger.ErrorField(err))
+		c.Abort()
+		c.Writer.Flush()
+		if conn, _, hijackErr := c.Writer.Hijack(); hijackErr == nil {
+			conn.Close()
+		}
+		return
+	}
+
+	switch err {
+	case models.ErrUnknownExportFormat, models.ErrUnknownExportField, models.ErrInvalidInput:
+		utils.Error(c, http.StatusBadRequest, err.Error(), err)
+	case models.ErrForbidden:
+		utils.Error(c, http.StatusForbidden, err.Error(), err)
+	case models.ErrURLNotFound, models.ErrWorkspaceNotFound:
+		utils.Error(c, http.StatusNotFound, err.Error(), err)
+	default:
+		h.log.Error("failed to export", logger.ErrorField(err))
+		utils.Error(c, http.StatusInternalServerError, "Failed to export", err)
+	}
+}
+
+// exportResponse sends the download headers with the first byte written,
+// so errors found before then can still be answered as JSON
+type exportResponse struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (r *exportResponse) start() {
+	if r.started {
+		return
+	}
+	r.started = true
+	r.c.Header("Content-Type", r.contentType)
+	r.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, r.filename))
+	r.c.Header("Cache-Control", "no-store")
+	r.c.Status(http.StatusOK)
+}
+
+func (r *exportResponse) Write(p []byte) (int, error) {
+	r.start()
+	return r.c.Writer.Write(p)
+}
+
+// optionalTime parses an RFC 3339 query parameter. It answers 400 and
+// reports false if the value is malformed.
+func optionalTime(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid %s date", name), err)
+		return nil, false
+	}
+	return &t, true
+}
+
+func (h *ExportHandler) CreateSchedule(c *gin.Context) {
+	var req models.CreateExportScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	schedule, err := h.service.CreateSchedule(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		switch err {
+		case models.ErrUnknownExportFormat, models.ErrUnknownExportField, models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrURLNotFound, models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrExportScheduleLimit:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to create export schedule", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to create export schedule", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Export schedule created successfully", schedule)
+}
+
+func (h *ExportHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.service.ListSchedules(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		h.log.Error("failed to list export schedules", logger.ErrorField(err))
+		utils.Error(c, http.StatusInternalServerError, "Failed to list export schedules", err)
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Export schedules retrieved successfully", schedules, pagination.All(len(schedules)))
+}
+
+func (h *ExportHandler) DeleteSchedule(c *gin.Context) {
+	err := h.service.DeleteSchedule(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		switch err {
+		case models.ErrExportScheduleNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to delete export schedule", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to delete export schedule", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Export schedule deleted successfully", nil)
+}
@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type FolderHandler struct {
+	service interfaces.FolderService
+	log     logger.Logger
+}
+
+func NewFolderHandler(service interfaces.FolderService, log logger.Logger) *FolderHandler {
+	return &FolderHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *FolderHandler) CreateFolder(c *gin.Context) {
+	var req models.CreateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	folder, err := h.service.CreateFolder(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		h.folderError(c, err, "Failed to create folder")
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Folder created successfully", folder)
+}
+
+// ListFolders lists the user's folders, or a workspace's with workspace_id
+func (h *FolderHandler) ListFolders(c *gin.Context) {
+	folders, err := h.service.ListFolders(c.Request.Context(), c.GetString("user_id"), c.Query("workspace_id"))
+	if err != nil {
+		h.folderError(c, err, "Failed to list folders")
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Folders retrieved successfully", folders, pagination.All(len(folders)))
+}
+
+func (h *FolderHandler) UpdateFolder(c *gin.Context) {
+	var req models.UpdateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	folder, err := h.service.UpdateFolder(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.folderError(c, err, "Failed to update folder")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Folder updated successfully", folder)
+}
+
+func (h *FolderHandler) DeleteFolder(c *gin.Context) {
+	if err := h.service.DeleteFolder(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		h.folderError(c, err, "Failed to delete folder")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Folder deleted successfully", nil)
+}
+
+// MoveURL puts a link in a folder, or takes it out with an empty folder_id
+func (h *FolderHandler) MoveURL(c *gin.Context) {
+	var req models.MoveURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	resp, err := h.service.MoveURL(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.folderError(c, err, "Failed to move URL")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "URL moved successfully", resp)
+}
+
+func (h *FolderHandler) folderError(c *gin.Context, err error, msg string) {
+	switch err {
+	case models.ErrInvalidInput:
+		utils.Error(c, http.StatusBadRequest, err.Error(), err)
+	case models.ErrForbidden:
+		utils.Error(c, http.StatusForbidden, err.Error(), err)
+	case models.ErrFolderNotFound, models.ErrURLNotFound, models.ErrWorkspaceNotFound:
+		utils.Error(c, http.StatusNotFound, err.Error(), err)
+	case models.ErrFolderExists:
+		utils.Error(c, http.StatusConflict, err.Error(), err)
+	default:
+		h.log.Error("folder request failed", logger.ErrorField(err), logger.String("action", msg))
+		utils.Error(c, http.StatusInternalServerError, msg, err)
+	}
+}
@@ -58,6 +58,24 @@ func (h *HealthHandler) GetStatistics(c *gin.Context) {
 	})
 }
 
+// GetConfig returns the running configuration with secrets redacted.
 func (h *HealthHandler) GetConfig(c *gin.Context) {
-	c.JSON(http.StatusOK, h.cfg)
+	c.JSON(http.StatusOK, configs.Redact(h.cfg))
+}
+
+// GetConfigDiff lists every setting and whether it came from a default, the
+// YAML file or the environment. ?source= keeps only settings from one source.
+func (h *HealthHandler) GetConfigDiff(c *gin.Context) {
+	settings := h.cfg.Diff()
+	if source := configs.Source(c.Query("source")); source != "" {
+		filtered := settings[:0]
+		for _, s := range settings {
+			if s.Source == source {
+				filtered = append(filtered, s)
+			}
+		}
+		settings = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
 }
\ No newline at end of file
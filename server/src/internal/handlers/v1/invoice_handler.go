@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type InvoiceHandler struct {
+	invoiceService interfaces.InvoiceService
+	log            logger.Logger
+}
+
+func NewInvoiceHandler(invoiceService interfaces.InvoiceService, log logger.Logger) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		log:            log,
+	}
+}
+
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	invoices, meta, err := h.invoiceService.ListInvoices(c.Request.Context(), userID, page)
+	if err != nil {
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to get invoices", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Invoices retrieved successfully", invoices, meta)
+}
+
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	invoice, err := h.invoiceService.GetInvoice(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		switch err {
+		case models.ErrInvoiceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to get invoice", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Invoice retrieved successfully", invoice)
+}
+
+func (h *InvoiceHandler) DownloadInvoice(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	pdf, filename, err := h.invoiceService.RenderPDF(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		switch err {
+		case models.ErrInvoiceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to render invoice", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to render invoice", err)
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+// PlanHandler serves the admin endpoints for the plan catalog.
+type PlanHandler struct {
+	service interfaces.PlanService
+	log     logger.Logger
+}
+
+func NewPlanHandler(service interfaces.PlanService, log logger.Logger) *PlanHandler {
+	return &PlanHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *PlanHandler) ListPlans(c *gin.Context) {
+	plans, err := h.service.ListPlans(c.Request.Context())
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "Failed to list plans", err)
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Plans retrieved successfully", plans, pagination.All(len(plans)))
+}
+
+func (h *PlanHandler) GetPlanVersions(c *gin.Context) {
+	plans, err := h.service.GetPlanVersions(c.Request.Context(), models.SubscriptionPlan(c.Param("code")))
+	if err != nil {
+		switch err {
+		case models.ErrPlanNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to get plan versions", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Plan versions retrieved successfully", plans, pagination.All(len(plans)))
+}
+
+func (h *PlanHandler) CreatePlan(c *gin.Context) {
+	var req models.CreatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	plan, err := h.service.CreatePlan(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidPlan:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrPlanExists:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to create plan", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to create plan", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Plan created successfully", plan)
+}
+
+func (h *PlanHandler) UpdatePlan(c *gin.Context) {
+	var req models.UpdatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	plan, err := h.service.UpdatePlan(c.Request.Context(), models.SubscriptionPlan(c.Param("code")), &req)
+	if err != nil {
+		switch err {
+		case models.ErrPlanNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to update plan", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to update plan", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Plan updated successfully", plan)
+}
+
+func (h *PlanHandler) ArchivePlan(c *gin.Context) {
+	if err := h.service.ArchivePlan(c.Request.Context(), models.SubscriptionPlan(c.Param("code"))); err != nil {
+		switch err {
+		case models.ErrPlanNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to archive plan", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to archive plan", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Plan archived successfully", nil)
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type PrivacyHandler struct {
+	service interfaces.PrivacyService
+	log     logger.Logger
+}
+
+func NewPrivacyHandler(service interfaces.PrivacyService, log logger.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *PrivacyHandler) RequestExport(c *gin.Context) {
+	userID := c.GetString("user_id")
+	export, err := h.service.RequestExport(c.Request.Context(), userID)
+	if err != nil {
+		switch err {
+		case models.ErrExportInProgress:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to start data export", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusAccepted, "Data export started", export)
+}
+
+func (h *PrivacyHandler) GetExport(c *gin.Context) {
+	userID := c.GetString("user_id")
+	export, err := h.service.GetExport(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		switch err {
+		case models.ErrExportNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to get data export", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Data export retrieved successfully", export)
+}
+
+func (h *PrivacyHandler) DeleteAccount(c *gin.Context) {
+	userID := c.GetString("user_id")
+	resp, err := h.service.DeleteAccount(c.Request.Context(), userID)
+	if err != nil {
+		switch err {
+		case models.ErrUserNotFound:
+			utils.Error(c, http.StatusNotFound, "User not found", err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to delete account", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusAccepted, "Account scheduled for deletion. Sign in before the deletion date to restore it", resp)
+}
@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+// PromoCodeHandler serves the admin endpoints for promo codes.
+type PromoCodeHandler struct {
+	service interfaces.PromoCodeService
+	log     logger.Logger
+}
+
+func NewPromoCodeHandler(service interfaces.PromoCodeService, log logger.Logger) *PromoCodeHandler {
+	return &PromoCodeHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *PromoCodeHandler) ListPromoCodes(c *gin.Context) {
+	promos, err := h.service.ListPromoCodes(c.Request.Context())
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "Failed to list promo codes", err)
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Promo codes retrieved successfully", promos, pagination.All(len(promos)))
+}
+
+func (h *PromoCodeHandler) GetPromoCode(c *gin.Context) {
+	promo, err := h.service.GetPromoCode(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.notFoundOr(c, err, "Failed to get promo code")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Promo code retrieved successfully", promo)
+}
+
+func (h *PromoCodeHandler) GetRedemptions(c *gin.Context) {
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	redemptions, meta, err := h.service.GetRedemptions(c.Request.Context(), c.Param("id"), page)
+	if err != nil {
+		h.notFoundOr(c, err, "Failed to get promo code redemptions")
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Promo code redemptions retrieved successfully", redemptions, meta)
+}
+
+func (h *PromoCodeHandler) CreatePromoCode(c *gin.Context) {
+	var req models.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	promo, err := h.service.CreatePromoCode(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput, models.ErrInvalidPlan:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrPromoCodeExists:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to create promo code", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to create promo code", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Promo code created successfully", promo)
+}
+
+func (h *PromoCodeHandler) UpdatePromoCode(c *gin.Context) {
+	var req models.UpdatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	promo, err := h.service.UpdatePromoCode(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput, models.ErrInvalidPlan:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		default:
+			h.notFoundOr(c, err, "Failed to update promo code")
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Promo code updated successfully", promo)
+}
+
+func (h *PromoCodeHandler) DeletePromoCode(c *gin.Context) {
+	if err := h.service.DeletePromoCode(c.Request.Context(), c.Param("id")); err != nil {
+		h.notFoundOr(c, err, "Failed to delete promo code")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Promo code deleted successfully", nil)
+}
+
+func (h *PromoCodeHandler) notFoundOr(c *gin.Context, err error, msg string) {
+	switch err {
+	case models.ErrPromoCodeNotFound:
+		utils.Error(c, http.StatusNotFound, err.Error(), err)
+		return
+	case pagination.ErrInvalidCursor:
+		utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		return
+	}
+	h.log.Error(msg, logger.ErrorField(err))
+	utils.Error(c, http.StatusInternalServerError, msg, err)
+}
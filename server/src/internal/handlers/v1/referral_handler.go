@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type ReferralHandler struct {
+	referralService interfaces.ReferralService
+	log             logger.Logger
+}
+
+func NewReferralHandler(referralService interfaces.ReferralService, log logger.Logger) *ReferralHandler {
+	return &ReferralHandler{
+		referralService: referralService,
+		log:             log,
+	}
+}
+
+func (h *ReferralHandler) GetReferrals(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	summary, err := h.referralService.GetReferrals(ctx, userID)
+	if err != nil {
+		h.log.Error("failed to get referrals", logger.ErrorField(err))
+		utils.Error(c, http.StatusInternalServerError, "Failed to get referrals", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Referrals retrieved successfully", summary)
+}
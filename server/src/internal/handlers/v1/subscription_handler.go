@@ -7,6 +7,7 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 	"github.com/imraushankr/bervity/server/src/internal/utils"
 )
 
@@ -36,7 +37,9 @@ func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
 	sub, err := h.subService.CreateSubscription(ctx, userID, &req)
 	if err != nil {
 		switch err {
-		case models.ErrInvalidInput, models.ErrInvalidPlan:
+		case models.ErrInvalidInput, models.ErrInvalidPlan,
+			models.ErrPromoCodeInvalid, models.ErrPromoCodeExpired, models.ErrPromoCodeNotApplicable,
+			models.ErrPromoCodeExhausted, models.ErrPromoCodeAlreadyUsed:
 			utils.Error(c, http.StatusBadRequest, err.Error(), err)
 		case models.ErrActiveSubscriptionExists:
 			utils.Error(c, http.StatusConflict, err.Error(), err)
@@ -84,8 +87,10 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	sub, err := h.subService.UpdateSubscription(ctx, userID, &req)
 	if err != nil {
 		switch err {
-		case models.ErrInvalidInput, models.ErrInvalidPlan:
+		case models.ErrInvalidInput, models.ErrInvalidPlan, models.ErrPlanIntervalMismatch:
 			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrPlanUnchanged, models.ErrSubscriptionTrialing:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
 		case models.ErrSubscriptionNotActive:
 			utils.Error(c, http.StatusNotFound, err.Error(), err)
 		case models.ErrPaymentFailed:
@@ -100,6 +105,30 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 	utils.Success(c, http.StatusOK, "Subscription updated successfully", sub)
 }
 
+func (h *SubscriptionHandler) PreviewPlanChange(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	plan := models.SubscriptionPlan(c.Query("plan"))
+
+	preview, err := h.subService.PreviewPlanChange(ctx, userID, plan)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidPlan, models.ErrPlanIntervalMismatch:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrPlanUnchanged, models.ErrSubscriptionTrialing:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		case models.ErrSubscriptionNotActive:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to preview plan change", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to preview plan change", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Plan change preview retrieved successfully", preview)
+}
+
 func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetString("user_id")
@@ -125,6 +154,232 @@ func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *SubscriptionHandler) StartTrial(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.StartTrialRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	trial, err := h.subService.StartTrial(ctx, userID, &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput, models.ErrInvalidPlan:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrUserNotVerified:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrActiveSubscriptionExists, models.ErrTrialUnavailable, models.ErrTrialPaymentMethodUsed:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		case models.ErrPaymentFailed:
+			utils.Error(c, http.StatusPaymentRequired, err.Error(), err)
+		default:
+			h.log.Error("failed to start trial", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to start trial", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Trial started successfully", trial)
+}
+
+func (h *SubscriptionHandler) GetTrial(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	trial, err := h.subService.GetTrial(ctx, userID)
+	if err != nil {
+		if err == models.ErrTrialNotFound {
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+			return
+		}
+		h.log.Error("failed to get trial", logger.ErrorField(err))
+		utils.Error(c, http.StatusInternalServerError, "Failed to get trial", err)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Trial retrieved successfully", trial)
+}
+
+func (h *SubscriptionHandler) SetTrialPaymentMethod(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.TrialPaymentMethodRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	trial, err := h.subService.SetTrialPaymentMethod(ctx, userID, req.Token)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrTrialNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrSubscriptionNotActive, models.ErrTrialPaymentMethodUsed:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		case models.ErrPaymentFailed:
+			utils.Error(c, http.StatusPaymentRequired, err.Error(), err)
+		default:
+			h.log.Error("failed to set trial payment method", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to set trial payment method", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Trial payment method updated successfully", trial)
+}
+
+func (h *SubscriptionHandler) CreateWorkspaceSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.CreateSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	sub, err := h.subService.CreateWorkspaceSubscription(ctx, c.Param("id"), userID, &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput, models.ErrInvalidPlan,
+			models.ErrPromoCodeInvalid, models.ErrPromoCodeExpired, models.ErrPromoCodeNotApplicable,
+			models.ErrPromoCodeExhausted, models.ErrPromoCodeAlreadyUsed:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrActiveSubscriptionExists:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		case models.ErrPaymentFailed:
+			utils.Error(c, http.StatusPaymentRequired, err.Error(), err)
+		default:
+			h.log.Error("failed to create workspace subscription", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to create subscription", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Subscription created successfully", sub)
+}
+
+func (h *SubscriptionHandler) GetWorkspaceSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	sub, err := h.subService.GetWorkspaceSubscription(ctx, c.Param("id"), userID)
+	if err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound, models.ErrSubscriptionNotActive:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to get workspace subscription", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get subscription", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subscription retrieved successfully", sub)
+}
+
+func (h *SubscriptionHandler) UpdateWorkspaceSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.UpdateSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	sub, err := h.subService.UpdateWorkspaceSubscription(ctx, c.Param("id"), userID, &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput, models.ErrInvalidPlan, models.ErrPlanIntervalMismatch:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrPlanUnchanged, models.ErrSubscriptionTrialing:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		case models.ErrWorkspaceNotFound, models.ErrSubscriptionNotActive:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrPaymentFailed:
+			utils.Error(c, http.StatusPaymentRequired, err.Error(), err)
+		default:
+			h.log.Error("failed to update workspace subscription", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to update subscription", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Subscription updated successfully", sub)
+}
+
+func (h *SubscriptionHandler) PreviewWorkspacePlanChange(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	plan := models.SubscriptionPlan(c.Query("plan"))
+
+	preview, err := h.subService.PreviewWorkspacePlanChange(ctx, c.Param("id"), userID, plan)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidPlan, models.ErrPlanIntervalMismatch:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrPlanUnchanged, models.ErrSubscriptionTrialing:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		case models.ErrWorkspaceNotFound, models.ErrSubscriptionNotActive:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to preview workspace plan change", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to preview plan change", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Plan change preview retrieved successfully", preview)
+}
+
+func (h *SubscriptionHandler) CancelWorkspaceSubscription(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.CancelSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	if err := h.subService.CancelWorkspaceSubscription(ctx, c.Param("id"), userID, &req); err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound, models.ErrSubscriptionNotActive:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to cancel workspace subscription", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to cancel subscription", err)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *SubscriptionHandler) GetPlans(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -135,19 +390,29 @@ func (h *SubscriptionHandler) GetPlans(c *gin.Context) {
 		return
 	}
 
-	utils.Success(c, http.StatusOK, "Subscription plans retrieved successfully", plans)
+	utils.List(c, http.StatusOK, "Subscription plans retrieved successfully", plans, pagination.All(len(plans)))
 }
 
 func (h *SubscriptionHandler) GetPaymentHistory(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetString("user_id")
 
-	payments, err := h.subService.GetPaymentHistory(ctx, userID)
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	payments, meta, err := h.subService.GetPaymentHistory(ctx, userID, page)
 	if err != nil {
-		h.log.Error("failed to get payment history", logger.ErrorField(err))
-		utils.Error(c, http.StatusInternalServerError, "Failed to get payment history", err)
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		default:
+			h.log.Error("failed to get payment history", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get payment history", err)
+		}
 		return
 	}
 
-	utils.Success(c, http.StatusOK, "Payment history retrieved successfully", payments)
+	utils.List(c, http.StatusOK, "Payment history retrieved successfully", payments, meta)
 }
\ No newline at end of file
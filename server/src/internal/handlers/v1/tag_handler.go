@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type TagHandler struct {
+	service interfaces.TagService
+	log     logger.Logger
+}
+
+func NewTagHandler(service interfaces.TagService, log logger.Logger) *TagHandler {
+	return &TagHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// ListTags lists the user's tags, or a workspace's with workspace_id
+func (h *TagHandler) ListTags(c *gin.Context) {
+	tags, err := h.service.ListTags(c.Request.Context(), c.GetString("user_id"), c.Query("workspace_id"))
+	if err != nil {
+		h.tagError(c, err, "Failed to list tags")
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Tags retrieved successfully", tags, pagination.All(len(tags)))
+}
+
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	if err := h.service.DeleteTag(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		h.tagError(c, err, "Failed to delete tag")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Tag deleted successfully", nil)
+}
+
+// SetURLTags replaces a link's tags, creating any that don't exist yet
+func (h *TagHandler) SetURLTags(c *gin.Context) {
+	var req models.SetURLTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	resp, err := h.service.SetURLTags(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		h.tagError(c, err, "Failed to set URL tags")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "URL tags updated successfully", resp)
+}
+
+func (h *TagHandler) tagError(c *gin.Context, err error, msg string) {
+	switch err {
+	case models.ErrInvalidInput:
+		utils.Error(c, http.StatusBadRequest, err.Error(), err)
+	case models.ErrForbidden:
+		utils.Error(c, http.StatusForbidden, err.Error(), err)
+	case models.ErrTagNotFound, models.ErrURLNotFound, models.ErrWorkspaceNotFound:
+		utils.Error(c, http.StatusNotFound, err.Error(), err)
+	default:
+		h.log.Error("tag request failed", logger.ErrorField(err), logger.String("action", msg))
+		utils.Error(c, http.StatusInternalServerError, msg, err)
+	}
+}
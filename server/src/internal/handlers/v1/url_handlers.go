@@ -9,18 +9,24 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 	"github.com/imraushankr/bervity/server/src/internal/utils"
 )
 
+// maxBulkBodySize caps bulk requests, JSON or CSV
+const maxBulkBodySize = 10 << 20
+
 type URLHandler struct {
-	urlService interfaces.URLService
-	log        logger.Logger
+	urlService    interfaces.URLService
+	importService interfaces.URLImportService
+	log           logger.Logger
 }
 
-func NewURLHandler(urlService interfaces.URLService, log logger.Logger) *URLHandler {
+func NewURLHandler(urlService interfaces.URLService, importService interfaces.URLImportService, log logger.Logger) *URLHandler {
 	return &URLHandler{
-		urlService: urlService,
-		log:        log,
+		urlService:    urlService,
+		importService: importService,
+		log:           log,
 	}
 }
 
@@ -43,6 +49,12 @@ func (h *URLHandler) CreateURL(c *gin.Context) {
 		switch err {
 		case models.ErrInvalidInput, models.ErrShortCodeTaken:
 			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrUnauthorized:
+			utils.Error(c, http.StatusUnauthorized, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
 		case models.ErrInsufficientCredits:
 			utils.Error(c, http.StatusPaymentRequired, err.Error(), err)
 		default:
@@ -55,6 +67,87 @@ func (h *URLHandler) CreateURL(c *gin.Context) {
 	utils.Success(c, http.StatusCreated, "URL created successfully", resp)
 }
 
+// CreateURLs creates many links at once from a JSON body, a CSV body
+// (text/csv) or a CSV file uploaded as "file". Small requests are answered
+// with each row's result; larger ones are queued and answered with 202 and
+// an import to poll at GET /urls/bulk/:id.
+func (h *URLHandler) CreateURLs(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkBodySize)
+
+	var imp *models.URLImport
+	var err error
+	switch c.ContentType() {
+	case "multipart/form-data":
+		header, ferr := c.FormFile("file")
+		if ferr != nil {
+			h.log.Debug("missing CSV file", logger.ErrorField(ferr))
+			utils.Error(c, http.StatusBadRequest, "A CSV file is required", models.ErrInvalidInput)
+			return
+		}
+		file, ferr := header.Open()
+		if ferr != nil {
+			utils.Error(c, http.StatusBadRequest, "A CSV file is required", models.ErrInvalidInput)
+			return
+		}
+		defer file.Close()
+		imp, err = h.importService.ImportCSV(ctx, userID, c.PostForm("workspace_id"), file)
+	case "text/csv":
+		imp, err = h.importService.ImportCSV(ctx, userID, c.Query("workspace_id"), c.Request.Body)
+	default:
+		var req models.CreateBulkURLRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.log.Debug("invalid request body", logger.ErrorField(err))
+			utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+			return
+		}
+		if err := req.Validate(); err != nil {
+			utils.ValidationError(c, utils.GetValidationErrors(err))
+			return
+		}
+		imp, err = h.importService.CreateURLs(ctx, userID, &req)
+	}
+	if err != nil {
+		switch err {
+		case models.ErrInvalidCSV, models.ErrTooManyRows:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrInsufficientCredits:
+			utils.Error(c, http.StatusPaymentRequired, err.Error(), err)
+		default:
+			h.log.Error("failed to create URLs", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to create URLs", err)
+		}
+		return
+	}
+
+	if imp.Status == models.URLImportPending {
+		utils.Success(c, http.StatusAccepted, "Bulk import queued", imp)
+		return
+	}
+	utils.Success(c, http.StatusCreated, "Bulk URLs processed", imp)
+}
+
+func (h *URLHandler) GetImport(c *gin.Context) {
+	imp, err := h.importService.GetImport(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		switch err {
+		case models.ErrImportNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to get URL import", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get URL import", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Bulk import retrieved successfully", imp)
+}
+
 func (h *URLHandler) GetURL(c *gin.Context) {
 	ctx := c.Request.Context()
 	shortCode := c.Param("code")
@@ -77,46 +170,109 @@ func (h *URLHandler) GetUserURLs(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetString("user_id")
 
-	// Convert limit and offset from string to int
-	limitStr := c.DefaultQuery("limit", "10")
-	offsetStr := c.DefaultQuery("offset", "0")
+	filter, ok := urlFilter(c)
+	if !ok {
+		return
+	}
 
-	limit, err := strconv.Atoi(limitStr)
+	urls, page, err := h.urlService.GetUserURLs(ctx, userID, filter)
 	if err != nil {
-		utils.Error(c, http.StatusBadRequest, "Invalid limit parameter", err)
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		default:
+			h.log.Error("failed to get user URLs", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get URLs", err)
+		}
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		utils.Error(c, http.StatusBadRequest, "Invalid offset parameter", err)
+	utils.List(c, http.StatusOK, "User URLs retrieved successfully", urls, page)
+}
+
+func (h *URLHandler) GetWorkspaceURLs(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	filter, ok := urlFilter(c)
+	if !ok {
 		return
 	}
 
-	urls, err := h.urlService.GetUserURLs(ctx, userID, limit, offset)
+	urls, page, err := h.urlService.GetWorkspaceURLs(ctx, c.Param("id"), userID, filter)
 	if err != nil {
-		h.log.Error("failed to get user URLs", logger.ErrorField(err))
-		utils.Error(c, http.StatusInternalServerError, "Failed to get URLs", err)
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to get workspace URLs", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get URLs", err)
+		}
 		return
 	}
 
-	utils.Success(c, http.StatusOK, "User URLs retrieved successfully", urls)
+	utils.List(c, http.StatusOK, "Workspace URLs retrieved successfully", urls, page)
+}
+
+// urlFilter reads the search, filter, sort and page parameters of a link
+// list. It answers 400 and reports false if one is malformed.
+func urlFilter(c *gin.Context) (*models.URLFilter, bool) {
+	page, ok := pageParams(c)
+	if !ok {
+		return nil, false
+	}
+
+	from, ok := optionalTime(c, "from")
+	if !ok {
+		return nil, false
+	}
+	to, ok := optionalTime(c, "to")
+	if !ok {
+		return nil, false
+	}
+
+	filter := &models.URLFilter{
+		Query:    c.Query("q"),
+		Tags:     c.QueryArray("tag"),
+		FolderID: c.Query("folder"),
+		Status:   models.URLStatus(c.Query("status")),
+		Domain:   c.Query("domain"),
+		From:     from,
+		To:       to,
+		Sort:     c.Query("sort"),
+		Page:     page,
+	}
+	if err := filter.Check(); err != nil {
+		utils.Error(c, http.StatusBadRequest, "Invalid status or sort parameter", err)
+		return nil, false
+	}
+	return filter, true
 }
 
 func (h *URLHandler) UpdateURL(c *gin.Context) {
 	ctx := c.Request.Context()
-	// userID := c.GetString("user_id")
-	var url models.URL
+	userID := c.GetString("user_id")
+	var req models.UpdateURLRequest
 
-	if err := c.ShouldBindJSON(&url); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil {
 		h.log.Debug("invalid request body", logger.ErrorField(err))
 		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
 		return
 	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
 
-	resp, err := h.urlService.UpdateURL(ctx, &url)
+	resp, err := h.urlService.UpdateURL(ctx, c.Param("id"), userID, &req)
 	if err != nil {
 		switch err {
+		case models.ErrInvalidInput, models.ErrShortCodeTaken:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
 		case models.ErrURLNotFound:
 			utils.Error(c, http.StatusNotFound, err.Error(), err)
 		case models.ErrForbidden:
@@ -131,6 +287,57 @@ func (h *URLHandler) UpdateURL(c *gin.Context) {
 	utils.Success(c, http.StatusOK, "URL updated successfully", resp)
 }
 
+func (h *URLHandler) GetRevisions(c *gin.Context) {
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	revisions, meta, err := h.urlService.GetURLRevisions(c.Request.Context(), c.Param("id"), c.GetString("user_id"), page)
+	if err != nil {
+		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		case models.ErrURLNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to get URL revisions", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get URL revisions", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "URL revisions retrieved successfully", revisions, meta)
+}
+
+func (h *URLHandler) RestoreRevision(c *gin.Context) {
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number < 1 {
+		utils.Error(c, http.StatusBadRequest, "Invalid revision number", models.ErrInvalidInput)
+		return
+	}
+
+	resp, err := h.urlService.RestoreURLRevision(c.Request.Context(), c.Param("id"), c.GetString("user_id"), number)
+	if err != nil {
+		switch err {
+		case models.ErrShortCodeTaken:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrURLNotFound, models.ErrURLRevisionNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to restore URL revision", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to restore URL revision", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "URL revision restored successfully", resp)
+}
+
 func (h *URLHandler) DeleteURL(c *gin.Context) {
 	ctx := c.Request.Context()
 	userID := c.GetString("user_id")
@@ -172,6 +379,10 @@ func (h *URLHandler) Redirect(c *gin.Context) {
 			utils.Error(c, http.StatusNotFound, "Short URL not found", err)
 			return
 		}
+		if err == models.ErrURLTakenDown {
+			utils.Error(c, http.StatusGone, "Short URL has been taken down", err)
+			return
+		}
 		utils.Error(c, http.StatusInternalServerError, "Failed to redirect", err)
 		return
 	}
@@ -180,7 +391,9 @@ func (h *URLHandler) Redirect(c *gin.Context) {
 		logger.String("shortCode", shortCode),
 		logger.String("originalURL", originalURL))
 
-	c.Redirect(http.StatusMovedPermanently, originalURL)
+	// Not a 301: browsers and CDNs cache those indefinitely, and a link's
+	// destination can be edited or restored later
+	c.Redirect(http.StatusFound, originalURL)
 }
 
 func (h *URLHandler) GetAnalytics(c *gin.Context) {
@@ -203,9 +416,16 @@ func (h *URLHandler) GetAnalytics(c *gin.Context) {
 		return
 	}
 
-	analytics, err := h.urlService.GetURLAnalytics(ctx, urlID, userID, from, to)
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+
+	clicks, meta, err := h.urlService.GetURLAnalytics(ctx, urlID, userID, from, to, page)
 	if err != nil {
 		switch err {
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
 		case models.ErrURLNotFound:
 			utils.Error(c, http.StatusNotFound, err.Error(), err)
 		case models.ErrForbidden:
@@ -217,5 +437,5 @@ func (h *URLHandler) GetAnalytics(c *gin.Context) {
 		return
 	}
 
-	utils.Success(c, http.StatusOK, "Analytics retrieved successfully", analytics)
+	utils.List(c, http.StatusOK, "Analytics retrieved successfully", clicks, meta)
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package v1
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/imraushankr/bervity/server/src/internal/models"
@@ -46,6 +47,10 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		utils.ValidationError(c, utils.GetValidationErrors(err))
 		return
 	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
 
 	user, err := h.service.FindUser(c.Request.Context(), userID)
 	if err != nil {
@@ -55,6 +60,10 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	user.FirstName = req.FirstName
 	user.LastName = req.LastName
+	if req.BillingAddress != nil {
+		user.BillingAddress = *req.BillingAddress
+		user.BillingAddress.Country = strings.ToUpper(user.BillingAddress.Country)
+	}
 
 	if err := h.service.UpdateUser(c.Request.Context(), user); err != nil {
 		utils.Error(c, http.StatusInternalServerError, "Failed to update profile", err)
@@ -90,12 +99,71 @@ func (h *UserHandler) UploadAvatar(c *gin.Context) {
 	})
 }
 
-func (h *UserHandler) DeleteAccount(c *gin.Context) {
+func (h *UserHandler) RequestEmailChange(c *gin.Context) {
 	userID := c.GetString("user_id")
-	if err := h.service.DeleteUser(c.Request.Context(), userID); err != nil {
-		utils.Error(c, http.StatusInternalServerError, "Failed to delete account", err)
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	if err := h.service.RequestEmailChange(c.Request.Context(), userID, &req); err != nil {
+		switch err {
+		case models.ErrInvalidCredentials:
+			utils.Error(c, http.StatusUnauthorized, "Password is incorrect", err)
+		case models.ErrUserNotVerified:
+			utils.Error(c, http.StatusForbidden, "Verify your current email first", err)
+		case models.ErrEmailUnchanged:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrEmailAlreadyExists:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to start email change", err)
+		}
 		return
 	}
 
-	utils.Success(c, http.StatusOK, "Account deleted successfully", nil)
+	utils.Success(c, http.StatusAccepted, "A confirmation link has been sent to the new email address", nil)
+}
+
+// ShowEmailChange renders the page the confirmation email links to. It
+// doesn't check or use the token.
+func (h *UserHandler) ShowEmailChange(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.Error(c, http.StatusBadRequest, "Invalid or expired token", models.ErrInvalidVerificationToken)
+		return
+	}
+
+	renderConfirmPage(c, h.log, confirmPage{
+		Title:   "Confirm email change",
+		Message: "Confirm the new email address for your account.",
+		Button:  "Confirm email",
+		Action:  "confirm",
+		Token:   token,
+	})
+}
+
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	var req models.ConfirmEmailChangeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	user, err := h.service.ConfirmEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidVerificationToken:
+			utils.Error(c, http.StatusBadRequest, "Invalid or expired token", err)
+		case models.ErrEmailAlreadyExists:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to confirm email change", err)
+		}
+		return
+	}
+
+	user.Sanitize()
+	utils.Success(c, http.StatusOK, "Email changed successfully", user)
 }
@@ -0,0 +1,188 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+// WebhookEndpointHandler manages the endpoints users register to receive
+// events. Incoming payment webhooks are handled by WebhookHandler.
+type WebhookEndpointHandler struct {
+	service interfaces.WebhookService
+	log     logger.Logger
+}
+
+func NewWebhookEndpointHandler(service interfaces.WebhookService, log logger.Logger) *WebhookEndpointHandler {
+	return &WebhookEndpointHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+func (h *WebhookEndpointHandler) CreateEndpoint(c *gin.Context) {
+	var req models.CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	endpoint, err := h.service.CreateEndpoint(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidWebhookURL, models.ErrUnknownEventType:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrWebhookEndpointLimit:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to create webhook endpoint", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to create webhook endpoint", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Webhook endpoint created successfully", endpoint)
+}
+
+func (h *WebhookEndpointHandler) ListEndpoints(c *gin.Context) {
+	endpoints, err := h.service.ListEndpoints(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		h.log.Error("failed to list webhook endpoints", logger.ErrorField(err))
+		utils.Error(c, http.StatusInternalServerError, "Failed to list webhook endpoints", err)
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Webhook endpoints retrieved successfully", endpoints, pagination.All(len(endpoints)))
+}
+
+func (h *WebhookEndpointHandler) GetEndpoint(c *gin.Context) {
+	endpoint, err := h.service.GetEndpoint(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		switch err {
+		case models.ErrWebhookEndpointNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to get webhook endpoint", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get webhook endpoint", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Webhook endpoint retrieved successfully", endpoint)
+}
+
+func (h *WebhookEndpointHandler) UpdateEndpoint(c *gin.Context) {
+	var req models.UpdateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	endpoint, err := h.service.UpdateEndpoint(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidWebhookURL, models.ErrUnknownEventType:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrWebhookEndpointNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to update webhook endpoint", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to update webhook endpoint", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Webhook endpoint updated successfully", endpoint)
+}
+
+func (h *WebhookEndpointHandler) DeleteEndpoint(c *gin.Context) {
+	err := h.service.DeleteEndpoint(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		switch err {
+		case models.ErrWebhookEndpointNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to delete webhook endpoint", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Webhook endpoint deleted successfully", nil)
+}
+
+func (h *WebhookEndpointHandler) RotateSecret(c *gin.Context) {
+	endpoint, err := h.service.RotateSecret(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		switch err {
+		case models.ErrWebhookEndpointNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to rotate webhook secret", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to rotate webhook secret", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Webhook secret rotated successfully", endpoint)
+}
+
+// ListDeliveries returns the endpoint's delivery log, newest first,
+// optionally filtered by status.
+func (h *WebhookEndpointHandler) ListDeliveries(c *gin.Context) {
+	page, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	status := models.WebhookDeliveryStatus(c.Query("status"))
+
+	deliveries, meta, err := h.service.ListDeliveries(c.Request.Context(), c.GetString("user_id"), c.Param("id"), status, page)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, "Invalid status parameter", err)
+		case pagination.ErrInvalidCursor:
+			utils.Error(c, http.StatusBadRequest, "Invalid cursor parameter", err)
+		case models.ErrWebhookEndpointNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		default:
+			h.log.Error("failed to list webhook deliveries", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to list webhook deliveries", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Webhook deliveries retrieved successfully", deliveries, meta)
+}
+
+func (h *WebhookEndpointHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.service.Redeliver(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		switch err {
+		case models.ErrWebhookEndpointNotFound, models.ErrWebhookDeliveryNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrWebhookEndpointDisabled:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to redeliver webhook", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to redeliver webhook", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusAccepted, "Webhook delivery queued", delivery)
+}
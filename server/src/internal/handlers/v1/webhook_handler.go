@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+// maxWebhookBody caps the size of a provider webhook payload.
+const maxWebhookBody = 1 << 20
+
+type WebhookHandler struct {
+	service interfaces.PaymentWebhookService
+	log     logger.Logger
+}
+
+func NewWebhookHandler(service interfaces.PaymentWebhookService, log logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		log:     log,
+	}
+}
+
+// HandlePayment receives payment provider events. The signature covers the
+// raw body, so it must be read before anything parses it.
+func (h *WebhookHandler) HandlePayment(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody)
+	payload, err := c.GetRawData()
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "Failed to read request body", err)
+		return
+	}
+
+	if err := h.service.HandleWebhook(c.Request.Context(), payload, c.GetHeader("Stripe-Signature")); err != nil {
+		switch err {
+		case models.ErrInvalidWebhookSignature:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, "Invalid webhook payload", err)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "Failed to store webhook event", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Webhook received", nil)
+}
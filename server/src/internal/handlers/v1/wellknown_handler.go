@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+)
+
+type WellKnownHandler struct {
+	auth *auth.Auth
+}
+
+func NewWellKnownHandler(auth *auth.Auth) *WellKnownHandler {
+	return &WellKnownHandler{auth: auth}
+}
+
+// JWKS publishes the public keys other services use to verify Brevity tokens.
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.auth.JWKS())
+}
@@ -0,0 +1,331 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/utils"
+)
+
+type WorkspaceHandler struct {
+	workspaceService interfaces.WorkspaceService
+	log              logger.Logger
+}
+
+func NewWorkspaceHandler(workspaceService interfaces.WorkspaceService, log logger.Logger) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+		log:              log,
+	}
+}
+
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.CreateWorkspaceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(ctx, userID, &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		default:
+			h.log.Error("failed to create workspace", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to create workspace", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Workspace created successfully", workspace)
+}
+
+func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	workspaces, err := h.workspaceService.ListWorkspaces(ctx, userID)
+	if err != nil {
+		h.log.Error("failed to list workspaces", logger.ErrorField(err))
+		utils.Error(c, http.StatusInternalServerError, "Failed to list workspaces", err)
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Workspaces retrieved successfully", workspaces, pagination.All(len(workspaces)))
+}
+
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	workspace, err := h.workspaceService.GetWorkspace(ctx, c.Param("id"), userID)
+	if err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to get workspace", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to get workspace", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Workspace retrieved successfully", workspace)
+}
+
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.UpdateWorkspaceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	workspace, err := h.workspaceService.UpdateWorkspace(ctx, c.Param("id"), userID, &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to update workspace", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to update workspace", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Workspace updated successfully", workspace)
+}
+
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	if err := h.workspaceService.DeleteWorkspace(ctx, c.Param("id"), userID); err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrWorkspaceHasSubscription:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to delete workspace", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to delete workspace", err)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	members, err := h.workspaceService.ListMembers(ctx, c.Param("id"), userID)
+	if err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to list workspace members", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to list members", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Members retrieved successfully", members, pagination.All(len(members)))
+}
+
+func (h *WorkspaceHandler) UpdateMemberRole(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.UpdateMemberRoleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	member, err := h.workspaceService.UpdateMemberRole(ctx, c.Param("id"), userID, c.Param("userId"), &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrWorkspaceNotFound, models.ErrMemberNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrOwnerRoleChange:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to update workspace member", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to update member", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Member updated successfully", member)
+}
+
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	if err := h.workspaceService.RemoveMember(ctx, c.Param("id"), userID, c.Param("userId")); err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound, models.ErrMemberNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrOwnerRoleChange:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to remove workspace member", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to remove member", err)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WorkspaceHandler) InviteMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.InviteMemberRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.ValidationError(c, utils.GetValidationErrors(err))
+		return
+	}
+
+	invitation, err := h.workspaceService.InviteMember(ctx, c.Param("id"), userID, &req)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInput:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrAlreadyMember:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to invite workspace member", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to send invitation", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+func (h *WorkspaceHandler) ListInvitations(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	invitations, err := h.workspaceService.ListInvitations(ctx, c.Param("id"), userID)
+	if err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to list workspace invitations", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to list invitations", err)
+		}
+		return
+	}
+
+	utils.List(c, http.StatusOK, "Invitations retrieved successfully", invitations, pagination.All(len(invitations)))
+}
+
+func (h *WorkspaceHandler) RevokeInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+
+	if err := h.workspaceService.RevokeInvitation(ctx, c.Param("id"), userID, c.Param("invitationId")); err != nil {
+		switch err {
+		case models.ErrWorkspaceNotFound, models.ErrInvitationNotFound:
+			utils.Error(c, http.StatusNotFound, err.Error(), err)
+		case models.ErrForbidden:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		default:
+			h.log.Error("failed to revoke workspace invitation", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to revoke invitation", err)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WorkspaceHandler) AcceptInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetString("user_id")
+	var req models.AcceptInvitationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.log.Debug("invalid request body", logger.ErrorField(err))
+		utils.Error(c, http.StatusBadRequest, "Invalid request body", models.ErrInvalidInput)
+		return
+	}
+
+	member, err := h.workspaceService.AcceptInvitation(ctx, userID, req.Token)
+	if err != nil {
+		switch err {
+		case models.ErrInvalidInvitation:
+			utils.Error(c, http.StatusBadRequest, err.Error(), err)
+		case models.ErrInvitationEmailMismatch:
+			utils.Error(c, http.StatusForbidden, err.Error(), err)
+		case models.ErrAlreadyMember:
+			utils.Error(c, http.StatusConflict, err.Error(), err)
+		default:
+			h.log.Error("failed to accept workspace invitation", logger.ErrorField(err))
+			utils.Error(c, http.StatusInternalServerError, "Failed to accept invitation", err)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Invitation accepted successfully", member)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/imraushankr/bervity/server/src/configs"
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
 )
 
@@ -53,18 +55,92 @@ func JWTAuth(authService *auth.Auth, cfg *configs.Config, log logger.Logger) gin
 			return
 		}
 
-		// Set user context
+		// Reject tokens whose account was shut off after they were issued
+		user, err := authService.CheckAccount(c.Request.Context(), claims.UserId)
+		if err != nil {
+			if !errors.Is(err, models.ErrInvalidToken) {
+				log.Error("Failed to check token account", logger.ErrorField(err))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+					Error: "Internal server error",
+				})
+				return
+			}
+			log.Warn("Token for closed account", logger.String("user_id", claims.UserId))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error: "Invalid token",
+			})
+			return
+		}
+
+		// Set user context. The stored role wins over the token's, so a
+		// demoted admin loses access right away.
+		role := claims.Role
+		if user != nil {
+			role = string(user.Role)
+		}
 		c.Set("user_id", claims.UserId)
-		c.Set("user_role", claims.Role)
+		c.Set("user_role", role)
 		log.Debug("User authenticated", 
 			logger.String("user_id", claims.UserId),
-			logger.String("role", claims.Role))
+			logger.String("role", role))
+
+		c.Next()
+	}
+}
+
+// ActiveAccountCheck rejects access tokens whose account is gone, suspended,
+// inactive or scheduled for deletion.
+func ActiveAccountCheck(userRepo interfaces.UserRepository) auth.AccountCheck {
+	return func(ctx context.Context, userID string) (*models.User, error) {
+		user, err := userRepo.FindUserByID(ctx, userID)
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, models.ErrInvalidToken
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.DeletionScheduledAt != nil || user.SuspendedAt != nil || !user.IsActive {
+			return nil, models.ErrInvalidToken
+		}
+		return user, nil
+	}
+}
+
+// OptionalJWTAuth identifies the user when a valid access token is present but
+// lets anonymous requests through, for routes that serve both.
+func OptionalJWTAuth(authService *auth.Auth, cfg *configs.Config, log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := extractToken(c, cfg.JWT.SecureCookie)
+		if tokenString == "" {
+			c.Next()
+			return
+		}
 
+		claims, err := authService.VerifyAccessToken(tokenString)
+		if err != nil || claims == nil || claims.UserId == "" {
+			log.Debug("Ignoring invalid token on optional auth route", logger.NamedError("error", err))
+			c.Next()
+			return
+		}
+		user, err := authService.CheckAccount(c.Request.Context(), claims.UserId)
+		if err != nil {
+			log.Debug("Ignoring token for closed account on optional auth route", logger.NamedError("error", err))
+			c.Next()
+			return
+		}
+
+		role := claims.Role
+		if user != nil {
+			role = string(user.Role)
+		}
+		c.Set("user_id", claims.UserId)
+		c.Set("user_role", role)
 		c.Next()
 	}
 }
 
-// RoleAuth creates a middleware to check user roles
+// RoleAuth creates a middleware to check user roles. It runs after JWTAuth,
+// which sets user_role from the stored user rather than the token.
 func RoleAuth(allowedRoles ...models.Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get logger from Gin context if available, otherwise create new one
@@ -162,12 +238,32 @@ func RefreshTokenAuth(authService *auth.Auth, cfg *configs.Config, log logger.Lo
 			return
 		}
 
-		// Set user context
+		// Reject tokens whose account was shut off after they were issued
+		user, err := authService.CheckAccount(c.Request.Context(), claims.UserId)
+		if err != nil {
+			if !errors.Is(err, models.ErrInvalidToken) {
+				log.Error("Failed to check token account", logger.ErrorField(err))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+					Error: "Internal server error",
+				})
+				return
+			}
+			log.Warn("Token for closed account", logger.String("user_id", claims.UserId))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+				Error: "Invalid token",
+			})
+			return
+		}
+
+		role := claims.Role
+		if user != nil {
+			role = string(user.Role)
+		}
 		c.Set("user_id", claims.UserId)
-		c.Set("user_role", claims.Role)
+		c.Set("user_role", role)
 		log.Debug("Refresh token validated", 
 			logger.String("user_id", claims.UserId),
-			logger.String("role", claims.Role))
+			logger.String("role", role))
 
 		c.Next()
 	}
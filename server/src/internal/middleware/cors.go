@@ -39,6 +39,7 @@ func CORSMiddleware(cfg *configs.Config) gin.HandlerFunc {
 			"origin",
 			"Cache-Control",
 			"X-Requested-With",
+			"X-Request-ID",
 		}, ","))
 		c.Writer.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.CORS.AllowMethods, ","))
 		c.Writer.Header().Set("Access-Control-Max-Age", cfg.CORS.MaxAge)
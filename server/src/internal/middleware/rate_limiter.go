@@ -1 +1,79 @@
 package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	requests int
+	window   time.Duration
+	clients  map[string]*rateWindow
+	sweepAt  time.Time
+}
+
+// RateLimit allows each client IP at most `requests` calls per `window` on the
+// routes it is attached to. State is kept in memory per server instance.
+func RateLimit(requests int, window time.Duration) gin.HandlerFunc {
+	if requests <= 0 || window <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	rl := &rateLimiter{
+		requests: requests,
+		window:   window,
+		clients:  make(map[string]*rateWindow),
+	}
+
+	return func(c *gin.Context) {
+		allowed, retryAfter := rl.allow(c.ClientIP() + "|" + c.FullPath())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.ErrorResponse{
+				Error: "Too many requests, please try again later",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+
+	// Drop expired windows once per window so the map doesn't grow unbounded
+	if now.After(rl.sweepAt) {
+		for k, w := range rl.clients {
+			if now.After(w.resetAt) {
+				delete(rl.clients, k)
+			}
+		}
+		rl.sweepAt = now.Add(rl.window)
+	}
+
+	w, ok := rl.clients[key]
+	if !ok || now.After(w.resetAt) {
+		rl.clients[key] = &rateWindow{count: 1, resetAt: now.Add(rl.window)}
+		return true, 0
+	}
+
+	if w.count >= rl.requests {
+		return false, w.resetAt.Sub(now)
+	}
+	w.count++
+	return true, 0
+}
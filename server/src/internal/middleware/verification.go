@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// Actions that can be restricted to verified accounts via verification.blocked_actions.
+const (
+	ActionCreateURL    = "create_url"
+	ActionCustomCode   = "custom_code"
+	ActionSubscription = "subscription"
+	ActionPromoCode    = "promo_code"
+	ActionBulkURL      = "bulk_url"
+)
+
+// VerificationPolicy decides what signed-in but unverified users may do.
+type VerificationPolicy struct {
+	userRepo interfaces.UserRepository
+	urlRepo  interfaces.URLRepository
+	cfg      *configs.VerificationConfig
+	log      logger.Logger
+}
+
+func NewVerificationPolicy(userRepo interfaces.UserRepository, urlRepo interfaces.URLRepository, cfg *configs.VerificationConfig, log logger.Logger) *VerificationPolicy {
+	return &VerificationPolicy{
+		userRepo: userRepo,
+		urlRepo:  urlRepo,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// Require blocks an action for unverified users when the policy says so.
+// Anonymous requests are left to the route's own checks.
+func (p *VerificationPolicy) Require(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		if userID == "" {
+			c.Next()
+			return
+		}
+
+		user, err := p.userRepo.FindUserByID(c.Request.Context(), userID)
+		if err != nil {
+			p.log.Error("failed to load user for verification policy", logger.ErrorField(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Internal server error",
+			})
+			return
+		}
+		if user.IsVerified {
+			c.Next()
+			return
+		}
+
+		if action == ActionCreateURL {
+			p.checkURLCreation(c, userID)
+			return
+		}
+
+		// Editing a link is only restricted when it picks a new short code,
+		// either from the body or by restoring a revision with another code
+		if action == ActionCustomCode {
+			if p.isBlocked(ActionCustomCode) && (requestsField(c, "short_code") || p.restoresShortCode(c)) {
+				p.deny(c, userID, ActionCustomCode, "Please verify your email address to use custom short codes")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if p.isBlocked(action) {
+			p.deny(c, userID, action, "Please verify your email address to use this feature")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (p *VerificationPolicy) checkURLCreation(c *gin.Context, userID string) {
+	if p.isBlocked(ActionCreateURL) {
+		p.deny(c, userID, ActionCreateURL, "Please verify your email address to create short URLs")
+		return
+	}
+
+	if p.isBlocked(ActionCustomCode) && requestsField(c, "custom_code") {
+		p.deny(c, userID, ActionCustomCode, "Please verify your email address to use custom short codes")
+		return
+	}
+
+	if p.cfg.UnverifiedURLLimit > 0 {
+		count, err := p.urlRepo.CountByUser(c.Request.Context(), userID)
+		if err != nil {
+			p.log.Error("failed to count user URLs", logger.ErrorField(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error: "Internal server error",
+			})
+			return
+		}
+		if count >= p.cfg.UnverifiedURLLimit {
+			p.deny(c, userID, ActionCreateURL, "Please verify your email address to create more short URLs")
+			return
+		}
+	}
+
+	c.Next()
+}
+
+// restoresShortCode reports whether restoring the revision in the route
+// would change the link's short code. Lookup failures are left to the handler.
+func (p *VerificationPolicy) restoresShortCode(c *gin.Context) bool {
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		return false
+	}
+	ctx := c.Request.Context()
+	url, err := p.urlRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return false
+	}
+	revision, err := p.urlRepo.GetRevision(ctx, url.ID, number)
+	if err != nil {
+		return false
+	}
+	return revision.ShortCode != url.ShortCode
+}
+
+func (p *VerificationPolicy) isBlocked(action string) bool {
+	for _, blocked := range p.cfg.BlockedActions {
+		if blocked == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *VerificationPolicy) deny(c *gin.Context, userID, action, message string) {
+	p.log.Debug("Action blocked for unverified user",
+		logger.String("user_id", userID),
+		logger.String("action", action))
+	c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+		Error: message,
+	})
+}
+
+// requestsField peeks at the JSON body for a non-empty string field and
+// restores the body so the handler can still bind it.
+func requestsField(c *gin.Context, field string) bool {
+	if c.Request.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return false
+	}
+	value, _ := req[field].(string)
+	return value != ""
+}
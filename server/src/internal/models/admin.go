@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+// UserSearchFilter narrows the admin user search. Query matches the email,
+// username or name.
+type UserSearchFilter struct {
+	Query  string
+	Role   Role
+	Status string // "active", "suspended" or "deleted"; empty for all
+	Page   pagination.Params
+}
+
+// Every admin request carries a reason, which goes into the audit log.
+
+type SetUserStatusRequest struct {
+	Active bool   `json:"active"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (r *SetUserStatusRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type SetUserRoleRequest struct {
+	Role   Role   `json:"role" validate:"required,oneof=admin user"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (r *SetUserRoleRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// GrantCreditsRequest adds a new batch of credits to a user's balance
+type GrantCreditsRequest struct {
+	Amount    int        `json:"amount" validate:"required,min=1"`
+	Type      CreditType `json:"type" validate:"omitempty,oneof=free trial paid promo referral"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Reason    string     `json:"reason" validate:"required,max=500"`
+}
+
+func (r *GrantCreditsRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// AdjustCreditsRequest corrects a user's balance. A positive amount adds
+// credits that never expire; a negative amount removes them, soonest-expiring
+// first.
+type AdjustCreditsRequest struct {
+	Amount int    `json:"amount" validate:"required,ne=0"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (r *AdjustCreditsRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// OverrideSubscriptionRequest puts a user on a plan until ExpiresAt without
+// charging them. A paid subscription can only be extended.
+type OverrideSubscriptionRequest struct {
+	Plan      SubscriptionPlan `json:"plan" validate:"required,max=50"`
+	ExpiresAt time.Time        `json:"expires_at" validate:"required"`
+	Reason    string           `json:"reason" validate:"required,max=500"`
+}
+
+func (r *OverrideSubscriptionRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type TakeDownURLRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (r *TakeDownURLRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type RestoreURLRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (r *RestoreURLRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	auditSid, _ = shortid.New(1, shortid.DefaultABC, 6847)
+)
+
+// AuditAction names something recorded in the audit log
+type AuditAction string
+
+const (
+	AuditURLCreate  AuditAction = "url.create"
+	AuditURLUpdate  AuditAction = "url.update"
+	AuditURLDelete  AuditAction = "url.delete"
+	AuditURLRestore AuditAction = "url.restore"
+
+	AuditLogin          AuditAction = "auth.login"
+	AuditLoginFailed    AuditAction = "auth.login_failed"
+	AuditPasswordChange AuditAction = "auth.password_change"
+	AuditPasswordReset  AuditAction = "auth.password_reset"
+
+	AuditSubscriptionCreate AuditAction = "subscription.create"
+	AuditSubscriptionChange AuditAction = "subscription.change_plan"
+	AuditSubscriptionCancel AuditAction = "subscription.cancel"
+	AuditTrialStart         AuditAction = "subscription.trial_start"
+
+	AuditCreditGrant AuditAction = "credits.grant"
+
+	AdminSuspendUser          AuditAction = "admin.user.suspend"
+	AdminReactivateUser       AuditAction = "admin.user.reactivate"
+	AdminChangeRole           AuditAction = "admin.user.change_role"
+	AdminGrantCredits         AuditAction = "admin.credits.grant"
+	AdminAdjustCredits        AuditAction = "admin.credits.adjust"
+	AdminOverrideSubscription AuditAction = "admin.subscription.override"
+	AdminTakeDownURL          AuditAction = "admin.url.take_down"
+	AdminRestoreURL           AuditAction = "admin.url.restore"
+)
+
+// AuditActorType says who performed an action
+type AuditActorType string
+
+const (
+	AuditActorUser      AuditActorType = "user"
+	AuditActorAdmin     AuditActorType = "admin"
+	AuditActorSystem    AuditActorType = "system"
+	AuditActorAnonymous AuditActorType = "anonymous"
+)
+
+// Audit target types
+const (
+	AuditTargetUser         = "user"
+	AuditTargetURL          = "url"
+	AuditTargetSubscription = "subscription"
+	AuditTargetWorkspace    = "workspace"
+)
+
+// AuditEvent records one action. Before and After hold only the fields the
+// action changed; Metadata carries anything else worth keeping, such as the
+// plan a subscription was created on. The database rejects updates.
+type AuditEvent struct {
+	ID         string          `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	ActorID    string          `json:"actor_id,omitempty" gorm:"type:varchar(20);index"`
+	ActorType  AuditActorType  `json:"actor_type" gorm:"type:varchar(20);not null"`
+	Action     AuditAction     `json:"action" gorm:"type:varchar(50);not null"`
+	TargetType string          `json:"target_type" gorm:"type:varchar(20);not null"`
+	TargetID   string          `json:"target_id,omitempty" gorm:"type:varchar(20)"`
+	Reason     string          `json:"reason,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty" gorm:"type:text"`
+	After      json.RawMessage `json:"after,omitempty" gorm:"type:text"`
+	Metadata   json.RawMessage `json:"metadata,omitempty" gorm:"type:text"`
+	IPAddress  string          `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	RequestID  string          `json:"request_id,omitempty" gorm:"type:varchar(64)"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime"`
+
+	// Set through SetChanges and SetMetadata and encoded when the event is
+	// created
+	before, after, metadata interface{}
+}
+
+// NewAuditEvent starts an event for action, taking the IP address, user agent
+// and request ID from the request info in ctx.
+func NewAuditEvent(ctx context.Context, actorType AuditActorType, actorID string, action AuditAction, targetType, targetID string) *AuditEvent {
+	info := RequestInfoFrom(ctx)
+	return &AuditEvent{
+		ActorID:    actorID,
+		ActorType:  actorType,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IPAddress:  info.IPAddress,
+		UserAgent:  info.UserAgent,
+		RequestID:  info.RequestID,
+	}
+}
+
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) error {
+	id, err := auditSid.Generate()
+	if err != nil {
+		return err
+	}
+	e.ID = id
+
+	if e.before != nil || e.after != nil {
+		if err := e.encodeChanges(); err != nil {
+			return err
+		}
+	}
+	if e.metadata != nil {
+		if e.Metadata, err = json.Marshal(e.metadata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetChanges records the state of the target before and after the action,
+// as maps or structs of the audited fields. Only the fields that differ are
+// stored; a field missing on one side counts as changed.
+func (e *AuditEvent) SetChanges(before, after interface{}) {
+	e.before, e.after = before, after
+}
+
+// SetMetadata records v as the event's metadata
+func (e *AuditEvent) SetMetadata(v interface{}) {
+	e.metadata = v
+}
+
+func (e *AuditEvent) encodeChanges() error {
+	from, err := auditFields(e.before)
+	if err != nil {
+		return err
+	}
+	to, err := auditFields(e.after)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range from {
+		if other, ok := to[key]; ok && reflect.DeepEqual(value, other) {
+			delete(from, key)
+			delete(to, key)
+		}
+	}
+	if e.Before, err = marshalAuditFields(from); err != nil {
+		return err
+	}
+	e.After, err = marshalAuditFields(to)
+	return err
+}
+
+// auditFields turns v into a map of its JSON fields, so that values of
+// different Go types compare equal when they encode the same way.
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if v == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func marshalAuditFields(fields map[string]interface{}) (json.RawMessage, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
+
+// AuditEventFilter narrows an audit log query. Subject matches events the
+// user performed or that target their account.
+type AuditEventFilter struct {
+	Subject    string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	Action     AuditAction
+	From       *time.Time
+	To         *time.Time
+	Page       pagination.Params
+}
+
+// RequestInfo describes the HTTP request an action came from. It travels in
+// the request context so audit events can record it.
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type requestInfoKey struct{}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns the request info stored in ctx, if any
+func RequestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
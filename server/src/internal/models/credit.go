@@ -114,6 +114,7 @@ type Credit struct {
 	ID          string     `json:"id" gorm:"primaryKey;type:varchar(20)"`
 	UserID      string     `json:"user_id" gorm:"type:varchar(20);index;not null"`
 	User        User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	WorkspaceID *string    `json:"workspace_id,omitempty" gorm:"type:varchar(20);index"` // set for a workspace's shared credits
 	Type        CreditType `json:"type" gorm:"type:varchar(20);not null"`
 	Amount      int        `json:"amount" gorm:"not null;default:0"`
 	Remaining   int        `json:"remaining" gorm:"not null;default:0"`
@@ -125,26 +126,29 @@ type Credit struct {
 
 // CreditUsage tracks how credits are consumed
 type CreditUsage struct {
-	ID        string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
-	UserID    string    `json:"user_id" gorm:"type:varchar(20);index;not null"`
-	User      User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
-	CreditID  string    `json:"credit_id" gorm:"type:varchar(20);index;not null"`
-	Credit    Credit    `json:"-" gorm:"foreignKey:CreditID;constraint:OnDelete:CASCADE"`
-	URLID     string    `json:"url_id,omitempty" gorm:"type:varchar(20);index"` // Optional, tracks URL creation usage
-	URL       URL       `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:SET NULL"`
-	Amount    int       `json:"amount" gorm:"not null;default:1"`  // Usually 1 per URL
-	Operation string    `json:"operation" gorm:"type:varchar(50)"` // e.g., "url_creation", "api_call"
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string    `json:"user_id" gorm:"type:varchar(20);index;not null"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	WorkspaceID *string   `json:"workspace_id,omitempty" gorm:"type:varchar(20);index"`
+	CreditID    *string   `json:"credit_id,omitempty" gorm:"type:varchar(20);index"` // nil for free URL creations
+	Credit      Credit    `json:"-" gorm:"foreignKey:CreditID;constraint:OnDelete:CASCADE"`
+	URLID       string    `json:"url_id,omitempty" gorm:"type:varchar(20);index"` // Optional, tracks URL creation usage
+	URL         URL       `json:"-" gorm:"foreignKey:URLID;constraint:OnDelete:SET NULL"`
+	Amount      int       `json:"amount" gorm:"not null;default:1"`  // Usually 1 per URL
+	Operation   string    `json:"operation" gorm:"type:varchar(50)"` // e.g., "url_creation", "api_call"
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
-// CreditBalanceResponse shows user's credit status
+// CreditBalanceResponse shows user's credit status. The totals come from the
+// credit ledger; Remaining only counts credits that haven't expired.
 type CreditBalanceResponse struct {
-	TotalCredits int  `json:"total_credits"`
-	UsedCredits  int  `json:"used_credits"`
-	Remaining    int  `json:"remaining_credits"`
-	FreeLimit    int  `json:"free_limit"` // Max free URLs allowed
-	UsedFree     int  `json:"used_free"`  // Number of free URLs used
-	CanCreate    bool `json:"can_create"` // Whether user can create more URLs
+	TotalCredits   int  `json:"total_credits"`
+	UsedCredits    int  `json:"used_credits"`
+	ExpiredCredits int  `json:"expired_credits"`
+	Remaining      int  `json:"remaining_credits"`
+	FreeLimit      int  `json:"free_limit"` // Max free URLs allowed
+	UsedFree       int  `json:"used_free"`  // Number of free URLs used
+	CanCreate      bool `json:"can_create"` // Whether user can create more URLs
 }
 
 // ApplyPromoCodeRequest for applying promo codes
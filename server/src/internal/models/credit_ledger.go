@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	ledgerSid, _ = shortid.New(1, shortid.DefaultABC, 4686)
+)
+
+// LedgerAccount is one side of a credit ledger transaction. A user's balance
+// is what their wallet holds; the other accounts record where credits came
+// from and where they went.
+type LedgerAccount string
+
+const (
+	LedgerWallet   LedgerAccount = "wallet"   // credits the user can spend
+	LedgerIssued   LedgerAccount = "issued"   // credits granted to the user
+	LedgerConsumed LedgerAccount = "consumed" // credits spent on URLs and other operations
+	LedgerExpired  LedgerAccount = "expired"  // credits that lapsed unused
+)
+
+// LedgerEntryKind is the kind of transaction an entry belongs to
+type LedgerEntryKind string
+
+const (
+	LedgerGrant  LedgerEntryKind = "grant"
+	LedgerDebit  LedgerEntryKind = "debit"
+	LedgerExpiry LedgerEntryKind = "expiry"
+)
+
+// CreditLedgerEntry is one leg of a double-entry credit transaction. Every
+// transaction moves credits between the wallet and one other account, so the
+// entries sharing a TransactionID always sum to zero. Entries are never
+// updated; the database rejects it.
+type CreditLedgerEntry struct {
+	ID            string          `json:"id" gorm:"primaryKey;type:varchar(24)"`
+	TransactionID string          `json:"transaction_id" gorm:"type:varchar(24);index;not null"`
+	UserID        string          `json:"user_id" gorm:"type:varchar(20);index;not null"`
+	WorkspaceID   *string         `json:"workspace_id,omitempty" gorm:"type:varchar(20)"`
+	CreditID      *string         `json:"credit_id,omitempty" gorm:"type:varchar(20);index"`
+	Account       LedgerAccount   `json:"account" gorm:"type:varchar(20);not null"`
+	Kind          LedgerEntryKind `json:"kind" gorm:"type:varchar(20);not null"`
+	Amount        int             `json:"amount" gorm:"not null"` // positive into the account, negative out of it
+	URLID         string          `json:"url_id,omitempty" gorm:"type:varchar(20)"`
+	Operation     string          `json:"operation,omitempty" gorm:"type:varchar(50)"`
+	Description   string          `json:"description,omitempty"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (e *CreditLedgerEntry) BeforeCreate(tx *gorm.DB) error {
+	id, err := ledgerSid.Generate()
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// NewLedgerTransaction returns the two entries that move amount credits of
+// credit out of from and into to.
+func NewLedgerTransaction(credit *Credit, kind LedgerEntryKind, from, to LedgerAccount, amount int) ([]*CreditLedgerEntry, error) {
+	txID, err := ledgerSid.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	creditID := credit.ID
+	return []*CreditLedgerEntry{
+		{TransactionID: txID, UserID: credit.UserID, WorkspaceID: credit.WorkspaceID, CreditID: &creditID, Account: from, Kind: kind, Amount: -amount},
+		{TransactionID: txID, UserID: credit.UserID, WorkspaceID: credit.WorkspaceID, CreditID: &creditID, Account: to, Kind: kind, Amount: amount},
+	}, nil
+}
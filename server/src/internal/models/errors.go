@@ -13,6 +13,9 @@ var (
 	ErrInvalidToken             = errors.New("invalid token")
 	ErrInvalidVerificationToken = errors.New("invalid verification token")
 	ErrInvalidResetToken        = errors.New("invalid reset token")
+	ErrInvalidMagicLink         = errors.New("invalid or expired sign-in link")
+	ErrEmailUnchanged           = errors.New("new email matches the current email")
+	ErrEmailChangePending       = errors.New("an email change is pending confirmation")
 	ErrExpiredToken             = errors.New("token has expired")
 	ErrUnauthorized             = errors.New("unauthorized access")
 	ErrForbidden                = errors.New("forbidden access")
@@ -28,10 +31,62 @@ var (
 	ErrCreditExpired            = errors.New("credit has expired")
 	ErrPromoCodeInvalid         = errors.New("invalid promo code")
 	ErrPromoCodeAlreadyUsed     = errors.New("promo code already used")
+	ErrPromoCodeExpired         = errors.New("promo code has expired")
+	ErrPromoCodeExhausted       = errors.New("promo code has reached its redemption limit")
+	ErrPromoCodeNotApplicable   = errors.New("promo code does not apply here")
+	ErrPromoCodeNotFound        = errors.New("promo code not found")
+	ErrPromoCodeExists          = errors.New("promo code already exists")
+	ErrReferralNotFound         = errors.New("referral not found")
+	ErrReferralNotPending       = errors.New("referral is no longer pending")
 	ErrActiveSubscriptionExists = errors.New("active subscription already exists")
 	ErrSubscriptionNotActive    = errors.New("subscription not active")
 	ErrInvalidPlan              = errors.New("invalid subscription plan")
 	ErrPaymentFailed            = errors.New("payment failed")
+	ErrSubscriptionNotFound     = errors.New("subscription not found")
+	ErrPlanUnchanged            = errors.New("subscription is already on this plan")
+	ErrPlanNotFound             = errors.New("plan not found")
+	ErrPlanExists               = errors.New("plan already exists")
+	ErrPlanIntervalMismatch     = errors.New("cannot switch between monthly and yearly billing mid-period")
+	ErrPaymentNotFound          = errors.New("payment not found")
+	ErrInvoiceNotFound          = errors.New("invoice not found")
+	ErrTrialNotFound            = errors.New("trial not found")
+	ErrTrialUnavailable         = errors.New("trial not available for this account")
+	ErrTrialPaymentMethodUsed   = errors.New("payment method already used for a trial")
+	ErrSubscriptionTrialing     = errors.New("not available during a trial")
+	ErrInvalidWebhookSignature  = errors.New("invalid webhook signature")
+	ErrWebhookEndpointNotFound  = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound  = errors.New("webhook delivery not found")
+	ErrWebhookEndpointDisabled  = errors.New("webhook endpoint is disabled")
+	ErrWebhookEndpointLimit     = errors.New("webhook endpoint limit reached")
+	ErrInvalidWebhookURL        = errors.New("webhook URL must be an absolute http or https URL")
+	ErrUnknownEventType         = errors.New("unknown event type")
+	ErrExportNotFound           = errors.New("data export not found")
+	ErrExportInProgress         = errors.New("a data export is already in progress")
+	ErrWorkspaceNotFound        = errors.New("workspace not found")
+	ErrWorkspaceHasSubscription = errors.New("cancel the workspace subscription first")
+	ErrMemberNotFound           = errors.New("workspace member not found")
+	ErrAlreadyMember            = errors.New("user is already a member of this workspace")
+	ErrOwnerRoleChange          = errors.New("the workspace owner can't be removed or change role")
+	ErrInvitationNotFound       = errors.New("invitation not found")
+	ErrInvalidInvitation        = errors.New("invalid or expired invitation")
+	ErrInvitationEmailMismatch  = errors.New("invitation was sent to a different email address")
+	ErrAccountSuspended         = errors.New("account suspended")
+	ErrCannotModifySelf         = errors.New("admins can't suspend themselves or change their own role")
+	ErrPaidSubscriptionOverride = errors.New("a paid subscription can only be extended on its current plan")
+	ErrURLTakenDown             = errors.New("URL has been taken down")
+	ErrURLNotTakenDown          = errors.New("URL is not taken down")
 	ErrURLNotFound              = errors.New("URL not found")
 	ErrShortCodeTaken           = errors.New("short code already taken")
+	ErrURLRevisionNotFound      = errors.New("URL revision not found")
+	ErrImportNotFound           = errors.New("import not found")
+	ErrInvalidCSV               = errors.New("invalid CSV file")
+	ErrTooManyRows              = errors.New("too many rows")
+	ErrUnknownExportField       = errors.New("unknown export field")
+	ErrUnknownExportFormat      = errors.New("unknown export format")
+	ErrExportScheduleNotFound   = errors.New("export schedule not found")
+	ErrExportScheduleLimit      = errors.New("export schedule limit reached")
+	ErrExportTooLarge           = errors.New("export is too large to email")
+	ErrFolderNotFound           = errors.New("folder not found")
+	ErrFolderExists             = errors.New("a folder with this name already exists")
+	ErrTagNotFound              = errors.New("tag not found")
 )
\ No newline at end of file
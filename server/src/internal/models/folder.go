@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	folderSid, _ = shortid.New(1, shortid.DefaultABC, 9583)
+)
+
+// Folder groups links. Like a link, it belongs to the user who made it, or
+// to a workspace when WorkspaceID is set. A link is in at most one folder.
+type Folder struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string    `json:"-" gorm:"type:varchar(20);not null;index"`
+	WorkspaceID *string   `json:"workspace_id,omitempty" gorm:"type:varchar(20);index"`
+	Name        string    `json:"name" gorm:"type:varchar(50);not null"`
+	URLCount    int64     `json:"url_count" gorm:"->;-:migration"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (f *Folder) BeforeCreate(tx *gorm.DB) error {
+	id, err := folderSid.Generate()
+	if err != nil {
+		return err
+	}
+	f.ID = id
+	return nil
+}
+
+type CreateFolderRequest struct {
+	Name        string `json:"name" validate:"required,max=50"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
+
+func (r *CreateFolderRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type UpdateFolderRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+func (r *UpdateFolderRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// MoveURLRequest puts a link in a folder, or takes it out of its folder
+// when FolderID is empty
+type MoveURLRequest struct {
+	FolderID string `json:"folder_id"`
+}
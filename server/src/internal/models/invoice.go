@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	invoiceSid, _ = shortid.New(1, shortid.DefaultABC, 3197)
+)
+
+// Invoice is the receipt issued for a paid Payment. Numbers are sequential
+// and never reused. The seller and billing details are copied in when the
+// invoice is issued, so later profile changes don't alter it.
+type Invoice struct {
+	ID             string `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	Sequence       int    `json:"-" gorm:"uniqueIndex;not null"`
+	Number         string `json:"number" gorm:"type:varchar(30);uniqueIndex;not null"`
+	UserID         string `json:"user_id" gorm:"type:varchar(20);index;not null"`
+	PaymentID      string `json:"payment_id" gorm:"type:varchar(20);uniqueIndex;not null"`
+	SubscriptionID string `json:"subscription_id,omitempty" gorm:"type:varchar(20)"`
+	Currency       string `json:"currency" gorm:"type:varchar(3);not null"`
+
+	// Amounts are in the currency's minor unit. Prices include tax, so
+	// Subtotal + TaxAmount = Total, which is what was charged.
+	Subtotal  int     `json:"subtotal" gorm:"not null"`
+	TaxRate   float64 `json:"tax_rate"` // percent
+	TaxLabel  string  `json:"tax_label,omitempty" gorm:"type:varchar(20)"`
+	TaxAmount int     `json:"tax_amount" gorm:"not null;default:0"`
+	Total     int     `json:"total" gorm:"not null"`
+
+	SellerName    string         `json:"seller_name" gorm:"type:varchar(100)"`
+	SellerAddress string         `json:"seller_address,omitempty"`
+	SellerTaxID   string         `json:"seller_tax_id,omitempty" gorm:"type:varchar(50)"`
+	BillingEmail  string         `json:"billing_email" gorm:"type:varchar(255)"`
+	BillingTo     BillingAddress `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+
+	LineItems []*InvoiceLineItem `json:"line_items" gorm:"foreignKey:InvoiceID;constraint:OnDelete:CASCADE"`
+
+	PDFURL        string     `json:"-" gorm:"type:varchar(500)"`
+	PDFKey        string     `json:"-" gorm:"type:varchar(255)"`
+	EmailedAt     *time.Time `json:"emailed_at,omitempty"`
+	EmailAttempts int        `json:"-" gorm:"not null;default:0"`
+	IssuedAt      time.Time  `json:"issued_at" gorm:"not null"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type InvoiceLineItem struct {
+	ID          string `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	InvoiceID   string `json:"-" gorm:"type:varchar(20);index;not null"`
+	Description string `json:"description" gorm:"not null"`
+	Quantity    int    `json:"quantity" gorm:"not null;default:1"`
+	UnitAmount  int    `json:"unit_amount" gorm:"not null"` // before tax
+	Amount      int    `json:"amount" gorm:"not null"`      // Quantity * UnitAmount
+}
+
+func (i *Invoice) BeforeCreate(tx *gorm.DB) error {
+	id, err := invoiceSid.Generate()
+	if err != nil {
+		return err
+	}
+	i.ID = id
+	return nil
+}
+
+func (li *InvoiceLineItem) BeforeCreate(tx *gorm.DB) error {
+	id, err := invoiceSid.Generate()
+	if err != nil {
+		return err
+	}
+	li.ID = id
+	return nil
+}
+
+// InvoiceNumber formats the sequence-th invoice number, e.g. "INV-000042".
+func InvoiceNumber(prefix string, sequence int) string {
+	return fmt.Sprintf("%s-%06d", prefix, sequence)
+}
+
+// SplitTax splits a tax-inclusive total into the amount before tax and the
+// tax itself at rate percent.
+func SplitTax(total int, rate float64) (subtotal, tax int) {
+	if rate <= 0 {
+		return total, 0
+	}
+	subtotal = int(float64(total)/(1+rate/100) + 0.5)
+	return subtotal, total - subtotal
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	magicLinkSid, _ = shortid.New(1, shortid.DefaultABC, 6121)
+)
+
+// MagicLinkToken is a single-use passwordless sign-in token. Only the SHA-256
+// hash of the token sent by email is stored.
+type MagicLinkToken struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string     `json:"user_id" gorm:"type:varchar(20);index;not null"`
+	User        User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	TokenHash   string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	RequestedIP string     `json:"-" gorm:"type:varchar(45)"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ConsumeMagicLinkRequest signs in with a magic link token, sent as JSON or
+// from the confirmation page's form
+type ConsumeMagicLinkRequest struct {
+	Token string `json:"token" form:"token"`
+}
+
+func (m *MagicLinkToken) BeforeCreate(tx *gorm.DB) error {
+	id, err := magicLinkSid.Generate()
+	if err != nil {
+		return err
+	}
+	m.ID = id
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type PaymentEventStatus string
+
+const (
+	PaymentEventPending    PaymentEventStatus = "pending"
+	PaymentEventProcessed  PaymentEventStatus = "processed"
+	PaymentEventFailed     PaymentEventStatus = "failed"
+	PaymentEventDeadLetter PaymentEventStatus = "dead_letter"
+)
+
+// PaymentEvent is a webhook received from the payment provider. The ID is the
+// provider's event ID, which makes redelivered events a no-op.
+type PaymentEvent struct {
+	ID            string             `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Type          string             `json:"type" gorm:"type:varchar(100);not null"`
+	Payload       string             `json:"-" gorm:"type:text;not null"`
+	Status        PaymentEventStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Attempts      int                `json:"attempts" gorm:"not null;default:0"`
+	LastError     string             `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time         `json:"next_attempt_at,omitempty"`
+	ProcessedAt   *time.Time         `json:"processed_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	planSid, _ = shortid.New(1, shortid.DefaultABC, 8231)
+)
+
+type BillingInterval string
+
+const (
+	IntervalMonthly BillingInterval = "monthly"
+	IntervalYearly  BillingInterval = "yearly"
+)
+
+// Plan is one version of a subscription plan. Changing a plan's terms adds a
+// new version and retires the old one for new sign-ups; subscriptions keep
+// pointing at the version they signed up for.
+type Plan struct {
+	ID              string           `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	Code            SubscriptionPlan `json:"code" gorm:"type:varchar(50);not null;uniqueIndex:idx_plans_code_version"`
+	Version         int              `json:"version" gorm:"not null;uniqueIndex:idx_plans_code_version"`
+	Name            string           `json:"name" gorm:"type:varchar(100);not null"`
+	Description     string           `json:"description,omitempty"`
+	Price           int              `json:"price" gorm:"not null"` // in cents, per interval
+	Currency        string           `json:"currency" gorm:"type:varchar(3);not null;default:'usd'"`
+	Interval        BillingInterval  `json:"interval" gorm:"type:varchar(10);not null"`
+	Credits         int              `json:"credits" gorm:"not null;default:0"`   // paid credits granted each period
+	URLLimit        int              `json:"url_limit" gorm:"not null;default:0"` // 0 means unlimited
+	Features        []string         `json:"features" gorm:"serializer:json;type:text"`
+	FeatureFlags    map[string]bool  `json:"feature_flags" gorm:"serializer:json;type:text"`
+	ProviderPriceID string           `json:"provider_price_id,omitempty" gorm:"type:varchar(255)"`
+	IsActive        bool             `json:"is_active" gorm:"default:true"` // offered to new subscribers
+	CreatedAt       time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (p *Plan) BeforeCreate(tx *gorm.DB) error {
+	id, err := planSid.Generate()
+	if err != nil {
+		return err
+	}
+	p.ID = id
+	return nil
+}
+
+// PeriodAfter returns when a billing period starting at t ends.
+func (p *Plan) PeriodAfter(t time.Time) time.Time {
+	if p.Interval == IntervalYearly {
+		return t.AddDate(1, 0, 0)
+	}
+	return t.AddDate(0, 1, 0)
+}
+
+// PeriodBefore returns when a billing period ending at t started.
+func (p *Plan) PeriodBefore(t time.Time) time.Time {
+	if p.Interval == IntervalYearly {
+		return t.AddDate(-1, 0, 0)
+	}
+	return t.AddDate(0, -1, 0)
+}
+
+type CreatePlanRequest struct {
+	Code            SubscriptionPlan `json:"code" validate:"required,min=2,max=50,alphanum"`
+	Name            string           `json:"name" validate:"required,max=100"`
+	Description     string           `json:"description" validate:"omitempty,max=500"`
+	Price           int              `json:"price" validate:"min=0"`
+	Currency        string           `json:"currency" validate:"omitempty,len=3"`
+	Interval        BillingInterval  `json:"interval" validate:"required,oneof=monthly yearly"`
+	Credits         int              `json:"credits" validate:"min=0"`
+	URLLimit        int              `json:"url_limit" validate:"min=0"`
+	Features        []string         `json:"features"`
+	FeatureFlags    map[string]bool  `json:"feature_flags"`
+	ProviderPriceID string           `json:"provider_price_id" validate:"omitempty,max=255"`
+}
+
+// UpdatePlanRequest changes a plan. Fields left out are kept. Changing any of
+// the billing terms (price, currency, interval, credits, limits, feature flags
+// or provider price) publishes a new version; name, description and features
+// are updated in place.
+type UpdatePlanRequest struct {
+	Name            *string          `json:"name" validate:"omitempty,max=100"`
+	Description     *string          `json:"description" validate:"omitempty,max=500"`
+	Price           *int             `json:"price" validate:"omitempty,min=0"`
+	Currency        *string          `json:"currency" validate:"omitempty,len=3"`
+	Interval        *BillingInterval `json:"interval" validate:"omitempty,oneof=monthly yearly"`
+	Credits         *int             `json:"credits" validate:"omitempty,min=0"`
+	URLLimit        *int             `json:"url_limit" validate:"omitempty,min=0"`
+	Features        []string         `json:"features"`
+	FeatureFlags    map[string]bool  `json:"feature_flags"`
+	ProviderPriceID *string          `json:"provider_price_id" validate:"omitempty,max=255"`
+}
+
+func (r *CreatePlanRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r *UpdatePlanRequest) Validate() error {
+	return validate.Struct(r)
+}
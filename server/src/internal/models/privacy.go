@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	exportSid, _ = shortid.New(1, shortid.DefaultABC, 7319)
+)
+
+type DataExportStatus string
+
+const (
+	DataExportPending    DataExportStatus = "pending"
+	DataExportProcessing DataExportStatus = "processing"
+	DataExportCompleted  DataExportStatus = "completed"
+	DataExportFailed     DataExportStatus = "failed"
+)
+
+// DataExport is a personal data export job. The finished archive lives in
+// storage under FileKey and is removed once ExpiresAt has passed.
+type DataExport struct {
+	ID          string           `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string           `json:"-" gorm:"type:varchar(20);index;not null"`
+	User        User             `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Status      DataExportStatus `json:"status" gorm:"type:varchar(20);not null"`
+	FileKey     string           `json:"-"`
+	DownloadURL string           `json:"download_url,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	ExpiresAt   *time.Time       `json:"expires_at,omitempty" gorm:"index"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (e *DataExport) BeforeCreate(tx *gorm.DB) error {
+	id, err := exportSid.Generate()
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// UserData is everything stored about a user, gathered for an export.
+type UserData struct {
+	User          *User
+	URLs          []*URL
+	Clicks        []*URLClick
+	Credits       []*Credit
+	CreditUsages  []*CreditUsage
+	CreditLedger  []*CreditLedgerEntry
+	Subscriptions []*Subscription
+	Payments      []*Payment
+	Redemptions   []*PromoCodeRedemption
+	Referrals     []*Referral
+	Invoices      []*Invoice
+	Trials        []*Trial
+	Workspaces    []*WorkspaceMember
+}
+
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
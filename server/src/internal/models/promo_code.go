@@ -0,0 +1,120 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	promoSid, _ = shortid.New(1, shortid.DefaultABC, 9346)
+)
+
+// PromoCodeType decides what a promo code is redeemed for
+type PromoCodeType string
+
+const (
+	PromoCodeCredits  PromoCodeType = "credits"  // redeemed for credits on /credits/apply-promo
+	PromoCodeDiscount PromoCodeType = "discount" // a subscription coupon
+)
+
+// PromoCode is a code users can redeem for credits or a percentage off their
+// subscription. Codes are stored upper case and matched case-insensitively.
+type PromoCode struct {
+	ID          string        `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	Code        string        `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Type        PromoCodeType `json:"type" gorm:"type:varchar(20);not null"`
+	Description string        `json:"description,omitempty"`
+	Credits     int           `json:"credits,omitempty"`
+	PercentOff  int           `json:"percent_off,omitempty"`
+	// Duration is how long a discount lasts, "once" or "forever"
+	Duration         string             `json:"duration,omitempty" gorm:"type:varchar(20)"`
+	ProviderCouponID string             `json:"-" gorm:"type:varchar(255)"`
+	MaxRedemptions   int                `json:"max_redemptions"` // 0 means unlimited
+	PerUserLimit     int                `json:"per_user_limit" gorm:"not null;default:1"`
+	RedemptionCount  int                `json:"redemption_count" gorm:"not null;default:0"`
+	Plans            []SubscriptionPlan `json:"plans,omitempty" gorm:"serializer:json;type:text"` // empty means any plan
+	StartsAt         *time.Time         `json:"starts_at,omitempty"`
+	ExpiresAt        *time.Time         `json:"expires_at,omitempty"`
+	IsActive         bool               `json:"is_active" gorm:"default:true"`
+	CreatedAt        time.Time          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time          `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// PromoCodeRedemption records one use of a promo code by a user, along with
+// the credit or subscription it produced.
+type PromoCodeRedemption struct {
+	ID             string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	PromoCodeID    string    `json:"promo_code_id" gorm:"type:varchar(20);index;not null"`
+	UserID         string    `json:"user_id" gorm:"type:varchar(20);index;not null"`
+	CreditID       string    `json:"credit_id,omitempty" gorm:"type:varchar(20)"`
+	SubscriptionID string    `json:"subscription_id,omitempty" gorm:"type:varchar(20)"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (p *PromoCode) BeforeCreate(tx *gorm.DB) error {
+	id, err := promoSid.Generate()
+	if err != nil {
+		return err
+	}
+	p.ID = id
+	return nil
+}
+
+func (r *PromoCodeRedemption) BeforeCreate(tx *gorm.DB) error {
+	id, err := promoSid.Generate()
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	return nil
+}
+
+// AllowsPlan reports whether the code can be used on plan.
+func (p *PromoCode) AllowsPlan(plan SubscriptionPlan) bool {
+	if len(p.Plans) == 0 {
+		return true
+	}
+	for _, allowed := range p.Plans {
+		if allowed == plan {
+			return true
+		}
+	}
+	return false
+}
+
+type CreatePromoCodeRequest struct {
+	Code           string             `json:"code" validate:"required,min=4,max=50,alphanum"`
+	Type           PromoCodeType      `json:"type" validate:"required,oneof=credits discount"`
+	Description    string             `json:"description" validate:"omitempty,max=500"`
+	Credits        int                `json:"credits" validate:"required_if=Type credits,min=0"`
+	PercentOff     int                `json:"percent_off" validate:"required_if=Type discount,min=0,max=100"`
+	Duration       string             `json:"duration" validate:"omitempty,oneof=once forever"`
+	MaxRedemptions int                `json:"max_redemptions" validate:"min=0"`
+	PerUserLimit   int                `json:"per_user_limit" validate:"min=0"`
+	Plans          []SubscriptionPlan `json:"plans" validate:"omitempty,dive,max=50"`
+	StartsAt       *time.Time         `json:"starts_at"`
+	ExpiresAt      *time.Time         `json:"expires_at"`
+}
+
+// UpdatePromoCodeRequest changes a promo code's limits and validity. The code,
+// type and discount can't be changed once the code exists.
+type UpdatePromoCodeRequest struct {
+	Description    *string            `json:"description" validate:"omitempty,max=500"`
+	Credits        *int               `json:"credits" validate:"omitempty,min=1"`
+	MaxRedemptions *int               `json:"max_redemptions" validate:"omitempty,min=0"`
+	PerUserLimit   *int               `json:"per_user_limit" validate:"omitempty,min=1"`
+	Plans          []SubscriptionPlan `json:"plans" validate:"omitempty,dive,max=50"`
+	StartsAt       *time.Time         `json:"starts_at"`
+	ExpiresAt      *time.Time         `json:"expires_at"`
+	IsActive       *bool              `json:"is_active"`
+}
+
+func (r *CreatePromoCodeRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func (r *UpdatePromoCodeRequest) Validate() error {
+	return validate.Struct(r)
+}
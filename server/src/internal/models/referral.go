@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	referralSid, _ = shortid.New(1, shortid.DefaultABC, 5786)
+)
+
+type ReferralStatus string
+
+const (
+	ReferralPending   ReferralStatus = "pending"   // waiting for the referee to verify their email
+	ReferralCompleted ReferralStatus = "completed" // both sides were credited
+	ReferralCapped    ReferralStatus = "capped"    // the referee was credited, the referrer was over their monthly cap
+	ReferralRejected  ReferralStatus = "rejected"  // failed an abuse check; nobody is credited
+)
+
+// Reasons a referral is rejected at signup
+const (
+	ReferralRejectSelf   = "self_referral"
+	ReferralRejectSameIP = "same_ip"
+)
+
+// ReferralCode is the code a user shares to refer others. It is created the
+// first time the user looks at their referrals.
+type ReferralCode struct {
+	UserID    string    `json:"user_id" gorm:"primaryKey;type:varchar(20)"`
+	Code      string    `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Referral links a user who signed up with a referral code to the user who
+// shared it. A user can only be referred once.
+type Referral struct {
+	ID              string         `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	ReferrerID      string         `json:"referrer_id" gorm:"type:varchar(20);index;not null"`
+	RefereeID       string         `json:"referee_id" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Code            string         `json:"code" gorm:"type:varchar(20);not null"`
+	Status          ReferralStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	RejectReason    string         `json:"reject_reason,omitempty" gorm:"type:varchar(50)"`
+	SignupIP        string         `json:"-" gorm:"type:varchar(45)"`
+	ReferrerCredits int            `json:"referrer_credits"`
+	RefereeCredits  int            `json:"referee_credits"`
+	CompletedAt     *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (r *Referral) BeforeCreate(tx *gorm.DB) error {
+	id, err := referralSid.Generate()
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	return nil
+}
+
+// ReferralEntry is one of the user's referrals as shown to the referrer.
+type ReferralEntry struct {
+	ID            string         `json:"id"`
+	Referee       string         `json:"referee"` // the referee's username
+	Status        ReferralStatus `json:"status"`
+	RejectReason  string         `json:"reject_reason,omitempty"`
+	CreditsEarned int            `json:"credits_earned"`
+	CreatedAt     time.Time      `json:"created_at"`
+	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
+}
+
+type ReferralSummaryResponse struct {
+	Code          string           `json:"code"`
+	Pending       int              `json:"pending"`
+	Completed     int              `json:"completed"`
+	CreditsEarned int              `json:"credits_earned"`
+	MonthlyCap    int              `json:"monthly_cap"` // rewarded referrals per month, 0 means unlimited
+	Referrals     []*ReferralEntry `json:"referrals"`
+}
@@ -21,34 +21,69 @@ const (
 	PlanEnterprise SubscriptionPlan = "enterprise"
 )
 
+// Subscription statuses mirror the provider's lifecycle
+const (
+	SubscriptionStatusActive   = "active"
+	SubscriptionStatusTrialing = "trialing"
+	SubscriptionStatusPastDue  = "past_due"
+	SubscriptionStatusCanceled = "canceled"
+	SubscriptionStatusUnpaid   = "unpaid"
+)
+
+// Payment statuses
+const (
+	PaymentStatusPaid              = "paid"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusDisputed          = "disputed"
+	PaymentStatusDisputeLost       = "dispute_lost"
+)
+
 type Subscription struct {
 	ID          string           `json:"id" gorm:"primaryKey;type:varchar(20)"`
 	UserID      string           `json:"user_id" gorm:"type:varchar(20);index;not null"`
 	User        User             `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	WorkspaceID *string          `json:"workspace_id,omitempty" gorm:"type:varchar(20);index"` // set when a workspace owns the subscription; UserID paid for it
 	Plan        SubscriptionPlan `json:"plan" gorm:"type:varchar(20);not null"`
+	PlanID      string           `json:"plan_id" gorm:"type:varchar(20)"` // the plan version the terms come from
 	StripeID    string           `json:"-" gorm:"type:varchar(255);index"`
+	Status      string           `json:"status" gorm:"type:varchar(20);default:'active'"`
 	IsActive    bool             `json:"is_active" gorm:"default:true"`
 	StartsAt    time.Time        `json:"starts_at" gorm:"not null"`
 	ExpiresAt   time.Time        `json:"expires_at" gorm:"not null"`
 	RenewsAt    *time.Time       `json:"renews_at,omitempty"`
 	CancelledAt *time.Time       `json:"cancelled_at,omitempty"`
-	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// PendingPlan is a downgrade that takes effect when the period renews
+	PendingPlan   SubscriptionPlan `json:"pending_plan,omitempty" gorm:"type:varchar(20)"`
+	PendingPlanID string           `json:"-" gorm:"type:varchar(20)"`
+
+	// GracePeriodEndsAt is set while a failed renewal is being retried.
+	// LockedUntil is a lease held by the scheduler instance processing the
+	// subscription. ProviderEventAt is when the last payment provider event
+	// applied to it was created; webhooks older than that are skipped.
+	GracePeriodEndsAt *time.Time `json:"grace_period_ends_at,omitempty"`
+	LockedUntil       *time.Time `json:"-"`
+	ProviderEventAt   *time.Time `json:"-"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 type Payment struct {
-	ID             string       `json:"id" gorm:"primaryKey;type:varchar(20)"`
-	UserID         string       `json:"user_id" gorm:"type:varchar(20);index;not null"`
-	User           User         `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
-	SubscriptionID string       `json:"subscription_id" gorm:"type:varchar(20);index"`
-	Subscription   Subscription `json:"-" gorm:"foreignKey:SubscriptionID;constraint:OnDelete:SET NULL"`
-	Amount         int          `json:"amount" gorm:"not null"`
-	Currency       string       `json:"currency" gorm:"type:varchar(3);default:'usd'"`
-	StripeID       string       `json:"-" gorm:"type:varchar(255);index"`
-	Status         string       `json:"status" gorm:"type:varchar(20)"`
-	Description    string       `json:"description,omitempty"`
-	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
-	PaidAt         *time.Time   `json:"paid_at,omitempty"`
+	ID              string       `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID          string       `json:"user_id" gorm:"type:varchar(20);index;not null"`
+	User            User         `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	SubscriptionID  string       `json:"subscription_id" gorm:"type:varchar(20);index"`
+	Subscription    Subscription `json:"-" gorm:"foreignKey:SubscriptionID;constraint:OnDelete:SET NULL"`
+	Amount          int          `json:"amount" gorm:"not null"`
+	Currency        string       `json:"currency" gorm:"type:varchar(3);default:'usd'"`
+	StripeID        string       `json:"-" gorm:"type:varchar(255);index"`
+	PaymentIntentID string       `json:"-" gorm:"type:varchar(255);index"`
+	Status          string       `json:"status" gorm:"type:varchar(20)"`
+	Description     string       `json:"description,omitempty"`
+	CreatedAt       time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	PaidAt          *time.Time   `json:"paid_at,omitempty"`
 }
 
 type SubscriptionPlanResponse struct {
@@ -57,19 +92,41 @@ type SubscriptionPlanResponse struct {
 	Description string   `json:"description"`
 	Price       int      `json:"price"`
 	Currency    string   `json:"currency"`
+	Interval    string   `json:"interval"`
+	Credits     int      `json:"credits"`
 	URLsAllowed int      `json:"urls_allowed"`
 	Features    []string `json:"features"`
 	IsCurrent   bool     `json:"is_current,omitempty"`
 }
 
 type CreateSubscriptionRequest struct {
-	Plan   SubscriptionPlan `json:"plan" validate:"required,oneof=basic pro enterprise"`
+	Plan   SubscriptionPlan `json:"plan" validate:"required,max=50"`
 	Token  string           `json:"token" validate:"required"`
 	Coupon string           `json:"coupon,omitempty"`
 }
 
 type UpdateSubscriptionRequest struct {
-	Plan SubscriptionPlan `json:"plan" validate:"required,oneof=basic pro enterprise"`
+	Plan SubscriptionPlan `json:"plan" validate:"required,max=50"`
+}
+
+// Plan change types
+const (
+	PlanChangeUpgrade   = "upgrade"
+	PlanChangeDowngrade = "downgrade"
+)
+
+// PlanChangePreview is what a plan change will cost, shown to the user before
+// they confirm it. Upgrades are charged AmountDue immediately; downgrades take
+// effect at the end of the current period.
+type PlanChangePreview struct {
+	CurrentPlan SubscriptionPlan `json:"current_plan"`
+	NewPlan     SubscriptionPlan `json:"new_plan"`
+	Type        string           `json:"type"`
+	AmountDue   int              `json:"amount_due"`
+	Currency    string           `json:"currency"`
+	CreditDelta int              `json:"credit_delta"`
+	EffectiveAt time.Time        `json:"effective_at"`
+	PeriodEnd   time.Time        `json:"period_end"`
 }
 
 type CancelSubscriptionRequest struct {
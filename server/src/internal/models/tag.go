@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	tagSid, _ = shortid.New(1, shortid.DefaultABC, 9694)
+)
+
+// MaxURLTags is the most tags one link can have
+const MaxURLTags = 20
+
+// Tag labels links. Tags are scoped like folders, to a user or a workspace,
+// and are created the first time a link is given them. Names are stored in
+// lower case, so "News" and "news" are the same tag.
+type Tag struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string    `json:"-" gorm:"type:varchar(20);not null;index"`
+	WorkspaceID *string   `json:"workspace_id,omitempty" gorm:"type:varchar(20);index"`
+	Name        string    `json:"name" gorm:"type:varchar(50);not null"`
+	URLCount    int64     `json:"url_count" gorm:"->;-:migration"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	id, err := tagSid.Generate()
+	if err != nil {
+		return err
+	}
+	t.ID = id
+	return nil
+}
+
+// TagName is the stored form of a tag name
+func TagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// SetURLTagsRequest replaces a link's tags
+type SetURLTagsRequest struct {
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+func (r *SetURLTagsRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	trialSid, _ = shortid.New(1, shortid.DefaultABC, 7421)
+)
+
+type TrialStatus string
+
+const (
+	TrialActive    TrialStatus = "active"    // the trial subscription is running
+	TrialConverted TrialStatus = "converted" // the payment method on file was charged for the first period
+	TrialReverted  TrialStatus = "reverted"  // the trial ended without a payment and the account is back on free
+	TrialCanceled  TrialStatus = "canceled"  // the user ended the trial early
+)
+
+// Trial is a time-boxed run of a paid plan without a payment up front. It
+// backs a subscription with status "trialing". Each user gets one trial, and
+// a payment method can only ever be added to one trial, which is what stops
+// people from starting trials on new accounts with the same card.
+type Trial struct {
+	ID                 string           `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID             string           `json:"user_id" gorm:"type:varchar(20);uniqueIndex;not null"`
+	SubscriptionID     string           `json:"subscription_id" gorm:"type:varchar(20);index;not null"`
+	Plan               SubscriptionPlan `json:"plan" gorm:"type:varchar(20);not null"`
+	PlanID             string           `json:"plan_id" gorm:"type:varchar(20);not null"`
+	Status             TrialStatus      `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	PaymentMethodID    string           `json:"-" gorm:"type:varchar(255)"`
+	PaymentFingerprint *string          `json:"-" gorm:"type:varchar(255);uniqueIndex"`
+	PaymentMethodBrand string           `json:"payment_method_brand,omitempty" gorm:"type:varchar(20)"`
+	PaymentMethodLast4 string           `json:"payment_method_last4,omitempty" gorm:"type:varchar(4)"`
+	StartedAt          time.Time        `json:"started_at" gorm:"not null"`
+	EndsAt             time.Time        `json:"ends_at" gorm:"not null"`
+	ReminderSentAt     *time.Time       `json:"reminder_sent_at,omitempty"`
+	EndedAt            *time.Time       `json:"ended_at,omitempty"`
+	CreatedAt          time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (t *Trial) BeforeCreate(tx *gorm.DB) error {
+	id, err := trialSid.Generate()
+	if err != nil {
+		return err
+	}
+	t.ID = id
+	return nil
+}
+
+// HasPaymentMethod reports whether the trial converts to a paid subscription
+// when it ends.
+func (t *Trial) HasPaymentMethod() bool {
+	return t.PaymentMethodID != ""
+}
+
+type StartTrialRequest struct {
+	Plan SubscriptionPlan `json:"plan" validate:"required,max=50"`
+	// Token is an optional payment method to convert to paid with
+	Token string `json:"token,omitempty"`
+}
+
+type TrialPaymentMethodRequest struct {
+	Token string `json:"token" validate:"required"`
+}
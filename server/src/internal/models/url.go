@@ -1,8 +1,12 @@
 package models
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 	"github.com/teris-io/shortid"
 	"gorm.io/gorm"
 )
@@ -17,6 +21,11 @@ type URL struct {
 	ShortCode   string         `json:"short_code" validate:"required,alphanum,min=3,max=10" gorm:"unique;not null"`
 	UserID      *string        `json:"user_id" gorm:"type:varchar(20);index;default:null"`
 	User        *User          `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL"`
+	WorkspaceID *string        `json:"workspace_id,omitempty" gorm:"type:varchar(20);index"` // set for shared links; UserID is the creator
+	FolderID    *string        `json:"folder_id,omitempty" gorm:"type:varchar(20);index"`
+	Tags        []*Tag         `json:"-" gorm:"many2many:url_tags"`
+	Aliases     []*URLAlias    `json:"-" gorm:"foreignKey:URLID"`
+	Domain      string         `json:"-" gorm:"type:varchar(255);index"` // host of OriginalURL, for filtering
 	Title       string         `json:"title" validate:"max=100"`
 	Description string         `json:"description" validate:"max=255"`
 	Clicks      int            `json:"clicks" gorm:"default:0"`
@@ -26,6 +35,14 @@ type URL struct {
 	CreatedAt   time.Time      `json:"created_at" gorm:"type:datetime;autoCreateTime"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"type:datetime;autoUpdateTime"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index;type:datetime"`
+
+	// TakenDownAt is set when an admin disables the link; only an admin can
+	// restore it
+	TakenDownAt    *time.Time `json:"taken_down_at,omitempty"`
+	TakedownReason string     `json:"takedown_reason,omitempty"`
+
+	// ExpiredNotifiedAt is set once the url.expired event has been sent
+	ExpiredNotifiedAt *time.Time `json:"-"`
 }
 
 func (u *URL) BeforeCreate(tx *gorm.DB) error {
@@ -34,9 +51,19 @@ func (u *URL) BeforeCreate(tx *gorm.DB) error {
 		return err
 	}
 	u.ID = id
+	u.Domain = URLDomain(u.OriginalURL)
 	return nil
 }
 
+// URLDomain is the lower-case host of a destination URL
+func URLDomain(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
 type URLClick struct {
 	ID        string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
 	URLID     string    `json:"url_id" gorm:"type:varchar(20);index"`
@@ -67,6 +94,8 @@ type CreateURLRequest struct {
 	Title       string     `json:"title" validate:"max=100"`
 	Description string     `json:"description" validate:"max=255"`
 	ExpiresAt   *time.Time `json:"expires_at"`
+	// WorkspaceID creates the link in a workspace, paid for with its credits
+	WorkspaceID string `json:"workspace_id,omitempty"`
 }
 
 type URLResponse struct {
@@ -74,25 +103,107 @@ type URLResponse struct {
 	OriginalURL string     `json:"original_url"`
 	ShortURL    string     `json:"short_url"`
 	ShortCode   string     `json:"short_code"`
+	WorkspaceID *string    `json:"workspace_id,omitempty"`
+	FolderID    *string    `json:"folder_id,omitempty"`
+	Tags        []string   `json:"tags"`
+	Aliases     []string   `json:"aliases,omitempty"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Clicks      int        `json:"clicks"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 	IsActive    bool       `json:"is_active"`
 	CreatedAt   time.Time  `json:"created_at"`
+
+	TakenDownAt    *time.Time `json:"taken_down_at,omitempty"`
+	TakedownReason string     `json:"takedown_reason,omitempty"`
 }
 
 func (u *URL) ToResponse(baseURL string) *URLResponse {
+	tags := make([]string, len(u.Tags))
+	for i, t := range u.Tags {
+		tags[i] = t.Name
+	}
+	var aliases []string
+	for _, a := range u.Aliases {
+		aliases = append(aliases, a.ShortCode)
+	}
 	return &URLResponse{
 		ID:          u.ID,
 		OriginalURL: u.OriginalURL,
 		ShortURL:    baseURL + "/" + u.ShortCode,
 		ShortCode:   u.ShortCode,
+		WorkspaceID: u.WorkspaceID,
+		FolderID:    u.FolderID,
+		Tags:        tags,
+		Aliases:     aliases,
 		Title:       u.Title,
 		Description: u.Description,
 		Clicks:      u.Clicks,
 		ExpiresAt:   u.ExpiresAt,
 		IsActive:    u.IsActive,
 		CreatedAt:   u.CreatedAt,
+
+		TakenDownAt:    u.TakenDownAt,
+		TakedownReason: u.TakedownReason,
+	}
+}
+
+type URLStatus string
+
+const (
+	URLStatusActive   URLStatus = "active"   // can be followed now
+	URLStatusExpired  URLStatus = "expired"  // past its expiry
+	URLStatusInactive URLStatus = "inactive" // turned off or taken down
+)
+
+// urlSortFields are the fields link listings can be sorted by
+var urlSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"clicks":     true,
+	"title":      true,
+	"expires_at": true,
+}
+
+// URLFilter narrows and orders a list of links. Query is a full-text
+// search of the title, description, destination and short code. Every tag
+// in Tags must be on a link for it to match. FolderID "none" matches links
+// outside any folder, and Domain also matches its subdomains.
+type URLFilter struct {
+	Query    string
+	Tags     []string
+	FolderID string
+	Status   URLStatus
+	Domain   string
+	From     *time.Time
+	To       *time.Time
+	Sort     string // a sort field, prefixed with "-" for descending
+	Page     pagination.Params
+}
+
+// OrderBy returns the ORDER BY clause for Sort, newest first by default.
+// Ties are broken by id so pages don't overlap.
+func (f *URLFilter) OrderBy() (string, error) {
+	if f.Sort == "" {
+		return "created_at DESC, id DESC", nil
 	}
-}
\ No newline at end of file
+	field, dir := f.Sort, "ASC"
+	if strings.HasPrefix(field, "-") {
+		field, dir = field[1:], "DESC"
+	}
+	if !urlSortFields[field] {
+		return "", ErrInvalidInput
+	}
+	return fmt.Sprintf("%s %s, id %s", field, dir, dir), nil
+}
+
+// Check reports whether the status and sort are ones the filter knows
+func (f *URLFilter) Check() error {
+	switch f.Status {
+	case "", URLStatusActive, URLStatusExpired, URLStatusInactive:
+	default:
+		return ErrInvalidInput
+	}
+	_, err := f.OrderBy()
+	return err
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	exportScheduleSid, _ = shortid.New(1, shortid.DefaultABC, 9471)
+)
+
+type URLExportKind string
+
+const (
+	URLExportLinks  URLExportKind = "links"
+	URLExportClicks URLExportKind = "clicks"
+)
+
+// Columns each kind of export can have, in their default order
+var (
+	LinkExportColumns = []string{
+		"id", "short_code", "short_url", "original_url", "title", "description",
+		"clicks", "is_active", "workspace_id", "expires_at", "created_at", "updated_at",
+	}
+	ClickExportColumns = []string{
+		"id", "url_id", "ip_address", "referrer", "user_agent", "country",
+		"city", "device", "os", "browser", "created_at",
+	}
+)
+
+func (k URLExportKind) Valid() bool {
+	return k == URLExportLinks || k == URLExportClicks
+}
+
+func (k URLExportKind) Columns() []string {
+	if k == URLExportClicks {
+		return ClickExportColumns
+	}
+	return LinkExportColumns
+}
+
+// SelectColumns picks the columns named in a comma-separated list, in the
+// order given. An empty list selects every column.
+func (k URLExportKind) SelectColumns(fields string) ([]string, error) {
+	if strings.TrimSpace(fields) == "" {
+		return k.Columns(), nil
+	}
+	return k.CheckColumns(strings.Split(fields, ","))
+}
+
+// CheckColumns returns the named columns without blanks or repeats
+func (k URLExportKind) CheckColumns(names []string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, column := range k.Columns() {
+		known[column] = true
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if !known[name] {
+			return nil, ErrUnknownExportField
+		}
+		seen[name] = true
+		columns = append(columns, name)
+	}
+	if len(columns) == 0 {
+		return k.Columns(), nil
+	}
+	return columns, nil
+}
+
+// URLExportQuery selects what an export contains. Links are filtered by
+// when they were created and clicks by when they happened; both ends of the
+// range are optional. Without URLID a click export covers every link in
+// scope.
+type URLExportQuery struct {
+	Kind        URLExportKind
+	Format      string
+	Columns     []string
+	URLID       string
+	WorkspaceID string
+	From        *time.Time
+	To          *time.Time
+}
+
+type ExportFrequency string
+
+const (
+	ExportDaily  ExportFrequency = "daily"
+	ExportWeekly ExportFrequency = "weekly"
+)
+
+func (f ExportFrequency) Period() time.Duration {
+	if f == ExportWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+type ExportDelivery string
+
+const (
+	ExportByEmail   ExportDelivery = "email"
+	ExportToStorage ExportDelivery = "storage"
+)
+
+// ExportSchedule runs an export every day or week and emails it to the user
+// or saves it to storage. Click exports cover the clicks since the last
+// export that was delivered; link exports list every link. A stored file
+// replaces the one before it.
+type ExportSchedule struct {
+	ID          string          `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string          `json:"-" gorm:"type:varchar(20);not null;index"`
+	Name        string          `json:"name"`
+	Kind        URLExportKind   `json:"kind" gorm:"type:varchar(10);not null"`
+	Format      string          `json:"format" gorm:"type:varchar(10);not null"`
+	Columns     []string        `json:"fields" gorm:"serializer:json;type:text"`
+	URLID       *string         `json:"url_id,omitempty" gorm:"type:varchar(20)"`
+	WorkspaceID *string         `json:"workspace_id,omitempty" gorm:"type:varchar(20)"`
+	Frequency   ExportFrequency `json:"frequency" gorm:"type:varchar(10);not null"`
+	Delivery    ExportDelivery  `json:"delivery" gorm:"type:varchar(10);not null"`
+	NextRunAt   time.Time       `json:"next_run_at"`
+	LastRunAt   *time.Time      `json:"last_run_at,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	LastFileURL string          `json:"last_file_url,omitempty"`
+	LastFileKey string          `json:"-"`
+	// CoveredUntil is the end of the last click window delivered
+	CoveredUntil *time.Time `json:"covered_until,omitempty"`
+	LockedUntil  *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (s *ExportSchedule) BeforeCreate(tx *gorm.DB) error {
+	id, err := exportScheduleSid.Generate()
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	return nil
+}
+
+type CreateExportScheduleRequest struct {
+	Name        string          `json:"name" validate:"max=100"`
+	Kind        URLExportKind   `json:"kind" validate:"required,oneof=links clicks"`
+	Format      string          `json:"format" validate:"omitempty,oneof=csv ndjson jsonl"`
+	Fields      []string        `json:"fields"`
+	URLID       string          `json:"url_id,omitempty"`
+	WorkspaceID string          `json:"workspace_id,omitempty"`
+	Frequency   ExportFrequency `json:"frequency" validate:"required,oneof=daily weekly"`
+	Delivery    ExportDelivery  `json:"delivery" validate:"required,oneof=email storage"`
+}
+
+func (r *CreateExportScheduleRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	importSid, _ = shortid.New(1, shortid.DefaultABC, 8462)
+)
+
+// Error codes reported for rows of a bulk request
+const (
+	BulkErrInvalidRow         = "invalid_row"
+	BulkErrInvalidURL         = "invalid_url"
+	BulkErrInvalidCustomCode  = "invalid_custom_code"
+	BulkErrInvalidExpiry      = "invalid_expires_at"
+	BulkErrDuplicateCode      = "duplicate_short_code"
+	BulkErrShortCodeTaken     = "short_code_taken"
+	BulkErrInsufficientCredit = "insufficient_credits"
+	BulkErrImportFailed       = "import_failed"
+)
+
+type BulkURLStatus string
+
+const (
+	BulkURLCreated BulkURLStatus = "created"
+	BulkURLFailed  BulkURLStatus = "failed"
+)
+
+// BulkURLRow is one link in a bulk request. ExpiresAt is RFC 3339, so rows
+// read from JSON and CSV look the same. OriginalURL and CustomCode are
+// checked by the same rules as a single link.
+type BulkURLRow struct {
+	OriginalURL string `json:"original_url"`
+	CustomCode  string `json:"custom_code"`
+	Title       string `json:"title" validate:"max=100"`
+	Description string `json:"description" validate:"max=255"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+// bulkRowFields maps the row's fields to their JSON names for error messages
+var bulkRowFields = map[string]string{
+	"Title":       "title",
+	"Description": "description",
+}
+
+// Validate checks the row's fields. The error names each field that failed
+// and the rule it broke, e.g. "title: max".
+func (r *BulkURLRow) Validate() error {
+	err := validate.Struct(r)
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	problems := make([]string, 0, len(fieldErrors))
+	for _, fe := range fieldErrors {
+		problems = append(problems, bulkRowFields[fe.Field()]+": "+fe.Tag())
+	}
+	return errors.New(strings.Join(problems, ", "))
+}
+
+type CreateBulkURLRequest struct {
+	URLs []*BulkURLRow `json:"urls" validate:"required,min=1"`
+	// WorkspaceID creates every link in a workspace, paid for with its credits
+	WorkspaceID string `json:"workspace_id,omitempty"`
+}
+
+func (r *CreateBulkURLRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// BulkURLResult is the outcome of one row. Row counts from 1 in the order
+// the rows were sent, not counting a CSV header.
+type BulkURLResult struct {
+	Row     int           `json:"row"`
+	Status  BulkURLStatus `json:"status"`
+	URL     *URLResponse  `json:"url,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+type URLImportStatus string
+
+const (
+	URLImportPending    URLImportStatus = "pending"
+	URLImportProcessing URLImportStatus = "processing"
+	URLImportCompleted  URLImportStatus = "completed"
+	URLImportFailed     URLImportStatus = "failed"
+)
+
+// URLImport is a bulk request. Small ones are answered straight away and
+// never stored; large ones are queued and processed by the import worker.
+// Rows are kept until the import has run.
+type URLImport struct {
+	ID           string           `json:"id,omitempty" gorm:"primaryKey;type:varchar(20)"`
+	UserID       string           `json:"-" gorm:"type:varchar(20);not null;index"`
+	WorkspaceID  *string          `json:"workspace_id,omitempty" gorm:"type:varchar(20)"`
+	Status       URLImportStatus  `json:"status" gorm:"type:varchar(20);not null"`
+	Source       string           `json:"source" gorm:"type:varchar(10);not null"` // json or csv
+	TotalRows    int              `json:"total_rows"`
+	CreatedCount int              `json:"created_count"`
+	FailedCount  int              `json:"failed_count"`
+	Error        string           `json:"error,omitempty"`
+	Rows         []*BulkURLRow    `json:"-" gorm:"serializer:json;type:text"`
+	Results      []*BulkURLResult `json:"results,omitempty" gorm:"serializer:json;type:text"`
+	LockedUntil  *time.Time       `json:"-"`
+	StartedAt    *time.Time       `json:"started_at,omitempty"`
+	CompletedAt  *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt    time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (i *URLImport) BeforeCreate(tx *gorm.DB) error {
+	id, err := importSid.Generate()
+	if err != nil {
+		return err
+	}
+	i.ID = id
+	return nil
+}
+
+// Finish records the results and marks the import as done
+func (i *URLImport) Finish(status URLImportStatus, results []*BulkURLResult, now time.Time) {
+	i.Status = status
+	i.Results = results
+	i.Rows = nil
+	i.LockedUntil = nil
+	i.CompletedAt = &now
+	i.CreatedCount, i.FailedCount = 0, 0
+	for _, result := range results {
+		if result.Status == BulkURLCreated {
+			i.CreatedCount++
+		} else {
+			i.FailedCount++
+		}
+	}
+}
+
+// URLBatch is a set of links created together in one transaction. Entries
+// that failed validation carry an error code and are skipped.
+type URLBatch struct {
+	UserID      string
+	WorkspaceID *string
+	FreeLimit   int    // free links a user may create in total; unused for workspaces
+	BaseURL     string // for the short URLs in the results
+	Entries     []*URLBatchEntry
+
+	// NewCode makes a random short code, to replace random codes that turn
+	// out to be taken by the time the batch is created
+	NewCode func() string
+}
+
+type URLBatchEntry struct {
+	Row     int
+	URL     *URL
+	Custom  bool // custom codes are never replaced
+	Error   string
+	Message string
+}
+
+// Valid returns the entries still to be created
+func (b *URLBatch) Valid() []*URLBatchEntry {
+	var valid []*URLBatchEntry
+	for _, entry := range b.Entries {
+		if entry.Error == "" {
+			valid = append(valid, entry)
+		}
+	}
+	return valid
+}
+
+// Results reports each entry in row order. Valid entries count as created,
+// so this is only meaningful once the batch has been committed.
+func (b *URLBatch) Results() []*BulkURLResult {
+	results := make([]*BulkURLResult, len(b.Entries))
+	for i, entry := range b.Entries {
+		if entry.Error != "" {
+			results[i] = &BulkURLResult{Row: entry.Row, Status: BulkURLFailed, Error: entry.Error, Message: entry.Message}
+			continue
+		}
+		results[i] = &BulkURLResult{Row: entry.Row, Status: BulkURLCreated, URL: entry.URL.ToResponse(b.BaseURL)}
+	}
+	return results
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	urlRevisionSid, _ = shortid.New(1, shortid.DefaultABC, 9805)
+)
+
+// URLRevision is the state a link was left in by one edit. Revisions are
+// numbered from 1 for each link; the first one is the link as it was before
+// its first edit. Changes names the fields the edit touched.
+type URLRevision struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	URLID        string     `json:"url_id" gorm:"type:varchar(20);not null;uniqueIndex:idx_url_revisions_url_number"`
+	Number       int        `json:"number" gorm:"not null;uniqueIndex:idx_url_revisions_url_number"`
+	UserID       *string    `json:"user_id,omitempty" gorm:"type:varchar(20)"`
+	OriginalURL  string     `json:"original_url" gorm:"not null"`
+	ShortCode    string     `json:"short_code" gorm:"type:varchar(10);not null"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	IsActive     bool       `json:"is_active"`
+	Changes      []string   `json:"changes" gorm:"serializer:json;type:text"`
+	RestoredFrom *int       `json:"restored_from,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (r *URLRevision) BeforeCreate(tx *gorm.DB) error {
+	id, err := urlRevisionSid.Generate()
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	return nil
+}
+
+// NewURLRevision snapshots the editable fields of u
+func NewURLRevision(u *URL) *URLRevision {
+	return &URLRevision{
+		URLID:       u.ID,
+		OriginalURL: u.OriginalURL,
+		ShortCode:   u.ShortCode,
+		Title:       u.Title,
+		Description: u.Description,
+		ExpiresAt:   u.ExpiresAt,
+		IsActive:    u.IsActive,
+	}
+}
+
+// URLAlias is a short code a link had before it was changed. The old code
+// keeps redirecting to the link and can't be given to another one.
+type URLAlias struct {
+	ShortCode string    `json:"short_code" gorm:"primaryKey;type:varchar(10)"`
+	URLID     string    `json:"url_id" gorm:"type:varchar(20);not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// UpdateURLRequest changes a link. Fields left out are kept as they are;
+// ClearExpiry removes the expiry. Changing the short code keeps the old one
+// as an alias.
+type UpdateURLRequest struct {
+	OriginalURL *string    `json:"original_url" validate:"omitempty,url"`
+	ShortCode   *string    `json:"short_code" validate:"omitempty,alphanum,min=3,max=10"`
+	Title       *string    `json:"title" validate:"omitempty,max=100"`
+	Description *string    `json:"description" validate:"omitempty,max=255"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	ClearExpiry bool       `json:"clear_expiry"`
+	IsActive    *bool      `json:"is_active"`
+}
+
+func (r *UpdateURLRequest) Validate() error {
+	return validate.Struct(r)
+}
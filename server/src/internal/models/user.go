@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -15,6 +16,14 @@ const (
 	RoleUser  Role = "user"
 )
 
+// VerificationPurpose tells what a pending verification token confirms.
+type VerificationPurpose string
+
+const (
+	VerificationPurposeSignup      VerificationPurpose = "signup"
+	VerificationPurposeEmailChange VerificationPurpose = "email_change"
+)
+
 var (
 	validate = validator.New()
 	sid, _   = shortid.New(1, shortid.DefaultABC, 2342)
@@ -34,18 +43,38 @@ type User struct {
 	IsActive   bool   `json:"is_active" gorm:"default:true"`
 	IsVerified bool   `json:"is_verified" gorm:"default:false"`
 
-	VerificationToken     string     `json:"-" gorm:"type:varchar(255)"`
-	VerificationExpiresAt *time.Time `json:"-" gorm:"type:timestamp"`
+	VerificationToken     string              `json:"-" gorm:"type:varchar(255)"`
+	VerificationPurpose   VerificationPurpose `json:"-" gorm:"type:varchar(20)"`
+	VerificationExpiresAt *time.Time          `json:"-" gorm:"type:timestamp"`
+	PendingEmail          string              `json:"pending_email,omitempty" gorm:"type:varchar(255)"`
 
 	ResetPasswordToken     string     `json:"-" gorm:"type:varchar(255)"`
 	ResetPasswordExpiresAt *time.Time `json:"-" gorm:"type:timestamp"`
 
 	RefreshToken string `json:"-" gorm:"-:all"`
 
+	// PaymentCustomerID is the customer ID at the payment provider.
+	PaymentCustomerID string `json:"-" gorm:"type:varchar(255);index"`
+
+	// BillingAddress is printed on invoices issued after it is set.
+	BillingAddress BillingAddress `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at,omitempty" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time  `json:"updated_at,omitempty" gorm:"autoUpdateTime"`
 	DeletedAt   *time.Time `json:"-" gorm:"index"`
+
+	// DeletionScheduledAt is set while a deleted account is in its grace
+	// period and marks when its personal data will be purged.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"index"`
+
+	// SuspendedAt is set while an admin has suspended the account. Suspended
+	// accounts are inactive and can't sign in.
+	SuspendedAt *time.Time `json:"suspended_at,omitempty"`
+
+	// CreditsLowAt is set when a credits.low event is sent and cleared once
+	// the balance is back above the threshold, so the event fires once per dip.
+	CreditsLowAt *time.Time `json:"-"`
 }
 
 // Request/Response structs
@@ -55,6 +84,8 @@ type RegisterRequest struct {
 	Username  string `json:"username" validate:"required,min=3,max=30,alphanum"`
 	Email     string `json:"email" validate:"required,email"`
 	Password  string `json:"password" validate:"required,min=8"`
+	// ReferralCode may also be given as ?ref= on the signup URL
+	ReferralCode string `json:"referral_code" validate:"omitempty,max=20"`
 }
 
 type LoginRequest struct {
@@ -63,10 +94,11 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	User        User   `json:"user"`
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
+	User         User   `json:"user"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"-"` // delivered only as an HttpOnly cookie
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 type UserProfileResponse struct {
@@ -77,6 +109,67 @@ type UpdateProfileRequest struct {
 	FirstName string `json:"first_name" validate:"required,min=2,max=50"`
 	LastName  string `json:"last_name" validate:"required,min=2,max=50"`
 	Bio       string `json:"bio,omitempty" validate:"omitempty,max=500"`
+	// BillingAddress replaces the stored address when given
+	BillingAddress *BillingAddress `json:"billing_address,omitempty"`
+}
+
+// BillingAddress is who invoices are made out to. Country is an ISO 3166-1
+// alpha-2 code.
+type BillingAddress struct {
+	Name       string `json:"name,omitempty" gorm:"type:varchar(100)" validate:"omitempty,max=100"`
+	Line1      string `json:"line1,omitempty" gorm:"type:varchar(200)" validate:"omitempty,max=200"`
+	Line2      string `json:"line2,omitempty" gorm:"type:varchar(200)" validate:"omitempty,max=200"`
+	City       string `json:"city,omitempty" gorm:"type:varchar(100)" validate:"omitempty,max=100"`
+	State      string `json:"state,omitempty" gorm:"type:varchar(100)" validate:"omitempty,max=100"`
+	PostalCode string `json:"postal_code,omitempty" gorm:"type:varchar(20)" validate:"omitempty,max=20"`
+	Country    string `json:"country,omitempty" gorm:"type:varchar(2)" validate:"omitempty,len=2,alpha"`
+	TaxID      string `json:"tax_id,omitempty" gorm:"type:varchar(50)" validate:"omitempty,max=50"`
+}
+
+// Lines returns the address as it is printed, skipping empty parts.
+func (a BillingAddress) Lines() []string {
+	var lines []string
+	for _, line := range []string{
+		a.Name,
+		a.Line1,
+		a.Line2,
+		strings.TrimSpace(strings.Join(nonEmpty(a.PostalCode, a.City, a.State), " ")),
+		strings.ToUpper(a.Country),
+	} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (r *UpdateProfileRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ConfirmEmailChangeRequest carries the token from the confirmation email,
+// sent as JSON or from the confirmation page's form
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" form:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
 type PasswordResetRequest struct {
@@ -143,14 +236,17 @@ func (u *User) Sanitize() {
 	u.VerificationToken = ""
 }
 
-func (u *User) GenerateVerificationToken(token string, expires time.Time) {
+func (u *User) GenerateVerificationToken(purpose VerificationPurpose, token string, expires time.Time) {
 	u.VerificationToken = token
+	u.VerificationPurpose = purpose
 	u.VerificationExpiresAt = &expires
 }
 
 func (u *User) ClearVerificationToken() {
 	u.VerificationToken = ""
+	u.VerificationPurpose = ""
 	u.VerificationExpiresAt = nil
+	u.PendingEmail = ""
 }
 
 func (u *User) GenerateResetToken(token string, expires time.Time) {
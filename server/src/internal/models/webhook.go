@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	webhookEndpointSid, _ = shortid.New(1, shortid.DefaultABC, 5923)
+	webhookDeliverySid, _ = shortid.New(1, shortid.DefaultABC, 6034)
+)
+
+// WebhookEndpoint is a URL a user registered to receive events. Events lists
+// the event types it receives; empty means all of them. After too many
+// deliveries in a row fail, the endpoint is disabled until the user turns
+// it back on.
+type WebhookEndpoint struct {
+	ID                  string     `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	UserID              string     `json:"-" gorm:"type:varchar(20);not null;index"`
+	URL                 string     `json:"url" gorm:"not null"`
+	Description         string     `json:"description"`
+	Secret              string     `json:"-" gorm:"not null"`
+	Events              []string   `json:"events" gorm:"serializer:json;type:text"`
+	IsActive            bool       `json:"is_active" gorm:"default:true"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"default:0"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (e *WebhookEndpoint) BeforeCreate(tx *gorm.DB) error {
+	id, err := webhookEndpointSid.Generate()
+	if err != nil {
+		return err
+	}
+	e.ID = id
+	return nil
+}
+
+// Subscribed reports whether the endpoint receives events of eventType
+func (e *WebhookEndpoint) Subscribed(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEndpointWithSecret is returned when an endpoint is created or its
+// secret rotated; the secret isn't shown again.
+type WebhookEndpointWithSecret struct {
+	*WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event queued for one endpoint. Pending deliveries
+// are retried with exponential backoff until they succeed or run out of
+// attempts. ResponseCode and ResponseBody come from the last attempt.
+type WebhookDelivery struct {
+	ID            string                `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	EndpointID    string                `json:"endpoint_id" gorm:"type:varchar(20);not null;index"`
+	EventID       string                `json:"event_id" gorm:"type:varchar(64);not null"`
+	EventType     string                `json:"event_type" gorm:"type:varchar(50);not null"`
+	Payload       string                `json:"payload" gorm:"type:text;not null"`
+	Status        WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	Attempts      int                   `json:"attempts" gorm:"default:0"`
+	NextAttemptAt *time.Time            `json:"next_attempt_at,omitempty"`
+	LockedUntil   *time.Time            `json:"-"`
+	ResponseCode  int                   `json:"response_code,omitempty"`
+	ResponseBody  string                `json:"response_body,omitempty"`
+	LastError     string                `json:"last_error,omitempty"`
+	DurationMS    int64                 `json:"duration_ms,omitempty"`
+	RedeliveryOf  *string               `json:"redelivery_of,omitempty" gorm:"type:varchar(20)"`
+	DeliveredAt   *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	id, err := webhookDeliverySid.Generate()
+	if err != nil {
+		return err
+	}
+	d.ID = id
+	return nil
+}
+
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	Description string   `json:"description" validate:"max=255"`
+	Events      []string `json:"events" validate:"omitempty,dive,required"`
+}
+
+func (r *CreateWebhookEndpointRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// UpdateWebhookEndpointRequest changes the fields that are set. Turning a
+// disabled endpoint back on resets its failure count.
+type UpdateWebhookEndpointRequest struct {
+	URL         *string   `json:"url" validate:"omitempty,url,max=2048"`
+	Description *string   `json:"description" validate:"omitempty,max=255"`
+	Events      *[]string `json:"events" validate:"omitempty,dive,required"`
+	IsActive    *bool     `json:"is_active"`
+}
+
+func (r *UpdateWebhookEndpointRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"time"
+
+	"github.com/teris-io/shortid"
+	"gorm.io/gorm"
+)
+
+var (
+	workspaceSid, _ = shortid.New(1, shortid.DefaultABC, 4217)
+)
+
+// WorkspaceRole is a member's role in a workspace. Roles are ordered: each one
+// can do everything the roles below it can.
+type WorkspaceRole string
+
+const (
+	WorkspaceOwner  WorkspaceRole = "owner"
+	WorkspaceAdmin  WorkspaceRole = "admin"
+	WorkspaceEditor WorkspaceRole = "editor"
+	WorkspaceViewer WorkspaceRole = "viewer"
+)
+
+// Permission is something a workspace member may be allowed to do.
+type Permission string
+
+const (
+	PermissionViewLinks       Permission = "links:view"       // list links and read their analytics
+	PermissionEditLinks       Permission = "links:edit"       // create, update and delete links
+	PermissionViewBilling     Permission = "billing:view"     // see credits and the subscription
+	PermissionManageBilling   Permission = "billing:manage"   // subscribe and cancel
+	PermissionManageMembers   Permission = "members:manage"   // invite, remove and change roles
+	PermissionManageWorkspace Permission = "workspace:manage" // rename and delete the workspace
+)
+
+var rolePermissions = map[WorkspaceRole][]Permission{
+	WorkspaceOwner: {
+		PermissionViewLinks, PermissionEditLinks, PermissionViewBilling,
+		PermissionManageBilling, PermissionManageMembers, PermissionManageWorkspace,
+	},
+	WorkspaceAdmin: {
+		PermissionViewLinks, PermissionEditLinks, PermissionViewBilling,
+		PermissionManageBilling, PermissionManageMembers,
+	},
+	WorkspaceEditor: {PermissionViewLinks, PermissionEditLinks, PermissionViewBilling},
+	WorkspaceViewer: {PermissionViewLinks},
+}
+
+var roleRanks = map[WorkspaceRole]int{
+	WorkspaceViewer: 1,
+	WorkspaceEditor: 2,
+	WorkspaceAdmin:  3,
+	WorkspaceOwner:  4,
+}
+
+func (r WorkspaceRole) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Can reports whether the role grants p.
+func (r WorkspaceRole) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Outranks reports whether r is strictly above other.
+func (r WorkspaceRole) Outranks(other WorkspaceRole) bool {
+	return roleRanks[r] > roleRanks[other]
+}
+
+// Workspace is a team that shares links, credits and a subscription.
+type Workspace struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	OwnerID   string    `json:"owner_id" gorm:"type:varchar(20);index;not null"`
+	Owner     User      `json:"-" gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (w *Workspace) BeforeCreate(tx *gorm.DB) error {
+	id, err := workspaceSid.Generate()
+	if err != nil {
+		return err
+	}
+	w.ID = id
+	return nil
+}
+
+// WorkspaceMember gives a user a role in a workspace. The owner is a member
+// too, with WorkspaceOwner.
+type WorkspaceMember struct {
+	WorkspaceID string        `json:"workspace_id" gorm:"primaryKey;type:varchar(20)"`
+	UserID      string        `json:"user_id" gorm:"primaryKey;type:varchar(20)"`
+	User        *User         `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Role        WorkspaceRole `json:"role" gorm:"type:varchar(20);not null"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WorkspaceInvitation invites an email address to join a workspace. Only the
+// SHA-256 hash of the token sent by email is stored.
+type WorkspaceInvitation struct {
+	ID          string        `json:"id" gorm:"primaryKey;type:varchar(20)"`
+	WorkspaceID string        `json:"workspace_id" gorm:"type:varchar(20);index;not null"`
+	Workspace   *Workspace    `json:"-" gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE"`
+	Email       string        `json:"email" gorm:"type:varchar(255);not null"`
+	Role        WorkspaceRole `json:"role" gorm:"type:varchar(20);not null"`
+	TokenHash   string        `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	InvitedBy   *string       `json:"invited_by,omitempty" gorm:"type:varchar(20)"`
+	ExpiresAt   time.Time     `json:"expires_at" gorm:"not null"`
+	AcceptedAt  *time.Time    `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (i *WorkspaceInvitation) BeforeCreate(tx *gorm.DB) error {
+	id, err := workspaceSid.Generate()
+	if err != nil {
+		return err
+	}
+	i.ID = id
+	return nil
+}
+
+// WorkspaceResponse is a workspace as seen by one of its members.
+type WorkspaceResponse struct {
+	Workspace
+	Role WorkspaceRole `json:"role"`
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+func (r *CreateWorkspaceRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type UpdateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+func (r *UpdateWorkspaceRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type InviteMemberRequest struct {
+	Email string        `json:"email" validate:"required,email,max=255"`
+	Role  WorkspaceRole `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+func (r *InviteMemberRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type UpdateMemberRoleRequest struct {
+	Role WorkspaceRole `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+func (r *UpdateMemberRoleRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (r *AcceptInvitationRequest) Validate() error {
+	return validate.Struct(r)
+}
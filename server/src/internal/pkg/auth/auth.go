@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -8,12 +12,48 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/models"
 )
 
+// Audiences identify what a token may be used for, so a token issued for one
+// purpose is rejected everywhere else.
+const (
+	AudienceAccess       = "brevity:access"
+	AudienceRefresh      = "brevity:refresh"
+	AudienceVerification = "brevity:verification"
+	AudienceReset        = "brevity:reset"
+)
+
+// AccountCheck loads the account behind an access token. It returns
+// models.ErrInvalidToken when the account may no longer use its tokens.
+type AccountCheck func(ctx context.Context, userID string) (*models.User, error)
+
 type Auth struct {
-	cfg *configs.JWTConfig
+	cfg          *configs.JWTConfig
+	keys         *KeySet // nil when signing with shared HS256 secrets
+	accountCheck AccountCheck
 }
 
-func NewAuth(cfg *configs.JWTConfig) *Auth {
-	return &Auth{cfg: cfg}
+func NewAuth(cfg *configs.JWTConfig) (*Auth, error) {
+	a := &Auth{cfg: cfg}
+
+	if a.isSymmetric() {
+		for audience, secret := range map[string]string{
+			AudienceAccess:       cfg.AccessTokenSecret,
+			AudienceRefresh:      cfg.RefreshTokenSecret,
+			AudienceVerification: cfg.VerificationTokenSecret,
+			AudienceReset:        cfg.ResetTokenSecret,
+		} {
+			if secret == "" {
+				return nil, fmt.Errorf("jwt secret for %s is not configured", audience)
+			}
+		}
+		return a, nil
+	}
+
+	keys, err := NewKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.keys = keys
+	return a, nil
 }
 
 type Claims struct {
@@ -28,33 +68,11 @@ type Tokens struct {
 }
 
 func (a *Auth) GenerateAccessToken(userId, role string) (string, error) {
-	claims := &Claims{
-		UserId: userId,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(a.cfg.AccessTokenExpiry) * time.Minute)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    a.cfg.Issuer,
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.cfg.AccessTokenSecret))
+	return a.sign(AudienceAccess, userId, role, a.cfg.AccessTokenExpiry)
 }
 
 func (a *Auth) GenerateRefreshToken(userId, role string) (string, error) {
-	claims := &Claims{
-		UserId: userId,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(a.cfg.RefreshTokenExpiry) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    a.cfg.Issuer,
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.cfg.RefreshTokenSecret))
+	return a.sign(AudienceRefresh, userId, role, a.cfg.RefreshTokenExpiry)
 }
 
 func (a *Auth) GenerateTokens(userId, role string) (*Tokens, error) {
@@ -75,87 +93,126 @@ func (a *Auth) GenerateTokens(userId, role string) (*Tokens, error) {
 }
 
 func (a *Auth) GenerateVerificationToken(userId string) (string, error) {
-	claims := &Claims{
-		UserId: userId,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours expiry
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    a.cfg.Issuer,
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.cfg.AccessTokenSecret)) // Using access token secret for verification
+	return a.sign(AudienceVerification, userId, "", 24*time.Hour) // 24 hours expiry
 }
 
 func (a *Auth) GeneratePasswordResetToken(userId string) (string, error) {
-	claims := &Claims{
-		UserId: userId,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)), // 15 minutes expiry
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    a.cfg.Issuer,
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(a.cfg.ResetTokenSecret))
+	return a.sign(AudienceReset, userId, "", 15*time.Minute) // 15 minutes expiry
 }
 
 func (a *Auth) VerifyAccessToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, models.ErrInvalidToken
-		}
-		return []byte(a.cfg.AccessTokenSecret), nil
-	})
+	return a.verify(AudienceAccess, tokenString)
+}
 
-	if err != nil {
-		if err == jwt.ErrTokenExpired {
-			return nil, models.ErrExpiredToken
-		}
-		return nil, models.ErrInvalidToken
+// SetAccountCheck installs a check that every access token's account must
+// pass, so an account can be shut off before its tokens expire.
+func (a *Auth) SetAccountCheck(check AccountCheck) {
+	a.accountCheck = check
+}
+
+// CheckAccount runs the account check for a verified access token. It passes
+// with no user when no check is installed.
+func (a *Auth) CheckAccount(ctx context.Context, userID string) (*models.User, error) {
+	if a.accountCheck == nil {
+		return nil, nil
 	}
+	return a.accountCheck(ctx, userID)
+}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+func (a *Auth) VerifyRefreshToken(tokenString string) (*Claims, error) {
+	return a.verify(AudienceRefresh, tokenString)
+}
+
+func (a *Auth) VerifyVerificationToken(tokenString string) (*Claims, error) {
+	return a.verify(AudienceVerification, tokenString)
+}
+
+func (a *Auth) VerifyPasswordResetToken(tokenString string) (*Claims, error) {
+	return a.verify(AudienceReset, tokenString)
+}
+
+// JWKS returns the public verification keys. It is empty when tokens are
+// signed with shared secrets, since those must never be published.
+func (a *Auth) JWKS() *JWKS {
+	if a.keys == nil {
+		return &JWKS{Keys: []JWK{}}
 	}
+	return a.keys.JWKS()
+}
 
-	return nil, models.ErrInvalidToken
+func (a *Auth) isSymmetric() bool {
+	return a.cfg.Algorithm == "" || strings.EqualFold(a.cfg.Algorithm, "HS256")
 }
 
-func (a *Auth) VerifyRefreshToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, models.ErrInvalidToken
-		}
-		return []byte(a.cfg.RefreshTokenSecret), nil
-	})
+func (a *Auth) secretFor(audience string) []byte {
+	switch audience {
+	case AudienceAccess:
+		return []byte(a.cfg.AccessTokenSecret)
+	case AudienceRefresh:
+		return []byte(a.cfg.RefreshTokenSecret)
+	case AudienceVerification:
+		return []byte(a.cfg.VerificationTokenSecret)
+	case AudienceReset:
+		return []byte(a.cfg.ResetTokenSecret)
+	default:
+		return nil
+	}
+}
 
-	if err != nil {
-		if err == jwt.ErrTokenExpired {
-			return nil, models.ErrExpiredToken
-		}
-		return nil, models.ErrInvalidToken
+func (a *Auth) sign(audience, userId, role string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserId: userId,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userId,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    a.cfg.Issuer,
+		},
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	if a.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(a.secretFor(audience))
 	}
 
-	return nil, models.ErrInvalidToken
+	key := a.keys.active
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
 }
 
-func (a *Auth) VerifyPasswordResetToken(tokenString string) (*Claims, error) {
+func (a *Auth) verify(audience, tokenString string) (*Claims, error) {
+	opts := []jwt.ParserOption{jwt.WithAudience(audience)}
+	if a.cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.cfg.Issuer))
+	}
+	if a.keys == nil {
+		opts = append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	} else {
+		opts = append(opts, jwt.WithValidMethods(a.keys.Methods()))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if a.keys == nil {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, models.ErrInvalidToken
+			}
+			return a.secretFor(audience), nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, ok := a.keys.Lookup(kid)
+		if !ok || key.Method.Alg() != token.Method.Alg() {
 			return nil, models.ErrInvalidToken
 		}
-		return []byte(a.cfg.ResetTokenSecret), nil
-	})
+		return key.public, nil
+	}, opts...)
 
 	if err != nil {
-		if err == jwt.ErrTokenExpired {
+		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, models.ErrExpiredToken
 		}
 		return nil, models.ErrInvalidToken
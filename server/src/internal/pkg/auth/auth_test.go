@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/imraushankr/bervity/server/src/configs"
+)
+
+func newTestAuth(t *testing.T) *Auth {
+	t.Helper()
+	a, err := NewAuth(&configs.JWTConfig{
+		Algorithm:               "HS256",
+		AccessTokenSecret:       "access",
+		AccessTokenExpiry:       15 * time.Minute,
+		RefreshTokenSecret:      "refresh",
+		RefreshTokenExpiry:      168 * time.Hour,
+		ResetTokenSecret:        "reset",
+		VerificationTokenSecret: "verification",
+		Issuer:                  "brevity-test",
+	})
+	if err != nil {
+		t.Fatalf("NewAuth: %v", err)
+	}
+	return a
+}
+
+func TestTokenLifetimes(t *testing.T) {
+	a := newTestAuth(t)
+
+	tests := []struct {
+		name     string
+		generate func() (string, error)
+		want     time.Duration
+	}{
+		{"access", func() (string, error) { return a.GenerateAccessToken("user-1", "user") }, 15 * time.Minute},
+		{"refresh", func() (string, error) { return a.GenerateRefreshToken("user-1", "user") }, 168 * time.Hour},
+		{"verification", func() (string, error) { return a.GenerateVerificationToken("user-1") }, 24 * time.Hour},
+		{"reset", func() (string, error) { return a.GeneratePasswordResetToken("user-1") }, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.generate()
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+
+			// Read the claims without verifying, only the timestamps matter here
+			claims := &Claims{}
+			if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if claims.ExpiresAt == nil || claims.IssuedAt == nil {
+				t.Fatalf("missing exp or iat: %+v", claims.RegisteredClaims)
+			}
+			if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != tt.want {
+				t.Errorf("lifetime = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAccessTokenRoundTrip(t *testing.T) {
+	a := newTestAuth(t)
+
+	token, err := a.GenerateAccessToken("user-1", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := a.VerifyAccessToken(token)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if claims.UserId != "user-1" || claims.Role != "admin" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	// A refresh token must not pass as an access token
+	refresh, err := a.GenerateRefreshToken("user-1", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.VerifyAccessToken(refresh); err == nil {
+		t.Error("refresh token accepted as access token")
+	}
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/imraushankr/bervity/server/src/configs"
+)
+
+// Key is a single asymmetric signing key identified by its kid.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// KeySet holds the active signing key and every key still accepted for
+// verification during a rotation.
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// JWK is the public part of a key as published in the JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeySet loads every configured key and selects the active one.
+func NewKeySet(cfg *configs.JWTConfig) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+
+	for _, kc := range cfg.Keys {
+		alg := kc.Algorithm
+		if alg == "" {
+			alg = cfg.Algorithm
+		}
+
+		key, err := loadKey(kc.ID, alg, kc.PrivateKeyPath, kc.PublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load jwt key %q: %w", kc.ID, err)
+		}
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	active, ok := ks.keys[cfg.ActiveKeyID]
+	if !ok {
+		return nil, fmt.Errorf("active jwt key %q is not configured", cfg.ActiveKeyID)
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("active jwt key %q has no private key", cfg.ActiveKeyID)
+	}
+	if !strings.EqualFold(active.Method.Alg(), cfg.Algorithm) {
+		return nil, fmt.Errorf("active jwt key %q uses %s, expected %s", cfg.ActiveKeyID, active.Method.Alg(), cfg.Algorithm)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// Lookup returns the verification key for a kid.
+func (ks *KeySet) Lookup(kid string) (*Key, bool) {
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// Methods lists the algorithms accepted by the key set.
+func (ks *KeySet) Methods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, key := range ks.keys {
+		if !seen[key.Method.Alg()] {
+			seen[key.Method.Alg()] = true
+			methods = append(methods, key.Method.Alg())
+		}
+	}
+	return methods
+}
+
+// JWKS renders the public keys of the set.
+func (ks *KeySet) JWKS() *JWKS {
+	doc := &JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	sort.Slice(doc.Keys, func(i, j int) bool { return doc.Keys[i].Kid < doc.Keys[j].Kid })
+	return doc
+}
+
+func loadKey(id, alg, privatePath, publicPath string) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("key id is required")
+	}
+	if privatePath == "" && publicPath == "" {
+		return nil, fmt.Errorf("either private_key_path or public_key_path is required")
+	}
+
+	key := &Key{ID: id}
+
+	switch strings.ToUpper(alg) {
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+		if privatePath != "" {
+			pem, err := os.ReadFile(privatePath)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.private = priv
+			key.public = &priv.PublicKey
+		}
+		if key.public == nil {
+			pem, err := os.ReadFile(publicPath)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.public = pub
+		}
+	case "EDDSA":
+		key.Method = jwt.SigningMethodEdDSA
+		if privatePath != "" {
+			pem, err := os.ReadFile(privatePath)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			edPriv, ok := priv.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("private key is not an Ed25519 key")
+			}
+			key.private = edPriv
+			key.public = edPriv.Public()
+		}
+		if key.public == nil {
+			pem, err := os.ReadFile(publicPath)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseEdPublicKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.public = pub
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	return key, nil
+}
@@ -1,8 +1,15 @@
 package email
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -14,6 +21,13 @@ const (
 	emailTimeout = 15 * time.Second
 )
 
+// Attachment is a file sent along with an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
 type EmailService struct {
 	cfg    *configs.EmailConfig
 	logger logger.Logger
@@ -118,7 +132,421 @@ func (e *EmailService) SendPasswordResetEmail(to, resetLink string) error {
 	return e.sendEmail(to, subject, body)
 }
 
-func (e *EmailService) sendEmail(to, subject, body string) error {
+func (e *EmailService) SendMagicLinkEmail(to, magicLink string, expiresIn time.Duration) error {
+	const subject = "Your Brevity Sign-In Link"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.button { display: inline-block; background-color: #2563eb; color: white !important; text-decoration: none; padding: 12px 24px; border-radius: 6px; font-weight: 500; margin: 20px 0; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Sign In to Brevity</h2>
+			</div>
+			
+			<div class="content">
+				<p>Click the button below to sign in to your Brevity account. No password needed.</p>
+				
+				<div style="text-align: center;">
+					<a href="%s" class="button">Sign In</a>
+				</div>
+				
+				<p>This link can only be used once and will expire in %s.</p>
+				<p>If you didn't request this link, you can safely ignore this email.</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, magicLink, formatDuration(expiresIn), time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+func (e *EmailService) SendEmailChangeConfirmation(to, confirmLink string) error {
+	const subject = "Confirm Your New Brevity Email Address"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.button { display: inline-block; background-color: #2563eb; color: white !important; text-decoration: none; padding: 12px 24px; border-radius: 6px; font-weight: 500; margin: 20px 0; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Confirm Your New Email Address</h2>
+			</div>
+			
+			<div class="content">
+				<p>You asked to use this address for your Brevity account.</p>
+				<p>Your email will only change once you confirm by clicking the button below:</p>
+				
+				<div style="text-align: center;">
+					<a href="%s" class="button">Confirm Email Change</a>
+				</div>
+				
+				<p>If you didn't request this change, you can safely ignore this email.</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>This confirmation link will expire in 24 hours.</p>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, confirmLink, time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+func (e *EmailService) SendEmailChangeNotice(to, newEmail string) error {
+	const subject = "Your Brevity Email Address Is Changing"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+				.warning { background-color: #fef2f2; padding: 12px; border-radius: 6px; border-left: 4px solid #dc2626; margin: 15px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Email Change Requested</h2>
+			</div>
+			
+			<div class="content">
+				<p>A request was made to change the email address on your Brevity account to <strong>%s</strong>.</p>
+				<p>The change will take effect once the new address is confirmed.</p>
+				
+				<div class="warning">
+					<p style="margin: 0; color: #dc2626;">If you didn't make this request, change your password immediately as someone else may have access to your account.</p>
+				</div>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity Security Team</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(newEmail), time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+func (e *EmailService) SendPaymentFailedEmail(to, plan string, graceEndsAt time.Time) error {
+	const subject = "Action Required: Your Brevity Payment Failed"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+				.warning { background-color: #fef2f2; padding: 12px; border-radius: 6px; border-left: 4px solid #dc2626; margin: 15px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">We Couldn't Renew Your Subscription</h2>
+			</div>
+			
+			<div class="content">
+				<p>We tried to charge your payment method for your <strong>%s</strong> plan, but the payment didn't go through.</p>
+				<p>We'll keep retrying automatically. Please update your payment method to avoid any interruption.</p>
+				
+				<div class="warning">
+					<p style="margin: 0; color: #dc2626;">If we can't collect payment by %s, your account will be moved to the free plan.</p>
+				</div>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(plan), graceEndsAt.UTC().Format("January 2, 2006"), time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+func (e *EmailService) SendSubscriptionDowngradedEmail(to, plan string) error {
+	const subject = "Your Brevity Subscription Has Ended"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Your Subscription Has Ended</h2>
+			</div>
+			
+			<div class="content">
+				<p>We weren't able to collect payment for your <strong>%s</strong> plan, so your account has been moved to the free plan.</p>
+				<p>Your existing links keep working. You can subscribe again at any time to restore your plan's limits.</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(plan), time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+// SendTrialEndingEmail reminds a user that their trial ends soon. converts
+// says whether a payment method is on file, so the trial becomes a paid
+// subscription at price rather than falling back to the free plan.
+func (e *EmailService) SendTrialEndingEmail(to, plan string, endsAt time.Time, price string, converts bool) error {
+	const subject = "Your Brevity Trial Ends Soon"
+	next := "Add a payment method before then to keep your plan. Otherwise your account will move to the free plan and your trial credits will expire."
+	if converts {
+		next = fmt.Sprintf("Your subscription will then start automatically and your payment method on file will be charged <strong>%s</strong>. You can cancel at any time before the trial ends.", html.EscapeString(price))
+	}
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Your Trial Ends Soon</h2>
+			</div>
+			
+			<div class="content">
+				<p>Your free trial of the <strong>%s</strong> plan ends on %s.</p>
+				<p>%s</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(plan), endsAt.UTC().Format("January 2, 2006"), next, time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+func (e *EmailService) SendTrialEndedEmail(to, plan string) error {
+	const subject = "Your Brevity Trial Has Ended"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Your Trial Has Ended</h2>
+			</div>
+			
+			<div class="content">
+				<p>Your free trial of the <strong>%s</strong> plan has ended and your account has been moved to the free plan.</p>
+				<p>Your existing links keep working. You can subscribe at any time to get your plan's limits back.</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(plan), time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+// SendWorkspaceInvitationEmail invites someone to join a workspace. The link
+// accepts the invitation once they are signed in.
+func (e *EmailService) SendWorkspaceInvitationEmail(to, workspace, inviter, inviteLink string, expiresIn time.Duration) error {
+	subject := fmt.Sprintf("You've Been Invited to %s on Brevity", workspace)
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.button { display: inline-block; background-color: #2563eb; color: white !important; text-decoration: none; padding: 12px 24px; border-radius: 6px; font-weight: 500; margin: 20px 0; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Join %s</h2>
+			</div>
+			
+			<div class="content">
+				<p>%s has invited you to the <strong>%s</strong> workspace on Brevity, where your team shares links, credits and a subscription.</p>
+				<p>Sign in with this email address, then accept the invitation:</p>
+				
+				<div style="text-align: center;">
+					<a href="%s" class="button">Accept Invitation</a>
+				</div>
+				
+				<p>This invitation will expire in %s.</p>
+				<p>If you weren't expecting it, you can safely ignore this email.</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(workspace), html.EscapeString(inviter), html.EscapeString(workspace),
+		inviteLink, formatDuration(expiresIn), time.Now().Year())
+
+	return e.sendEmail(to, subject, body)
+}
+
+func (e *EmailService) SendInvoiceEmail(to, number, total string, issuedAt time.Time, pdf Attachment) error {
+	subject := fmt.Sprintf("Your Brevity Receipt %s", number)
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+				.summary { width: 100%%; border-collapse: collapse; margin: 15px 0; }
+				.summary td { padding: 6px 0; }
+				.summary td:last-child { text-align: right; font-weight: 500; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Thanks for Your Payment</h2>
+			</div>
+			
+			<div class="content">
+				<p>We received your payment. Your invoice is attached to this email as a PDF.</p>
+				
+				<table class="summary">
+					<tr><td>Invoice number</td><td>%s</td></tr>
+					<tr><td>Date</td><td>%s</td></tr>
+					<tr><td>Amount paid</td><td>%s</td></tr>
+				</table>
+				
+				<p style="font-size: 14px; color: #6b7280;">You can also download your invoices at any time from your account.</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(number), issuedAt.UTC().Format("January 2, 2006"), html.EscapeString(total), time.Now().Year())
+
+	return e.sendEmail(to, subject, body, pdf)
+}
+
+func (e *EmailService) SendScheduledExportEmail(to, name string, rows int, file Attachment) error {
+	subject := fmt.Sprintf("Your Brevity Export: %s", name)
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<style>
+				body { font-family: 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+				.header { text-align: center; margin-bottom: 30px; }
+				.logo { color: #2563eb; font-size: 24px; font-weight: bold; margin-bottom: 10px; }
+				.content { background-color: #f9fafb; padding: 25px; border-radius: 8px; }
+				.footer { margin-top: 30px; font-size: 12px; color: #6b7280; text-align: center; }
+				hr { border: none; height: 1px; background-color: #e5e7eb; margin: 25px 0; }
+			</style>
+		</head>
+		<body>
+			<div class="header">
+				<div class="logo">Brevity</div>
+				<h2 style="margin: 0; font-weight: 500;">Your Scheduled Export</h2>
+			</div>
+			
+			<div class="content">
+				<p>Your export <strong>%s</strong> is attached to this email. It has %s.</p>
+				
+				<p style="font-size: 14px; color: #6b7280;">You can change or remove your scheduled exports at any time from your account.</p>
+			</div>
+			
+			<div class="footer">
+				<hr>
+				<p>&copy; %d Brevity. All rights reserved.</p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(name), pluralize(rows, "row"), time.Now().Year())
+
+	return e.sendEmail(to, subject, body, file)
+}
+
+func (e *EmailService) sendEmail(to, subject, body string, attachments ...Attachment) error {
 	from := e.cfg.SMTP.FromEmail
 	if from == "" {
 		return fmt.Errorf("from email address not configured")
@@ -129,20 +557,10 @@ func (e *EmailService) sendEmail(to, subject, body string) error {
 		return fmt.Errorf("invalid recipient email address")
 	}
 
-	// Construct MIME email
-	headers := map[string]string{
-		"From":         from,
-		"To":           to,
-		"Subject":      subject,
-		"MIME-Version": "1.0",
-		"Content-Type": "text/html; charset=UTF-8",
-	}
-
-	var msg strings.Builder
-	for k, v := range headers {
-		msg.WriteString(fmt.Sprintf("%s: %s\r\n", k, v))
+	msg, err := buildMessage(from, to, subject, body, attachments)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
 	}
-	msg.WriteString("\r\n" + body)
 
 	auth := smtp.PlainAuth("", e.cfg.SMTP.Username, e.cfg.SMTP.Password, e.cfg.SMTP.Host)
 	addr := fmt.Sprintf("%s:%d", e.cfg.SMTP.Host, e.cfg.SMTP.Port)
@@ -155,7 +573,7 @@ func (e *EmailService) sendEmail(to, subject, body string) error {
 			auth,
 			from,
 			strings.Split(to, ";"),
-			[]byte(msg.String()),
+			msg,
 		)
 		done <- err
 	}()
@@ -180,3 +598,100 @@ func (e *EmailService) sendEmail(to, subject, body string) error {
 		return fmt.Errorf("email sending timed out after %s", emailTimeout)
 	}
 }
+
+// buildMessage constructs the MIME email. A message without attachments is a
+// single HTML part; otherwise the HTML body and the attachments are sent as
+// multipart/mixed with base64 encoded parts.
+func buildMessage(from, to, subject, body string, attachments []Attachment) ([]byte, error) {
+	var msg bytes.Buffer
+	writeHeaders := func(headers textproto.MIMEHeader) {
+		for _, k := range []string{"From", "To", "Subject", "MIME-Version", "Content-Type"} {
+			if v := headers.Get(k); v != "" {
+				msg.WriteString(fmt.Sprintf("%s: %s\r\n", k, v))
+			}
+		}
+		msg.WriteString("\r\n")
+	}
+
+	headers := textproto.MIMEHeader{}
+	headers.Set("From", from)
+	headers.Set("To", to)
+	headers.Set("Subject", mime.QEncoding.Encode("utf-8", subject))
+	headers.Set("MIME-Version", "1.0")
+
+	if len(attachments) == 0 {
+		headers.Set("Content-Type", "text/html; charset=UTF-8")
+		writeHeaders(headers)
+		msg.WriteString(body)
+		return msg.Bytes(), nil
+	}
+
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+	headers.Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	writeHeaders(headers)
+
+	htmlPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBase64(htmlPart, []byte(body)); err != nil {
+		return nil, err
+	}
+
+	for _, a := range attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": a.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	msg.Write(parts.Bytes())
+	return msg.Bytes(), nil
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters, the
+// maximum RFC 2045 allows.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := io.WriteString(w, encoded+"\r\n")
+	return err
+}
+
+// formatDuration renders durations like "15 minutes" or "1 hour" for email copy.
+func formatDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return pluralize(int(d/time.Hour), "hour")
+	}
+	return pluralize(int(d/time.Minute), "minute")
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,116 @@
+// Package events is an in-process publish/subscribe bus. Services publish
+// what happened to a user's links and credits; other services, such as
+// outbound webhooks, subscribe to the types they care about.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+type Type string
+
+const (
+	URLCreated    Type = "url.created"
+	URLUpdated    Type = "url.updated"
+	URLDeleted    Type = "url.deleted"
+	URLExpired    Type = "url.expired"
+	ClickRecorded Type = "click.recorded"
+	CreditsLow    Type = "credits.low"
+)
+
+// Types lists every event type in the order they are documented
+var Types = []Type{URLCreated, URLUpdated, URLDeleted, URLExpired, ClickRecorded, CreditsLow}
+
+// Valid reports whether t is a known event type
+func (t Type) Valid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is something that happened to UserID's account. Data is encoded as
+// JSON when the event leaves the process.
+type Event struct {
+	ID         string      `json:"id"`
+	Type       Type        `json:"type"`
+	UserID     string      `json:"-"`
+	OccurredAt time.Time   `json:"created_at"`
+	Data       interface{} `json:"data"`
+}
+
+// New returns an event of type t for userID
+func New(t Type, userID string, data interface{}) *Event {
+	return &Event{
+		ID:         "evt_" + uuid.New().String(),
+		Type:       t,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+type Handler func(ctx context.Context, event *Event)
+
+// Bus delivers published events to their subscribers synchronously, in the
+// order they subscribed. Handlers should hand slow work off to a queue.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]Handler
+	all      []Handler
+	log      logger.Logger
+}
+
+func NewBus(log logger.Logger) *Bus {
+	return &Bus{
+		handlers: make(map[Type][]Handler),
+		log:      log,
+	}
+}
+
+// Subscribe calls h for every event of type t
+func (b *Bus) Subscribe(t Type, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[t] = append(b.handlers[t], h)
+}
+
+// SubscribeAll calls h for every event
+func (b *Bus) SubscribeAll(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.all = append(b.all, h)
+}
+
+// Publish hands event to its subscribers. A handler that panics is logged
+// and doesn't stop the others. Handlers may publish events of their own.
+func (b *Bus) Publish(ctx context.Context, event *Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[event.Type])+len(b.all))
+	handlers = append(handlers, b.handlers[event.Type]...)
+	handlers = append(handlers, b.all...)
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		b.call(ctx, h, event)
+	}
+}
+
+func (b *Bus) call(ctx context.Context, h Handler, event *Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.log.Error("Event handler panicked",
+				logger.String("event_id", event.ID),
+				logger.String("type", string(event.Type)),
+				logger.Any("panic", r))
+		}
+	}()
+	h(ctx, event)
+}
@@ -0,0 +1,167 @@
+// Package export writes records as CSV or newline-delimited JSON. Both
+// formats keep the columns in the order asked for, and NDJSON keeps each
+// value's type (numbers, booleans, RFC 3339 times, null), so the output can
+// be loaded into a warehouse without a schema guess.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// ParseFormat accepts the format names used in query strings. An empty name
+// means CSV.
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "", "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+func (f Format) ContentType() string {
+	if f == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (f Format) Extension() string {
+	if f == FormatNDJSON {
+		return "ndjson"
+	}
+	return "csv"
+}
+
+// Record maps column names to values. Values may be strings, ints, bools,
+// time.Time or *time.Time, *string, or nil.
+type Record map[string]interface{}
+
+// Writer writes records with a fixed set of columns. Close must be called
+// to flush the output; a CSV with no records still gets its header line.
+type Writer interface {
+	Write(record Record) error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer, columns []string) Writer {
+	if format == FormatNDJSON {
+		return &ndjsonWriter{w: bufio.NewWriter(w), columns: columns}
+	}
+	return &csvWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+}
+
+func (cw *csvWriter) Write(record Record) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(cw.columns))
+	for i, column := range cw.columns {
+		row[i] = formatCSV(record[column])
+	}
+	return cw.w.Write(row)
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.started {
+		return nil
+	}
+	cw.started = true
+	return cw.w.Write(cw.columns)
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func formatCSV(value interface{}) string {
+	switch v := normalize(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+// Write encodes the record as one JSON object with its keys in column order
+func (nw *ndjsonWriter) Write(record Record) error {
+	nw.w.WriteByte('{')
+	for i, column := range nw.columns {
+		if i > 0 {
+			nw.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(normalize(record[column]))
+		if err != nil {
+			return err
+		}
+		nw.w.Write(key)
+		nw.w.WriteByte(':')
+		nw.w.Write(value)
+	}
+	nw.w.WriteByte('}')
+	return nw.w.WriteByte('\n')
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
+
+// normalize turns times into RFC 3339 strings and nil pointers into nil
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return v.UTC().Format(time.RFC3339)
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+// AdminRepository applies admin actions, writing event to the audit log in
+// the same transaction as the change.
+type AdminRepository interface {
+	SearchUsers(ctx context.Context, filter *models.UserSearchFilter) ([]*models.User, *pagination.Page, error)
+	SetUserSuspended(ctx context.Context, userID string, suspended bool, event *models.AuditEvent) (*models.User, error)
+	SetUserRole(ctx context.Context, userID string, role models.Role, event *models.AuditEvent) (*models.User, error)
+	GrantCredits(ctx context.Context, credit *models.Credit, event *models.AuditEvent) error
+	DeductCredits(ctx context.Context, userID string, amount int, event *models.AuditEvent) error
+	OverrideSubscription(ctx context.Context, userID string, plan *models.Plan, expiresAt time.Time, event *models.AuditEvent) (*models.Subscription, error)
+	TakeDownURL(ctx context.Context, urlID string, event *models.AuditEvent) (*models.URL, error)
+	RestoreURL(ctx context.Context, urlID string, event *models.AuditEvent) (*models.URL, error)
+}
+
+// AdminService carries out admin actions. adminID identifies who made the
+// change in the audit log.
+type AdminService interface {
+	SearchUsers(ctx context.Context, filter *models.UserSearchFilter) ([]*models.User, *pagination.Page, error)
+	SetUserStatus(ctx context.Context, adminID, userID string, req *models.SetUserStatusRequest) (*models.User, error)
+	SetUserRole(ctx context.Context, adminID, userID string, req *models.SetUserRoleRequest) (*models.User, error)
+	GrantCredits(ctx context.Context, adminID, userID string, req *models.GrantCreditsRequest) (*models.CreditBalanceResponse, error)
+	AdjustCredits(ctx context.Context, adminID, userID string, req *models.AdjustCreditsRequest) (*models.CreditBalanceResponse, error)
+	OverrideSubscription(ctx context.Context, adminID, userID string, req *models.OverrideSubscriptionRequest) (*models.Subscription, error)
+	TakeDownURL(ctx context.Context, adminID, urlID string, req *models.TakeDownURLRequest) (*models.URLResponse, error)
+	RestoreURL(ctx context.Context, adminID, urlID string, req *models.RestoreURLRequest) (*models.URLResponse, error)
+}
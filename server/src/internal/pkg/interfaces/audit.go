@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	List(ctx context.Context, filter *models.AuditEventFilter) ([]*models.AuditEvent, *pagination.Page, error)
+	// DeleteBefore removes events created before cutoff, either the admin
+	// actions or everything else.
+	DeleteBefore(ctx context.Context, cutoff time.Time, admin bool) (int64, error)
+}
+
+type AuditService interface {
+	// Record writes event to the audit log. A failure is logged rather than
+	// returned, so auditing never undoes the action it describes.
+	Record(ctx context.Context, event *models.AuditEvent)
+	ListUserEvents(ctx context.Context, userID string, filter *models.AuditEventFilter) ([]*models.AuditEvent, *pagination.Page, error)
+	ListEvents(ctx context.Context, filter *models.AuditEventFilter) ([]*models.AuditEvent, *pagination.Page, error)
+	PurgeExpired(ctx context.Context) error
+	RunRetentionWorker(ctx context.Context)
+}
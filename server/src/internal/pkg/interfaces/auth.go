@@ -12,19 +12,25 @@ type AuthRepository interface {
 	FindUserByIdentifier(ctx context.Context, identifier string) (*models.User, error)
 	FindUserByID(ctx context.Context, id string) (*models.User, error)
 	SaveVerificationToken(ctx context.Context, email, token string, expires time.Time) error
-	VerifyUser(ctx context.Context, token string) error
+	VerifyUser(ctx context.Context, token string) (*models.User, error)
 	SaveResetToken(ctx context.Context, email, token string, expires time.Time) error
-	ResetPassword(ctx context.Context, token, newPassword string) error
+	ResetPassword(ctx context.Context, token, newPassword string) (string, error)
 	UpdatePassword(ctx context.Context, userID, hashedPassword string) error
+	CreateMagicLinkToken(ctx context.Context, token *models.MagicLinkToken) error
+	ConsumeMagicLinkToken(ctx context.Context, tokenHash string) (*models.User, error)
+	RestoreUser(ctx context.Context, userID string) error
 }
 
 type AuthService interface {
-	Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error)
+	Register(ctx context.Context, req *models.RegisterRequest, ip string) (*models.User, error)
 	Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error)
 	Logout(ctx context.Context, userID string) error
 	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, email string) error
 	InitiatePasswordReset(ctx context.Context, email string) error
 	CompletePasswordReset(ctx context.Context, token, newPassword string) error
 	RefreshToken(ctx context.Context, refreshToken string) (*models.RefreshTokenResponse, error)
 	ChangePassword(ctx context.Context, userID string, req *models.ChangePasswordRequest) error
+	RequestMagicLink(ctx context.Context, email, ip string) error
+	LoginWithMagicLink(ctx context.Context, token string) (*models.LoginResponse, error)
 }
\ No newline at end of file
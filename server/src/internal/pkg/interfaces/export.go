@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type ExportScheduleRepository interface {
+	CreateSchedule(ctx context.Context, schedule *models.ExportSchedule) error
+	GetSchedule(ctx context.Context, id, userID string) (*models.ExportSchedule, error)
+	ListSchedules(ctx context.Context, userID string) ([]*models.ExportSchedule, error)
+	CountSchedules(ctx context.Context, userID string) (int, error)
+	UpdateSchedule(ctx context.Context, schedule *models.ExportSchedule) error
+	DeleteSchedule(ctx context.Context, id, userID string) error
+	GetDueScheduleIDs(ctx context.Context, now time.Time, limit int) ([]string, error)
+	ClaimSchedule(ctx context.Context, id string, now, until time.Time) (*models.ExportSchedule, bool, error)
+}
+
+type ExportService interface {
+	Export(ctx context.Context, userID string, q *models.URLExportQuery, w io.Writer) error
+	CreateSchedule(ctx context.Context, userID string, req *models.CreateExportScheduleRequest) (*models.ExportSchedule, error)
+	ListSchedules(ctx context.Context, userID string) ([]*models.ExportSchedule, error)
+	DeleteSchedule(ctx context.Context, userID, id string) error
+	RunDueSchedules(ctx context.Context) error
+	RunScheduleWorker(ctx context.Context)
+}
@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type FolderRepository interface {
+	CreateFolder(ctx context.Context, folder *models.Folder) error
+	GetFolder(ctx context.Context, id string) (*models.Folder, error)
+	ListFolders(ctx context.Context, userID, workspaceID string) ([]*models.Folder, error)
+	UpdateFolder(ctx context.Context, folder *models.Folder) error
+	DeleteFolder(ctx context.Context, id string) error
+	SetURLFolder(ctx context.Context, urlID string, folderID *string) error
+}
+
+type FolderService interface {
+	CreateFolder(ctx context.Context, userID string, req *models.CreateFolderRequest) (*models.Folder, error)
+	ListFolders(ctx context.Context, userID, workspaceID string) ([]*models.Folder, error)
+	UpdateFolder(ctx context.Context, userID, id string, req *models.UpdateFolderRequest) (*models.Folder, error)
+	DeleteFolder(ctx context.Context, userID, id string) error
+	MoveURL(ctx context.Context, userID, urlID string, req *models.MoveURLRequest) (*models.URLResponse, error)
+}
@@ -0,0 +1,41 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+type InvoiceRepository interface {
+	// Create numbers the invoice with the next sequence number and stores it
+	// along with its line items.
+	Create(ctx context.Context, invoice *models.Invoice, prefix string) error
+	// SetPDF records the stored PDF unless another run stored one first, in
+	// which case it returns false.
+	SetPDF(ctx context.Context, id, url, key string) (bool, error)
+	// ClaimEmail counts an attempt to email the invoice. It returns false if
+	// the invoice was emailed or attempted by another run since it was read.
+	ClaimEmail(ctx context.Context, invoice *models.Invoice) (bool, error)
+	MarkEmailed(ctx context.Context, id string, at time.Time) error
+	GetByID(ctx context.Context, userID, id string) (*models.Invoice, error)
+	ListByUser(ctx context.Context, userID string, p pagination.Params) ([]*models.Invoice, *pagination.Page, error)
+	// ListUninvoicedPayments returns paid payments that have no invoice yet,
+	// oldest first.
+	ListUninvoicedPayments(ctx context.Context, limit int) ([]*models.Payment, error)
+	// ListUndelivered returns invoices whose PDF hasn't been stored or that
+	// haven't been emailed in fewer than maxAttempts tries.
+	ListUndelivered(ctx context.Context, maxAttempts, limit int) ([]*models.Invoice, error)
+}
+
+type InvoiceService interface {
+	ListInvoices(ctx context.Context, userID string, p pagination.Params) ([]*models.Invoice, *pagination.Page, error)
+	GetInvoice(ctx context.Context, userID, id string) (*models.Invoice, error)
+	// RenderPDF returns the invoice's PDF and its file name.
+	RenderPDF(ctx context.Context, userID, id string) ([]byte, string, error)
+	// IssueInvoices invoices new payments and delivers invoices that haven't
+	// been stored or emailed yet.
+	IssueInvoices(ctx context.Context) error
+	RunInvoiceWorker(ctx context.Context)
+}
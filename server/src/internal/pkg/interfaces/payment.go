@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type PaymentEventRepository interface {
+	CreateIfNotExists(ctx context.Context, event *models.PaymentEvent) (bool, error)
+	Update(ctx context.Context, event *models.PaymentEvent) error
+	GetRetryable(ctx context.Context, now, staleBefore time.Time, limit int) ([]*models.PaymentEvent, error)
+}
+
+type SubscriptionScheduler interface {
+	ProcessDue(ctx context.Context) error
+	RunScheduler(ctx context.Context)
+}
+
+type PaymentWebhookService interface {
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	RetryFailedEvents(ctx context.Context) error
+	RunRetryWorker(ctx context.Context)
+}
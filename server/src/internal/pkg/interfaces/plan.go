@@ -0,0 +1,24 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type PlanRepository interface {
+	GetByID(ctx context.Context, id string) (*models.Plan, error)
+	GetLatest(ctx context.Context, code models.SubscriptionPlan) (*models.Plan, error)
+	ListLatest(ctx context.Context, activeOnly bool) ([]*models.Plan, error)
+	ListVersions(ctx context.Context, code models.SubscriptionPlan) ([]*models.Plan, error)
+	CreateVersion(ctx context.Context, plan *models.Plan) error
+	Update(ctx context.Context, plan *models.Plan) error
+}
+
+type PlanService interface {
+	ListPlans(ctx context.Context) ([]*models.Plan, error)
+	GetPlanVersions(ctx context.Context, code models.SubscriptionPlan) ([]*models.Plan, error)
+	CreatePlan(ctx context.Context, req *models.CreatePlanRequest) (*models.Plan, error)
+	UpdatePlan(ctx context.Context, code models.SubscriptionPlan, req *models.UpdatePlanRequest) (*models.Plan, error)
+	ArchivePlan(ctx context.Context, code models.SubscriptionPlan) error
+}
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type PrivacyRepository interface {
+	CreateExport(ctx context.Context, export *models.DataExport) error
+	UpdateExport(ctx context.Context, export *models.DataExport) error
+	GetExport(ctx context.Context, id, userID string) (*models.DataExport, error)
+	HasActiveExport(ctx context.Context, userID string) (bool, error)
+	GetUserExports(ctx context.Context, userID string) ([]*models.DataExport, error)
+	GetExpiredExports(ctx context.Context, now time.Time) ([]*models.DataExport, error)
+	DeleteExport(ctx context.Context, id string) error
+	CollectUserData(ctx context.Context, userID string) (*models.UserData, error)
+	ScheduleDeletion(ctx context.Context, userID string, purgeAt time.Time) error
+	GetUsersDueForPurge(ctx context.Context, now time.Time) ([]string, error)
+	GetScheduledExportFileKeys(ctx context.Context, userID string) ([]string, error)
+	PurgeUser(ctx context.Context, userID string) error
+}
+
+type PrivacyService interface {
+	RequestExport(ctx context.Context, userID string) (*models.DataExport, error)
+	GetExport(ctx context.Context, id, userID string) (*models.DataExport, error)
+	DeleteAccount(ctx context.Context, userID string) (*models.AccountDeletionResponse, error)
+	PurgeDueAccounts(ctx context.Context) error
+	RunPurgeWorker(ctx context.Context)
+}
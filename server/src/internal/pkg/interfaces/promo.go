@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+type PromoCodeRepository interface {
+	Create(ctx context.Context, promo *models.PromoCode) error
+	GetByID(ctx context.Context, id string) (*models.PromoCode, error)
+	GetByCode(ctx context.Context, code string) (*models.PromoCode, error)
+	List(ctx context.Context) ([]*models.PromoCode, error)
+	Update(ctx context.Context, promo *models.PromoCode) error
+	Delete(ctx context.Context, id string) error
+	// Redeem records a redemption, and the credit it grants if any, within
+	// the code's redemption limits.
+	Redeem(ctx context.Context, promo *models.PromoCode, redemption *models.PromoCodeRedemption, credit *models.Credit) error
+	// Release undoes a redemption whose purchase did not go through.
+	Release(ctx context.Context, redemption *models.PromoCodeRedemption) error
+	UpdateRedemption(ctx context.Context, redemption *models.PromoCodeRedemption) error
+	ListRedemptions(ctx context.Context, promoID string, p pagination.Params) ([]*models.PromoCodeRedemption, *pagination.Page, error)
+}
+
+type PromoCodeService interface {
+	ListPromoCodes(ctx context.Context) ([]*models.PromoCode, error)
+	GetPromoCode(ctx context.Context, id string) (*models.PromoCode, error)
+	GetRedemptions(ctx context.Context, id string, p pagination.Params) ([]*models.PromoCodeRedemption, *pagination.Page, error)
+	CreatePromoCode(ctx context.Context, req *models.CreatePromoCodeRequest) (*models.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, id string, req *models.UpdatePromoCodeRequest) (*models.PromoCode, error)
+	DeletePromoCode(ctx context.Context, id string) error
+}
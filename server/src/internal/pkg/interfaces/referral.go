@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type ReferralRepository interface {
+	GetCode(ctx context.Context, userID string) (*models.ReferralCode, error)
+	GetCodeByCode(ctx context.Context, code string) (*models.ReferralCode, error)
+	// CreateCode stores code unless the user already has one, in which case
+	// code is replaced with the existing one.
+	CreateCode(ctx context.Context, code *models.ReferralCode) error
+	Create(ctx context.Context, referral *models.Referral) error
+	GetByReferee(ctx context.Context, refereeID string) (*models.Referral, error)
+	ListByReferrer(ctx context.Context, referrerID string) ([]*models.ReferralEntry, error)
+	// IPSeen reports whether ip has been used by the referrer or by anyone
+	// they referred before.
+	IPSeen(ctx context.Context, referrerID, ip string) (bool, error)
+	// Complete credits a pending referral. The referrer is only credited while
+	// they have fewer than monthlyCap rewarded referrals since monthStart.
+	Complete(ctx context.Context, referral *models.Referral, referrerCredit, refereeCredit *models.Credit, monthStart time.Time, monthlyCap int) error
+}
+
+type ReferralService interface {
+	GetReferrals(ctx context.Context, userID string) (*models.ReferralSummaryResponse, error)
+	// RecordSignup attaches a new user to the owner of code. Unknown codes
+	// are ignored so a stale link never blocks a signup.
+	RecordSignup(ctx context.Context, referee *models.User, code, ip string) error
+	// CompleteReferral rewards the referral of a user who has just verified
+	// their email. It does nothing if the user wasn't referred.
+	CompleteReferral(ctx context.Context, refereeID string) error
+}
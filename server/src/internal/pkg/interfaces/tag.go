@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type TagRepository interface {
+	GetTag(ctx context.Context, id string) (*models.Tag, error)
+	ListTags(ctx context.Context, userID, workspaceID string) ([]*models.Tag, error)
+	DeleteTag(ctx context.Context, id string) error
+	SetURLTags(ctx context.Context, url *models.URL, userID string, names []string) error
+}
+
+type TagService interface {
+	ListTags(ctx context.Context, userID, workspaceID string) ([]*models.Tag, error)
+	DeleteTag(ctx context.Context, userID, id string) error
+	SetURLTags(ctx context.Context, userID, urlID string, req *models.SetURLTagsRequest) (*models.URLResponse, error)
+}
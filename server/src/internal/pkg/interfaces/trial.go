@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type TrialRepository interface {
+	// Start creates the trial subscription, the trial and its credits. It
+	// returns ErrTrialUnavailable if the user has had a trial or subscription
+	// before, and ErrTrialPaymentMethodUsed if the trial's payment method was
+	// used for another trial.
+	Start(ctx context.Context, trial *models.Trial, sub *models.Subscription, credit *models.Credit) error
+	GetByUser(ctx context.Context, userID string) (*models.Trial, error)
+	GetBySubscription(ctx context.Context, subscriptionID string) (*models.Trial, error)
+	// SetPaymentMethod stores the payment method of an active trial. It
+	// returns ErrTrialPaymentMethodUsed if the card was used for another trial.
+	SetPaymentMethod(ctx context.Context, trial *models.Trial) error
+	// End records how an active trial finished.
+	End(ctx context.Context, trial *models.Trial) error
+	// ListReminderDue returns active trials ending before the given time
+	// whose reminder hasn't been sent.
+	ListReminderDue(ctx context.Context, before time.Time, limit int) ([]*models.Trial, error)
+	// MarkReminded claims a trial's reminder. Only the first caller gets true.
+	MarkReminded(ctx context.Context, id string, at time.Time) (bool, error)
+}
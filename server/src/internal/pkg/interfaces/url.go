@@ -5,38 +5,63 @@ import (
 	"time"
 
 	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 )
 
 type URLRepository interface {
 	Create(ctx context.Context, url *models.URL) error
 	CountByIP(ctx context.Context, ip string) (int, error)
+	CountByUser(ctx context.Context, userID string) (int, error)
 	GetByID(ctx context.Context, id string) (*models.URL, error)
 	GetByShortCode(ctx context.Context, code string) (*models.URL, error)
-	GetByUser(ctx context.Context, userID string, limit, offset int) ([]*models.URL, error)
-	Update(ctx context.Context, url *models.URL) error
+	GetByUser(ctx context.Context, userID string, f *models.URLFilter) ([]*models.URL, *pagination.Page, error)
+	GetByWorkspace(ctx context.Context, workspaceID string, f *models.URLFilter) ([]*models.URL, *pagination.Page, error)
+	UpdateWithRevision(ctx context.Context, url *models.URL, before, rev *models.URLRevision) error
+	ListRevisions(ctx context.Context, urlID string, p pagination.Params) ([]*models.URLRevision, *pagination.Page, error)
+	GetRevision(ctx context.Context, urlID string, number int) (*models.URLRevision, error)
 	Delete(ctx context.Context, id string) error
 	IncrementClicks(ctx context.Context, id string) error
 	RecordClick(ctx context.Context, click *models.URLClick) error
-	GetClicksAnalytics(ctx context.Context, urlID string, from, to time.Time) ([]*models.URLClick, error)
+	GetClicksAnalytics(ctx context.Context, urlID string, from, to time.Time, p pagination.Params) ([]*models.URLClick, *pagination.Page, error)
+	GetNewlyExpired(ctx context.Context, now time.Time, limit int) ([]*models.URL, error)
+	MarkExpiredNotified(ctx context.Context, id string, at time.Time) (bool, error)
+	StreamURLs(ctx context.Context, userID string, q *models.URLExportQuery, fn func(*models.URL) error) error
+	StreamClicks(ctx context.Context, userID string, q *models.URLExportQuery, fn func(*models.URLClick) error) error
 }
 
 type CreditRepository interface {
 	GetUserCredits(ctx context.Context, userID string) ([]*models.Credit, error)
 	GetUserCreditBalance(ctx context.Context, userID string) (*models.CreditBalanceResponse, error)
+	GetWorkspaceCreditBalance(ctx context.Context, workspaceID string) (*models.CreditBalanceResponse, error)
 	AddCredits(ctx context.Context, credit *models.Credit) error
-	UseCredits(ctx context.Context, usage *models.CreditUsage) error
-	GetCreditUsage(ctx context.Context, userID string) ([]*models.CreditUsage, error)
+	UseCredits(ctx context.Context, userID string, amount int, operation, urlID string) error
+	UseWorkspaceCredits(ctx context.Context, workspaceID, userID string, amount int, operation, urlID string) error
+	ExpireCredits(ctx context.Context, now time.Time) (int, error)
+	GetLedger(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error)
+	GetWorkspaceLedger(ctx context.Context, workspaceID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error)
+	GetCreditUsage(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditUsage, *pagination.Page, error)
 	RecordFreeURLCreation(ctx context.Context, userID, urlID string) error
 	GetFreeURLCount(ctx context.Context, userID string) (int, error)
+	MarkCreditsLow(ctx context.Context, userID string, at time.Time) (bool, error)
+	ClearCreditsLow(ctx context.Context, userID string) error
 }
 
 type SubscriptionRepository interface {
 	CreateSubscription(ctx context.Context, subscription *models.Subscription) error
 	GetUserSubscription(ctx context.Context, userID string) (*models.Subscription, error)
+	GetWorkspaceSubscription(ctx context.Context, workspaceID string) (*models.Subscription, error)
 	UpdateSubscription(ctx context.Context, subscription *models.Subscription) error
 	CancelSubscription(ctx context.Context, userID string) error
+	CancelWorkspaceSubscription(ctx context.Context, workspaceID string) error
 	CreatePayment(ctx context.Context, payment *models.Payment) error
-	GetUserPayments(ctx context.Context, userID string) ([]*models.Payment, error)
+	GetUserPayments(ctx context.Context, userID string, p pagination.Params) ([]*models.Payment, *pagination.Page, error)
+	GetSubscriptionByStripeID(ctx context.Context, stripeID string) (*models.Subscription, error)
+	GetPaymentByStripeID(ctx context.Context, stripeID string) (*models.Payment, error)
+	GetPaymentByIntentID(ctx context.Context, intentID string) (*models.Payment, error)
+	UpdatePayment(ctx context.Context, payment *models.Payment) error
+	RecordPaidInvoice(ctx context.Context, payment *models.Payment) (bool, error)
+	GetDueSubscriptionIDs(ctx context.Context, now time.Time, limit int) ([]string, error)
+	ClaimSubscription(ctx context.Context, id string, now, until time.Time) (*models.Subscription, bool, error)
 }
 
 type AnalyticsRepository interface {
@@ -49,24 +74,44 @@ type AnalyticsRepository interface {
 type URLService interface {
 	CreateURL(ctx context.Context, req *models.CreateURLRequest, userID string, ip string) (*models.URLResponse, error)
 	GetURL(ctx context.Context, shortCode string) (*models.URL, error)
-	GetUserURLs(ctx context.Context, userID string, limit, offset int) ([]*models.URLResponse, error)
-	UpdateURL(ctx context.Context, url *models.URL) (*models.URLResponse, error)
+	GetUserURLs(ctx context.Context, userID string, f *models.URLFilter) ([]*models.URLResponse, *pagination.Page, error)
+	GetWorkspaceURLs(ctx context.Context, workspaceID, userID string, f *models.URLFilter) ([]*models.URLResponse, *pagination.Page, error)
+	UpdateURL(ctx context.Context, id, userID string, req *models.UpdateURLRequest) (*models.URLResponse, error)
+	GetURLRevisions(ctx context.Context, id, userID string, p pagination.Params) ([]*models.URLRevision, *pagination.Page, error)
+	RestoreURLRevision(ctx context.Context, id, userID string, number int) (*models.URLResponse, error)
 	DeleteURL(ctx context.Context, id, userID string) error
 	RedirectURL(ctx context.Context, shortCode string, clickData *models.URLClick) (string, error)
-	GetURLAnalytics(ctx context.Context, urlID, userID string, from, to time.Time) ([]*models.URLClick, error)
+	GetURLAnalytics(ctx context.Context, urlID, userID string, from, to time.Time, p pagination.Params) ([]*models.URLClick, *pagination.Page, error)
+	NotifyExpired(ctx context.Context) error
+	RunExpiryWorker(ctx context.Context)
 }
 
 type CreditService interface {
 	GetCreditBalance(ctx context.Context, userID string) (*models.CreditBalanceResponse, error)
 	ApplyPromoCode(ctx context.Context, userID, code string) (*models.Credit, error)
-	GetCreditUsage(ctx context.Context, userID string) ([]*models.CreditUsage, error)
+	GetCreditUsage(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditUsage, *pagination.Page, error)
+	GetCreditLedger(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error)
+	GetWorkspaceCreditBalance(ctx context.Context, workspaceID, userID string) (*models.CreditBalanceResponse, error)
+	GetWorkspaceCreditLedger(ctx context.Context, workspaceID, userID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error)
+	ExpireCredits(ctx context.Context) error
+	RunExpiryWorker(ctx context.Context)
 }
 
 type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, userID string, req *models.CreateSubscriptionRequest) (*models.Subscription, error)
 	GetUserSubscription(ctx context.Context, userID string) (*models.Subscription, error)
 	UpdateSubscription(ctx context.Context, userID string, req *models.UpdateSubscriptionRequest) (*models.Subscription, error)
+	PreviewPlanChange(ctx context.Context, userID string, plan models.SubscriptionPlan) (*models.PlanChangePreview, error)
 	CancelSubscription(ctx context.Context, userID string, req *models.CancelSubscriptionRequest) error
 	GetSubscriptionPlans(ctx context.Context) ([]*models.SubscriptionPlanResponse, error)
-	GetPaymentHistory(ctx context.Context, userID string) ([]*models.Payment, error)
+	GetPaymentHistory(ctx context.Context, userID string, p pagination.Params) ([]*models.Payment, *pagination.Page, error)
+	StartTrial(ctx context.Context, userID string, req *models.StartTrialRequest) (*models.Trial, error)
+	GetTrial(ctx context.Context, userID string) (*models.Trial, error)
+	SetTrialPaymentMethod(ctx context.Context, userID, token string) (*models.Trial, error)
+
+	CreateWorkspaceSubscription(ctx context.Context, workspaceID, userID string, req *models.CreateSubscriptionRequest) (*models.Subscription, error)
+	GetWorkspaceSubscription(ctx context.Context, workspaceID, userID string) (*models.Subscription, error)
+	UpdateWorkspaceSubscription(ctx context.Context, workspaceID, userID string, req *models.UpdateSubscriptionRequest) (*models.Subscription, error)
+	PreviewWorkspacePlanChange(ctx context.Context, workspaceID, userID string, plan models.SubscriptionPlan) (*models.PlanChangePreview, error)
+	CancelWorkspaceSubscription(ctx context.Context, workspaceID, userID string, req *models.CancelSubscriptionRequest) error
 }
\ No newline at end of file
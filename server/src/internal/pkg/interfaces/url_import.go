@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type URLImportRepository interface {
+	CreateImport(ctx context.Context, imp *models.URLImport) error
+	GetImport(ctx context.Context, id, userID string) (*models.URLImport, error)
+	UpdateImport(ctx context.Context, imp *models.URLImport) error
+	GetPendingImportIDs(ctx context.Context, now time.Time, limit int) ([]string, error)
+	ClaimImport(ctx context.Context, id string, now, until time.Time) (*models.URLImport, bool, error)
+	CreateBatch(ctx context.Context, batch *models.URLBatch, imp *models.URLImport) error
+}
+
+type URLImportService interface {
+	CreateURLs(ctx context.Context, userID string, req *models.CreateBulkURLRequest) (*models.URLImport, error)
+	ImportCSV(ctx context.Context, userID, workspaceID string, r io.Reader) (*models.URLImport, error)
+	GetImport(ctx context.Context, userID, id string) (*models.URLImport, error)
+	ProcessImports(ctx context.Context) error
+	RunImportWorker(ctx context.Context)
+}
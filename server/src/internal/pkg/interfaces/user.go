@@ -3,6 +3,7 @@ package interfaces
 import (
 	"context"
 	"mime/multipart"
+	"time"
 
 	"github.com/imraushankr/bervity/server/src/internal/models"
 )
@@ -13,6 +14,9 @@ type UserRepository interface {
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id string) error
 	UpdateAvatar(ctx context.Context, userID, avatarURL string) error
+	SavePendingEmailChange(ctx context.Context, userID, newEmail, token string, expires time.Time) error
+	ConfirmEmailChange(ctx context.Context, token string) (*models.User, error)
+	SetPaymentCustomerID(ctx context.Context, userID, customerID string) error
 }
 
 type UserService interface {
@@ -20,4 +24,6 @@ type UserService interface {
 	UpdateUser(ctx context.Context, user *models.User) error
 	DeleteUser(ctx context.Context, id string) error
 	UploadAvatar(ctx context.Context, userID string, file multipart.File, header *multipart.FileHeader) (string, error)
+	RequestEmailChange(ctx context.Context, userID string, req *models.ChangeEmailRequest) error
+	ConfirmEmailChange(ctx context.Context, token string) (*models.User, error)
 }
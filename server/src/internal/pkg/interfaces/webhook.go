@@ -0,0 +1,42 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	GetEndpoint(ctx context.Context, id, userID string) (*models.WebhookEndpoint, error)
+	GetEndpointByID(ctx context.Context, id string) (*models.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error)
+	GetActiveEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error)
+	CountEndpoints(ctx context.Context, userID string) (int, error)
+	UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, id, userID string) error
+	ResetEndpointFailures(ctx context.Context, id string) error
+	RecordEndpointFailure(ctx context.Context, id string, disableAfter int, now time.Time) (bool, error)
+
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetDelivery(ctx context.Context, endpointID, id string) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, endpointID string, status models.WebhookDeliveryStatus, p pagination.Params) ([]*models.WebhookDelivery, *pagination.Page, error)
+	GetDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]string, error)
+	ClaimDelivery(ctx context.Context, id string, now, until time.Time) (*models.WebhookDelivery, bool, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, userID string, req *models.CreateWebhookEndpointRequest) (*models.WebhookEndpointWithSecret, error)
+	ListEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, userID, id string) (*models.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, userID, id string, req *models.UpdateWebhookEndpointRequest) (*models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, userID, id string) error
+	RotateSecret(ctx context.Context, userID, id string) (*models.WebhookEndpointWithSecret, error)
+	ListDeliveries(ctx context.Context, userID, endpointID string, status models.WebhookDeliveryStatus, p pagination.Params) ([]*models.WebhookDelivery, *pagination.Page, error)
+	Redeliver(ctx context.Context, userID, endpointID, deliveryID string) (*models.WebhookDelivery, error)
+	DeliverDue(ctx context.Context) error
+	RunDeliveryWorker(ctx context.Context)
+}
@@ -0,0 +1,69 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+type WorkspaceRepository interface {
+	// Create stores the workspace with its owner as the first member.
+	Create(ctx context.Context, workspace *models.Workspace) error
+	GetByID(ctx context.Context, id string) (*models.Workspace, error)
+	ListForUser(ctx context.Context, userID string) ([]*models.WorkspaceResponse, error)
+	Update(ctx context.Context, workspace *models.Workspace) error
+	// Delete removes the workspace with its members, invitations and credits.
+	// Its links go back to the members who created them. It returns
+	// ErrWorkspaceHasSubscription while the workspace has an active
+	// subscription.
+	Delete(ctx context.Context, id string) error
+
+	GetMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error)
+	ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error)
+	UpdateMemberRole(ctx context.Context, workspaceID, userID string, role models.WorkspaceRole) error
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+
+	// CreateInvitation stores an invitation, replacing any open invitation
+	// for the same address. It returns ErrAlreadyMember if the address
+	// belongs to a member.
+	CreateInvitation(ctx context.Context, invitation *models.WorkspaceInvitation) error
+	// GetInvitationByToken returns the open, unexpired invitation with the
+	// given token hash, or ErrInvalidInvitation.
+	GetInvitationByToken(ctx context.Context, tokenHash string, now time.Time) (*models.WorkspaceInvitation, error)
+	ListInvitations(ctx context.Context, workspaceID string) ([]*models.WorkspaceInvitation, error)
+	DeleteInvitation(ctx context.Context, workspaceID, id string) error
+	// AcceptInvitation marks the invitation used and adds the user as a
+	// member in one transaction, so an invitation can only be used once.
+	AcceptInvitation(ctx context.Context, invitation *models.WorkspaceInvitation, userID string) (*models.WorkspaceMember, error)
+}
+
+type PermissionService interface {
+	// Require returns the user's membership if their role in the workspace
+	// grants perm. Non-members get ErrWorkspaceNotFound so workspaces can't
+	// be probed; members without the permission get ErrForbidden.
+	Require(ctx context.Context, workspaceID, userID string, perm models.Permission) (*models.WorkspaceMember, error)
+	// CanAccessURL returns ErrForbidden unless the user may do perm on the
+	// link. Personal links are only open to their creator; workspace links to
+	// members whose role grants perm.
+	CanAccessURL(ctx context.Context, userID string, url *models.URL, perm models.Permission) error
+}
+
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, userID string, req *models.CreateWorkspaceRequest) (*models.WorkspaceResponse, error)
+	ListWorkspaces(ctx context.Context, userID string) ([]*models.WorkspaceResponse, error)
+	GetWorkspace(ctx context.Context, workspaceID, userID string) (*models.WorkspaceResponse, error)
+	UpdateWorkspace(ctx context.Context, workspaceID, userID string, req *models.UpdateWorkspaceRequest) (*models.WorkspaceResponse, error)
+	DeleteWorkspace(ctx context.Context, workspaceID, userID string) error
+
+	ListMembers(ctx context.Context, workspaceID, userID string) ([]*models.WorkspaceMember, error)
+	UpdateMemberRole(ctx context.Context, workspaceID, userID, memberID string, req *models.UpdateMemberRoleRequest) (*models.WorkspaceMember, error)
+	// RemoveMember removes a member, or lets a member leave when memberID is
+	// their own ID.
+	RemoveMember(ctx context.Context, workspaceID, userID, memberID string) error
+
+	InviteMember(ctx context.Context, workspaceID, userID string, req *models.InviteMemberRequest) (*models.WorkspaceInvitation, error)
+	ListInvitations(ctx context.Context, workspaceID, userID string) ([]*models.WorkspaceInvitation, error)
+	RevokeInvitation(ctx context.Context, workspaceID, userID, invitationID string) error
+	AcceptInvitation(ctx context.Context, userID, token string) (*models.WorkspaceMember, error)
+}
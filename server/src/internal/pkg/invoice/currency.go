@@ -0,0 +1,63 @@
+package invoice
+
+import (
+	"strconv"
+	"strings"
+)
+
+// currencySymbols covers the currencies with a symbol the PDF's standard
+// fonts can draw. Others are written with their ISO code.
+var currencySymbols = map[string]string{
+	"usd": "$",
+	"cad": "CA$",
+	"aud": "A$",
+	"eur": "€",
+	"gbp": "£",
+	"jpy": "¥",
+}
+
+// zeroDecimal lists currencies whose minor unit is the main unit.
+var zeroDecimal = map[string]bool{
+	"jpy": true, "krw": true, "vnd": true, "clp": true, "isk": true,
+	"ugx": true, "xaf": true, "xof": true, "pyg": true, "rwf": true,
+}
+
+// FormatAmount renders an amount in the currency's minor unit for people,
+// e.g. FormatAmount(123456, "usd") is "$1,234.56" and
+// FormatAmount(500, "chf") is "5.00 CHF".
+func FormatAmount(amount int, currency string) string {
+	currency = strings.ToLower(currency)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	var number string
+	if zeroDecimal[currency] {
+		number = groupThousands(amount)
+	} else {
+		number = groupThousands(amount/100) + "." + twoDigits(amount%100)
+	}
+
+	if symbol, ok := currencySymbols[currency]; ok {
+		return sign + symbol + number
+	}
+	return sign + number + " " + strings.ToUpper(currency)
+}
+
+func groupThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
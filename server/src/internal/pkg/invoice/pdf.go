@@ -0,0 +1,178 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+)
+
+const (
+	pageMargin = 20.0
+	lineHeight = 5.0
+)
+
+// RenderPDF draws inv as an A4 PDF. It only uses the standard PDF fonts, so
+// no font files are needed at runtime. The output depends only on inv, which
+// lets a stored invoice be rendered again byte for byte.
+func RenderPDF(inv *models.Invoice) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetCreationDate(inv.IssuedAt)
+	pdf.SetModificationDate(inv.IssuedAt)
+	pdf.SetCatalogSort(true)
+	pdf.SetTitle("Invoice "+inv.Number, true)
+	pdf.SetAuthor(inv.SellerName, true)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.CellFormat(0, lineHeight, fmt.Sprintf("%s - page %d of {nb}", inv.Number, pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	// The standard fonts use cp1252, which covers the currency symbols we print
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	width, _ := pdf.GetPageSize()
+	contentWidth := width - 2*pageMargin
+
+	pdf.AddPage()
+
+	// Header: seller on the left, invoice details on the right
+	top := pdf.GetY()
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(37, 99, 235)
+	pdf.CellFormat(contentWidth/2, 9, tr(inv.SellerName), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(55, 65, 81)
+	for _, line := range splitLines(inv.SellerAddress) {
+		pdf.CellFormat(contentWidth/2, lineHeight, tr(line), "", 2, "L", false, 0, "")
+	}
+	if inv.SellerTaxID != "" {
+		pdf.CellFormat(contentWidth/2, lineHeight, tr("Tax ID: "+inv.SellerTaxID), "", 2, "L", false, 0, "")
+	}
+	leftBottom := pdf.GetY()
+
+	pdf.SetXY(pageMargin+contentWidth/2, top)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(17, 24, 39)
+	pdf.CellFormat(contentWidth/2, 9, "INVOICE", "", 2, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(55, 65, 81)
+	for _, line := range []string{
+		"Number: " + inv.Number,
+		"Issued: " + inv.IssuedAt.UTC().Format("January 2, 2006"),
+		"Status: Paid",
+	} {
+		pdf.CellFormat(contentWidth/2, lineHeight, tr(line), "", 2, "R", false, 0, "")
+	}
+	pdf.SetY(max(leftBottom, pdf.GetY()) + 10)
+
+	// Bill to
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetTextColor(17, 24, 39)
+	pdf.CellFormat(contentWidth, 6, "Bill to", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(55, 65, 81)
+	billTo := inv.BillingTo.Lines()
+	billTo = append(billTo, inv.BillingEmail)
+	if inv.BillingTo.TaxID != "" {
+		billTo = append(billTo, "Tax ID: "+inv.BillingTo.TaxID)
+	}
+	for _, line := range billTo {
+		pdf.CellFormat(contentWidth, lineHeight, tr(line), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	// Line items
+	columns := []struct {
+		title string
+		width float64
+		align string
+	}{
+		{"Description", contentWidth - 90, "L"},
+		{"Qty", 20, "R"},
+		{"Unit price", 35, "R"},
+		{"Amount", 35, "R"},
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(243, 244, 246)
+	pdf.SetTextColor(17, 24, 39)
+	for _, col := range columns {
+		pdf.CellFormat(col.width, 8, col.title, "B", 0, col.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(55, 65, 81)
+	for _, item := range inv.LineItems {
+		cells := []string{
+			item.Description,
+			strconv.Itoa(item.Quantity),
+			FormatAmount(item.UnitAmount, inv.Currency),
+			FormatAmount(item.Amount, inv.Currency),
+		}
+		for i, col := range columns {
+			pdf.CellFormat(col.width, 8, tr(cells[i]), "B", 0, col.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(4)
+
+	// Totals, right aligned under the amount column
+	labelWidth := 55.0
+	totals := [][2]string{{"Subtotal", FormatAmount(inv.Subtotal, inv.Currency)}}
+	if inv.TaxRate > 0 {
+		label := inv.TaxLabel
+		if label == "" {
+			label = "Tax"
+		}
+		totals = append(totals, [2]string{
+			fmt.Sprintf("%s (%s%%)", label, strconv.FormatFloat(inv.TaxRate, 'f', -1, 64)),
+			FormatAmount(inv.TaxAmount, inv.Currency),
+		})
+	}
+	for _, row := range totals {
+		pdf.SetX(pageMargin + contentWidth - labelWidth - 35)
+		pdf.CellFormat(labelWidth, 7, tr(row[0]), "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 7, tr(row[1]), "", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.SetTextColor(17, 24, 39)
+	pdf.SetX(pageMargin + contentWidth - labelWidth - 35)
+	pdf.CellFormat(labelWidth, 9, "Total paid", "T", 0, "R", false, 0, "")
+	pdf.CellFormat(35, 9, tr(FormatAmount(inv.Total, inv.Currency)), "T", 1, "R", false, 0, "")
+
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(107, 114, 128)
+	pdf.MultiCell(contentWidth, 4, tr("Thank you for your business. This invoice was paid in full on "+
+		inv.IssuedAt.UTC().Format("January 2, 2006")+"."), "", "L", false)
+
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("failed to render invoice %s: %w", inv.Number, err)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render invoice %s: %w", inv.Number, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// splitLines splits a configured address into lines. Environment variables
+// can't hold newlines, so a literal "\n" also separates lines.
+func splitLines(s string) []string {
+	var lines []string
+	s = strings.ReplaceAll(s, `\n`, "\n")
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
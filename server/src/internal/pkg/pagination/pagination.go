@@ -0,0 +1,172 @@
+// Package pagination pages lists with opaque cursors. A cursor names the
+// last row of the previous page by its creation time and ID, so pages stay
+// stable while rows are added, unlike offsets, which shift. Lists sorted by
+// anything else carry an offset in the cursor instead.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Cursor is the position a page starts after
+type Cursor struct {
+	CreatedAt time.Time `json:"t,omitempty"`
+	ID        string    `json:"i,omitempty"`
+	Offset    int       `json:"o,omitempty"`
+}
+
+// Encode returns the cursor as a URL-safe token
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode reads a token made by Encode
+func Decode(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset < 0 {
+		return nil, ErrInvalidCursor
+	}
+	if (c.ID == "") == (c.Offset == 0) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Params asks for one page. A nil Cursor asks for the first.
+type Params struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// Parse reads the limit and cursor query parameters. An empty limit means
+// DefaultLimit and larger ones are cut to MaxLimit.
+func Parse(limit, cursor string) (Params, error) {
+	p := Params{Limit: DefaultLimit}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return p, ErrInvalidLimit
+		}
+		p.Limit = min(n, MaxLimit)
+	}
+	if cursor != "" {
+		c, err := Decode(cursor)
+		if err != nil {
+			return p, err
+		}
+		p.Cursor = c
+	}
+	return p, nil
+}
+
+func (p Params) limit() int {
+	if p.Limit < 1 {
+		return DefaultLimit
+	}
+	return min(p.Limit, MaxLimit)
+}
+
+// Newest orders a query newest first and skips to the cursor. It fetches
+// one row past the limit so Trim can tell whether more follow. table
+// qualifies the columns and may be empty.
+func (p Params) Newest(table string) func(*gorm.DB) *gorm.DB {
+	return p.keyset(table, "<", "DESC")
+}
+
+// Oldest is Newest for lists read oldest first
+func (p Params) Oldest(table string) func(*gorm.DB) *gorm.DB {
+	return p.keyset(table, ">", "ASC")
+}
+
+func (p Params) keyset(table, op, dir string) func(*gorm.DB) *gorm.DB {
+	createdAt, id := "created_at", "id"
+	if table != "" {
+		createdAt, id = table+".created_at", table+".id"
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		if p.Cursor != nil {
+			if p.Cursor.ID == "" {
+				db.AddError(ErrInvalidCursor)
+				return db
+			}
+			db = db.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", createdAt, op, createdAt, id, op),
+				p.Cursor.CreatedAt, p.Cursor.CreatedAt, p.Cursor.ID)
+		}
+		return db.Order(fmt.Sprintf("%s %s, %s %s", createdAt, dir, id, dir)).Limit(p.limit() + 1)
+	}
+}
+
+// Sorted pages a query in the given order by offset. It is for orders that
+// a row position can't resume, such as by title.
+func (p Params) Sorted(order string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		offset := 0
+		if p.Cursor != nil {
+			if p.Cursor.ID != "" {
+				db.AddError(ErrInvalidCursor)
+				return db
+			}
+			offset = p.Cursor.Offset
+		}
+		return db.Order(order).Offset(offset).Limit(p.limit() + 1)
+	}
+}
+
+// Page describes a page of a list. Responses carry it as their meta.
+type Page struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// All describes a list that is returned whole
+func All(n int) *Page {
+	return &Page{Total: int64(n)}
+}
+
+// Trim drops the extra row fetched by Newest, Oldest or Sorted and
+// describes the page. position gives the cursor a row ends a page at; it is
+// nil for lists paged with Sorted.
+func Trim[T any](items []T, p Params, total int64, position func(T) Cursor) ([]T, *Page) {
+	page := &Page{Total: total, Limit: p.limit()}
+	if len(items) <= page.Limit {
+		return items, page
+	}
+
+	items = items[:page.Limit]
+	var next Cursor
+	if position != nil {
+		next = position(items[len(items)-1])
+	} else {
+		next.Offset = page.Limit
+		if p.Cursor != nil {
+			next.Offset += p.Cursor.Offset
+		}
+	}
+	page.HasMore = true
+	page.NextCursor = next.Encode()
+	return items, page
+}
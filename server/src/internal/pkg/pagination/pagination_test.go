@@ -0,0 +1,154 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"position", Cursor{CreatedAt: time.Date(2025, 10, 19, 13, 30, 0, 123456789, time.UTC), ID: "abc_-123"}},
+		{"position in another zone", Cursor{CreatedAt: time.Date(2025, 10, 19, 13, 30, 0, 0, time.FixedZone("IST", 5*3600+1800)), ID: "x"}},
+		{"offset", Cursor{Offset: 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.cursor.Encode()
+			got, err := Decode(token)
+			if err != nil {
+				t.Fatalf("Decode(%q): %v", token, err)
+			}
+			if !got.CreatedAt.Equal(tt.cursor.CreatedAt) || got.ID != tt.cursor.ID || got.Offset != tt.cursor.Offset {
+				t.Errorf("Decode(Encode(%+v)) = %+v", tt.cursor, *got)
+			}
+
+			// Parse reads the same token from a query string
+			p, err := Parse("", token)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if p.Cursor == nil || p.Cursor.ID != tt.cursor.ID || p.Cursor.Offset != tt.cursor.Offset {
+				t.Errorf("Parse cursor = %+v, want %+v", p.Cursor, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsTamperedCursors(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	valid := (&Cursor{CreatedAt: time.Now(), ID: "abc"}).Encode()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "not a cursor!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"o":20}`))},
+		{"standard base64 alphabet", "+/" + valid},
+		{"truncated", valid[:len(valid)-4]},
+		{"not json", encode("hello")},
+		{"json array", encode(`[1,2]`)},
+		{"wrong field type", encode(`{"o":"20"}`)},
+		{"bad time", encode(`{"t":"yesterday","i":"abc"}`)},
+		{"empty object", encode(`{}`)},
+		{"negative offset", encode(`{"o":-20}`)},
+		{"position and offset", encode(`{"t":"2025-10-19T13:30:00Z","i":"abc","o":20}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := Decode(tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q) = %+v, %v; want ErrInvalidCursor", tt.token, c, err)
+			}
+			if _, err := Parse("", tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidCursor", tt.token, err)
+			}
+		})
+	}
+}
+
+// A cursor from a list paged one way can't be replayed against a list paged
+// the other way
+func TestScopesRejectCursorsOfTheOtherKind(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		DryRun: true,
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type row struct {
+		ID        string
+		CreatedAt time.Time
+	}
+	position := Params{Cursor: &Cursor{CreatedAt: time.Now(), ID: "abc"}}
+	offset := Params{Cursor: &Cursor{Offset: 20}}
+
+	tests := []struct {
+		name  string
+		scope func(*gorm.DB) *gorm.DB
+	}{
+		{"offset cursor on Newest", offset.Newest("")},
+		{"offset cursor on Oldest", offset.Oldest("")},
+		{"position cursor on Sorted", position.Sorted("id")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []row
+			err := db.Scopes(tt.scope).Find(&rows).Error
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestTrimNextCursorResumesAfterLastRow(t *testing.T) {
+	type row struct {
+		id        string
+		createdAt time.Time
+	}
+	base := time.Date(2025, 10, 19, 0, 0, 0, 0, time.UTC)
+	rows := []row{{"a", base}, {"b", base.Add(time.Second)}, {"c", base.Add(2 * time.Second)}}
+	position := func(r row) Cursor { return Cursor{CreatedAt: r.createdAt, ID: r.id} }
+
+	items, page := Trim(rows, Params{Limit: 2}, 10, position)
+	if len(items) != 2 || !page.HasMore || page.Total != 10 || page.Limit != 2 {
+		t.Fatalf("Trim = %d items, %+v", len(items), *page)
+	}
+	next, err := Decode(page.NextCursor)
+	if err != nil {
+		t.Fatalf("Decode next cursor: %v", err)
+	}
+	if next.ID != "b" || !next.CreatedAt.Equal(rows[1].createdAt) {
+		t.Errorf("next cursor = %+v, want position of b", *next)
+	}
+
+	// Offset cursors add up across pages
+	_, page = Trim(rows, Params{Limit: 2, Cursor: &Cursor{Offset: 4}}, 10, nil)
+	next, err = Decode(page.NextCursor)
+	if err != nil {
+		t.Fatalf("Decode next offset cursor: %v", err)
+	}
+	if next.Offset != 6 {
+		t.Errorf("next offset = %d, want 6", next.Offset)
+	}
+
+	// A short page has no next cursor
+	_, page = Trim(rows, Params{Limit: 5}, 3, position)
+	if page.HasMore || page.NextCursor != "" {
+		t.Errorf("last page = %+v, want no next cursor", *page)
+	}
+}
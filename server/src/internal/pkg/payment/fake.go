@@ -0,0 +1,368 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Payment method tokens the fake gateway declines, named after Stripe's test
+// payment methods. Every other non-empty token is charged successfully.
+var fakeDeclinedTokens = map[string]bool{
+	"pm_card_chargeDeclined":                  true,
+	"pm_card_chargeDeclinedInsufficientFunds": true,
+	"tok_chargeDeclined":                      true,
+}
+
+// FakeGateway is an in-memory PaymentGateway for tests and local runs. IDs are
+// sequential so results are deterministic. Webhooks use Stripe's payload and
+// signature format, so the same fixtures work against both gateways.
+type FakeGateway struct {
+	mu            sync.Mutex
+	seq           int
+	webhookSecret string
+	Now           func() time.Time
+	customers     map[string]*Customer
+	methods       map[string]*PaymentMethod // by customer ID
+	subscriptions map[string]*Subscription
+	invoices      map[string]*Invoice
+	coupons       map[string]*Coupon
+}
+
+func NewFakeGateway(webhookSecret string) *FakeGateway {
+	return &FakeGateway{
+		webhookSecret: webhookSecret,
+		Now:           time.Now,
+		customers:     make(map[string]*Customer),
+		methods:       make(map[string]*PaymentMethod),
+		subscriptions: make(map[string]*Subscription),
+		invoices:      make(map[string]*Invoice),
+		coupons:       make(map[string]*Coupon),
+	}
+}
+
+// AddCoupon registers a coupon that discounts every invoice.
+func (g *FakeGateway) AddCoupon(id string, percentOff int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.coupons[id] = &Coupon{ID: id, PercentOff: percentOff, Duration: CouponForever}
+}
+
+func (g *FakeGateway) CreateCoupon(ctx context.Context, params CouponParams) (*Coupon, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.coupons[params.ID]; ok {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Code: "resource_already_exists", Message: "coupon already exists"}
+	}
+	c := &Coupon{ID: params.ID, PercentOff: params.PercentOff, Duration: params.Duration}
+	g.coupons[c.ID] = c
+	copied := *c
+	return &copied, nil
+}
+
+func (g *FakeGateway) CreateCustomer(ctx context.Context, params CustomerParams) (*Customer, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c := &Customer{ID: g.nextID("cus"), Email: params.Email}
+	g.customers[c.ID] = c
+	return c, nil
+}
+
+func (g *FakeGateway) AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.customers[customerID]; !ok {
+		return nil, missing("customer", customerID)
+	}
+	if token == "" {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Message: "payment method is required"}
+	}
+
+	// Test tokens stand for one card each, so the token is the fingerprint
+	pm := &PaymentMethod{ID: token, Brand: "visa", Last4: "4242", Fingerprint: "fp_" + token}
+	g.methods[customerID] = pm
+	return pm, nil
+}
+
+func (g *FakeGateway) CreateSubscription(ctx context.Context, params SubscriptionParams) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.customers[params.CustomerID]; !ok {
+		return nil, missing("customer", params.CustomerID)
+	}
+
+	pmID := params.PaymentMethodID
+	if pmID == "" {
+		if pm, ok := g.methods[params.CustomerID]; ok {
+			pmID = pm.ID
+		}
+	}
+	if pmID == "" {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Code: "resource_missing", Message: "customer has no payment method"}
+	}
+	if fakeDeclinedTokens[pmID] {
+		return nil, &Error{StatusCode: http.StatusPaymentRequired, Type: "card_error", Code: "card_declined", DeclineCode: "generic_decline", Message: "Your card was declined."}
+	}
+
+	amount, recurring := params.Amount, params.Amount
+	if params.Coupon != "" {
+		coupon, ok := g.coupons[params.Coupon]
+		if !ok {
+			return nil, missing("coupon", params.Coupon)
+		}
+		amount -= amount * coupon.PercentOff / 100
+		if coupon.Duration == CouponForever {
+			recurring = amount
+		}
+	}
+
+	interval := params.Interval
+	if interval == "" {
+		interval = "month"
+	}
+
+	now := g.Now()
+	sub := &Subscription{
+		ID:                 g.nextID("sub"),
+		CustomerID:         params.CustomerID,
+		ItemID:             g.nextID("si"),
+		PriceID:            params.PriceID,
+		Status:             StatusActive,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   nextPeriod(now, interval),
+		Amount:             recurring,
+		Interval:           interval,
+	}
+	sub.LatestInvoice = g.paidInvoice(sub, amount, params.Currency, BillingReasonCreate, now)
+	g.subscriptions[sub.ID] = sub
+
+	return copySubscription(sub), nil
+}
+
+func (g *FakeGateway) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sub, ok := g.subscriptions[id]
+	if !ok {
+		return nil, missing("subscription", id)
+	}
+	return copySubscription(sub), nil
+}
+
+func (g *FakeGateway) UpdateSubscription(ctx context.Context, id string, params SubscriptionUpdateParams) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sub, ok := g.subscriptions[id]
+	if !ok {
+		return nil, missing("subscription", id)
+	}
+	if sub.Status == StatusCanceled {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Message: "subscription is canceled"}
+	}
+
+	if params.Prorate {
+		at := params.ProrationDate
+		if at.IsZero() {
+			at = g.Now()
+		}
+		if charge := ProratedAmount(sub.Amount, params.Amount, sub.CurrentPeriodStart, sub.CurrentPeriodEnd, at); charge > 0 {
+			if pm, ok := g.methods[sub.CustomerID]; !ok || fakeDeclinedTokens[pm.ID] {
+				return nil, &Error{StatusCode: http.StatusPaymentRequired, Type: "card_error", Code: "card_declined", DeclineCode: "generic_decline", Message: "Your card was declined."}
+			}
+			sub.LatestInvoice = g.paidInvoice(sub, charge, sub.LatestInvoice.Currency, BillingReasonUpdate, g.Now())
+		}
+	}
+
+	if params.PriceID != "" {
+		sub.PriceID = params.PriceID
+	}
+	if params.Amount > 0 {
+		sub.Amount = params.Amount
+	}
+	return copySubscription(sub), nil
+}
+
+// RenewSubscription opens an invoice for the next period once the current one
+// has ended and charges the customer's payment method. Attach a declined
+// token to the customer to simulate a failed renewal.
+func (g *FakeGateway) RenewSubscription(ctx context.Context, id string) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sub, ok := g.subscriptions[id]
+	if !ok {
+		return nil, missing("subscription", id)
+	}
+	if sub.Status == StatusCanceled {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Message: "subscription is canceled"}
+	}
+
+	now := g.Now()
+	inv := g.invoices[sub.LatestInvoice.ID]
+	if inv.Status == InvoicePaid {
+		if now.Before(sub.CurrentPeriodEnd) {
+			return copySubscription(sub), nil
+		}
+		sub.CurrentPeriodStart = sub.CurrentPeriodEnd
+		sub.CurrentPeriodEnd = nextPeriod(sub.CurrentPeriodEnd, sub.Interval)
+		inv = g.newInvoice(sub, sub.Amount, inv.Currency, BillingReasonCycle)
+	}
+
+	pm, ok := g.methods[sub.CustomerID]
+	if !ok || fakeDeclinedTokens[pm.ID] {
+		sub.Status = StatusPastDue
+		copied := *inv
+		sub.LatestInvoice = &copied
+		return nil, &Error{StatusCode: http.StatusPaymentRequired, Type: "card_error", Code: "card_declined", DeclineCode: "generic_decline", Message: "Your card was declined."}
+	}
+
+	inv.Status = InvoicePaid
+	inv.AmountPaid = inv.AmountDue
+	inv.PaidAt = &now
+	copied := *inv
+	sub.Status = StatusActive
+	sub.LatestInvoice = &copied
+	return copySubscription(sub), nil
+}
+
+func (g *FakeGateway) CancelSubscription(ctx context.Context, id string, atPeriodEnd bool) (*Subscription, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	sub, ok := g.subscriptions[id]
+	if !ok {
+		return nil, missing("subscription", id)
+	}
+	if atPeriodEnd {
+		sub.CancelAtPeriodEnd = true
+	} else {
+		sub.Status = StatusCanceled
+	}
+	return copySubscription(sub), nil
+}
+
+func (g *FakeGateway) GetInvoice(ctx context.Context, id string) (*Invoice, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	inv, ok := g.invoices[id]
+	if !ok {
+		return nil, missing("invoice", id)
+	}
+	copied := *inv
+	return &copied, nil
+}
+
+func (g *FakeGateway) ListInvoices(ctx context.Context, customerID string) ([]*Invoice, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var invoices []*Invoice
+	for _, inv := range g.invoices {
+		if inv.CustomerID == customerID {
+			copied := *inv
+			invoices = append(invoices, &copied)
+		}
+	}
+	return invoices, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, params RefundParams) (*Refund, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, inv := range g.invoices {
+		if inv.PaymentIntentID != params.PaymentIntentID {
+			continue
+		}
+		amount := params.Amount
+		if amount == 0 {
+			amount = inv.AmountPaid
+		}
+		if amount > inv.AmountPaid {
+			return nil, &Error{StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Code: "amount_too_large", Message: "refund exceeds the amount paid"}
+		}
+		return &Refund{ID: g.nextID("re"), PaymentIntentID: params.PaymentIntentID, Amount: amount, Status: "succeeded"}, nil
+	}
+	return nil, missing("payment_intent", params.PaymentIntentID)
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if err := verifyStripeSignature(payload, signature, g.webhookSecret, g.Now()); err != nil {
+		return nil, err
+	}
+	return decodeStripeEvent(payload)
+}
+
+func (g *FakeGateway) DecodeEvent(payload []byte) (*Event, error) {
+	return decodeStripeEvent(payload)
+}
+
+func (g *FakeGateway) paidInvoice(sub *Subscription, amount int, currency, reason string, now time.Time) *Invoice {
+	inv := g.newInvoice(sub, amount, currency, reason)
+	inv.Status = InvoicePaid
+	inv.AmountPaid = amount
+	inv.PaidAt = &now
+	copied := *inv
+	return &copied
+}
+
+// newInvoice stores an open invoice for the subscription's current period.
+func (g *FakeGateway) newInvoice(sub *Subscription, amount int, currency, reason string) *Invoice {
+	if currency == "" {
+		currency = "usd"
+	}
+	inv := &Invoice{
+		ID:              g.nextID("in"),
+		CustomerID:      sub.CustomerID,
+		SubscriptionID:  sub.ID,
+		PaymentIntentID: g.nextID("pi"),
+		Status:          InvoiceOpen,
+		AmountDue:       amount,
+		Currency:        strings.ToLower(currency),
+		BillingReason:   reason,
+		PeriodStart:     sub.CurrentPeriodStart,
+		PeriodEnd:       sub.CurrentPeriodEnd,
+	}
+	g.invoices[inv.ID] = inv
+	return inv
+}
+
+func (g *FakeGateway) nextID(prefix string) string {
+	g.seq++
+	return fmt.Sprintf("%s_fake_%06d", prefix, g.seq)
+}
+
+func nextPeriod(t time.Time, interval string) time.Time {
+	if interval == "year" {
+		return t.AddDate(1, 0, 0)
+	}
+	return t.AddDate(0, 1, 0)
+}
+
+func copySubscription(sub *Subscription) *Subscription {
+	copied := *sub
+	if sub.LatestInvoice != nil {
+		inv := *sub.LatestInvoice
+		copied.LatestInvoice = &inv
+	}
+	return &copied
+}
+
+func missing(resource, id string) *Error {
+	return &Error{
+		StatusCode: http.StatusNotFound,
+		Type:       "invalid_request_error",
+		Code:       "resource_missing",
+		Message:    fmt.Sprintf("No such %s: '%s'", resource, id),
+	}
+}
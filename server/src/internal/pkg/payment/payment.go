@@ -0,0 +1,235 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// PaymentGateway is the billing provider used for customers, payment
+// methods, subscriptions, invoices and refunds. Amounts are in the smallest
+// currency unit (cents).
+type PaymentGateway interface {
+	CreateCustomer(ctx context.Context, params CustomerParams) (*Customer, error)
+	AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error)
+	CreateSubscription(ctx context.Context, params SubscriptionParams) (*Subscription, error)
+	GetSubscription(ctx context.Context, id string) (*Subscription, error)
+	UpdateSubscription(ctx context.Context, id string, params SubscriptionUpdateParams) (*Subscription, error)
+	// RenewSubscription charges for the next period once the current one has
+	// ended, or retries the charge for an unpaid period. A declined card is
+	// returned as an *Error.
+	RenewSubscription(ctx context.Context, id string) (*Subscription, error)
+	CancelSubscription(ctx context.Context, id string, atPeriodEnd bool) (*Subscription, error)
+	CreateCoupon(ctx context.Context, params CouponParams) (*Coupon, error)
+	GetInvoice(ctx context.Context, id string) (*Invoice, error)
+	ListInvoices(ctx context.Context, customerID string) ([]*Invoice, error)
+	Refund(ctx context.Context, params RefundParams) (*Refund, error)
+
+	// ParseWebhook verifies the provider signature and decodes the event.
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+	// DecodeEvent decodes a payload that was verified when it was received.
+	DecodeEvent(payload []byte) (*Event, error)
+}
+
+type SubscriptionStatus string
+
+const (
+	StatusActive     SubscriptionStatus = "active"
+	StatusTrialing   SubscriptionStatus = "trialing"
+	StatusIncomplete SubscriptionStatus = "incomplete"
+	StatusPastDue    SubscriptionStatus = "past_due"
+	StatusCanceled   SubscriptionStatus = "canceled"
+	StatusUnpaid     SubscriptionStatus = "unpaid"
+)
+
+const (
+	InvoiceOpen = "open"
+	InvoicePaid = "paid"
+)
+
+// Billing reasons say why an invoice was raised. Only create and cycle
+// invoices pay for a whole period of the plan.
+const (
+	BillingReasonCreate = "subscription_create"
+	BillingReasonCycle  = "subscription_cycle"
+	BillingReasonUpdate = "subscription_update"
+)
+
+type Customer struct {
+	ID    string
+	Email string
+}
+
+type PaymentMethod struct {
+	ID    string
+	Brand string
+	Last4 string
+	// Fingerprint identifies the card itself, so the same card attached to
+	// different customers has the same fingerprint
+	Fingerprint string
+}
+
+type Subscription struct {
+	ID                 string
+	CustomerID         string
+	ItemID             string
+	PriceID            string
+	Status             SubscriptionStatus
+	CurrentPeriodStart time.Time
+	CurrentPeriodEnd   time.Time
+	CancelAtPeriodEnd  bool
+	Amount             int    // recurring charge per period, in cents
+	Interval           string // billing period, "month" or "year"
+	LatestInvoice      *Invoice
+}
+
+// IsPaid reports whether the subscription is active and its latest invoice
+// has been charged successfully.
+func (s *Subscription) IsPaid() bool {
+	return s.Status == StatusActive && s.LatestInvoice != nil && s.LatestInvoice.Status == InvoicePaid
+}
+
+type Invoice struct {
+	ID              string
+	CustomerID      string
+	SubscriptionID  string
+	PaymentIntentID string
+	Status          string
+	AmountDue       int
+	AmountPaid      int
+	Currency        string
+	HostedURL       string
+	BillingReason   string
+	PeriodStart     time.Time
+	PeriodEnd       time.Time
+	PaidAt          *time.Time
+}
+
+type Refund struct {
+	ID              string
+	PaymentIntentID string
+	Amount          int
+	Status          string
+}
+
+type CustomerParams struct {
+	Email    string
+	Name     string
+	Metadata map[string]string
+}
+
+// SubscriptionParams describes a new subscription. Amount and Currency are the
+// plan price; providers that price server-side, like Stripe, ignore them.
+type SubscriptionParams struct {
+	CustomerID      string
+	PriceID         string
+	PaymentMethodID string
+	Coupon          string
+	Amount          int
+	Currency        string
+	Interval        string // "month" (default) or "year"; Stripe takes it from the price
+	Metadata        map[string]string
+}
+
+// SubscriptionUpdateParams changes a subscription's price. With Prorate the
+// difference for the rest of the period is charged immediately, calculated as
+// of ProrationDate so it matches what the customer was shown. Without it the
+// new price applies from the next period.
+type SubscriptionUpdateParams struct {
+	PriceID       string
+	Amount        int
+	Prorate       bool
+	ProrationDate time.Time
+	Metadata      map[string]string
+}
+
+// Coupon durations
+const (
+	CouponOnce    = "once"    // first invoice only
+	CouponForever = "forever" // every invoice
+)
+
+// CouponParams creates a percentage discount that subscriptions can be
+// created with.
+type CouponParams struct {
+	ID         string
+	Name       string
+	PercentOff int
+	Duration   string
+}
+
+type Coupon struct {
+	ID         string
+	PercentOff int
+	Duration   string
+}
+
+// RefundParams refunds a payment. A zero Amount refunds it in full.
+type RefundParams struct {
+	PaymentIntentID string
+	Amount          int
+	Reason          string
+}
+
+// Error is returned by gateways when the provider rejects a request.
+type Error struct {
+	StatusCode  int
+	Type        string
+	Code        string
+	DeclineCode string
+	Message     string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("payment provider error (%s): %s", e.Code, e.Message)
+	}
+	return "payment provider error: " + e.Message
+}
+
+// Declined reports whether the error is a card or payment method failure as
+// opposed to a configuration or provider problem.
+func (e *Error) Declined() bool {
+	return e.Type == "card_error"
+}
+
+// ProratedAmount is what switching from oldAmount to newAmount at the given
+// time costs for the rest of the period, rounded to the nearest cent. It is
+// zero for downgrades, which take effect at the end of the period instead.
+func ProratedAmount(oldAmount, newAmount int, periodStart, periodEnd, at time.Time) int {
+	total := periodEnd.Sub(periodStart)
+	remaining := periodEnd.Sub(at)
+	if newAmount <= oldAmount || total <= 0 || remaining <= 0 {
+		return 0
+	}
+	if remaining > total {
+		remaining = total
+	}
+	return int(math.Round(float64(newAmount-oldAmount) * remaining.Seconds() / total.Seconds()))
+}
+
+// NewGateway builds the configured provider. The fake gateway is used when no
+// provider is set so local runs never hit a real payment API.
+func NewGateway(cfg *configs.PaymentConfig, log logger.Logger) (PaymentGateway, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "stripe":
+		if cfg.Stripe.SecretKey == "" {
+			return nil, fmt.Errorf("payment.stripe.secret_key is required for the stripe provider")
+		}
+		if cfg.Prices.Basic == "" || cfg.Prices.Pro == "" || cfg.Prices.Enterprise == "" {
+			return nil, fmt.Errorf("payment.prices must be set for every plan when using the stripe provider")
+		}
+		log.Info("Stripe payment gateway initialized")
+		return NewStripeGateway(&cfg.Stripe, cfg.WebhookSecret, log), nil
+	case "", "fake":
+		log.Info("Using fake payment gateway")
+		return NewFakeGateway(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,442 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+const (
+	defaultStripeAPIBase = "https://api.stripe.com"
+	// The subscription fields below follow this API version, so it is pinned
+	// rather than left to the account default.
+	stripeAPIVersion = "2024-06-20"
+	stripeTimeout    = 20 * time.Second
+)
+
+// StripeGateway talks to the Stripe REST API directly over HTTP.
+type StripeGateway struct {
+	secretKey     string
+	webhookSecret string
+	apiBase       string
+	client        *http.Client
+	log           logger.Logger
+}
+
+func NewStripeGateway(cfg *configs.StripeConfig, webhookSecret string, log logger.Logger) *StripeGateway {
+	apiBase := strings.TrimRight(cfg.APIBase, "/")
+	if apiBase == "" {
+		apiBase = defaultStripeAPIBase
+	}
+	return &StripeGateway{
+		secretKey:     cfg.SecretKey,
+		webhookSecret: webhookSecret,
+		apiBase:       apiBase,
+		client:        &http.Client{Timeout: stripeTimeout},
+		log:           log,
+	}
+}
+
+func (g *StripeGateway) CreateCustomer(ctx context.Context, params CustomerParams) (*Customer, error) {
+	form := url.Values{}
+	form.Set("email", params.Email)
+	if params.Name != "" {
+		form.Set("name", params.Name)
+	}
+	setMetadata(form, params.Metadata)
+
+	var out stripeCustomer
+	if err := g.do(ctx, http.MethodPost, "/v1/customers", form, &out); err != nil {
+		return nil, err
+	}
+	return &Customer{ID: out.ID, Email: out.Email}, nil
+}
+
+func (g *StripeGateway) CreateCoupon(ctx context.Context, params CouponParams) (*Coupon, error) {
+	form := url.Values{}
+	form.Set("id", params.ID)
+	form.Set("percent_off", strconv.Itoa(params.PercentOff))
+	form.Set("duration", params.Duration)
+	if params.Name != "" {
+		form.Set("name", params.Name)
+	}
+
+	var out struct {
+		ID         string  `json:"id"`
+		PercentOff float64 `json:"percent_off"`
+		Duration   string  `json:"duration"`
+	}
+	if err := g.do(ctx, http.MethodPost, "/v1/coupons", form, &out); err != nil {
+		return nil, err
+	}
+	return &Coupon{ID: out.ID, PercentOff: int(out.PercentOff), Duration: out.Duration}, nil
+}
+
+// AttachPaymentMethod attaches a payment method created client-side (for
+// example with Stripe.js) and makes it the customer's default for invoices.
+func (g *StripeGateway) AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error) {
+	form := url.Values{}
+	form.Set("customer", customerID)
+
+	var pm stripePaymentMethod
+	if err := g.do(ctx, http.MethodPost, "/v1/payment_methods/"+url.PathEscape(token)+"/attach", form, &pm); err != nil {
+		return nil, err
+	}
+
+	form = url.Values{}
+	form.Set("invoice_settings[default_payment_method]", pm.ID)
+	if err := g.do(ctx, http.MethodPost, "/v1/customers/"+url.PathEscape(customerID), form, nil); err != nil {
+		return nil, err
+	}
+
+	return &PaymentMethod{ID: pm.ID, Brand: pm.Card.Brand, Last4: pm.Card.Last4, Fingerprint: pm.Card.Fingerprint}, nil
+}
+
+// CreateSubscription charges the first invoice immediately. With
+// error_if_incomplete Stripe rejects the request when the charge fails instead
+// of leaving an incomplete subscription behind.
+func (g *StripeGateway) CreateSubscription(ctx context.Context, params SubscriptionParams) (*Subscription, error) {
+	form := url.Values{}
+	form.Set("customer", params.CustomerID)
+	form.Set("items[0][price]", params.PriceID)
+	form.Set("payment_behavior", "error_if_incomplete")
+	form.Add("expand[]", "latest_invoice")
+	if params.PaymentMethodID != "" {
+		form.Set("default_payment_method", params.PaymentMethodID)
+	}
+	if params.Coupon != "" {
+		form.Set("discounts[0][coupon]", params.Coupon)
+	}
+	setMetadata(form, params.Metadata)
+
+	var out stripeSubscription
+	if err := g.do(ctx, http.MethodPost, "/v1/subscriptions", form, &out); err != nil {
+		return nil, err
+	}
+	return out.toSubscription()
+}
+
+func (g *StripeGateway) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	var out stripeSubscription
+	if err := g.do(ctx, http.MethodGet, "/v1/subscriptions/"+url.PathEscape(id)+"?expand[]=latest_invoice", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.toSubscription()
+}
+
+func (g *StripeGateway) UpdateSubscription(ctx context.Context, id string, params SubscriptionUpdateParams) (*Subscription, error) {
+	current, err := g.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	if params.PriceID != "" {
+		form.Set("items[0][id]", current.ItemID)
+		form.Set("items[0][price]", params.PriceID)
+	}
+	if params.Prorate {
+		// Invoice and charge the difference now, failing if the card declines
+		form.Set("proration_behavior", "always_invoice")
+		form.Set("payment_behavior", "error_if_incomplete")
+		if !params.ProrationDate.IsZero() {
+			form.Set("proration_date", strconv.FormatInt(params.ProrationDate.Unix(), 10))
+		}
+	} else {
+		form.Set("proration_behavior", "none")
+	}
+	form.Add("expand[]", "latest_invoice")
+	setMetadata(form, params.Metadata)
+
+	var out stripeSubscription
+	if err := g.do(ctx, http.MethodPost, "/v1/subscriptions/"+url.PathEscape(id), form, &out); err != nil {
+		return nil, err
+	}
+	return out.toSubscription()
+}
+
+// RenewSubscription retries an open invoice. Stripe renews subscriptions on its
+// own schedule, so there is nothing to start here.
+func (g *StripeGateway) RenewSubscription(ctx context.Context, id string) (*Subscription, error) {
+	sub, err := g.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv := sub.LatestInvoice; inv == nil || inv.Status != InvoiceOpen {
+		return sub, nil
+	}
+
+	var out stripeInvoice
+	if err := g.do(ctx, http.MethodPost, "/v1/invoices/"+url.PathEscape(sub.LatestInvoice.ID)+"/pay", url.Values{}, &out); err != nil {
+		return nil, err
+	}
+	return g.GetSubscription(ctx, id)
+}
+
+func (g *StripeGateway) CancelSubscription(ctx context.Context, id string, atPeriodEnd bool) (*Subscription, error) {
+	var out stripeSubscription
+	if atPeriodEnd {
+		form := url.Values{}
+		form.Set("cancel_at_period_end", "true")
+		if err := g.do(ctx, http.MethodPost, "/v1/subscriptions/"+url.PathEscape(id), form, &out); err != nil {
+			return nil, err
+		}
+	} else if err := g.do(ctx, http.MethodDelete, "/v1/subscriptions/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.toSubscription()
+}
+
+func (g *StripeGateway) GetInvoice(ctx context.Context, id string) (*Invoice, error) {
+	var out stripeInvoice
+	if err := g.do(ctx, http.MethodGet, "/v1/invoices/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.toInvoice(), nil
+}
+
+func (g *StripeGateway) ListInvoices(ctx context.Context, customerID string) ([]*Invoice, error) {
+	var out struct {
+		Data []stripeInvoice `json:"data"`
+	}
+	path := "/v1/invoices?limit=100&customer=" + url.QueryEscape(customerID)
+	if err := g.do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*Invoice, 0, len(out.Data))
+	for i := range out.Data {
+		invoices = append(invoices, out.Data[i].toInvoice())
+	}
+	return invoices, nil
+}
+
+func (g *StripeGateway) Refund(ctx context.Context, params RefundParams) (*Refund, error) {
+	form := url.Values{}
+	form.Set("payment_intent", params.PaymentIntentID)
+	if params.Amount > 0 {
+		form.Set("amount", strconv.Itoa(params.Amount))
+	}
+	if params.Reason != "" {
+		form.Set("reason", params.Reason)
+	}
+
+	var out struct {
+		ID            string `json:"id"`
+		PaymentIntent string `json:"payment_intent"`
+		Amount        int    `json:"amount"`
+		Status        string `json:"status"`
+	}
+	if err := g.do(ctx, http.MethodPost, "/v1/refunds", form, &out); err != nil {
+		return nil, err
+	}
+	return &Refund{ID: out.ID, PaymentIntentID: out.PaymentIntent, Amount: out.Amount, Status: out.Status}, nil
+}
+
+func (g *StripeGateway) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if err := verifyStripeSignature(payload, signature, g.webhookSecret, time.Now()); err != nil {
+		return nil, err
+	}
+	return decodeStripeEvent(payload)
+}
+
+func (g *StripeGateway) DecodeEvent(payload []byte) (*Event, error) {
+	return decodeStripeEvent(payload)
+}
+
+func (g *StripeGateway) do(ctx context.Context, method, path string, form url.Values, out interface{}) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.apiBase+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build stripe request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+g.secretKey)
+	req.Header.Set("Stripe-Version", stripeAPIVersion)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		g.log.Error("Stripe request failed",
+			logger.ErrorField(err),
+			logger.String("method", method),
+			logger.String("path", path))
+		return fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read stripe response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var envelope struct {
+			Error struct {
+				Type        string `json:"type"`
+				Code        string `json:"code"`
+				DeclineCode string `json:"decline_code"`
+				Message     string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(data, &envelope)
+		perr := &Error{
+			StatusCode:  resp.StatusCode,
+			Type:        envelope.Error.Type,
+			Code:        envelope.Error.Code,
+			DeclineCode: envelope.Error.DeclineCode,
+			Message:     envelope.Error.Message,
+		}
+		if perr.Message == "" {
+			perr.Message = http.StatusText(resp.StatusCode)
+		}
+		g.log.Warn("Stripe returned an error",
+			logger.String("path", path),
+			logger.Int("status", resp.StatusCode),
+			logger.String("type", perr.Type),
+			logger.String("code", perr.Code))
+		return perr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+	return nil
+}
+
+func setMetadata(form url.Values, metadata map[string]string) {
+	for k, v := range metadata {
+		form.Set("metadata["+k+"]", v)
+	}
+}
+
+type stripeCustomer struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type stripePaymentMethod struct {
+	ID   string `json:"id"`
+	Card struct {
+		Brand       string `json:"brand"`
+		Last4       string `json:"last4"`
+		Fingerprint string `json:"fingerprint"`
+	} `json:"card"`
+}
+
+type stripeSubscription struct {
+	ID                 string          `json:"id"`
+	Customer           string          `json:"customer"`
+	Status             string          `json:"status"`
+	CurrentPeriodStart int64           `json:"current_period_start"`
+	CurrentPeriodEnd   int64           `json:"current_period_end"`
+	CancelAtPeriodEnd  bool            `json:"cancel_at_period_end"`
+	LatestInvoice      json.RawMessage `json:"latest_invoice"`
+	Items              struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Price struct {
+				ID         string `json:"id"`
+				UnitAmount int    `json:"unit_amount"`
+				Recurring  struct {
+					Interval string `json:"interval"`
+				} `json:"recurring"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+func (s *stripeSubscription) toSubscription() (*Subscription, error) {
+	sub := &Subscription{
+		ID:                 s.ID,
+		CustomerID:         s.Customer,
+		Status:             SubscriptionStatus(s.Status),
+		CurrentPeriodStart: time.Unix(s.CurrentPeriodStart, 0),
+		CurrentPeriodEnd:   time.Unix(s.CurrentPeriodEnd, 0),
+		CancelAtPeriodEnd:  s.CancelAtPeriodEnd,
+	}
+	if len(s.Items.Data) > 0 {
+		sub.ItemID = s.Items.Data[0].ID
+		sub.PriceID = s.Items.Data[0].Price.ID
+		sub.Amount = s.Items.Data[0].Price.UnitAmount
+		sub.Interval = s.Items.Data[0].Price.Recurring.Interval
+	}
+
+	// latest_invoice is an ID unless it was expanded
+	raw := bytes.TrimSpace(s.LatestInvoice)
+	if len(raw) > 0 && raw[0] == '{' {
+		var inv stripeInvoice
+		if err := json.Unmarshal(raw, &inv); err != nil {
+			return nil, fmt.Errorf("failed to decode stripe invoice: %w", err)
+		}
+		sub.LatestInvoice = inv.toInvoice()
+	}
+	return sub, nil
+}
+
+type stripeInvoice struct {
+	ID                string `json:"id"`
+	Customer          string `json:"customer"`
+	Subscription      string `json:"subscription"`
+	PaymentIntent     string `json:"payment_intent"`
+	Status            string `json:"status"`
+	AmountDue         int    `json:"amount_due"`
+	AmountPaid        int    `json:"amount_paid"`
+	Currency          string `json:"currency"`
+	HostedInvoiceURL  string `json:"hosted_invoice_url"`
+	BillingReason     string `json:"billing_reason"`
+	StatusTransitions struct {
+		PaidAt int64 `json:"paid_at"`
+	} `json:"status_transitions"`
+	Lines struct {
+		Data []struct {
+			Period struct {
+				Start int64 `json:"start"`
+				End   int64 `json:"end"`
+			} `json:"period"`
+		} `json:"data"`
+	} `json:"lines"`
+}
+
+func (i *stripeInvoice) toInvoice() *Invoice {
+	inv := &Invoice{
+		ID:              i.ID,
+		CustomerID:      i.Customer,
+		SubscriptionID:  i.Subscription,
+		PaymentIntentID: i.PaymentIntent,
+		Status:          i.Status,
+		AmountDue:       i.AmountDue,
+		AmountPaid:      i.AmountPaid,
+		Currency:        i.Currency,
+		HostedURL:       i.HostedInvoiceURL,
+		BillingReason:   i.BillingReason,
+	}
+	// The line items carry the service period the invoice pays for
+	if len(i.Lines.Data) > 0 {
+		inv.PeriodStart = time.Unix(i.Lines.Data[0].Period.Start, 0)
+		inv.PeriodEnd = time.Unix(i.Lines.Data[0].Period.End, 0)
+	}
+	if i.StatusTransitions.PaidAt > 0 {
+		paidAt := time.Unix(i.StatusTransitions.PaidAt, 0)
+		inv.PaidAt = &paidAt
+	}
+	return inv
+}
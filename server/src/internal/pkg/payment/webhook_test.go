@@ -0,0 +1,201 @@
+package payment
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// Fixture payloads in Stripe's event format, trimmed to the fields the
+// gateway reads.
+const (
+	invoicePaidFixture = `{
+  "id": "evt_invoice_paid",
+  "type": "invoice.paid",
+  "created": 1760000000,
+  "data": {"object": {
+    "id": "in_1",
+    "customer": "cus_1",
+    "subscription": "sub_1",
+    "payment_intent": "pi_1",
+    "status": "paid",
+    "amount_due": 1500,
+    "amount_paid": 1500,
+    "currency": "usd",
+    "billing_reason": "subscription_cycle",
+    "status_transitions": {"paid_at": 1760000000},
+    "lines": {"data": [{"period": {"start": 1760000000, "end": 1762592000}}]}
+  }}
+}`
+
+	subscriptionUpdatedFixture = `{
+  "id": "evt_sub_updated",
+  "type": "customer.subscription.updated",
+  "created": 1760000100,
+  "data": {"object": {
+    "id": "sub_1",
+    "customer": "cus_1",
+    "status": "past_due",
+    "current_period_start": 1760000000,
+    "current_period_end": 1762592000,
+    "cancel_at_period_end": true,
+    "items": {"data": [{"id": "si_1", "price": {"id": "price_1", "unit_amount": 1500, "recurring": {"interval": "month"}}}]}
+  }}
+}`
+
+	chargeRefundedFixture = `{
+  "id": "evt_refund",
+  "type": "charge.refunded",
+  "created": 1760000200,
+  "data": {"object": {"id": "ch_1", "payment_intent": "pi_1", "invoice": "in_1", "amount": 1500, "amount_refunded": 500}}
+}`
+
+	disputeClosedFixture = `{
+  "id": "evt_dispute",
+  "type": "charge.dispute.closed",
+  "created": 1760000300,
+  "data": {"object": {"id": "dp_1", "charge": "ch_1", "payment_intent": "pi_1", "amount": 1500, "status": "won"}}
+}`
+)
+
+func newTestGateway(now time.Time) *FakeGateway {
+	g := NewFakeGateway(testWebhookSecret)
+	g.Now = func() time.Time { return now }
+	return g
+}
+
+func TestParseWebhookAcceptsSignedPayload(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	payload := []byte(invoicePaidFixture)
+
+	event, err := newTestGateway(now).ParseWebhook(payload, SignWebhookPayload(payload, testWebhookSecret, now))
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.ID != "evt_invoice_paid" || event.Type != EventInvoicePaid {
+		t.Fatalf("got event %s %s", event.ID, event.Type)
+	}
+	if !event.Created.Equal(now) {
+		t.Errorf("Created = %v, want %v", event.Created, now)
+	}
+}
+
+func TestParseWebhookRejectsBadSignatures(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	payload := []byte(invoicePaidFixture)
+	valid := SignWebhookPayload(payload, testWebhookSecret, now)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+	}{
+		{"missing header", payload, ""},
+		{"wrong secret", payload, SignWebhookPayload(payload, "whsec_other", now)},
+		{"tampered payload", []byte(strings.Replace(invoicePaidFixture, "1500", "1", 1)), valid},
+		{"too old", payload, SignWebhookPayload(payload, testWebhookSecret, now.Add(-WebhookTolerance-time.Second))},
+		{"too far ahead", payload, SignWebhookPayload(payload, testWebhookSecret, now.Add(WebhookTolerance+time.Second))},
+		{"no v1 signature", payload, strings.Split(valid, ",")[0]},
+		{"bad timestamp", payload, "t=abc," + strings.Split(valid, ",")[1]},
+	}
+
+	g := newTestGateway(now)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := g.ParseWebhook(tt.payload, tt.signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("err = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestParseWebhookAcceptsAnyMatchingSignature(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	payload := []byte(invoicePaidFixture)
+
+	// Stripe sends one v1 signature per active secret while a secret is rolled
+	ts := strings.Split(SignWebhookPayload(payload, "whsec_old", now), ",")
+	current := strings.Split(SignWebhookPayload(payload, testWebhookSecret, now), ",")[1]
+	header := strings.Join(append(ts, current), ",")
+
+	if _, err := newTestGateway(now).ParseWebhook(payload, header); err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	g := newTestGateway(time.Now())
+
+	t.Run("invoice", func(t *testing.T) {
+		event, err := g.DecodeEvent([]byte(invoicePaidFixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		inv := event.Invoice
+		if inv == nil {
+			t.Fatal("Invoice not set")
+		}
+		if inv.ID != "in_1" || inv.SubscriptionID != "sub_1" || inv.PaymentIntentID != "pi_1" || inv.AmountPaid != 1500 || inv.BillingReason != BillingReasonCycle {
+			t.Errorf("unexpected invoice %+v", inv)
+		}
+		if !inv.PeriodEnd.Equal(time.Unix(1762592000, 0)) {
+			t.Errorf("PeriodEnd = %v", inv.PeriodEnd)
+		}
+		if inv.PaidAt == nil || !inv.PaidAt.Equal(time.Unix(1760000000, 0)) {
+			t.Errorf("PaidAt = %v", inv.PaidAt)
+		}
+	})
+
+	t.Run("subscription", func(t *testing.T) {
+		event, err := g.DecodeEvent([]byte(subscriptionUpdatedFixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sub := event.Subscription
+		if sub == nil {
+			t.Fatal("Subscription not set")
+		}
+		if sub.ID != "sub_1" || sub.Status != StatusPastDue || !sub.CancelAtPeriodEnd {
+			t.Errorf("unexpected subscription %+v", sub)
+		}
+		if sub.ItemID != "si_1" || sub.PriceID != "price_1" || sub.Amount != 1500 || sub.Interval != "month" {
+			t.Errorf("unexpected subscription item %+v", sub)
+		}
+		if !sub.CurrentPeriodEnd.Equal(time.Unix(1762592000, 0)) {
+			t.Errorf("CurrentPeriodEnd = %v", sub.CurrentPeriodEnd)
+		}
+	})
+
+	t.Run("charge", func(t *testing.T) {
+		event, err := g.DecodeEvent([]byte(chargeRefundedFixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch := event.Charge
+		if ch == nil || ch.PaymentIntentID != "pi_1" || ch.InvoiceID != "in_1" || ch.Amount != 1500 || ch.AmountRefunded != 500 {
+			t.Errorf("unexpected charge %+v", ch)
+		}
+	})
+
+	t.Run("dispute", func(t *testing.T) {
+		event, err := g.DecodeEvent([]byte(disputeClosedFixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dp := event.Dispute
+		if dp == nil || dp.PaymentIntentID != "pi_1" || dp.Status != "won" {
+			t.Errorf("unexpected dispute %+v", dp)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, payload := range []string{`not json`, `{"type": "invoice.paid"}`, `{"id": "evt_1"}`} {
+			if _, err := g.DecodeEvent([]byte(payload)); err == nil {
+				t.Errorf("DecodeEvent(%s) succeeded", payload)
+			}
+		}
+	})
+}
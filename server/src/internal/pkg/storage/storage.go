@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -19,6 +20,9 @@ import (
 type Storage interface {
 	UploadFile(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string, publicID string) (string, error)
 	UploadAvatar(ctx context.Context, userID string, file multipart.File, header *multipart.FileHeader) (string, error)
+	// SaveFile stores generated content such as export archives. It returns
+	// the file URL and the key to pass to DeleteFile.
+	SaveFile(ctx context.Context, r io.Reader, folder, filename string) (url string, key string, err error)
 	DeleteFile(ctx context.Context, publicID string) error
 }
 
@@ -95,10 +99,37 @@ func (cs *CloudinaryStorage) UploadAvatar(ctx context.Context, userID string, fi
 	return cs.UploadFile(ctx, file, header, "avatars", fmt.Sprintf("avatar_%s", userID))
 }
 
+func (cs *CloudinaryStorage) SaveFile(ctx context.Context, r io.Reader, folder, filename string) (string, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	uploadResult, err := cs.cld.Upload.Upload(ctx, bytes.NewReader(data), uploader.UploadParams{
+		Folder:       folder,
+		PublicID:     filename,
+		ResourceType: "raw",
+	})
+	if err != nil {
+		cs.logger.Warn("Failed to upload to Cloudinary, falling back to local storage",
+			logger.ErrorField(err))
+		return cs.local.SaveFile(ctx, bytes.NewReader(data), folder, filename)
+	}
+
+	return uploadResult.SecureURL, uploadResult.PublicID, nil
+}
+
 func (cs *CloudinaryStorage) DeleteFile(ctx context.Context, publicID string) error {
-	_, err := cs.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+	result, err := cs.cld.Upload.Destroy(ctx, uploader.DestroyParams{
 		PublicID: publicID,
 	})
+	if err == nil && result.Result == "not found" {
+		// Generated files are uploaded as raw resources, not images
+		_, err = cs.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+			PublicID:     publicID,
+			ResourceType: "raw",
+		})
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete file from Cloudinary: %w", err)
 	}
@@ -152,6 +183,26 @@ func (ls *LocalStorage) UploadAvatar(ctx context.Context, userID string, file mu
 	return ls.UploadFile(ctx, file, header, "avatars", fmt.Sprintf("avatar_%s", userID))
 }
 
+func (ls *LocalStorage) SaveFile(ctx context.Context, r io.Reader, folder, filename string) (string, string, error) {
+	fullPath := filepath.Join(ls.uploadDir, folder)
+	if err := os.MkdirAll(fullPath, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	dst, err := os.Create(filepath.Join(fullPath, filename))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, r); err != nil {
+		return "", "", fmt.Errorf("failed to save file: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/uploads/%s/%s", strings.TrimRight(ls.baseURL, "/"), folder, filename)
+	return url, filepath.Join(folder, filename), nil
+}
+
 func (ls *LocalStorage) DeleteFile(ctx context.Context, publicID string) error {
 	filePath := filepath.Join(ls.uploadDir, publicID)
 	if err := os.Remove(filePath); err != nil {
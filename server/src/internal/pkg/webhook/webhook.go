@@ -0,0 +1,143 @@
+// Package webhook sends signed event payloads to endpoints registered by
+// users.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Brevity-Signature"
+	EventHeader     = "X-Brevity-Event"
+	DeliveryHeader  = "X-Brevity-Delivery"
+
+	userAgent = "Brevity-Webhooks/1.0"
+	// Only the start of the response is kept for the delivery log
+	maxResponseBody = 1024
+)
+
+var ErrPrivateTarget = errors.New("webhook target resolves to a private address")
+
+// Sign builds the signature header for payload: "t=<unix>,v1=<hex hmac>",
+// where the HMAC-SHA256 is computed over "<unix>.<payload>" with the
+// endpoint's secret. Receivers should reject old timestamps to stop replays.
+func Sign(payload []byte, secret string, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Request is one delivery attempt
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID string
+	Payload    []byte
+}
+
+// Result is what the endpoint answered. StatusCode is 0 when no response
+// was received.
+type Result struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Success reports whether the endpoint accepted the delivery
+func (r *Result) Success() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a sender that gives up on an endpoint after timeout.
+// Unless allowPrivate is set, it refuses to connect to loopback, private and
+// link-local addresses, so endpoints can't be used to reach internal services.
+func NewSender(timeout time.Duration, allowPrivate bool) *Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = refusePrivate
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &Sender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// Redirects aren't followed; the endpoint should be registered
+			// with its final URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts the payload to the endpoint. An error means no response was
+// received; a response with any status is returned as a result.
+func (s *Sender) Send(ctx context.Context, req *Request) (*Result, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Payload))
+	if err != nil {
+		return &Result{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", userAgent)
+	httpReq.Header.Set(EventHeader, req.EventType)
+	httpReq.Header.Set(DeliveryHeader, req.DeliveryID)
+	httpReq.Header.Set(SignatureHeader, Sign(req.Payload, req.Secret, time.Now()))
+
+	start := time.Now()
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return &Result{Duration: time.Since(start)}, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// Drain a little more so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	return &Result{
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Duration:   time.Since(start),
+	}, nil
+}
+
+// refusePrivate runs after the host is resolved, so it also catches names
+// that point at internal addresses.
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ErrPrivateTarget
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return ErrPrivateTarget
+	}
+	return nil
+}
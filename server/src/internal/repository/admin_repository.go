@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+// adminAdjustment is the operation recorded on ledger debits made by an admin
+const adminAdjustment = "admin_adjustment"
+
+// adminRepository applies admin actions. Each change and its audit event are
+// written in one transaction, so an action is never applied without being
+// recorded.
+type adminRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewAdminRepository(db *gorm.DB, log logger.Logger) interfaces.AdminRepository {
+	return &adminRepository{db: db, log: log}
+}
+
+func (r *adminRepository) SearchUsers(ctx context.Context, filter *models.UserSearchFilter) ([]*models.User, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if filter.Query != "" {
+		like := "%" + strings.ToLower(filter.Query) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(username) LIKE ? OR LOWER(first_name || ' ' || last_name) LIKE ?", like, like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	switch filter.Status {
+	case "active":
+		query = query.Where("is_active = true")
+	case "suspended":
+		query = query.Where("suspended_at IS NOT NULL")
+	case "deleted":
+		query = query.Where("deletion_scheduled_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("failed to count users", logger.ErrorField(err))
+		return nil, nil, err
+	}
+	var users []*models.User
+	if err := query.Scopes(filter.Page.Newest("")).Find(&users).Error; err != nil {
+		r.log.Error("failed to search users", logger.ErrorField(err))
+		return nil, nil, err
+	}
+	users, page := pagination.Trim(users, filter.Page, total, userPosition)
+	return users, page, nil
+}
+
+func userPosition(u *models.User) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: u.CreatedAt, ID: u.ID}
+}
+
+// SetUserSuspended suspends or reactivates a user. A reactivated account
+// stays inactive while its deletion is pending. Nothing is written when the
+// user is already in the requested state.
+func (r *adminRepository) SetUserSuspended(ctx context.Context, userID string, suspended bool, event *models.AuditEvent) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if (user.SuspendedAt != nil) == suspended {
+			return nil
+		}
+
+		before := map[string]interface{}{"suspended_at": user.SuspendedAt, "is_active": user.IsActive}
+		if suspended {
+			now := time.Now()
+			user.SuspendedAt = &now
+			user.IsActive = false
+		} else {
+			user.SuspendedAt = nil
+			user.IsActive = user.DeletionScheduledAt == nil
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"suspended_at": user.SuspendedAt,
+			"is_active":    user.IsActive,
+		}).Error; err != nil {
+			return err
+		}
+		event.SetChanges(before, map[string]interface{}{"suspended_at": user.SuspendedAt, "is_active": user.IsActive})
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) {
+			r.log.Error("failed to change user status",
+				logger.ErrorField(err),
+				logger.String("userID", userID))
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *adminRepository) SetUserRole(ctx context.Context, userID string, role models.Role, event *models.AuditEvent) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.Role == role {
+			return nil
+		}
+
+		event.SetChanges(map[string]models.Role{"role": user.Role}, map[string]models.Role{"role": role})
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("role", role).Error; err != nil {
+			return err
+		}
+		user.Role = role
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) {
+			r.log.Error("failed to change user role",
+				logger.ErrorField(err),
+				logger.String("userID", userID))
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GrantCredits adds credit to the user's own balance.
+func (r *adminRepository) GrantCredits(ctx context.Context, credit *models.Credit, event *models.AuditEvent) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := findUser(tx, credit.UserID, &user); err != nil {
+			return err
+		}
+		if err := grantCredits(tx, credit); err != nil {
+			return err
+		}
+		return writeAuditEvent(tx, event, map[string]interface{}{
+			"credit_id":  credit.ID,
+			"type":       credit.Type,
+			"amount":     credit.Amount,
+			"expires_at": credit.ExpiresAt,
+		})
+	})
+	if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+		r.log.Error("failed to grant credits",
+			logger.ErrorField(err),
+			logger.String("userID", credit.UserID))
+	}
+	return err
+}
+
+// DeductCredits removes amount credits from the user's own balance the same
+// way spending does, but without a usage record. It fails with
+// ErrInsufficientCredits when the balance is smaller than amount.
+func (r *adminRepository) DeductCredits(ctx context.Context, userID string, amount int, event *models.AuditEvent) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+
+		var credits []string
+		err := spendCredits(tx, creditAccount{userID: userID}, amount, func(credit *models.Credit, entries []*models.CreditLedgerEntry, n int) error {
+			for _, e := range entries {
+				e.Operation = adminAdjustment
+				e.Description = event.Reason
+			}
+			credits = append(credits, credit.ID)
+			return tx.Create(&entries).Error
+		})
+		if err != nil {
+			return err
+		}
+		return writeAuditEvent(tx, event, map[string]interface{}{
+			"amount":  -amount,
+			"credits": credits,
+		})
+	})
+	if err != nil && !errors.Is(err, models.ErrUserNotFound) && err != models.ErrInsufficientCredits {
+		r.log.Error("failed to deduct credits",
+			logger.ErrorField(err),
+			logger.String("userID", userID),
+			logger.Int("amount", amount))
+	}
+	return err
+}
+
+// OverrideSubscription puts the user on plan until expiresAt. An active
+// complimentary subscription is moved to the plan; a paid one can only have
+// its period extended, which also pushes back its next charge. Without an
+// active subscription a complimentary one is created with the plan's credits.
+// Complimentary subscriptions have no provider subscription and simply expire.
+func (r *adminRepository) OverrideSubscription(ctx context.Context, userID string, plan *models.Plan, expiresAt time.Time, event *models.AuditEvent) (*models.Subscription, error) {
+	var sub models.Subscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+
+		err := tx.Where("user_id = ? AND workspace_id IS NULL AND is_active = true", userID).First(&sub).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			sub = models.Subscription{
+				UserID:    userID,
+				Plan:      plan.Code,
+				PlanID:    plan.ID,
+				Status:    models.SubscriptionStatusActive,
+				IsActive:  true,
+				StartsAt:  time.Now(),
+				ExpiresAt: expiresAt,
+			}
+			if err := tx.Create(&sub).Error; err != nil {
+				return err
+			}
+			if plan.Credits > 0 {
+				if err := grantCredits(tx, &models.Credit{
+					UserID:      userID,
+					Type:        models.CreditTypePaid,
+					Amount:      plan.Credits,
+					Description: string(plan.Code) + " subscription credits",
+				}); err != nil {
+					return err
+				}
+			}
+			event.SetChanges(nil, map[string]interface{}{"plan": plan.Code, "expires_at": expiresAt})
+			return writeAuditEvent(tx, event, map[string]interface{}{"subscription_id": sub.ID})
+		}
+		if err != nil {
+			return err
+		}
+
+		if sub.Status == models.SubscriptionStatusTrialing {
+			return models.ErrSubscriptionTrialing
+		}
+		paid := sub.StripeID != ""
+		if paid && sub.Plan != plan.Code {
+			return models.ErrPaidSubscriptionOverride
+		}
+
+		event.SetChanges(
+			map[string]interface{}{"plan": sub.Plan, "expires_at": sub.ExpiresAt},
+			map[string]interface{}{"plan": plan.Code, "expires_at": expiresAt},
+		)
+		updates := map[string]interface{}{
+			"plan":            plan.Code,
+			"plan_id":         plan.ID,
+			"expires_at":      expiresAt,
+			"pending_plan":    "",
+			"pending_plan_id": "",
+		}
+		if paid {
+			// The provider price stays with the subscription
+			delete(updates, "plan_id")
+			if sub.RenewsAt != nil {
+				updates["renews_at"] = expiresAt
+			}
+		}
+		if err := tx.Model(&sub).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&sub, "id = ?", sub.ID).Error; err != nil {
+			return err
+		}
+		return writeAuditEvent(tx, event, map[string]interface{}{"subscription_id": sub.ID})
+	})
+	if err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) && err != models.ErrPaidSubscriptionOverride && err != models.ErrSubscriptionTrialing {
+			r.log.Error("failed to override subscription",
+				logger.ErrorField(err),
+				logger.String("userID", userID))
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// TakeDownURL disables a link. Taken down links stop redirecting and their
+// owners can't re-enable them.
+func (r *adminRepository) TakeDownURL(ctx context.Context, urlID string, event *models.AuditEvent) (*models.URL, error) {
+	var url models.URL
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findURL(tx, urlID, &url); err != nil {
+			return err
+		}
+		if url.TakenDownAt != nil {
+			return models.ErrURLTakenDown
+		}
+
+		now := time.Now()
+		if err := tx.Model(&url).Updates(map[string]interface{}{
+			"taken_down_at":   now,
+			"takedown_reason": event.Reason,
+		}).Error; err != nil {
+			return err
+		}
+		url.TakenDownAt = &now
+		url.TakedownReason = event.Reason
+		event.SetChanges(nil, map[string]interface{}{"taken_down_at": now, "takedown_reason": event.Reason})
+		return writeAuditEvent(tx, event, map[string]interface{}{
+			"short_code":   url.ShortCode,
+			"original_url": url.OriginalURL,
+			"user_id":      url.UserID,
+		})
+	})
+	if err != nil {
+		if err != models.ErrURLNotFound && err != models.ErrURLTakenDown {
+			r.log.Error("failed to take down URL",
+				logger.ErrorField(err),
+				logger.String("urlID", urlID))
+		}
+		return nil, err
+	}
+	return &url, nil
+}
+
+func (r *adminRepository) RestoreURL(ctx context.Context, urlID string, event *models.AuditEvent) (*models.URL, error) {
+	var url models.URL
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findURL(tx, urlID, &url); err != nil {
+			return err
+		}
+		if url.TakenDownAt == nil {
+			return models.ErrURLNotTakenDown
+		}
+
+		event.SetChanges(map[string]interface{}{
+			"taken_down_at":   url.TakenDownAt,
+			"takedown_reason": url.TakedownReason,
+		}, nil)
+		if err := tx.Model(&url).Updates(map[string]interface{}{
+			"taken_down_at":   nil,
+			"takedown_reason": "",
+		}).Error; err != nil {
+			return err
+		}
+		url.TakenDownAt = nil
+		url.TakedownReason = ""
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		if err != models.ErrURLNotFound && err != models.ErrURLNotTakenDown {
+			r.log.Error("failed to restore URL",
+				logger.ErrorField(err),
+				logger.String("urlID", urlID))
+		}
+		return nil, err
+	}
+	return &url, nil
+}
+
+// writeAuditEvent stores event with metadata as its metadata.
+func writeAuditEvent(tx *gorm.DB, event *models.AuditEvent, metadata interface{}) error {
+	event.SetMetadata(metadata)
+	return tx.Create(event).Error
+}
+
+func findUser(tx *gorm.DB, id string, user *models.User) error {
+	if err := tx.Where("id = ?", id).First(user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func findURL(tx *gorm.DB, id string, url *models.URL) error {
+	if err := tx.Where("id = ?", id).First(url).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrURLNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewAuditRepository(db *gorm.DB, log logger.Logger) interfaces.AuditRepository {
+	return &auditRepository{db: db, log: log}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		r.log.Error("failed to create audit event",
+			logger.ErrorField(err),
+			logger.String("action", string(event.Action)))
+		return err
+	}
+	return nil
+}
+
+func (r *auditRepository) List(ctx context.Context, filter *models.AuditEventFilter) ([]*models.AuditEvent, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).Model(&models.AuditEvent{})
+	if filter.Subject != "" {
+		query = query.Where("actor_id = ? OR (target_type = ? AND target_id = ?)", filter.Subject, models.AuditTargetUser, filter.Subject)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("failed to count audit events", logger.ErrorField(err))
+		return nil, nil, err
+	}
+	var events []*models.AuditEvent
+	if err := query.Scopes(filter.Page.Newest("")).Find(&events).Error; err != nil {
+		r.log.Error("failed to list audit events", logger.ErrorField(err))
+		return nil, nil, err
+	}
+	events, page := pagination.Trim(events, filter.Page, total, auditEventPosition)
+	return events, page, nil
+}
+
+func auditEventPosition(e *models.AuditEvent) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: e.CreatedAt, ID: e.ID}
+}
+
+func (r *auditRepository) DeleteBefore(ctx context.Context, cutoff time.Time, admin bool) (int64, error) {
+	query := r.db.WithContext(ctx).Where("created_at < ?", cutoff)
+	if admin {
+		query = query.Where("action LIKE 'admin.%'")
+	} else {
+		query = query.Where("action NOT LIKE 'admin.%'")
+	}
+
+	result := query.Delete(&models.AuditEvent{})
+	if result.Error != nil {
+		r.log.Error("failed to delete audit events",
+			logger.ErrorField(result.Error),
+			logger.Any("cutoff", cutoff))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
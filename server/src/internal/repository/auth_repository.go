@@ -64,35 +64,61 @@ func (r *authRepository) FindUserByID(ctx context.Context, id string) (*models.U
 	return &user, nil
 }
 
+// SaveVerificationToken stores a signup verification token. A pending email
+// change uses the same token slot, so while it is live it is kept and
+// ErrEmailChangePending is returned instead.
 func (r *authRepository) SaveVerificationToken(ctx context.Context, email, token string, expires time.Time) error {
-	err := r.db.WithContext(ctx).Model(&models.User{}).
+	result := r.db.WithContext(ctx).Model(&models.User{}).
 		Where("email = ?", email).
+		Where("verification_purpose IS NULL OR verification_purpose <> ? OR verification_expires_at IS NULL OR verification_expires_at <= ?",
+			models.VerificationPurposeEmailChange, time.Now()).
 		Updates(map[string]interface{}{
 			"verification_token":      token,
+			"verification_purpose":    models.VerificationPurposeSignup,
 			"verification_expires_at": expires,
-		}).Error
+			"pending_email":           nil,
+		})
 
-	if err != nil {
-		r.log.Error("Failed to save verification token", logger.NamedError("error", err))
-		return err
+	if result.Error != nil {
+		r.log.Error("Failed to save verification token", logger.NamedError("error", result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrEmailChangePending
 	}
 	return nil
 }
 
-func (r *authRepository) VerifyUser(ctx context.Context, token string) error {
-	err := r.db.WithContext(ctx).Model(&models.User{}).
-		Where("verification_token = ? AND verification_expires_at > ?", token, time.Now()).
-		Updates(map[string]interface{}{
+// VerifyUser marks the owner of a signup verification token as verified and
+// returns them.
+func (r *authRepository) VerifyUser(ctx context.Context, token string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("verification_token = ? AND verification_expires_at > ?", token, time.Now()).
+			Where("verification_purpose = ? OR verification_purpose IS NULL OR verification_purpose = ''", models.VerificationPurposeSignup).
+			First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.ErrInvalidVerificationToken
+			}
+			return err
+		}
+
+		user.IsVerified = true
+		return tx.Model(&user).Updates(map[string]interface{}{
 			"is_verified":             true,
 			"verification_token":      nil,
+			"verification_purpose":    nil,
 			"verification_expires_at": nil,
 		}).Error
+	})
 
 	if err != nil {
-		r.log.Error("Failed to verify user", logger.NamedError("error", err))
-		return err
+		if !errors.Is(err, models.ErrInvalidVerificationToken) {
+			r.log.Error("Failed to verify user", logger.NamedError("error", err))
+		}
+		return nil, err
 	}
-	return nil
+	return &user, nil
 }
 
 func (r *authRepository) SaveResetToken(ctx context.Context, email, token string, expires time.Time) error {
@@ -110,24 +136,35 @@ func (r *authRepository) SaveResetToken(ctx context.Context, email, token string
 	return nil
 }
 
-func (r *authRepository) ResetPassword(ctx context.Context, token, newPassword string) error {
-	result := r.db.WithContext(ctx).Model(&models.User{}).
-		Where("reset_password_token = ? AND reset_password_expires_at > ?", token, time.Now()).
-		Updates(map[string]interface{}{
-			"password":                  newPassword,
-			"reset_password_token":      nil,
-			"reset_password_expires_at": nil,
-		})
+// ResetPassword sets a new password for the user holding token and returns
+// their ID.
+func (r *authRepository) ResetPassword(ctx context.Context, token, newPassword string) (string, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("reset_password_token = ? AND reset_password_expires_at > ?", token, time.Now()).
+			First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.ErrInvalidResetToken
+			}
+			return err
+		}
 
-	if result.Error != nil {
-		r.log.Error("Failed to reset password", logger.NamedError("error", result.Error))
-		return result.Error
-	}
+		return tx.Model(&models.User{}).
+			Where("id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"password":                  newPassword,
+				"reset_password_token":      nil,
+				"reset_password_expires_at": nil,
+			}).Error
+	})
 
-	if result.RowsAffected == 0 {
-		return models.ErrInvalidResetToken
+	if err != nil {
+		if !errors.Is(err, models.ErrInvalidResetToken) {
+			r.log.Error("Failed to reset password", logger.NamedError("error", err))
+		}
+		return "", err
 	}
-	return nil
+	return user.ID, nil
 }
 
 func (r *authRepository) UpdatePassword(ctx context.Context, userID, hashedPassword string) error {
@@ -141,3 +178,86 @@ func (r *authRepository) UpdatePassword(ctx context.Context, userID, hashedPassw
 	}
 	return nil
 }
+
+func (r *authRepository) CreateMagicLinkToken(ctx context.Context, token *models.MagicLinkToken) error {
+	err := r.db.WithContext(ctx).Create(token).Error
+	if err != nil {
+		r.log.Error("Failed to create magic link token", logger.NamedError("error", err))
+		return err
+	}
+	return nil
+}
+
+// ConsumeMagicLinkToken marks the token as used and returns its owner. The
+// conditional update guarantees a link can only be redeemed once, even under
+// concurrent requests.
+func (r *authRepository) ConsumeMagicLinkToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var token models.MagicLinkToken
+		if err := tx.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.ErrInvalidMagicLink
+			}
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.MagicLinkToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrInvalidMagicLink
+		}
+
+		if err := tx.Where("id = ?", token.UserID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.ErrUserNotFound
+			}
+			return err
+		}
+
+		// Receiving the link proves control of the mailbox.
+		if !user.IsVerified {
+			if err := tx.Model(&user).Updates(map[string]interface{}{
+				"is_verified":             true,
+				"verification_token":      nil,
+				"verification_purpose":    nil,
+				"verification_expires_at": nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&user).Update("last_login_at", now).Error
+	})
+
+	if err != nil {
+		if !errors.Is(err, models.ErrInvalidMagicLink) && !errors.Is(err, models.ErrUserNotFound) {
+			r.log.Error("Failed to consume magic link token", logger.NamedError("error", err))
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RestoreUser cancels a pending account deletion.
+func (r *authRepository) RestoreUser(ctx context.Context, userID string) error {
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+		Updates(map[string]interface{}{
+			"is_active":             gorm.Expr("suspended_at IS NULL"),
+			"deleted_at":            nil,
+			"deletion_scheduled_at": nil,
+		}).Error
+	if err != nil {
+		r.log.Error("Failed to restore user",
+			logger.NamedError("error", err),
+			logger.String("user_id", userID))
+		return err
+	}
+	return nil
+}
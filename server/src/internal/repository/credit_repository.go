@@ -136,9 +136,12 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -156,7 +159,7 @@ func NewCreditRepository(db *gorm.DB, log logger.Logger) interfaces.CreditReposi
 
 func (r *creditRepository) GetUserCredits(ctx context.Context, userID string) ([]*models.Credit, error) {
 	var credits []*models.Credit
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&credits).Error
+	err := r.db.WithContext(ctx).Where("user_id = ? AND workspace_id IS NULL", userID).Find(&credits).Error
 	if err != nil {
 		r.log.Error("failed to get user credits",
 			logger.ErrorField(err),
@@ -166,61 +169,77 @@ func (r *creditRepository) GetUserCredits(ctx context.Context, userID string) ([
 	return credits, nil
 }
 
+// GetUserCreditBalance derives the balance from the ledger. Everything is read
+// in one transaction so the totals and the spendable credits agree.
 func (r *creditRepository) GetUserCreditBalance(ctx context.Context, userID string) (*models.CreditBalanceResponse, error) {
-	var totalCredits int64
-	var remainingCredits int64
-	var usedFree int64
+	return r.balance(ctx, creditAccount{userID: userID})
+}
 
-	// Get total credits
-	err := r.db.WithContext(ctx).Model(&models.Credit{}).
-		Select("COALESCE(SUM(amount), 0)").
-		Where("user_id = ?", userID).
-		Scan(&totalCredits).Error
-	if err != nil {
-		r.log.Error("failed to get total credits",
-			logger.ErrorField(err),
-			logger.String("userID", userID))
-		return nil, err
-	}
+// GetWorkspaceCreditBalance is GetUserCreditBalance for a workspace's shared
+// credits. Workspaces have no free links, so UsedFree is always zero.
+func (r *creditRepository) GetWorkspaceCreditBalance(ctx context.Context, workspaceID string) (*models.CreditBalanceResponse, error) {
+	return r.balance(ctx, creditAccount{workspaceID: workspaceID})
+}
 
-	// Get remaining credits
-	err = r.db.WithContext(ctx).Model(&models.Credit{}).
-		Select("COALESCE(SUM(remaining), 0)").
-		Where("user_id = ?", userID).
-		Scan(&remainingCredits).Error
-	if err != nil {
-		r.log.Error("failed to get remaining credits",
-			logger.ErrorField(err),
-			logger.String("userID", userID))
-		return nil, err
+func (r *creditRepository) balance(ctx context.Context, account creditAccount) (*models.CreditBalanceResponse, error) {
+	var totals struct {
+		Granted int64
+		Used    int64
+		Expired int64
 	}
+	var remaining int64
+	var usedFree int64
 
-	// Get used free credits
-	err = r.db.WithContext(ctx).Model(&models.CreditUsage{}).
-		Select("COALESCE(COUNT(*), 0)").
-		Where("user_id = ? AND operation = 'url_creation_free'", userID).
-		Scan(&usedFree).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Wallet entries are positive for grants and negative for debits and expiries
+		if err := tx.Model(&models.CreditLedgerEntry{}).
+			Select(`COALESCE(SUM(CASE WHEN kind = ? THEN amount END), 0) AS granted,
+				COALESCE(-SUM(CASE WHEN kind = ? THEN amount END), 0) AS used,
+				COALESCE(-SUM(CASE WHEN kind = ? THEN amount END), 0) AS expired`,
+				models.LedgerGrant, models.LedgerDebit, models.LedgerExpiry).
+			Scopes(account.scope).
+			Where("account = ?", models.LedgerWallet).
+			Scan(&totals).Error; err != nil {
+			return err
+		}
+
+		// Credits past their expiry don't count even before the expiry job has run
+		if err := spendableCredits(tx, account, time.Now()).
+			Select("COALESCE(SUM(remaining), 0)").
+			Scan(&remaining).Error; err != nil {
+			return err
+		}
+
+		if account.workspaceID != "" {
+			return nil
+		}
+		return tx.Model(&models.CreditUsage{}).
+			Where("user_id = ? AND operation = 'url_creation_free'", account.userID).
+			Count(&usedFree).Error
+	})
 	if err != nil {
-		r.log.Error("failed to get used free credits",
+		r.log.Error("failed to get credit balance",
 			logger.ErrorField(err),
-			logger.String("userID", userID))
+			logger.String("userID", account.userID),
+			logger.String("workspaceID", account.workspaceID))
 		return nil, err
 	}
 
-	// Calculate used credits
-	usedCredits := totalCredits - remainingCredits
-
 	return &models.CreditBalanceResponse{
-		TotalCredits: int(totalCredits),
-		UsedCredits:  int(usedCredits),
-		Remaining:    int(remainingCredits),
-		UsedFree:     int(usedFree),
-		CanCreate:    remainingCredits > 0,
+		TotalCredits:   int(totals.Granted),
+		UsedCredits:    int(totals.Used),
+		ExpiredCredits: int(totals.Expired),
+		Remaining:      int(remaining),
+		UsedFree:       int(usedFree),
+		CanCreate:      remaining > 0,
 	}, nil
 }
 
+// AddCredits stores credit and records its grant in the ledger.
 func (r *creditRepository) AddCredits(ctx context.Context, credit *models.Credit) error {
-	err := r.db.WithContext(ctx).Create(credit).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return grantCredits(tx, credit)
+	})
 	if err != nil {
 		r.log.Error("failed to add credits",
 			logger.ErrorField(err),
@@ -230,53 +249,143 @@ func (r *creditRepository) AddCredits(ctx context.Context, credit *models.Credit
 	return nil
 }
 
-func (r *creditRepository) UseCredits(ctx context.Context, usage *models.CreditUsage) error {
-	tx := r.db.WithContext(ctx).Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
+// UseCredits spends amount credits, drawing from the soonest-expiring credits
+// first and from credits that never expire last. Each credit drawn from gets
+// its own debit in the ledger and its own usage record. The user's credits are
+// locked before they are read, so concurrent debits can't spend the same
+// balance twice.
+func (r *creditRepository) UseCredits(ctx context.Context, userID string, amount int, operation, urlID string) error {
+	return r.useCredits(ctx, creditAccount{userID: userID}, userID, amount, operation, urlID)
+}
 
-	// First, check if credit exists and has enough remaining
-	var credit models.Credit
-	if err := tx.Where("id = ? AND user_id = ?", usage.CreditID, usage.UserID).First(&credit).Error; err != nil {
-		tx.Rollback()
-		return err
+// UseWorkspaceCredits is UseCredits for a workspace's shared credits. The
+// usage is recorded against userID, the member who spent them.
+func (r *creditRepository) UseWorkspaceCredits(ctx context.Context, workspaceID, userID string, amount int, operation, urlID string) error {
+	return r.useCredits(ctx, creditAccount{workspaceID: workspaceID}, userID, amount, operation, urlID)
+}
+
+func (r *creditRepository) useCredits(ctx context.Context, account creditAccount, userID string, amount int, operation, urlID string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return spendCredits(tx, account, amount, recordUsage(tx, userID, operation, urlID))
+	})
+	if err != nil && err != models.ErrInsufficientCredits {
+		r.log.Error("failed to use credits",
+			logger.ErrorField(err),
+			logger.String("userID", userID),
+			logger.String("workspaceID", account.workspaceID),
+			logger.Int("amount", amount))
 	}
+	return err
+}
 
-	if credit.Remaining < usage.Amount {
-		tx.Rollback()
-		return models.ErrInsufficientCredits
+// ExpireCredits zeroes every credit that expired at or before now with some
+// left over, and records what lapsed in the ledger. It returns how many
+// credits were expired.
+func (r *creditRepository) ExpireCredits(ctx context.Context, now time.Time) (int, error) {
+	var credits []*models.Credit
+	if err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ? AND remaining > 0", now).
+		Find(&credits).Error; err != nil {
+		r.log.Error("failed to find expired credits", logger.ErrorField(err))
+		return 0, err
 	}
 
-	// Update credit remaining
-	if err := tx.Model(&models.Credit{}).
-		Where("id = ?", usage.CreditID).
-		Update("remaining", gorm.Expr("remaining - ?", usage.Amount)).Error; err != nil {
-		tx.Rollback()
-		return err
+	expired := 0
+	for _, credit := range credits {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// A debit that got in first leaves the credit for the next run
+			result := tx.Model(&models.Credit{}).
+				Where("id = ? AND remaining = ?", credit.ID, credit.Remaining).
+				Update("remaining", 0)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			entries, err := models.NewLedgerTransaction(credit, models.LedgerExpiry, models.LedgerWallet, models.LedgerExpired, credit.Remaining)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&entries).Error; err != nil {
+				return err
+			}
+
+			expired++
+			return nil
+		})
+		if err != nil {
+			r.log.Error("failed to expire credit",
+				logger.ErrorField(err),
+				logger.String("creditID", credit.ID))
+			return expired, err
+		}
 	}
+	return expired, nil
+}
 
-	// Record the usage
-	if err := tx.Create(usage).Error; err != nil {
-		tx.Rollback()
-		return err
+// GetLedger returns a page of the wallet side of a user's ledger, oldest
+// first.
+func (r *creditRepository) GetLedger(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error) {
+	return r.ledger(ctx, creditAccount{userID: userID}, p)
+}
+
+// GetWorkspaceLedger returns a page of the wallet side of a workspace's
+// ledger, oldest first.
+func (r *creditRepository) GetWorkspaceLedger(ctx context.Context, workspaceID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error) {
+	return r.ledger(ctx, creditAccount{workspaceID: workspaceID}, p)
+}
+
+func (r *creditRepository) ledger(ctx context.Context, account creditAccount, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.CreditLedgerEntry{}).
+		Scopes(account.scope).
+		Where("account = ?", models.LedgerWallet)
+
+	var total int64
+	var entries []*models.CreditLedgerEntry
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Scopes(p.Oldest("")).Find(&entries).Error
 	}
+	if err != nil {
+		r.log.Error("failed to get credit ledger",
+			logger.ErrorField(err),
+			logger.String("userID", account.userID),
+			logger.String("workspaceID", account.workspaceID))
+		return nil, nil, err
+	}
+	entries, page := pagination.Trim(entries, p, total, ledgerEntryPosition)
+	return entries, page, nil
+}
 
-	return tx.Commit().Error
+func ledgerEntryPosition(e *models.CreditLedgerEntry) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: e.CreatedAt, ID: e.ID}
 }
 
-func (r *creditRepository) GetCreditUsage(ctx context.Context, userID string) ([]*models.CreditUsage, error) {
+// GetCreditUsage returns a page of the user's personal credit spending,
+// newest first.
+func (r *creditRepository) GetCreditUsage(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditUsage, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.CreditUsage{}).
+		Where("user_id = ? AND workspace_id IS NULL", userID)
+
+	var total int64
 	var usages []*models.CreditUsage
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&usages).Error
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Scopes(p.Newest("")).Find(&usages).Error
+	}
 	if err != nil {
 		r.log.Error("failed to get credit usage",
 			logger.ErrorField(err),
 			logger.String("userID", userID))
-		return nil, err
+		return nil, nil, err
 	}
-	return usages, nil
+	usages, page := pagination.Trim(usages, p, total, creditUsagePosition)
+	return usages, page, nil
+}
+
+func creditUsagePosition(u *models.CreditUsage) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: u.CreatedAt, ID: u.ID}
 }
 
 func (r *creditRepository) RecordFreeURLCreation(ctx context.Context, userID, urlID string) error {
@@ -298,4 +407,163 @@ func (r *creditRepository) GetFreeURLCount(ctx context.Context, userID string) (
 		return 0, err
 	}
 	return int(count), nil
-}
\ No newline at end of file
+}
+
+// MarkCreditsLow records that the user was told their balance is low. It
+// reports false if they already were.
+func (r *creditRepository) MarkCreditsLow(ctx context.Context, userID string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND credits_low_at IS NULL", userID).
+		UpdateColumn("credits_low_at", at)
+	if result.Error != nil {
+		r.log.Error("failed to mark credits low",
+			logger.ErrorField(result.Error),
+			logger.String("userID", userID))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *creditRepository) ClearCreditsLow(ctx context.Context, userID string) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND credits_low_at IS NOT NULL", userID).
+		UpdateColumn("credits_low_at", nil).Error
+	if err != nil {
+		r.log.Error("failed to clear credits low",
+			logger.ErrorField(err),
+			logger.String("userID", userID))
+		return err
+	}
+	return nil
+}
+
+// grantCredits creates credit with its full amount remaining and records the
+// grant in the ledger. It must run inside a transaction.
+func grantCredits(tx *gorm.DB, credit *models.Credit) error {
+	credit.Remaining = credit.Amount
+	if err := tx.Create(credit).Error; err != nil {
+		return err
+	}
+	if credit.Amount <= 0 {
+		return nil
+	}
+
+	entries, err := models.NewLedgerTransaction(credit, models.LedgerGrant, models.LedgerIssued, models.LedgerWallet, credit.Amount)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entry.Description = credit.Description
+	}
+	return tx.Create(&entries).Error
+}
+
+// spendCredits takes amount credits out of the account's wallet, drawing from
+// the soonest-expiring credits first and from credits that never expire last.
+// Each credit drawn from gets its own debit; record is called with the
+// unsaved ledger entries and must store them. It must run inside a
+// transaction, which it locks.
+func spendCredits(tx *gorm.DB, account creditAccount, amount int, record func(credit *models.Credit, entries []*models.CreditLedgerEntry, n int) error) error {
+	if err := lockCredits(tx, account); err != nil {
+		return err
+	}
+
+	var credits []*models.Credit
+	if err := spendableCredits(tx, account, time.Now()).
+		Order("expires_at IS NULL, expires_at, created_at").
+		Find(&credits).Error; err != nil {
+		return err
+	}
+
+	needed := amount
+	for _, credit := range credits {
+		if needed == 0 {
+			break
+		}
+		n := min(needed, credit.Remaining)
+
+		result := tx.Model(&models.Credit{}).
+			Where("id = ? AND remaining >= ?", credit.ID, n).
+			Update("remaining", gorm.Expr("remaining - ?", n))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrInsufficientCredits
+		}
+
+		entries, err := models.NewLedgerTransaction(credit, models.LedgerDebit, models.LedgerWallet, models.LedgerConsumed, n)
+		if err != nil {
+			return err
+		}
+		if err := record(credit, entries, n); err != nil {
+			return err
+		}
+
+		needed -= n
+	}
+
+	if needed > 0 {
+		return models.ErrInsufficientCredits
+	}
+	return nil
+}
+
+// recordUsage returns a spendCredits callback that stores the debit and a
+// usage record against urlID.
+func recordUsage(tx *gorm.DB, userID, operation, urlID string) func(*models.Credit, []*models.CreditLedgerEntry, int) error {
+	return func(credit *models.Credit, entries []*models.CreditLedgerEntry, n int) error {
+		for _, entry := range entries {
+			entry.URLID = urlID
+			entry.Operation = operation
+		}
+		if err := tx.Create(&entries).Error; err != nil {
+			return err
+		}
+
+		creditID := credit.ID
+		return tx.Create(&models.CreditUsage{
+			UserID:      userID,
+			WorkspaceID: credit.WorkspaceID,
+			CreditID:    &creditID,
+			URLID:       urlID,
+			Amount:      n,
+			Operation:   operation,
+		}).Error
+	}
+}
+
+// creditAccount is whose credits a query covers: a user's own credits, or
+// the shared credits of a workspace when workspaceID is set.
+type creditAccount struct {
+	userID      string
+	workspaceID string
+}
+
+func (a creditAccount) scope(db *gorm.DB) *gorm.DB {
+	if a.workspaceID != "" {
+		return db.Where("workspace_id = ?", a.workspaceID)
+	}
+	return db.Where("user_id = ? AND workspace_id IS NULL", a.userID)
+}
+
+// spendableCredits scopes a query to the account's credits that have
+// something left and haven't expired by now.
+func spendableCredits(tx *gorm.DB, account creditAccount, now time.Time) *gorm.DB {
+	return tx.Model(&models.Credit{}).
+		Scopes(account.scope).
+		Where("remaining > 0 AND (expires_at IS NULL OR expires_at > ?)", now)
+}
+
+// lockCredits takes the write lock on the account's credits before they are
+// read. SQLite only has a database-wide write lock, which this acquires
+// (waiting out the busy timeout) instead of failing when a read-only
+// transaction later tries to upgrade.
+func lockCredits(tx *gorm.DB, account creditAccount) error {
+	if account.workspaceID != "" {
+		return tx.Exec("UPDATE credits SET remaining = remaining WHERE workspace_id = ?", account.workspaceID).Error
+	}
+	return tx.Exec("UPDATE credits SET remaining = remaining WHERE user_id = ? AND workspace_id IS NULL", account.userID).Error
+}
@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const testUserID = "user-1"
+
+// testGrant is a credit granted before a test runs. expiresIn is relative to
+// now; nil never expires.
+type testGrant struct {
+	name      string
+	amount    int
+	expiresIn *time.Duration
+}
+
+func expiresIn(d time.Duration) *time.Duration { return &d }
+
+func newTestCreditRepository(t *testing.T) (*creditRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Credit{}, &models.CreditUsage{}, &models.CreditLedgerEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return &creditRepository{db: db, log: nopLogger{}}, db
+}
+
+// grant adds grants oldest first and returns their IDs by name
+func grant(t *testing.T, repo *creditRepository, now time.Time, grants []testGrant) map[string]string {
+	t.Helper()
+	ids := make(map[string]string, len(grants))
+	for i, g := range grants {
+		credit := &models.Credit{
+			UserID:      testUserID,
+			Type:        models.CreditType("bonus"),
+			Amount:      g.amount,
+			Description: g.name,
+			// Space the grants out so ties on expiry fall back to grant order
+			CreatedAt: now.Add(time.Duration(i-len(grants)) * time.Minute),
+		}
+		if g.expiresIn != nil {
+			at := now.Add(*g.expiresIn)
+			credit.ExpiresAt = &at
+		}
+		if err := repo.AddCredits(context.Background(), credit); err != nil {
+			t.Fatalf("grant %s: %v", g.name, err)
+		}
+		ids[g.name] = credit.ID
+	}
+	return ids
+}
+
+// remaining returns what is left of each grant by name
+func remaining(t *testing.T, db *gorm.DB, ids map[string]string) map[string]int {
+	t.Helper()
+	got := make(map[string]int, len(ids))
+	for name, id := range ids {
+		var credit models.Credit
+		if err := db.First(&credit, "id = ?", id).Error; err != nil {
+			t.Fatalf("load %s: %v", name, err)
+		}
+		got[name] = credit.Remaining
+	}
+	return got
+}
+
+// walletTotals sums the wallet side of each credit's ledger entries of kind
+// by grant name. Only grants with entries of that kind appear.
+func walletTotals(t *testing.T, db *gorm.DB, ids map[string]string, kind models.LedgerEntryKind) map[string]int {
+	t.Helper()
+	names := make(map[string]string, len(ids))
+	for name, id := range ids {
+		names[id] = name
+	}
+
+	var entries []models.CreditLedgerEntry
+	if err := db.Where("kind = ? AND account = ?", kind, models.LedgerWallet).Find(&entries).Error; err != nil {
+		t.Fatalf("load ledger: %v", err)
+	}
+	got := map[string]int{}
+	for _, e := range entries {
+		if e.CreditID == nil {
+			t.Fatalf("%s entry %s has no credit", kind, e.ID)
+		}
+		got[names[*e.CreditID]] += e.Amount
+	}
+	return got
+}
+
+// assertBalanced checks that every ledger transaction sums to zero
+func assertBalanced(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	var unbalanced []string
+	if err := db.Model(&models.CreditLedgerEntry{}).
+		Group("transaction_id").
+		Having("SUM(amount) != 0").
+		Pluck("transaction_id", &unbalanced).Error; err != nil {
+		t.Fatalf("check ledger: %v", err)
+	}
+	if len(unbalanced) > 0 {
+		t.Errorf("unbalanced ledger transactions: %v", unbalanced)
+	}
+}
+
+func assertCounts(t *testing.T, what string, got, want map[string]int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+		return
+	}
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("%s = %v, want %v", what, got, want)
+			return
+		}
+	}
+}
+
+func TestUseCreditsSpendsSoonestExpiringFirst(t *testing.T) {
+	tests := []struct {
+		name          string
+		grants        []testGrant
+		spend         int
+		wantErr       error
+		wantRemaining map[string]int
+		wantDebits    map[string]int // wallet side, so negative
+	}{
+		{
+			name: "soonest expiring before later and never expiring",
+			grants: []testGrant{
+				{"never", 10, nil},
+				{"week", 10, expiresIn(7 * 24 * time.Hour)},
+				{"day", 10, expiresIn(24 * time.Hour)},
+			},
+			spend:         4,
+			wantRemaining: map[string]int{"never": 10, "week": 10, "day": 6},
+			wantDebits:    map[string]int{"day": -4},
+		},
+		{
+			name: "spills over into the next grant to expire",
+			grants: []testGrant{
+				{"never", 10, nil},
+				{"week", 10, expiresIn(7 * 24 * time.Hour)},
+				{"day", 10, expiresIn(24 * time.Hour)},
+			},
+			spend:         15,
+			wantRemaining: map[string]int{"never": 10, "week": 5, "day": 0},
+			wantDebits:    map[string]int{"day": -10, "week": -5},
+		},
+		{
+			name: "never expiring grants are spent last",
+			grants: []testGrant{
+				{"never", 10, nil},
+				{"day", 10, expiresIn(24 * time.Hour)},
+			},
+			spend:         13,
+			wantRemaining: map[string]int{"never": 7, "day": 0},
+			wantDebits:    map[string]int{"day": -10, "never": -3},
+		},
+		{
+			name: "same expiry is spent oldest grant first",
+			grants: []testGrant{
+				{"older", 5, expiresIn(24 * time.Hour)},
+				{"newer", 5, expiresIn(24 * time.Hour)},
+			},
+			spend:         6,
+			wantRemaining: map[string]int{"older": 0, "newer": 4},
+			wantDebits:    map[string]int{"older": -5, "newer": -1},
+		},
+		{
+			name: "expired grants are skipped before the expiry job runs",
+			grants: []testGrant{
+				{"lapsed", 10, expiresIn(-time.Hour)},
+				{"never", 10, nil},
+			},
+			spend:         3,
+			wantRemaining: map[string]int{"lapsed": 10, "never": 7},
+			wantDebits:    map[string]int{"never": -3},
+		},
+		{
+			name: "not enough spends nothing",
+			grants: []testGrant{
+				{"lapsed", 10, expiresIn(-time.Hour)},
+				{"day", 2, expiresIn(24 * time.Hour)},
+				{"never", 2, nil},
+			},
+			spend:         5,
+			wantErr:       models.ErrInsufficientCredits,
+			wantRemaining: map[string]int{"lapsed": 10, "day": 2, "never": 2},
+			wantDebits:    map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, db := newTestCreditRepository(t)
+			ids := grant(t, repo, time.Now(), tt.grants)
+
+			err := repo.UseCredits(context.Background(), testUserID, tt.spend, "url_creation", "url-1")
+			if err != tt.wantErr {
+				t.Fatalf("UseCredits error = %v, want %v", err, tt.wantErr)
+			}
+
+			assertCounts(t, "remaining", remaining(t, db, ids), tt.wantRemaining)
+			assertCounts(t, "debits", walletTotals(t, db, ids, models.LedgerDebit), tt.wantDebits)
+			assertBalanced(t, db)
+
+			// One usage record per grant drawn from
+			var usages int64
+			if err := db.Model(&models.CreditUsage{}).Count(&usages).Error; err != nil {
+				t.Fatal(err)
+			}
+			if int(usages) != len(tt.wantDebits) {
+				t.Errorf("usage records = %d, want %d", usages, len(tt.wantDebits))
+			}
+		})
+	}
+}
+
+func TestExpireCredits(t *testing.T) {
+	tests := []struct {
+		name          string
+		grants        []testGrant
+		spend         int
+		after         time.Duration // how long after the grants the expiry job runs
+		wantExpired   int
+		wantRemaining map[string]int
+		wantExpiries  map[string]int // wallet side, so negative
+		wantBalance   models.CreditBalanceResponse
+	}{
+		{
+			name: "partly spent grant expires what is left",
+			grants: []testGrant{
+				{"day", 10, expiresIn(24 * time.Hour)},
+				{"never", 5, nil},
+			},
+			spend:         4,
+			after:         48 * time.Hour,
+			wantExpired:   1,
+			wantRemaining: map[string]int{"day": 0, "never": 5},
+			wantExpiries:  map[string]int{"day": -6},
+			wantBalance:   models.CreditBalanceResponse{TotalCredits: 15, UsedCredits: 4, ExpiredCredits: 6, Remaining: 5, CanCreate: true},
+		},
+		{
+			name: "fully spent grant has nothing to expire",
+			grants: []testGrant{
+				{"day", 3, expiresIn(24 * time.Hour)},
+				{"never", 5, nil},
+			},
+			spend:         4,
+			after:         48 * time.Hour,
+			wantExpired:   0,
+			wantRemaining: map[string]int{"day": 0, "never": 4},
+			wantExpiries:  map[string]int{},
+			wantBalance:   models.CreditBalanceResponse{TotalCredits: 8, UsedCredits: 4, Remaining: 4, CanCreate: true},
+		},
+		{
+			name: "only grants past their expiry lapse",
+			grants: []testGrant{
+				{"day", 10, expiresIn(24 * time.Hour)},
+				{"week", 10, expiresIn(7 * 24 * time.Hour)},
+			},
+			spend:         12,
+			after:         48 * time.Hour,
+			wantExpired:   0,
+			wantRemaining: map[string]int{"day": 0, "week": 8},
+			wantExpiries:  map[string]int{},
+			wantBalance:   models.CreditBalanceResponse{TotalCredits: 20, UsedCredits: 12, Remaining: 8, CanCreate: true},
+		},
+		{
+			name: "every lapsed grant expires in one run",
+			grants: []testGrant{
+				{"day", 10, expiresIn(24 * time.Hour)},
+				{"week", 10, expiresIn(7 * 24 * time.Hour)},
+			},
+			spend:         12,
+			after:         8 * 24 * time.Hour,
+			wantExpired:   1,
+			wantRemaining: map[string]int{"day": 0, "week": 0},
+			wantExpiries:  map[string]int{"week": -8},
+			wantBalance:   models.CreditBalanceResponse{TotalCredits: 20, UsedCredits: 12, ExpiredCredits: 8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, db := newTestCreditRepository(t)
+			ctx := context.Background()
+			now := time.Now()
+			ids := grant(t, repo, now, tt.grants)
+
+			if err := repo.UseCredits(ctx, testUserID, tt.spend, "url_creation", "url-1"); err != nil {
+				t.Fatalf("UseCredits: %v", err)
+			}
+
+			expired, err := repo.ExpireCredits(ctx, now.Add(tt.after))
+			if err != nil {
+				t.Fatalf("ExpireCredits: %v", err)
+			}
+			if expired != tt.wantExpired {
+				t.Errorf("expired = %d, want %d", expired, tt.wantExpired)
+			}
+
+			assertCounts(t, "remaining", remaining(t, db, ids), tt.wantRemaining)
+			assertCounts(t, "expiries", walletTotals(t, db, ids, models.LedgerExpiry), tt.wantExpiries)
+			assertBalanced(t, db)
+
+			balance, err := repo.GetUserCreditBalance(ctx, testUserID)
+			if err != nil {
+				t.Fatalf("GetUserCreditBalance: %v", err)
+			}
+			if *balance != tt.wantBalance {
+				t.Errorf("balance = %+v, want %+v", *balance, tt.wantBalance)
+			}
+
+			// A second run finds nothing left to expire
+			again, err := repo.ExpireCredits(ctx, now.Add(tt.after))
+			if err != nil {
+				t.Fatalf("ExpireCredits again: %v", err)
+			}
+			if again != 0 {
+				t.Errorf("second run expired %d, want 0", again)
+			}
+		})
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...logger.Field)        {}
+func (nopLogger) Info(string, ...logger.Field)         {}
+func (nopLogger) Warn(string, ...logger.Field)         {}
+func (nopLogger) Error(string, ...logger.Field)        {}
+func (nopLogger) Fatal(string, ...logger.Field)        {}
+func (nopLogger) Debugf(string, ...interface{})        {}
+func (nopLogger) Infof(string, ...interface{})         {}
+func (nopLogger) Warnf(string, ...interface{})         {}
+func (nopLogger) Errorf(string, ...interface{})        {}
+func (nopLogger) Fatalf(string, ...interface{})        {}
+func (nopLogger) Sync() error                          { return nil }
+func (l nopLogger) With(...logger.Field) logger.Logger { return l }
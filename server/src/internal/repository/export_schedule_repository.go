@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type exportScheduleRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewExportScheduleRepository(db *gorm.DB, log logger.Logger) interfaces.ExportScheduleRepository {
+	return &exportScheduleRepository{db: db, log: log}
+}
+
+func (r *exportScheduleRepository) CreateSchedule(ctx context.Context, schedule *models.ExportSchedule) error {
+	if err := r.db.WithContext(ctx).Create(schedule).Error; err != nil {
+		r.log.Error("failed to create export schedule",
+			logger.ErrorField(err),
+			logger.String("userID", schedule.UserID))
+		return err
+	}
+	return nil
+}
+
+func (r *exportScheduleRepository) GetSchedule(ctx context.Context, id, userID string) (*models.ExportSchedule, error) {
+	var schedule models.ExportSchedule
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&schedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrExportScheduleNotFound
+		}
+		r.log.Error("failed to get export schedule",
+			logger.ErrorField(err),
+			logger.String("scheduleID", id))
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *exportScheduleRepository) ListSchedules(ctx context.Context, userID string) ([]*models.ExportSchedule, error) {
+	var schedules []*models.ExportSchedule
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&schedules).Error
+	if err != nil {
+		r.log.Error("failed to list export schedules",
+			logger.ErrorField(err),
+			logger.String("userID", userID))
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *exportScheduleRepository) CountSchedules(ctx context.Context, userID string) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.ExportSchedule{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *exportScheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.ExportSchedule) error {
+	if err := r.db.WithContext(ctx).Save(schedule).Error; err != nil {
+		r.log.Error("failed to update export schedule",
+			logger.ErrorField(err),
+			logger.String("scheduleID", schedule.ID))
+		return err
+	}
+	return nil
+}
+
+func (r *exportScheduleRepository) DeleteSchedule(ctx context.Context, id, userID string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.ExportSchedule{})
+	if result.Error != nil {
+		r.log.Error("failed to delete export schedule",
+			logger.ErrorField(result.Error),
+			logger.String("scheduleID", id))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrExportScheduleNotFound
+	}
+	return nil
+}
+
+const dueForExport = "next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)"
+
+func (r *exportScheduleRepository) GetDueScheduleIDs(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&models.ExportSchedule{}).
+		Where(dueForExport, now, now).
+		Order("next_run_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		r.log.Error("failed to get due export schedules", logger.ErrorField(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ClaimSchedule takes a lease on a due schedule until the given time, so
+// only one instance runs it.
+func (r *exportScheduleRepository) ClaimSchedule(ctx context.Context, id string, now, until time.Time) (*models.ExportSchedule, bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ExportSchedule{}).
+		Where("id = ? AND "+dueForExport, id, now, now).
+		UpdateColumn("locked_until", until)
+	if result.Error != nil {
+		r.log.Error("failed to claim export schedule",
+			logger.ErrorField(result.Error),
+			logger.String("scheduleID", id))
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, false, nil
+	}
+
+	var schedule models.ExportSchedule
+	if err := r.db.WithContext(ctx).First(&schedule, "id = ?", id).Error; err != nil {
+		return nil, false, err
+	}
+	return &schedule, true, nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// folderURLCount selects each folder's live links as url_count
+const folderURLCount = "folders.*, (SELECT COUNT(*) FROM urls WHERE urls.folder_id = folders.id AND urls.deleted_at IS NULL) AS url_count"
+
+type folderRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewFolderRepository(db *gorm.DB, log logger.Logger) interfaces.FolderRepository {
+	return &folderRepository{db: db, log: log}
+}
+
+// CreateFolder stores the folder unless its scope already has one with the
+// same name, ignoring case
+func (r *folderRepository) CreateFolder(ctx context.Context, folder *models.Folder) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkFolderName(tx, folder); err != nil {
+			return err
+		}
+		if err := tx.Create(folder).Error; err != nil {
+			r.log.Error("failed to create folder",
+				logger.ErrorField(err),
+				logger.String("userID", folder.UserID))
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *folderRepository) GetFolder(ctx context.Context, id string) (*models.Folder, error) {
+	var folder models.Folder
+	err := r.db.WithContext(ctx).Select(folderURLCount).Where("id = ?", id).First(&folder).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrFolderNotFound
+		}
+		r.log.Error("failed to get folder",
+			logger.ErrorField(err),
+			logger.String("folderID", id))
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (r *folderRepository) ListFolders(ctx context.Context, userID, workspaceID string) ([]*models.Folder, error) {
+	var folders []*models.Folder
+	err := r.db.WithContext(ctx).
+		Select(folderURLCount).
+		Scopes(ownerScope(userID, workspaceID)).
+		Order("name COLLATE NOCASE").
+		Find(&folders).Error
+	if err != nil {
+		r.log.Error("failed to list folders",
+			logger.ErrorField(err),
+			logger.String("userID", userID),
+			logger.String("workspaceID", workspaceID))
+		return nil, err
+	}
+	return folders, nil
+}
+
+func (r *folderRepository) UpdateFolder(ctx context.Context, folder *models.Folder) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkFolderName(tx, folder); err != nil {
+			return err
+		}
+		if err := tx.Model(folder).Update("name", folder.Name).Error; err != nil {
+			r.log.Error("failed to update folder",
+				logger.ErrorField(err),
+				logger.String("folderID", folder.ID))
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteFolder deletes the folder. Its links are kept, outside any folder.
+func (r *folderRepository) DeleteFolder(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.URL{}).Where("folder_id = ?", id).Update("folder_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Folder{})
+		if result.Error != nil {
+			r.log.Error("failed to delete folder",
+				logger.ErrorField(result.Error),
+				logger.String("folderID", id))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrFolderNotFound
+		}
+		return nil
+	})
+}
+
+func (r *folderRepository) SetURLFolder(ctx context.Context, urlID string, folderID *string) error {
+	result := r.db.WithContext(ctx).Model(&models.URL{}).Where("id = ?", urlID).Update("folder_id", folderID)
+	if result.Error != nil {
+		r.log.Error("failed to move URL",
+			logger.ErrorField(result.Error),
+			logger.String("urlID", urlID))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrURLNotFound
+	}
+	return nil
+}
+
+// checkFolderName fails with ErrFolderExists if another folder in the
+// folder's scope has its name
+func checkFolderName(tx *gorm.DB, folder *models.Folder) error {
+	workspaceID := ""
+	if folder.WorkspaceID != nil {
+		workspaceID = *folder.WorkspaceID
+	}
+
+	var count int64
+	query := tx.Model(&models.Folder{}).
+		Scopes(ownerScope(folder.UserID, workspaceID)).
+		Where("name = ? COLLATE NOCASE", folder.Name)
+	if folder.ID != "" {
+		query = query.Where("id <> ?", folder.ID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return models.ErrFolderExists
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type invoiceRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewInvoiceRepository(db *gorm.DB, log logger.Logger) interfaces.InvoiceRepository {
+	return &invoiceRepository{db: db, log: log}
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, invoice *models.Invoice, prefix string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Take the write lock before reading the sequence so two issuers
+		// can't hand out the same number
+		if err := tx.Exec("UPDATE invoices SET sequence = sequence WHERE id = ''").Error; err != nil {
+			return err
+		}
+
+		var last int
+		if err := tx.Model(&models.Invoice{}).Select("COALESCE(MAX(sequence), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		invoice.Sequence = last + 1
+		invoice.Number = models.InvoiceNumber(prefix, invoice.Sequence)
+
+		return tx.Create(invoice).Error
+	})
+	if err != nil {
+		r.log.Error("failed to create invoice",
+			logger.ErrorField(err),
+			logger.String("paymentID", invoice.PaymentID))
+		return err
+	}
+	return nil
+}
+
+func (r *invoiceRepository) SetPDF(ctx context.Context, id, url, key string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Invoice{}).
+		Where("id = ? AND (pdf_key IS NULL OR pdf_key = '')", id).
+		Updates(map[string]interface{}{"pdf_url": url, "pdf_key": key})
+	if result.Error != nil {
+		r.log.Error("failed to set invoice PDF",
+			logger.ErrorField(result.Error),
+			logger.String("invoiceID", id))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *invoiceRepository) ClaimEmail(ctx context.Context, invoice *models.Invoice) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Invoice{}).
+		Where("id = ? AND emailed_at IS NULL AND email_attempts = ?", invoice.ID, invoice.EmailAttempts).
+		Update("email_attempts", gorm.Expr("email_attempts + 1"))
+	if result.Error != nil {
+		r.log.Error("failed to claim invoice email",
+			logger.ErrorField(result.Error),
+			logger.String("invoiceID", invoice.ID))
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	invoice.EmailAttempts++
+	return true, nil
+}
+
+func (r *invoiceRepository) MarkEmailed(ctx context.Context, id string, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.Invoice{}).
+		Where("id = ?", id).
+		Update("emailed_at", at).Error
+	if err != nil {
+		r.log.Error("failed to mark invoice emailed",
+			logger.ErrorField(err),
+			logger.String("invoiceID", id))
+		return err
+	}
+	return nil
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, userID, id string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.WithContext(ctx).
+		Preload("LineItems").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&invoice).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrInvoiceNotFound
+		}
+		r.log.Error("failed to get invoice",
+			logger.ErrorField(err),
+			logger.String("invoiceID", id))
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) ListByUser(ctx context.Context, userID string, p pagination.Params) ([]*models.Invoice, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Invoice{}).
+		Where("user_id = ?", userID)
+
+	var total int64
+	var invoices []*models.Invoice
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Preload("LineItems").Scopes(p.Newest("")).Find(&invoices).Error
+	}
+	if err != nil {
+		r.log.Error("failed to list invoices",
+			logger.ErrorField(err),
+			logger.String("userID", userID))
+		return nil, nil, err
+	}
+	invoices, page := pagination.Trim(invoices, p, total, invoicePosition)
+	return invoices, page, nil
+}
+
+func invoicePosition(inv *models.Invoice) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: inv.CreatedAt, ID: inv.ID}
+}
+
+func (r *invoiceRepository) ListUninvoicedPayments(ctx context.Context, limit int) ([]*models.Payment, error) {
+	var payments []*models.Payment
+	err := r.db.WithContext(ctx).
+		Where("(paid_at IS NOT NULL OR status = ?) AND amount > 0", models.PaymentStatusPaid).
+		Where("NOT EXISTS (SELECT 1 FROM invoices WHERE invoices.payment_id = payments.id)").
+		Order("created_at").
+		Limit(limit).
+		Find(&payments).Error
+	if err != nil {
+		r.log.Error("failed to list uninvoiced payments", logger.ErrorField(err))
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *invoiceRepository) ListUndelivered(ctx context.Context, maxAttempts, limit int) ([]*models.Invoice, error) {
+	var invoices []*models.Invoice
+	err := r.db.WithContext(ctx).
+		Preload("LineItems").
+		Where("(pdf_key IS NULL OR pdf_key = '') OR (emailed_at IS NULL AND email_attempts < ?)", maxAttempts).
+		Order("sequence").
+		Limit(limit).
+		Find(&invoices).Error
+	if err != nil {
+		r.log.Error("failed to list undelivered invoices", logger.ErrorField(err))
+		return nil, err
+	}
+	return invoices, nil
+}
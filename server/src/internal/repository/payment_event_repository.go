@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type paymentEventRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewPaymentEventRepository(db *gorm.DB, log logger.Logger) interfaces.PaymentEventRepository {
+	return &paymentEventRepository{db: db, log: log}
+}
+
+// CreateIfNotExists stores the event unless one with the same ID was already
+// received. It reports whether the event is new.
+func (r *paymentEventRepository) CreateIfNotExists(ctx context.Context, event *models.PaymentEvent) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	if result.Error != nil {
+		r.log.Error("Failed to store payment event",
+			logger.NamedError("error", result.Error),
+			logger.String("event_id", event.ID))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentEventRepository) Update(ctx context.Context, event *models.PaymentEvent) error {
+	if err := r.db.WithContext(ctx).Save(event).Error; err != nil {
+		r.log.Error("Failed to update payment event",
+			logger.NamedError("error", err),
+			logger.String("event_id", event.ID))
+		return err
+	}
+	return nil
+}
+
+// GetRetryable returns failed events whose backoff has elapsed and pending
+// events last touched before staleBefore, oldest first. A pending event that
+// old was never finished, because processing crashed or its status couldn't
+// be saved, and the provider's redelivery is dropped as a duplicate.
+func (r *paymentEventRepository) GetRetryable(ctx context.Context, now, staleBefore time.Time, limit int) ([]*models.PaymentEvent, error) {
+	var events []*models.PaymentEvent
+	err := r.db.WithContext(ctx).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND updated_at <= ?)",
+			models.PaymentEventFailed, now, models.PaymentEventPending, staleBefore).
+		Order("COALESCE(next_attempt_at, updated_at) ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		r.log.Error("Failed to get retryable payment events", logger.NamedError("error", err))
+		return nil, err
+	}
+	return events, nil
+}
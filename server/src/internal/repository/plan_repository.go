@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type planRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewPlanRepository(db *gorm.DB, log logger.Logger) interfaces.PlanRepository {
+	return &planRepository{db: db, log: log}
+}
+
+// latestVersion restricts a query to the newest version of each plan.
+const latestVersion = "version = (SELECT MAX(p.version) FROM plans p WHERE p.code = plans.code)"
+
+func (r *planRepository) GetByID(ctx context.Context, id string) (*models.Plan, error) {
+	var plan models.Plan
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&plan).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrPlanNotFound
+		}
+		r.log.Error("failed to get plan",
+			logger.ErrorField(err),
+			logger.String("plan_id", id))
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetLatest returns the newest version of a plan, whether or not it is still
+// offered.
+func (r *planRepository) GetLatest(ctx context.Context, code models.SubscriptionPlan) (*models.Plan, error) {
+	var plan models.Plan
+	err := r.db.WithContext(ctx).
+		Where("code = ?", code).
+		Order("version DESC").
+		First(&plan).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrPlanNotFound
+		}
+		r.log.Error("failed to get plan",
+			logger.ErrorField(err),
+			logger.String("code", string(code)))
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *planRepository) ListLatest(ctx context.Context, activeOnly bool) ([]*models.Plan, error) {
+	query := r.db.WithContext(ctx).Where(latestVersion)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var plans []*models.Plan
+	if err := query.Order("price ASC, code ASC").Find(&plans).Error; err != nil {
+		r.log.Error("failed to list plans", logger.ErrorField(err))
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *planRepository) ListVersions(ctx context.Context, code models.SubscriptionPlan) ([]*models.Plan, error) {
+	var plans []*models.Plan
+	err := r.db.WithContext(ctx).
+		Where("code = ?", code).
+		Order("version DESC").
+		Find(&plans).Error
+	if err != nil {
+		r.log.Error("failed to list plan versions",
+			logger.ErrorField(err),
+			logger.String("code", string(code)))
+		return nil, err
+	}
+	return plans, nil
+}
+
+// CreateVersion stores plan as the next version of its code and retires the
+// earlier versions for new subscribers.
+func (r *planRepository) CreateVersion(ctx context.Context, plan *models.Plan) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.Plan{}).
+			Where("code = ?", plan.Code).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Plan{}).
+			Where("code = ? AND is_active = ?", plan.Code, true).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		plan.Version = latest + 1
+		plan.IsActive = true
+		return tx.Create(plan).Error
+	})
+	if err != nil {
+		r.log.Error("failed to create plan version",
+			logger.ErrorField(err),
+			logger.String("code", string(plan.Code)))
+		return err
+	}
+	return nil
+}
+
+func (r *planRepository) Update(ctx context.Context, plan *models.Plan) error {
+	if err := r.db.WithContext(ctx).Save(plan).Error; err != nil {
+		r.log.Error("failed to update plan",
+			logger.ErrorField(err),
+			logger.String("plan_id", plan.ID))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type privacyRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewPrivacyRepository(db *gorm.DB, log logger.Logger) interfaces.PrivacyRepository {
+	return &privacyRepository{db: db, log: log}
+}
+
+func (r *privacyRepository) CreateExport(ctx context.Context, export *models.DataExport) error {
+	if err := r.db.WithContext(ctx).Create(export).Error; err != nil {
+		r.log.Error("Failed to create data export",
+			logger.NamedError("error", err),
+			logger.String("user_id", export.UserID))
+		return err
+	}
+	return nil
+}
+
+func (r *privacyRepository) UpdateExport(ctx context.Context, export *models.DataExport) error {
+	if err := r.db.WithContext(ctx).Save(export).Error; err != nil {
+		r.log.Error("Failed to update data export",
+			logger.NamedError("error", err),
+			logger.String("export_id", export.ID))
+		return err
+	}
+	return nil
+}
+
+func (r *privacyRepository) GetExport(ctx context.Context, id, userID string) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&export).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrExportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *privacyRepository) HasActiveExport(ctx context.Context, userID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.DataExport{}).
+		Where("user_id = ? AND status IN ?", userID,
+			[]models.DataExportStatus{models.DataExportPending, models.DataExportProcessing}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *privacyRepository) GetUserExports(ctx context.Context, userID string) ([]*models.DataExport, error) {
+	var exports []*models.DataExport
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&exports).Error
+	return exports, err
+}
+
+func (r *privacyRepository) GetExpiredExports(ctx context.Context, now time.Time) ([]*models.DataExport, error) {
+	var exports []*models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *privacyRepository) DeleteExport(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.DataExport{}, "id = ?", id).Error
+}
+
+// CollectUserData loads every record tied to the user, including soft
+// deleted links, since those still hold the user's data.
+func (r *privacyRepository) CollectUserData(ctx context.Context, userID string) (*models.UserData, error) {
+	db := r.db.WithContext(ctx)
+	data := &models.UserData{}
+
+	var user models.User
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, err
+	}
+	data.User = &user
+
+	if err := db.Unscoped().Where("user_id = ?", userID).Order("created_at").Find(&data.URLs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load urls: %w", err)
+	}
+	if err := db.Where("url_id IN (?)", r.userURLIDs(db, userID)).Order("created_at").Find(&data.Clicks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load clicks: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Credits).Error; err != nil {
+		return nil, fmt.Errorf("failed to load credits: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.CreditUsages).Error; err != nil {
+		return nil, fmt.Errorf("failed to load credit usages: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at, id").Find(&data.CreditLedger).Error; err != nil {
+		return nil, fmt.Errorf("failed to load credit ledger: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load subscriptions: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payments: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Redemptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load promo code redemptions: %w", err)
+	}
+	if err := db.Where("referrer_id = ? OR referee_id = ?", userID, userID).Order("created_at").Find(&data.Referrals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load referrals: %w", err)
+	}
+	if err := db.Preload("LineItems").Where("user_id = ?", userID).Order("sequence").Find(&data.Invoices).Error; err != nil {
+		return nil, fmt.Errorf("failed to load invoices: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Trials).Error; err != nil {
+		return nil, fmt.Errorf("failed to load trials: %w", err)
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&data.Workspaces).Error; err != nil {
+		return nil, fmt.Errorf("failed to load workspace memberships: %w", err)
+	}
+
+	return data, nil
+}
+
+// ScheduleDeletion is phase one of account deletion: the account is
+// deactivated and hidden but nothing is removed until purgeAt.
+func (r *privacyRepository) ScheduleDeletion(ctx context.Context, userID string, purgeAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"is_active":             false,
+			"deleted_at":            time.Now(),
+			"deletion_scheduled_at": purgeAt,
+		})
+	if result.Error != nil {
+		r.log.Error("Failed to schedule account deletion",
+			logger.NamedError("error", result.Error),
+			logger.String("user_id", userID))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *privacyRepository) GetUsersDueForPurge(ctx context.Context, now time.Time) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetScheduledExportFileKeys returns the stored files the user's export
+// schedules delivered last, which PurgeUser cannot remove from storage.
+func (r *privacyRepository) GetScheduledExportFileKeys(ctx context.Context, userID string) ([]string, error) {
+	var keys []string
+	err := r.db.WithContext(ctx).Model(&models.ExportSchedule{}).
+		Where("user_id = ? AND last_file_key <> ''", userID).
+		Pluck("last_file_key", &keys).Error
+	return keys, err
+}
+
+// PurgeUser is phase two of account deletion. Links, click analytics (with
+// their IPs and user agents), credits and sign-in tokens are removed. Payments,
+// subscriptions and invoices are kept for accounting, so the user row itself
+// is anonymized rather than deleted.
+func (r *privacyRepository) PurgeUser(ctx context.Context, userID string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("email").Where("id = ?", userID).First(&user).Error; err != nil {
+			return fmt.Errorf("failed to load user: %w", err)
+		}
+
+		// Workspaces the user owns go first, so their links come back to
+		// whoever created them. Links, credits and usage in other workspaces
+		// belong to those teams and are kept.
+		if err := r.purgeWorkspaces(tx, userID, user.Email); err != nil {
+			return err
+		}
+
+		if err := tx.Where("url_id IN (?)", r.userURLIDs(tx, userID)).Delete(&models.URLClick{}).Error; err != nil {
+			return fmt.Errorf("failed to delete clicks: %w", err)
+		}
+		if err := tx.Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.CreditUsage{}).Error; err != nil {
+			return fmt.Errorf("failed to delete credit usages: %w", err)
+		}
+		if err := tx.Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.CreditLedgerEntry{}).Error; err != nil {
+			return fmt.Errorf("failed to delete credit ledger: %w", err)
+		}
+		if err := tx.Exec("DELETE FROM url_tags WHERE url_id IN (?)", r.userURLIDs(tx, userID)).Error; err != nil {
+			return fmt.Errorf("failed to delete url tags: %w", err)
+		}
+		if err := tx.Where("url_id IN (?)", r.userURLIDs(tx, userID)).Delete(&models.URLRevision{}).Error; err != nil {
+			return fmt.Errorf("failed to delete url revisions: %w", err)
+		}
+		if err := tx.Where("url_id IN (?)", r.userURLIDs(tx, userID)).Delete(&models.URLAlias{}).Error; err != nil {
+			return fmt.Errorf("failed to delete url aliases: %w", err)
+		}
+		if err := tx.Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.Tag{}).Error; err != nil {
+			return fmt.Errorf("failed to delete tags: %w", err)
+		}
+		if err := tx.Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.Folder{}).Error; err != nil {
+			return fmt.Errorf("failed to delete folders: %w", err)
+		}
+		if err := tx.Unscoped().Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.URL{}).Error; err != nil {
+			return fmt.Errorf("failed to delete urls: %w", err)
+		}
+		if err := tx.Unscoped().Model(&models.URL{}).Where("user_id = ?", userID).Update("created_by_ip", "").Error; err != nil {
+			return fmt.Errorf("failed to clear workspace url IPs: %w", err)
+		}
+		if err := tx.Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.Credit{}).Error; err != nil {
+			return fmt.Errorf("failed to delete credits: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MagicLinkToken{}).Error; err != nil {
+			return fmt.Errorf("failed to delete magic link tokens: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.DataExport{}).Error; err != nil {
+			return fmt.Errorf("failed to delete data exports: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.ReferralCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete referral code: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.URLImport{}).Error; err != nil {
+			return fmt.Errorf("failed to delete url imports: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.ExportSchedule{}).Error; err != nil {
+			return fmt.Errorf("failed to delete export schedules: %w", err)
+		}
+		if err := tx.Where("endpoint_id IN (?)", tx.Model(&models.WebhookEndpoint{}).Select("id").Where("user_id = ?", userID)).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WebhookEndpoint{}).Error; err != nil {
+			return fmt.Errorf("failed to delete webhook endpoints: %w", err)
+		}
+		if err := tx.Model(&models.Referral{}).Where("referee_id = ?", userID).Update("signup_ip", nil).Error; err != nil {
+			return fmt.Errorf("failed to clear referral IP: %w", err)
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"first_name":                "Deleted",
+				"last_name":                 "User",
+				"username":                  "deleted_" + userID,
+				"email":                     fmt.Sprintf("deleted+%s@users.invalid", userID),
+				"phone":                     "",
+				"avatar":                    "",
+				"password":                  "",
+				"is_active":                 false,
+				"verification_token":        nil,
+				"verification_purpose":      nil,
+				"verification_expires_at":   nil,
+				"pending_email":             nil,
+				"reset_password_token":      nil,
+				"reset_password_expires_at": nil,
+				"last_login_at":             nil,
+				"deletion_scheduled_at":     nil,
+				"billing_name":              "",
+				"billing_line1":             "",
+				"billing_line2":             "",
+				"billing_city":              "",
+				"billing_state":             "",
+				"billing_postal_code":       "",
+				"billing_country":           "",
+				"billing_tax_id":            "",
+			}).Error
+	})
+
+	if err != nil {
+		r.log.Error("Failed to purge user",
+			logger.NamedError("error", err),
+			logger.String("user_id", userID))
+		return err
+	}
+	return nil
+}
+
+// purgeWorkspaces deletes the workspaces the user owns, cancelling their
+// subscriptions, and removes the user from the rest along with any
+// invitations sent to them.
+func (r *privacyRepository) purgeWorkspaces(tx *gorm.DB, userID, email string) error {
+	var owned []string
+	if err := tx.Model(&models.Workspace{}).Where("owner_id = ?", userID).Pluck("id", &owned).Error; err != nil {
+		return fmt.Errorf("failed to load workspaces: %w", err)
+	}
+	if len(owned) > 0 {
+		if err := tx.Model(&models.Subscription{}).
+			Where("workspace_id IN ? AND is_active = true", owned).
+			Updates(map[string]interface{}{
+				"is_active":    false,
+				"status":       models.SubscriptionStatusCanceled,
+				"cancelled_at": time.Now(),
+			}).Error; err != nil {
+			return fmt.Errorf("failed to cancel workspace subscriptions: %w", err)
+		}
+	}
+	for _, id := range owned {
+		if err := deleteWorkspace(tx, id); err != nil {
+			return fmt.Errorf("failed to delete workspace: %w", err)
+		}
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return fmt.Errorf("failed to delete workspace memberships: %w", err)
+	}
+	if err := tx.Where("LOWER(email) = LOWER(?)", email).Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+		return fmt.Errorf("failed to delete workspace invitations: %w", err)
+	}
+	if err := tx.Model(&models.WorkspaceInvitation{}).Where("invited_by = ?", userID).Update("invited_by", nil).Error; err != nil {
+		return fmt.Errorf("failed to clear workspace invitations: %w", err)
+	}
+	return nil
+}
+
+// userURLIDs selects the user's personal links. Links they created in a
+// workspace belong to it and keep their clicks.
+func (r *privacyRepository) userURLIDs(db *gorm.DB, userID string) *gorm.DB {
+	return db.Unscoped().Model(&models.URL{}).Select("id").Where("user_id = ? AND workspace_id IS NULL", userID)
+}
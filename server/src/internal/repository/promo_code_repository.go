@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type promoCodeRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewPromoCodeRepository(db *gorm.DB, log logger.Logger) interfaces.PromoCodeRepository {
+	return &promoCodeRepository{db: db, log: log}
+}
+
+func (r *promoCodeRepository) Create(ctx context.Context, promo *models.PromoCode) error {
+	if err := r.db.WithContext(ctx).Create(promo).Error; err != nil {
+		r.log.Error("failed to create promo code",
+			logger.ErrorField(err),
+			logger.String("code", promo.Code))
+		return err
+	}
+	return nil
+}
+
+func (r *promoCodeRepository) GetByID(ctx context.Context, id string) (*models.PromoCode, error) {
+	return r.get(ctx, "id = ?", id)
+}
+
+func (r *promoCodeRepository) GetByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	return r.get(ctx, "code = ?", code)
+}
+
+func (r *promoCodeRepository) get(ctx context.Context, query string, arg string) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&promo).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrPromoCodeNotFound
+		}
+		r.log.Error("failed to get promo code", logger.ErrorField(err))
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r *promoCodeRepository) List(ctx context.Context) ([]*models.PromoCode, error) {
+	var promos []*models.PromoCode
+	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&promos).Error; err != nil {
+		r.log.Error("failed to list promo codes", logger.ErrorField(err))
+		return nil, err
+	}
+	return promos, nil
+}
+
+func (r *promoCodeRepository) Update(ctx context.Context, promo *models.PromoCode) error {
+	// redemption_count is only changed by Redeem and Release
+	if err := r.db.WithContext(ctx).Omit("redemption_count").Save(promo).Error; err != nil {
+		r.log.Error("failed to update promo code",
+			logger.ErrorField(err),
+			logger.String("promo_code_id", promo.ID))
+		return err
+	}
+	return nil
+}
+
+func (r *promoCodeRepository) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.PromoCode{}).Error; err != nil {
+		r.log.Error("failed to delete promo code",
+			logger.ErrorField(err),
+			logger.String("promo_code_id", id))
+		return err
+	}
+	return nil
+}
+
+// Redeem claims one use of the code before recording it. The counter is only
+// incremented while the code is under its limit, so concurrent redemptions
+// can't overshoot it, and the per-user count is checked in the same
+// transaction.
+func (r *promoCodeRepository) Redeem(ctx context.Context, promo *models.PromoCode, redemption *models.PromoCodeRedemption, credit *models.Credit) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PromoCode{}).
+			Where("id = ? AND (max_redemptions = 0 OR redemption_count < max_redemptions)", promo.ID).
+			Update("redemption_count", gorm.Expr("redemption_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrPromoCodeExhausted
+		}
+
+		if promo.PerUserLimit > 0 {
+			var used int64
+			if err := tx.Model(&models.PromoCodeRedemption{}).
+				Where("promo_code_id = ? AND user_id = ?", promo.ID, redemption.UserID).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			if int(used) >= promo.PerUserLimit {
+				return models.ErrPromoCodeAlreadyUsed
+			}
+		}
+
+		if credit != nil {
+			if err := grantCredits(tx, credit); err != nil {
+				return err
+			}
+			redemption.CreditID = credit.ID
+		}
+
+		redemption.PromoCodeID = promo.ID
+		return tx.Create(redemption).Error
+	})
+	if err != nil && err != models.ErrPromoCodeExhausted && err != models.ErrPromoCodeAlreadyUsed {
+		r.log.Error("failed to redeem promo code",
+			logger.ErrorField(err),
+			logger.String("promo_code_id", promo.ID),
+			logger.String("userID", redemption.UserID))
+	}
+	return err
+}
+
+func (r *promoCodeRepository) Release(ctx context.Context, redemption *models.PromoCodeRedemption) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", redemption.ID).Delete(&models.PromoCodeRedemption{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.PromoCode{}).
+			Where("id = ? AND redemption_count > 0", redemption.PromoCodeID).
+			Update("redemption_count", gorm.Expr("redemption_count - 1")).Error
+	})
+	if err != nil {
+		r.log.Error("failed to release promo code redemption",
+			logger.ErrorField(err),
+			logger.String("redemption_id", redemption.ID))
+	}
+	return err
+}
+
+func (r *promoCodeRepository) UpdateRedemption(ctx context.Context, redemption *models.PromoCodeRedemption) error {
+	if err := r.db.WithContext(ctx).Save(redemption).Error; err != nil {
+		r.log.Error("failed to update promo code redemption",
+			logger.ErrorField(err),
+			logger.String("redemption_id", redemption.ID))
+		return err
+	}
+	return nil
+}
+
+func (r *promoCodeRepository) ListRedemptions(ctx context.Context, promoID string, p pagination.Params) ([]*models.PromoCodeRedemption, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.PromoCodeRedemption{}).
+		Where("promo_code_id = ?", promoID)
+
+	var total int64
+	var redemptions []*models.PromoCodeRedemption
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Scopes(p.Newest("")).Find(&redemptions).Error
+	}
+	if err != nil {
+		r.log.Error("failed to list promo code redemptions",
+			logger.ErrorField(err),
+			logger.String("promo_code_id", promoID))
+		return nil, nil, err
+	}
+	redemptions, page := pagination.Trim(redemptions, p, total, redemptionPosition)
+	return redemptions, page, nil
+}
+
+func redemptionPosition(r *models.PromoCodeRedemption) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+}
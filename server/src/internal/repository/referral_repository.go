@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type referralRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewReferralRepository(db *gorm.DB, log logger.Logger) interfaces.ReferralRepository {
+	return &referralRepository{db: db, log: log}
+}
+
+func (r *referralRepository) GetCode(ctx context.Context, userID string) (*models.ReferralCode, error) {
+	return r.getCode(ctx, "user_id = ?", userID)
+}
+
+func (r *referralRepository) GetCodeByCode(ctx context.Context, code string) (*models.ReferralCode, error) {
+	return r.getCode(ctx, "code = ?", code)
+}
+
+func (r *referralRepository) getCode(ctx context.Context, query string, arg string) (*models.ReferralCode, error) {
+	var code models.ReferralCode
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrReferralNotFound
+		}
+		r.log.Error("failed to get referral code", logger.ErrorField(err))
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *referralRepository) CreateCode(ctx context.Context, code *models.ReferralCode) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Two first visits racing each other both end up with the same code
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}}, DoNothing: true}).
+			Create(code).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", code.UserID).First(code).Error
+	})
+	if err != nil {
+		r.log.Error("failed to create referral code",
+			logger.ErrorField(err),
+			logger.String("userID", code.UserID))
+		return err
+	}
+	return nil
+}
+
+func (r *referralRepository) Create(ctx context.Context, referral *models.Referral) error {
+	if err := r.db.WithContext(ctx).Create(referral).Error; err != nil {
+		r.log.Error("failed to create referral",
+			logger.ErrorField(err),
+			logger.String("referrerID", referral.ReferrerID),
+			logger.String("refereeID", referral.RefereeID))
+		return err
+	}
+	return nil
+}
+
+func (r *referralRepository) GetByReferee(ctx context.Context, refereeID string) (*models.Referral, error) {
+	var referral models.Referral
+	if err := r.db.WithContext(ctx).Where("referee_id = ?", refereeID).First(&referral).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrReferralNotFound
+		}
+		r.log.Error("failed to get referral",
+			logger.ErrorField(err),
+			logger.String("refereeID", refereeID))
+		return nil, err
+	}
+	return &referral, nil
+}
+
+func (r *referralRepository) ListByReferrer(ctx context.Context, referrerID string) ([]*models.ReferralEntry, error) {
+	var entries []*models.ReferralEntry
+	err := r.db.WithContext(ctx).Table("referrals").
+		Select(`referrals.id, users.username AS referee, referrals.status, referrals.reject_reason,
+			referrals.referrer_credits AS credits_earned, referrals.created_at, referrals.completed_at`).
+		Joins("JOIN users ON users.id = referrals.referee_id").
+		Where("referrals.referrer_id = ?", referrerID).
+		Order("referrals.created_at DESC").
+		Scan(&entries).Error
+	if err != nil {
+		r.log.Error("failed to list referrals",
+			logger.ErrorField(err),
+			logger.String("referrerID", referrerID))
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *referralRepository) IPSeen(ctx context.Context, referrerID, ip string) (bool, error) {
+	var seen int64
+	err := r.db.WithContext(ctx).Raw(`SELECT
+			EXISTS (SELECT 1 FROM urls WHERE user_id = ? AND created_by_ip = ?)
+			OR EXISTS (SELECT 1 FROM magic_link_tokens WHERE user_id = ? AND requested_ip = ?)
+			OR EXISTS (SELECT 1 FROM referrals WHERE referrer_id = ? AND signup_ip = ?)`,
+		referrerID, ip, referrerID, ip, referrerID, ip).
+		Scan(&seen).Error
+	if err != nil {
+		r.log.Error("failed to check referral IP",
+			logger.ErrorField(err),
+			logger.String("referrerID", referrerID))
+		return false, err
+	}
+	return seen > 0, nil
+}
+
+// Complete claims the referral before anything is credited, so a
+// verification that is processed twice only pays out once. Claiming it also
+// takes the write lock, which keeps concurrent completions for the same
+// referrer from both slipping under the cap.
+func (r *referralRepository) Complete(ctx context.Context, referral *models.Referral, referrerCredit, refereeCredit *models.Credit, monthStart time.Time, monthlyCap int) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Referral{}).
+			Where("id = ? AND status = ?", referral.ID, models.ReferralPending).
+			Updates(map[string]interface{}{
+				"status":       models.ReferralCompleted,
+				"completed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrReferralNotPending
+		}
+		referral.Status = models.ReferralCompleted
+		referral.CompletedAt = &now
+
+		if monthlyCap > 0 {
+			var rewarded int64
+			if err := tx.Model(&models.Referral{}).
+				Where("referrer_id = ? AND id <> ? AND status = ? AND completed_at >= ?",
+					referral.ReferrerID, referral.ID, models.ReferralCompleted, monthStart).
+				Count(&rewarded).Error; err != nil {
+				return err
+			}
+			if int(rewarded) >= monthlyCap {
+				referral.Status = models.ReferralCapped
+				referrerCredit = nil
+			}
+		}
+
+		if referrerCredit != nil && referrerCredit.Amount > 0 {
+			if err := grantCredits(tx, referrerCredit); err != nil {
+				return err
+			}
+			referral.ReferrerCredits = referrerCredit.Amount
+		}
+		if refereeCredit != nil && refereeCredit.Amount > 0 {
+			if err := grantCredits(tx, refereeCredit); err != nil {
+				return err
+			}
+			referral.RefereeCredits = refereeCredit.Amount
+		}
+
+		return tx.Model(&models.Referral{}).
+			Where("id = ?", referral.ID).
+			Updates(map[string]interface{}{
+				"status":           referral.Status,
+				"referrer_credits": referral.ReferrerCredits,
+				"referee_credits":  referral.RefereeCredits,
+			}).Error
+	})
+	if err != nil && err != models.ErrReferralNotPending {
+		r.log.Error("failed to complete referral",
+			logger.ErrorField(err),
+			logger.String("referralID", referral.ID))
+	}
+	return err
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type subscriptionRepository struct {
@@ -48,10 +50,12 @@ func (r *subscriptionRepository) CreateSubscription(ctx context.Context, subscri
 	return tx.Commit().Error
 }
 
+// GetUserSubscription returns the user's own active subscription. Workspace
+// subscriptions they pay for are not included.
 func (r *subscriptionRepository) GetUserSubscription(ctx context.Context, userID string) (*models.Subscription, error) {
 	var subscription models.Subscription
 	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND is_active = true", userID).
+		Where("user_id = ? AND workspace_id IS NULL AND is_active = true", userID).
 		First(&subscription).Error
 
 	if err != nil {
@@ -66,6 +70,24 @@ func (r *subscriptionRepository) GetUserSubscription(ctx context.Context, userID
 	return &subscription, nil
 }
 
+func (r *subscriptionRepository) GetWorkspaceSubscription(ctx context.Context, workspaceID string) (*models.Subscription, error) {
+	var subscription models.Subscription
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND is_active = true", workspaceID).
+		First(&subscription).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrSubscriptionNotActive
+		}
+		r.log.Error("failed to get workspace subscription",
+			logger.ErrorField(err),
+			logger.String("workspaceID", workspaceID))
+		return nil, err
+	}
+	return &subscription, nil
+}
+
 func (r *subscriptionRepository) UpdateSubscription(ctx context.Context, subscription *models.Subscription) error {
 	err := r.db.WithContext(ctx).Save(subscription).Error
 	if err != nil {
@@ -80,7 +102,7 @@ func (r *subscriptionRepository) UpdateSubscription(ctx context.Context, subscri
 func (r *subscriptionRepository) CancelSubscription(ctx context.Context, userID string) error {
 	err := r.db.WithContext(ctx).
 		Model(&models.Subscription{}).
-		Where("user_id = ?", userID).
+		Where("user_id = ? AND workspace_id IS NULL", userID).
 		Updates(map[string]interface{}{
 			"is_active":     false,
 			"cancelled_at":  time.Now(),
@@ -95,6 +117,24 @@ func (r *subscriptionRepository) CancelSubscription(ctx context.Context, userID
 	return nil
 }
 
+func (r *subscriptionRepository) CancelWorkspaceSubscription(ctx context.Context, workspaceID string) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.Subscription{}).
+		Where("workspace_id = ? AND is_active = true", workspaceID).
+		Updates(map[string]interface{}{
+			"is_active":    false,
+			"cancelled_at": time.Now(),
+		}).Error
+
+	if err != nil {
+		r.log.Error("failed to cancel workspace subscription",
+			logger.ErrorField(err),
+			logger.String("workspaceID", workspaceID))
+		return err
+	}
+	return nil
+}
+
 func (r *subscriptionRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
 	err := r.db.WithContext(ctx).Create(payment).Error
 	if err != nil {
@@ -106,18 +146,166 @@ func (r *subscriptionRepository) CreatePayment(ctx context.Context, payment *mod
 	return nil
 }
 
-func (r *subscriptionRepository) GetUserPayments(ctx context.Context, userID string) ([]*models.Payment, error) {
-	var payments []*models.Payment
-	err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Find(&payments).Error
+func (r *subscriptionRepository) GetUserPayments(ctx context.Context, userID string, p pagination.Params) ([]*models.Payment, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Where("user_id = ?", userID)
 
+	var total int64
+	var payments []*models.Payment
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Scopes(p.Newest("")).Find(&payments).Error
+	}
 	if err != nil {
 		r.log.Error("failed to get payments",
 			logger.ErrorField(err),
 			logger.String("userID", userID))
+		return nil, nil, err
+	}
+	payments, page := pagination.Trim(payments, p, total, paymentPosition)
+	return payments, page, nil
+}
+
+func paymentPosition(p *models.Payment) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
+func (r *subscriptionRepository) GetSubscriptionByStripeID(ctx context.Context, stripeID string) (*models.Subscription, error) {
+	var subscription models.Subscription
+	err := r.db.WithContext(ctx).
+		Where("stripe_id = ?", stripeID).
+		First(&subscription).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrSubscriptionNotFound
+		}
+		r.log.Error("failed to get subscription by stripe id",
+			logger.ErrorField(err),
+			logger.String("stripeID", stripeID))
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *subscriptionRepository) GetPaymentByStripeID(ctx context.Context, stripeID string) (*models.Payment, error) {
+	return r.getPayment(ctx, "stripe_id = ?", stripeID)
+}
+
+func (r *subscriptionRepository) GetPaymentByIntentID(ctx context.Context, intentID string) (*models.Payment, error) {
+	return r.getPayment(ctx, "payment_intent_id = ?", intentID)
+}
+
+func (r *subscriptionRepository) getPayment(ctx context.Context, query string, value string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.WithContext(ctx).
+		Where(query, value).
+		First(&payment).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrPaymentNotFound
+		}
+		r.log.Error("failed to get payment",
+			logger.ErrorField(err),
+			logger.String("value", value))
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *subscriptionRepository) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	err := r.db.WithContext(ctx).Save(payment).Error
+	if err != nil {
+		r.log.Error("failed to update payment",
+			logger.ErrorField(err),
+			logger.Any("payment", payment))
+		return err
+	}
+	return nil
+}
+
+// RecordPaidInvoice stores a paid invoice, or marks a previously failed
+// attempt for the same invoice as paid. It reports whether this call recorded
+// the payment, so each invoice's credits are only granted once.
+func (r *subscriptionRepository) RecordPaidInvoice(ctx context.Context, payment *models.Payment) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(payment)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			recorded = true
+			return nil
+		}
+
+		result = tx.Model(&models.Payment{}).
+			Where("stripe_id = ? AND status = ?", payment.StripeID, models.PaymentStatusFailed).
+			Updates(map[string]interface{}{
+				"status":            models.PaymentStatusPaid,
+				"amount":            payment.Amount,
+				"payment_intent_id": payment.PaymentIntentID,
+				"paid_at":           payment.PaidAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		recorded = result.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		r.log.Error("failed to record paid invoice",
+			logger.ErrorField(err),
+			logger.String("invoiceID", payment.StripeID))
+		return false, err
+	}
+	return recorded, nil
+}
+
+// dueForRenewal matches active subscriptions whose renewal (or final expiry,
+// when they won't renew) is due and that no scheduler currently holds.
+const dueForRenewal = "is_active = true AND " +
+	"((renews_at IS NOT NULL AND renews_at <= ?) OR (renews_at IS NULL AND expires_at <= ?)) AND " +
+	"(locked_until IS NULL OR locked_until < ?)"
+
+func (r *subscriptionRepository) GetDueSubscriptionIDs(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Subscription{}).
+		Where(dueForRenewal, now, now, now).
+		Order("COALESCE(renews_at, expires_at) ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		r.log.Error("failed to get subscriptions due for renewal", logger.ErrorField(err))
 		return nil, err
 	}
-	return payments, nil
-}
\ No newline at end of file
+	return ids, nil
+}
+
+// ClaimSubscription takes a lease on a due subscription until the given time.
+// The claim is a single conditional update, so when several instances race
+// for the same subscription only one of them gets it.
+func (r *subscriptionRepository) ClaimSubscription(ctx context.Context, id string, now, until time.Time) (*models.Subscription, bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Subscription{}).
+		Where("id = ? AND "+dueForRenewal, id, now, now, now).
+		Update("locked_until", until)
+	if result.Error != nil {
+		r.log.Error("failed to claim subscription",
+			logger.ErrorField(result.Error),
+			logger.String("subscriptionID", id))
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, false, nil
+	}
+
+	var subscription models.Subscription
+	if err := r.db.WithContext(ctx).First(&subscription, "id = ?", id).Error; err != nil {
+		return nil, false, err
+	}
+	return &subscription, true, nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tagURLCount selects each tag's live links as url_count
+const tagURLCount = "tags.*, (SELECT COUNT(*) FROM url_tags JOIN urls ON urls.id = url_tags.url_id WHERE url_tags.tag_id = tags.id AND urls.deleted_at IS NULL) AS url_count"
+
+type tagRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewTagRepository(db *gorm.DB, log logger.Logger) interfaces.TagRepository {
+	return &tagRepository{db: db, log: log}
+}
+
+func (r *tagRepository) GetTag(ctx context.Context, id string) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.db.WithContext(ctx).Select(tagURLCount).Where("id = ?", id).First(&tag).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrTagNotFound
+		}
+		r.log.Error("failed to get tag",
+			logger.ErrorField(err),
+			logger.String("tagID", id))
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) ListTags(ctx context.Context, userID, workspaceID string) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	err := r.db.WithContext(ctx).
+		Select(tagURLCount).
+		Scopes(ownerScope(userID, workspaceID)).
+		Order("name").
+		Find(&tags).Error
+	if err != nil {
+		r.log.Error("failed to list tags",
+			logger.ErrorField(err),
+			logger.String("userID", userID),
+			logger.String("workspaceID", workspaceID))
+		return nil, err
+	}
+	return tags, nil
+}
+
+// DeleteTag deletes the tag and takes it off every link
+func (r *tagRepository) DeleteTag(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM url_tags WHERE tag_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Tag{})
+		if result.Error != nil {
+			r.log.Error("failed to delete tag",
+				logger.ErrorField(result.Error),
+				logger.String("tagID", id))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrTagNotFound
+		}
+		return nil
+	})
+}
+
+// SetURLTags replaces the link's tags with the named ones, which must
+// already be in their stored form. Tags the link's scope doesn't have yet
+// are created, with userID as their creator. url.Tags is set to the result.
+func (r *tagRepository) SetURLTags(ctx context.Context, url *models.URL, userID string, names []string) error {
+	workspaceID := ""
+	if url.WorkspaceID != nil {
+		workspaceID = *url.WorkspaceID
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tags := make([]*models.Tag, 0, len(names))
+		if len(names) > 0 {
+			var existing []*models.Tag
+			if err := tx.Scopes(ownerScope(userID, workspaceID)).Where("name IN ?", names).Find(&existing).Error; err != nil {
+				return err
+			}
+			found := make(map[string]bool, len(existing))
+			for _, t := range existing {
+				found[t.Name] = true
+			}
+			for _, name := range names {
+				if found[name] {
+					continue
+				}
+				// Another request may create the same tag first
+				tag := &models.Tag{UserID: userID, WorkspaceID: url.WorkspaceID, Name: name}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(tag).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Scopes(ownerScope(userID, workspaceID)).Where("name IN ?", names).Find(&tags).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec("DELETE FROM url_tags WHERE url_id = ?", url.ID).Error; err != nil {
+			return err
+		}
+		for _, t := range tags {
+			if err := tx.Exec("INSERT INTO url_tags (url_id, tag_id) VALUES (?, ?)", url.ID, t.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+		url.Tags = tags
+		return nil
+	})
+	if err != nil {
+		r.log.Error("failed to set URL tags",
+			logger.ErrorField(err),
+			logger.String("urlID", url.ID))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type trialRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewTrialRepository(db *gorm.DB, log logger.Logger) interfaces.TrialRepository {
+	return &trialRepository{db: db, log: log}
+}
+
+func (r *trialRepository) Start(ctx context.Context, trial *models.Trial, sub *models.Subscription, credit *models.Credit) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Take the write lock first so two sign-ups can't both pass the checks
+		if err := tx.Exec("UPDATE trials SET status = status WHERE user_id = ?", trial.UserID).Error; err != nil {
+			return err
+		}
+
+		var previous int64
+		if err := tx.Model(&models.Trial{}).Where("user_id = ?", trial.UserID).Count(&previous).Error; err != nil {
+			return err
+		}
+		if previous > 0 {
+			return models.ErrTrialUnavailable
+		}
+		if err := tx.Model(&models.Subscription{}).Where("user_id = ?", trial.UserID).Count(&previous).Error; err != nil {
+			return err
+		}
+		if previous > 0 {
+			return models.ErrTrialUnavailable
+		}
+		if err := checkTrialFingerprint(tx, trial); err != nil {
+			return err
+		}
+
+		if err := tx.Create(sub).Error; err != nil {
+			return err
+		}
+		trial.SubscriptionID = sub.ID
+		if err := tx.Create(trial).Error; err != nil {
+			return err
+		}
+		if credit != nil {
+			return grantCredits(tx, credit)
+		}
+		return nil
+	})
+	if err != nil && err != models.ErrTrialUnavailable && err != models.ErrTrialPaymentMethodUsed {
+		r.log.Error("failed to start trial",
+			logger.ErrorField(err),
+			logger.String("userID", trial.UserID))
+	}
+	return err
+}
+
+func (r *trialRepository) GetByUser(ctx context.Context, userID string) (*models.Trial, error) {
+	return r.get(ctx, "user_id = ?", userID)
+}
+
+func (r *trialRepository) GetBySubscription(ctx context.Context, subscriptionID string) (*models.Trial, error) {
+	return r.get(ctx, "subscription_id = ?", subscriptionID)
+}
+
+func (r *trialRepository) get(ctx context.Context, query string, arg string) (*models.Trial, error) {
+	var trial models.Trial
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&trial).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrTrialNotFound
+		}
+		r.log.Error("failed to get trial", logger.ErrorField(err))
+		return nil, err
+	}
+	return &trial, nil
+}
+
+func (r *trialRepository) SetPaymentMethod(ctx context.Context, trial *models.Trial) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkTrialFingerprint(tx, trial); err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Trial{}).
+			Where("id = ? AND status = ?", trial.ID, models.TrialActive).
+			Updates(map[string]interface{}{
+				"payment_method_id":    trial.PaymentMethodID,
+				"payment_fingerprint":  trial.PaymentFingerprint,
+				"payment_method_brand": trial.PaymentMethodBrand,
+				"payment_method_last4": trial.PaymentMethodLast4,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrSubscriptionNotActive
+		}
+		return nil
+	})
+	if err != nil && err != models.ErrTrialPaymentMethodUsed && err != models.ErrSubscriptionNotActive {
+		r.log.Error("failed to set trial payment method",
+			logger.ErrorField(err),
+			logger.String("trialID", trial.ID))
+	}
+	return err
+}
+
+func (r *trialRepository) End(ctx context.Context, trial *models.Trial) error {
+	err := r.db.WithContext(ctx).Model(&models.Trial{}).
+		Where("id = ? AND status = ?", trial.ID, models.TrialActive).
+		Updates(map[string]interface{}{
+			"status":   trial.Status,
+			"ended_at": trial.EndedAt,
+		}).Error
+	if err != nil {
+		r.log.Error("failed to end trial",
+			logger.ErrorField(err),
+			logger.String("trialID", trial.ID))
+		return err
+	}
+	return nil
+}
+
+func (r *trialRepository) ListReminderDue(ctx context.Context, before time.Time, limit int) ([]*models.Trial, error) {
+	var trials []*models.Trial
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND reminder_sent_at IS NULL AND ends_at <= ?", models.TrialActive, before).
+		Order("ends_at").
+		Limit(limit).
+		Find(&trials).Error
+	if err != nil {
+		r.log.Error("failed to list trial reminders", logger.ErrorField(err))
+		return nil, err
+	}
+	return trials, nil
+}
+
+func (r *trialRepository) MarkReminded(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Trial{}).
+		Where("id = ? AND reminder_sent_at IS NULL", id).
+		Update("reminder_sent_at", at)
+	if result.Error != nil {
+		r.log.Error("failed to mark trial reminded",
+			logger.ErrorField(result.Error),
+			logger.String("trialID", id))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// checkTrialFingerprint rejects a payment method that another trial has used.
+func checkTrialFingerprint(tx *gorm.DB, trial *models.Trial) error {
+	if trial.PaymentFingerprint == nil {
+		return nil
+	}
+
+	var used int64
+	if err := tx.Model(&models.Trial{}).
+		Where("payment_fingerprint = ? AND user_id <> ?", *trial.PaymentFingerprint, trial.UserID).
+		Count(&used).Error; err != nil {
+		return err
+	}
+	if used > 0 {
+		return models.ErrTrialPaymentMethodUsed
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// shortCodeChunk keeps the IN lists of the short code check under SQLite's
+// variable limit
+const shortCodeChunk = 500
+
+type urlImportRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewURLImportRepository(db *gorm.DB, log logger.Logger) interfaces.URLImportRepository {
+	return &urlImportRepository{db: db, log: log}
+}
+
+func (r *urlImportRepository) CreateImport(ctx context.Context, imp *models.URLImport) error {
+	if err := r.db.WithContext(ctx).Create(imp).Error; err != nil {
+		r.log.Error("failed to create URL import",
+			logger.ErrorField(err),
+			logger.String("userID", imp.UserID))
+		return err
+	}
+	return nil
+}
+
+func (r *urlImportRepository) GetImport(ctx context.Context, id, userID string) (*models.URLImport, error) {
+	var imp models.URLImport
+	err := r.db.WithContext(ctx).
+		Omit("rows").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&imp).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrImportNotFound
+		}
+		r.log.Error("failed to get URL import",
+			logger.ErrorField(err),
+			logger.String("importID", id))
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (r *urlImportRepository) UpdateImport(ctx context.Context, imp *models.URLImport) error {
+	if err := r.db.WithContext(ctx).Save(imp).Error; err != nil {
+		r.log.Error("failed to update URL import",
+			logger.ErrorField(err),
+			logger.String("importID", imp.ID))
+		return err
+	}
+	return nil
+}
+
+// An import is due while it is waiting, or when the instance processing it
+// let its lease run out
+const dueForImport = "(status = 'pending' OR status = 'processing') AND " +
+	"(locked_until IS NULL OR locked_until < ?)"
+
+func (r *urlImportRepository) GetPendingImportIDs(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&models.URLImport{}).
+		Where(dueForImport, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		r.log.Error("failed to get pending URL imports", logger.ErrorField(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ClaimImport takes a lease on a due import until the given time and marks
+// it as processing, so only one instance runs it.
+func (r *urlImportRepository) ClaimImport(ctx context.Context, id string, now, until time.Time) (*models.URLImport, bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.URLImport{}).
+		Where("id = ? AND "+dueForImport, id, now).
+		UpdateColumns(map[string]interface{}{
+			"status":       models.URLImportProcessing,
+			"locked_until": until,
+			"started_at":   gorm.Expr("COALESCE(started_at, ?)", now),
+		})
+	if result.Error != nil {
+		r.log.Error("failed to claim URL import",
+			logger.ErrorField(result.Error),
+			logger.String("importID", id))
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, false, nil
+	}
+
+	var imp models.URLImport
+	if err := r.db.WithContext(ctx).First(&imp, "id = ?", id).Error; err != nil {
+		return nil, false, err
+	}
+	return &imp, true, nil
+}
+
+// CreateBatch creates the batch's valid entries and pays for them in one
+// transaction: either every valid link is created and charged for, or none
+// are and it returns ErrInsufficientCredits. Custom codes taken since the
+// batch was checked are reported on their entry; random ones are replaced.
+// When imp is set it is finished with the batch's results in the same
+// transaction, so a crash can't leave links created by an import that is
+// still due to run.
+func (r *urlImportRepository) CreateBatch(ctx context.Context, batch *models.URLBatch, imp *models.URLImport) error {
+	account := creditAccount{userID: batch.UserID}
+	if batch.WorkspaceID != nil {
+		account = creditAccount{workspaceID: *batch.WorkspaceID}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Taking the write lock first keeps the codes, the free allowance and
+		// the balance checked below from changing before the links exist
+		if err := lockCredits(tx, account); err != nil {
+			return err
+		}
+		if err := claimShortCodes(tx, batch); err != nil {
+			return err
+		}
+
+		valid := batch.Valid()
+		if len(valid) > 0 {
+			if err := createBatchURLs(tx, batch, account, valid); err != nil {
+				return err
+			}
+		}
+
+		if imp == nil {
+			return nil
+		}
+		imp.Finish(models.URLImportCompleted, batch.Results(), time.Now())
+		return tx.Save(imp).Error
+	})
+	if err != nil && err != models.ErrInsufficientCredits {
+		r.log.Error("failed to create URL batch",
+			logger.ErrorField(err),
+			logger.String("userID", batch.UserID),
+			logger.Int("entries", len(batch.Entries)))
+	}
+	return err
+}
+
+func createBatchURLs(tx *gorm.DB, batch *models.URLBatch, account creditAccount, valid []*models.URLBatchEntry) error {
+	// Users get their remaining free links first; workspaces pay for all
+	free := 0
+	if batch.WorkspaceID == nil {
+		var used int64
+		if err := tx.Model(&models.CreditUsage{}).
+			Where("user_id = ? AND operation = 'url_creation_free'", batch.UserID).
+			Count(&used).Error; err != nil {
+			return err
+		}
+		free = min(len(valid), max(0, batch.FreeLimit-int(used)))
+	}
+
+	if paid := len(valid) - free; paid > 0 {
+		var remaining int64
+		if err := spendableCredits(tx, account, time.Now()).
+			Select("COALESCE(SUM(remaining), 0)").
+			Scan(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining < int64(paid) {
+			return models.ErrInsufficientCredits
+		}
+	}
+
+	urls := make([]*models.URL, len(valid))
+	for i, entry := range valid {
+		urls[i] = entry.URL
+	}
+	if err := tx.CreateInBatches(urls, 100).Error; err != nil {
+		return err
+	}
+
+	var usages []*models.CreditUsage
+	for i, entry := range valid {
+		if i < free {
+			usages = append(usages, &models.CreditUsage{
+				UserID:    batch.UserID,
+				URLID:     entry.URL.ID,
+				Amount:    1,
+				Operation: "url_creation_free",
+			})
+			continue
+		}
+		if err := spendCredits(tx, account, 1, recordUsage(tx, batch.UserID, "url_creation", entry.URL.ID)); err != nil {
+			return err
+		}
+	}
+	if len(usages) > 0 {
+		return tx.CreateInBatches(usages, 100).Error
+	}
+	return nil
+}
+
+// claimShortCodes checks the batch's codes against every link ever created,
+// deleted ones included. A taken custom code fails its entry; a taken
+// random code is replaced until a free one is found.
+func claimShortCodes(tx *gorm.DB, batch *models.URLBatch) error {
+	pending := batch.Valid()
+	inBatch := make(map[string]bool, len(pending))
+	for _, entry := range pending {
+		inBatch[entry.URL.ShortCode] = true
+	}
+
+	for len(pending) > 0 {
+		codes := make([]string, len(pending))
+		for i, entry := range pending {
+			codes[i] = entry.URL.ShortCode
+		}
+		taken, err := takenShortCodes(tx, codes)
+		if err != nil {
+			return err
+		}
+
+		var retry []*models.URLBatchEntry
+		for _, entry := range pending {
+			if !taken[entry.URL.ShortCode] {
+				continue
+			}
+			if entry.Custom {
+				entry.Error = models.BulkErrShortCodeTaken
+				entry.Message = models.ErrShortCodeTaken.Error()
+				continue
+			}
+			code := batch.NewCode()
+			for inBatch[code] {
+				code = batch.NewCode()
+			}
+			inBatch[code] = true
+			entry.URL.ShortCode = code
+			retry = append(retry, entry)
+		}
+		pending = retry
+	}
+	return nil
+}
+
+func takenShortCodes(tx *gorm.DB, codes []string) (map[string]bool, error) {
+	taken := make(map[string]bool)
+	for start := 0; start < len(codes); start += shortCodeChunk {
+		end := min(start+shortCodeChunk, len(codes))
+
+		var found []string
+		if err := tx.Unscoped().
+			Model(&models.URL{}).
+			Where("short_code IN ?", codes[start:end]).
+			Pluck("short_code", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, code := range found {
+			taken[code] = true
+		}
+
+		found = nil
+		if err := tx.Model(&models.URLAlias{}).
+			Where("short_code IN ?", codes[start:end]).
+			Pluck("short_code", &found).Error; err != nil {
+			return nil, err
+		}
+		for _, code := range found {
+			taken[code] = true
+		}
+	}
+	return taken, nil
+}
@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type urlRepository struct {
@@ -50,9 +53,20 @@ func (r *urlRepository) CountByIP(ctx context.Context, ip string) (int, error) {
 	return int(count), nil
 }
 
+func (r *urlRepository) CountByUser(ctx context.Context, userID string) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.URL{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
 func (r *urlRepository) GetByID(ctx context.Context, id string) (*models.URL, error) {
 	var url models.URL
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&url).Error
+	err := r.db.WithContext(ctx).Preload("Tags", tagOrder).Preload("Aliases", aliasOrder).Where("id = ?", id).First(&url).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, models.ErrURLNotFound
@@ -65,10 +79,12 @@ func (r *urlRepository) GetByID(ctx context.Context, id string) (*models.URL, er
 	return &url, nil
 }
 
+// GetByShortCode finds the link with the code, or the link that had it
+// before its code was changed.
 func (r *urlRepository) GetByShortCode(ctx context.Context, shortCode string) (*models.URL, error) {
 	var url models.URL
 	result := r.db.WithContext(ctx).
-		Where("short_code = ?", shortCode).
+		Where("short_code = ? OR id IN (SELECT url_id FROM url_aliases WHERE short_code = ?)", shortCode, shortCode).
 		First(&url)
 
 	if result.Error != nil {
@@ -81,32 +97,244 @@ func (r *urlRepository) GetByShortCode(ctx context.Context, shortCode string) (*
 	return &url, nil
 }
 
-func (r *urlRepository) GetByUser(ctx context.Context, userID string, limit, offset int) ([]*models.URL, error) {
-	var urls []*models.URL
-	err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&urls).Error
+// GetByUser returns the user's own links. Links they created in a workspace
+// belong to the workspace and are listed with GetByWorkspace.
+func (r *urlRepository) GetByUser(ctx context.Context, userID string, f *models.URLFilter) ([]*models.URL, *pagination.Page, error) {
+	urls, page, err := listURLs(r.db.WithContext(ctx).Model(&models.URL{}).Scopes(ownerScope(userID, "")), f)
 	if err != nil {
 		r.logger.Error("failed to get URLs by user",
 			logger.ErrorField(err),
 			logger.String("userID", userID))
-		return nil, err
+		return nil, nil, err
 	}
-	return urls, nil
+	return urls, page, nil
 }
 
-func (r *urlRepository) Update(ctx context.Context, url *models.URL) error {
-	err := r.db.WithContext(ctx).Save(url).Error
+func (r *urlRepository) GetByWorkspace(ctx context.Context, workspaceID string, f *models.URLFilter) ([]*models.URL, *pagination.Page, error) {
+	urls, page, err := listURLs(r.db.WithContext(ctx).Model(&models.URL{}).Scopes(ownerScope("", workspaceID)), f)
 	if err != nil {
-		r.logger.Error("failed to update URL",
+		r.logger.Error("failed to get URLs by workspace",
 			logger.ErrorField(err),
-			logger.Any("url", url))
-		return err
+			logger.String("workspaceID", workspaceID))
+		return nil, nil, err
 	}
-	return nil
+	return urls, page, nil
+}
+
+// listURLs narrows, orders and pages a query of links in one scope by f.
+// Lists in creation order page by cursor; other sorts page by offset.
+func listURLs(query *gorm.DB, f *models.URLFilter) ([]*models.URL, *pagination.Page, error) {
+	order, err := f.OrderBy()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if match := matchExpression(f.Query); match != "" {
+		query = query.Where("urls.rowid IN (SELECT docid FROM urls_fts WHERE urls_fts MATCH ?)", match)
+	}
+	for _, tag := range f.Tags {
+		query = query.Where("id IN (SELECT url_tags.url_id FROM url_tags JOIN tags ON tags.id = url_tags.tag_id WHERE tags.name = ?)", models.TagName(tag))
+	}
+	switch f.FolderID {
+	case "":
+	case "none":
+		query = query.Where("folder_id IS NULL")
+	default:
+		query = query.Where("folder_id = ?", f.FolderID)
+	}
+
+	now := time.Now()
+	switch f.Status {
+	case models.URLStatusActive:
+		query = query.Where("is_active = true AND taken_down_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", now)
+	case models.URLStatusExpired:
+		query = query.Where("expires_at IS NOT NULL AND expires_at <= ?", now)
+	case models.URLStatusInactive:
+		query = query.Where("(is_active = false OR taken_down_at IS NOT NULL)")
+	}
+	if f.Domain != "" {
+		domain := strings.ToLower(f.Domain)
+		query = query.Where(`(domain = ? OR domain LIKE ? ESCAPE '\')`, domain, "%."+escapeLike(domain))
+	}
+	if f.From != nil {
+		query = query.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("created_at < ?", *f.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var page func(*gorm.DB) *gorm.DB
+	position := urlPosition
+	switch f.Sort {
+	case "", "-created_at":
+		page = f.Page.Newest("urls")
+	case "created_at":
+		page = f.Page.Oldest("urls")
+	default:
+		page, position = f.Page.Sorted(order), nil
+	}
+
+	var urls []*models.URL
+	if err := query.Preload("Tags", tagOrder).Preload("Aliases", aliasOrder).Scopes(page).Find(&urls).Error; err != nil {
+		return nil, nil, err
+	}
+	urls, meta := pagination.Trim(urls, f.Page, total, position)
+	return urls, meta, nil
+}
+
+func urlPosition(u *models.URL) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: u.CreatedAt, ID: u.ID}
+}
+
+// matchExpression turns free text into a full-text query matching links
+// that contain every word, each as a prefix. Quotes and stars are dropped,
+// so the text can't use the query syntax.
+func matchExpression(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		word = strings.NewReplacer(`"`, "", "*", "").Replace(word)
+		if word != "" {
+			terms = append(terms, `"`+word+`*"`)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+func tagOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name")
+}
+
+func aliasOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("url_aliases.created_at")
+}
+
+// UpdateWithRevision saves the link and records rev as its next revision.
+// The first revision of a link is its state before it was ever edited, so
+// before is stored as revision 1 if the link has none yet. If the short
+// code changed, the old one becomes an alias and the new one must not be
+// used by another link.
+func (r *urlRepository) UpdateWithRevision(ctx context.Context, url *models.URL, before, rev *models.URLRevision) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if url.ShortCode != before.ShortCode {
+			taken, err := shortCodeTakenByOther(tx, url.ShortCode, url.ID)
+			if err != nil {
+				return err
+			}
+			if taken {
+				return models.ErrShortCodeTaken
+			}
+
+			// Going back to one of the link's old codes takes it off the
+			// alias list
+			if err := tx.Where("short_code = ? AND url_id = ?", url.ShortCode, url.ID).Delete(&models.URLAlias{}).Error; err != nil {
+				return err
+			}
+			alias := &models.URLAlias{ShortCode: before.ShortCode, URLID: url.ID}
+			if err := tx.Create(alias).Error; err != nil {
+				return err
+			}
+
+			aliases := []*models.URLAlias{}
+			for _, a := range url.Aliases {
+				if a.ShortCode != url.ShortCode {
+					aliases = append(aliases, a)
+				}
+			}
+			url.Aliases = append(aliases, alias)
+		}
+
+		if err := tx.Omit(clause.Associations).Save(url).Error; err != nil {
+			return err
+		}
+
+		var last int
+		if err := tx.Model(&models.URLRevision{}).
+			Where("url_id = ?", url.ID).
+			Select("COALESCE(MAX(number), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		if last == 0 {
+			before.Number = 1
+			if err := tx.Create(before).Error; err != nil {
+				return err
+			}
+			last = 1
+		}
+		rev.Number = last + 1
+		return tx.Create(rev).Error
+	})
+	if err != nil && !errors.Is(err, models.ErrShortCodeTaken) {
+		r.logger.Error("failed to update URL with revision",
+			logger.ErrorField(err),
+			logger.String("urlID", url.ID))
+	}
+	return err
+}
+
+// shortCodeTakenByOther reports whether a link other than urlID uses code,
+// as its short code or an alias. Deleted links keep their codes.
+func shortCodeTakenByOther(tx *gorm.DB, code, urlID string) (bool, error) {
+	var count int64
+	if err := tx.Unscoped().Model(&models.URL{}).
+		Where("short_code = ? AND id <> ?", code, urlID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := tx.Model(&models.URLAlias{}).
+		Where("short_code = ? AND url_id <> ?", code, urlID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListRevisions returns a page of the link's revisions, newest first
+func (r *urlRepository) ListRevisions(ctx context.Context, urlID string, p pagination.Params) ([]*models.URLRevision, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.URLRevision{}).
+		Where("url_id = ?", urlID)
+
+	var total int64
+	var revisions []*models.URLRevision
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Scopes(p.Sorted("number DESC")).Find(&revisions).Error
+	}
+	if err != nil {
+		r.logger.Error("failed to list URL revisions",
+			logger.ErrorField(err),
+			logger.String("urlID", urlID))
+		return nil, nil, err
+	}
+	revisions, page := pagination.Trim(revisions, p, total, nil)
+	return revisions, page, nil
+}
+
+func (r *urlRepository) GetRevision(ctx context.Context, urlID string, number int) (*models.URLRevision, error) {
+	var revision models.URLRevision
+	err := r.db.WithContext(ctx).
+		Where("url_id = ? AND number = ?", urlID, number).
+		First(&revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrURLRevisionNotFound
+		}
+		r.logger.Error("failed to get URL revision",
+			logger.ErrorField(err),
+			logger.String("urlID", urlID),
+			logger.Int("number", number))
+		return nil, err
+	}
+	return &revision, nil
 }
 
 func (r *urlRepository) Delete(ctx context.Context, id string) error {
@@ -145,18 +373,153 @@ func (r *urlRepository) RecordClick(ctx context.Context, click *models.URLClick)
 	return nil
 }
 
-func (r *urlRepository) GetClicksAnalytics(ctx context.Context, urlID string, from, to time.Time) ([]*models.URLClick, error) {
+// GetClicksAnalytics returns a page of the clicks on a link between from and
+// to, newest first.
+func (r *urlRepository) GetClicksAnalytics(ctx context.Context, urlID string, from, to time.Time, p pagination.Params) ([]*models.URLClick, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.URLClick{}).
+		Where("url_id = ? AND created_at BETWEEN ? AND ?", urlID, from, to)
+
+	var total int64
 	var clicks []*models.URLClick
-	err := r.db.WithContext(ctx).
-		Where("url_id = ? AND created_at BETWEEN ? AND ?", urlID, from, to).
-		Find(&clicks).Error
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Scopes(p.Newest("")).Find(&clicks).Error
+	}
 	if err != nil {
 		r.logger.Error("failed to get URL click analytics",
 			logger.ErrorField(err),
 			logger.String("urlID", urlID),
 			logger.Time("from", from),
 			logger.Time("to", to))
+		return nil, nil, err
+	}
+	clicks, page := pagination.Trim(clicks, p, total, clickPosition)
+	return clicks, page, nil
+}
+
+func clickPosition(c *models.URLClick) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+}
+
+// GetNewlyExpired returns links that have expired since they were last
+// checked, oldest expiry first.
+func (r *urlRepository) GetNewlyExpired(ctx context.Context, now time.Time, limit int) ([]*models.URL, error) {
+	var urls []*models.URL
+	err := r.db.WithContext(ctx).
+		Where("expires_at <= ? AND expired_notified_at IS NULL", now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&urls).Error
+	if err != nil {
+		r.logger.Error("failed to get newly expired URLs", logger.ErrorField(err))
 		return nil, err
 	}
-	return clicks, nil
+	return urls, nil
+}
+
+// MarkExpiredNotified records that the link's expiry has been announced. It
+// reports false if another instance got there first.
+func (r *urlRepository) MarkExpiredNotified(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.URL{}).
+		Where("id = ? AND expired_notified_at IS NULL", id).
+		UpdateColumn("expired_notified_at", at)
+	if result.Error != nil {
+		r.logger.Error("failed to mark URL expiry notified",
+			logger.ErrorField(result.Error),
+			logger.String("urlID", id))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// StreamURLs calls fn with each link the query selects, oldest first. Links
+// are read from a cursor one at a time rather than loaded all at once, and
+// the first error from fn stops the stream.
+func (r *urlRepository) StreamURLs(ctx context.Context, userID string, q *models.URLExportQuery, fn func(*models.URL) error) error {
+	query := r.db.WithContext(ctx).Model(&models.URL{}).Scopes(ownerScope(userID, q.WorkspaceID))
+	if q.From != nil {
+		query = query.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("created_at < ?", *q.To)
+	}
+
+	rows, err := query.Order("created_at, id").Rows()
+	if err != nil {
+		r.logger.Error("failed to stream URLs",
+			logger.ErrorField(err),
+			logger.String("userID", userID))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u models.URL
+		if err := r.db.ScanRows(rows, &u); err != nil {
+			return err
+		}
+		if err := fn(&u); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamClicks is StreamURLs for clicks: those on q.URLID, or on every link
+// in scope when it is empty, oldest first.
+func (r *urlRepository) StreamClicks(ctx context.Context, userID string, q *models.URLExportQuery, fn func(*models.URLClick) error) error {
+	query := r.db.WithContext(ctx).Model(&models.URLClick{})
+	if q.URLID != "" {
+		query = query.Where("url_id = ?", q.URLID)
+	} else {
+		links := r.db.Model(&models.URL{}).Select("id").Scopes(ownerScope(userID, q.WorkspaceID))
+		query = query.Where("url_id IN (?)", links)
+	}
+	if q.From != nil {
+		query = query.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("created_at < ?", *q.To)
+	}
+
+	rows, err := query.Order("created_at, id").Rows()
+	if err != nil {
+		r.logger.Error("failed to stream URL clicks",
+			logger.ErrorField(err),
+			logger.String("userID", userID),
+			logger.String("urlID", q.URLID))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var click models.URLClick
+		if err := r.db.ScanRows(rows, &click); err != nil {
+			return err
+		}
+		if err := fn(&click); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ownerScope limits links, folders or tags to a workspace's, or to the
+// user's own outside any workspace
+func ownerScope(userID, workspaceID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if workspaceID != "" {
+			return db.Where("workspace_id = ?", workspaceID)
+		}
+		return db.Where("user_id = ? AND workspace_id IS NULL", userID)
+	}
+}
+
+// likeEscaper escapes the LIKE wildcards, for patterns used with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
 }
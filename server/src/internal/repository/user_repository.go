@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
@@ -112,4 +113,88 @@ func (r *userRepository) UpdateAvatar(ctx context.Context, userID, avatarURL str
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (r *userRepository) SetPaymentCustomerID(ctx context.Context, userID, customerID string) error {
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("payment_customer_id", customerID).Error
+	if err != nil {
+		r.log.Error("Failed to save payment customer ID",
+			logger.NamedError("error", err),
+			logger.String("user_id", userID))
+		return err
+	}
+	return nil
+}
+
+func (r *userRepository) SavePendingEmailChange(ctx context.Context, userID, newEmail, token string, expires time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"pending_email":           newEmail,
+			"verification_token":      token,
+			"verification_purpose":    models.VerificationPurposeEmailChange,
+			"verification_expires_at": expires,
+		}).Error
+
+	if err != nil {
+		r.log.Error("Failed to save pending email change",
+			logger.NamedError("error", err),
+			logger.String("user_id", userID))
+		return err
+	}
+	return nil
+}
+
+// ConfirmEmailChange swaps in the pending email. Uniqueness is checked again
+// inside the transaction because the address may have been claimed since the
+// change was requested.
+func (r *userRepository) ConfirmEmailChange(ctx context.Context, token string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("verification_token = ? AND verification_purpose = ? AND verification_expires_at > ?",
+			token, models.VerificationPurposeEmailChange, time.Now()).
+			First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrInvalidVerificationToken
+		}
+		if err != nil {
+			return err
+		}
+		if user.PendingEmail == "" {
+			return models.ErrInvalidVerificationToken
+		}
+
+		var taken int64
+		if err := tx.Model(&models.User{}).
+			Where("email = ? AND id <> ?", user.PendingEmail, user.ID).
+			Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return models.ErrEmailAlreadyExists
+		}
+
+		user.Email = user.PendingEmail
+		user.ClearVerificationToken()
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"email":                   user.Email,
+				"pending_email":           nil,
+				"verification_token":      nil,
+				"verification_purpose":    nil,
+				"verification_expires_at": nil,
+			}).Error
+	})
+
+	if err != nil {
+		if !errors.Is(err, models.ErrInvalidVerificationToken) && !errors.Is(err, models.ErrEmailAlreadyExists) {
+			r.log.Error("Failed to confirm email change", logger.NamedError("error", err))
+		}
+		return nil, err
+	}
+	return &user, nil
+}
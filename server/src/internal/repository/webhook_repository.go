@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type webhookRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewWebhookRepository(db *gorm.DB, log logger.Logger) interfaces.WebhookRepository {
+	return &webhookRepository{db: db, log: log}
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	if err := r.db.WithContext(ctx).Create(endpoint).Error; err != nil {
+		r.log.Error("failed to create webhook endpoint",
+			logger.ErrorField(err),
+			logger.String("userID", endpoint.UserID))
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetEndpoint(ctx context.Context, id, userID string) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&endpoint).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrWebhookEndpointNotFound
+		}
+		r.log.Error("failed to get webhook endpoint",
+			logger.ErrorField(err),
+			logger.String("endpointID", id))
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *webhookRepository) GetEndpointByID(ctx context.Context, id string) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&endpoint).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrWebhookEndpointNotFound
+		}
+		r.log.Error("failed to get webhook endpoint",
+			logger.ErrorField(err),
+			logger.String("endpointID", id))
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *webhookRepository) ListEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error) {
+	var endpoints []*models.WebhookEndpoint
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&endpoints).Error
+	if err != nil {
+		r.log.Error("failed to list webhook endpoints",
+			logger.ErrorField(err),
+			logger.String("userID", userID))
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepository) GetActiveEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error) {
+	var endpoints []*models.WebhookEndpoint
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Find(&endpoints).Error
+	if err != nil {
+		r.log.Error("failed to get active webhook endpoints",
+			logger.ErrorField(err),
+			logger.String("userID", userID))
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepository) CountEndpoints(ctx context.Context, userID string) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.WebhookEndpoint{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *webhookRepository) UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	if err := r.db.WithContext(ctx).Save(endpoint).Error; err != nil {
+		r.log.Error("failed to update webhook endpoint",
+			logger.ErrorField(err),
+			logger.String("endpointID", endpoint.ID))
+		return err
+	}
+	return nil
+}
+
+// DeleteEndpoint removes the endpoint along with its delivery log
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, id, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.WebhookEndpoint{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrWebhookEndpointNotFound
+		}
+		return tx.Where("endpoint_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+func (r *webhookRepository) ResetEndpointFailures(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).
+		Model(&models.WebhookEndpoint{}).
+		Where("id = ? AND consecutive_failures > 0", id).
+		UpdateColumn("consecutive_failures", 0).Error
+	if err != nil {
+		r.log.Error("failed to reset webhook endpoint failures",
+			logger.ErrorField(err),
+			logger.String("endpointID", id))
+		return err
+	}
+	return nil
+}
+
+// RecordEndpointFailure counts a delivery that ran out of attempts. Once
+// disableAfter deliveries in a row have failed the endpoint is disabled, and
+// it reports true if this call disabled it.
+func (r *webhookRepository) RecordEndpointFailure(ctx context.Context, id string, disableAfter int, now time.Time) (bool, error) {
+	disabled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.WebhookEndpoint{}).
+			Where("id = ?", id).
+			UpdateColumn("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error
+		if err != nil {
+			return err
+		}
+		if disableAfter <= 0 {
+			return nil
+		}
+
+		result := tx.Model(&models.WebhookEndpoint{}).
+			Where("id = ? AND is_active = ? AND consecutive_failures >= ?", id, true, disableAfter).
+			Updates(map[string]interface{}{
+				"is_active":       false,
+				"disabled_at":     now,
+				"disabled_reason": "too many consecutive failed deliveries",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		disabled = result.RowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		r.log.Error("failed to record webhook endpoint failure",
+			logger.ErrorField(err),
+			logger.String("endpointID", id))
+		return false, err
+	}
+	return disabled, nil
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := r.db.WithContext(ctx).Create(delivery).Error; err != nil {
+		r.log.Error("failed to create webhook delivery",
+			logger.ErrorField(err),
+			logger.String("endpointID", delivery.EndpointID),
+			logger.String("eventID", delivery.EventID))
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, endpointID, id string) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("id = ? AND endpoint_id = ?", id, endpointID).First(&delivery).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrWebhookDeliveryNotFound
+		}
+		r.log.Error("failed to get webhook delivery",
+			logger.ErrorField(err),
+			logger.String("deliveryID", id))
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, endpointID string, status models.WebhookDeliveryStatus, p pagination.Params) ([]*models.WebhookDelivery, *pagination.Page, error) {
+	query := r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpointID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("failed to count webhook deliveries", logger.ErrorField(err))
+		return nil, nil, err
+	}
+	var deliveries []*models.WebhookDelivery
+	if err := query.Scopes(p.Newest("")).Find(&deliveries).Error; err != nil {
+		r.log.Error("failed to list webhook deliveries", logger.ErrorField(err))
+		return nil, nil, err
+	}
+	deliveries, page := pagination.Trim(deliveries, p, total, deliveryPosition)
+	return deliveries, page, nil
+}
+
+func deliveryPosition(d *models.WebhookDelivery) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: d.CreatedAt, ID: d.ID}
+}
+
+const dueForDelivery = "status = 'pending' AND next_attempt_at <= ? AND " +
+	"(locked_until IS NULL OR locked_until < ?)"
+
+func (r *webhookRepository) GetDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Model(&models.WebhookDelivery{}).
+		Where(dueForDelivery, now, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		r.log.Error("failed to get due webhook deliveries", logger.ErrorField(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ClaimDelivery takes a lease on a due delivery until the given time, so
+// only one instance sends it.
+func (r *webhookRepository) ClaimDelivery(ctx context.Context, id string, now, until time.Time) (*models.WebhookDelivery, bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.WebhookDelivery{}).
+		Where("id = ? AND "+dueForDelivery, id, now, now).
+		UpdateColumn("locked_until", until)
+	if result.Error != nil {
+		r.log.Error("failed to claim webhook delivery",
+			logger.ErrorField(result.Error),
+			logger.String("deliveryID", id))
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, false, nil
+	}
+
+	var delivery models.WebhookDelivery
+	if err := r.db.WithContext(ctx).First(&delivery, "id = ?", id).Error; err != nil {
+		return nil, false, err
+	}
+	return &delivery, true, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := r.db.WithContext(ctx).Save(delivery).Error; err != nil {
+		r.log.Error("failed to update webhook delivery",
+			logger.ErrorField(err),
+			logger.String("deliveryID", delivery.ID))
+		return err
+	}
+	return nil
+}
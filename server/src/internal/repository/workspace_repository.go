@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type workspaceRepository struct {
+	db  *gorm.DB
+	log logger.Logger
+}
+
+func NewWorkspaceRepository(db *gorm.DB, log logger.Logger) interfaces.WorkspaceRepository {
+	return &workspaceRepository{db: db, log: log}
+}
+
+func (r *workspaceRepository) Create(ctx context.Context, workspace *models.Workspace) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      workspace.OwnerID,
+			Role:        models.WorkspaceOwner,
+		}).Error
+	})
+	if err != nil {
+		r.log.Error("failed to create workspace",
+			logger.ErrorField(err),
+			logger.String("userID", workspace.OwnerID))
+		return err
+	}
+	return nil
+}
+
+func (r *workspaceRepository) GetByID(ctx context.Context, id string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&workspace).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		r.log.Error("failed to get workspace", logger.ErrorField(err))
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (r *workspaceRepository) ListForUser(ctx context.Context, userID string) ([]*models.WorkspaceResponse, error) {
+	var workspaces []*models.WorkspaceResponse
+	err := r.db.WithContext(ctx).
+		Table("workspaces").
+		Select("workspaces.*, workspace_members.role").
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.created_at").
+		Scan(&workspaces).Error
+	if err != nil {
+		r.log.Error("failed to list workspaces",
+			logger.ErrorField(err),
+			logger.String("userID", userID))
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) Update(ctx context.Context, workspace *models.Workspace) error {
+	err := r.db.WithContext(ctx).Model(workspace).Update("name", workspace.Name).Error
+	if err != nil {
+		r.log.Error("failed to update workspace",
+			logger.ErrorField(err),
+			logger.String("workspaceID", workspace.ID))
+		return err
+	}
+	return nil
+}
+
+func (r *workspaceRepository) Delete(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var active int64
+		if err := tx.Model(&models.Subscription{}).
+			Where("workspace_id = ? AND is_active = true", id).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return models.ErrWorkspaceHasSubscription
+		}
+		return deleteWorkspace(tx, id)
+	})
+	if err != nil && err != models.ErrWorkspaceHasSubscription && err != models.ErrWorkspaceNotFound {
+		r.log.Error("failed to delete workspace",
+			logger.ErrorField(err),
+			logger.String("workspaceID", id))
+	}
+	return err
+}
+
+func (r *workspaceRepository) GetMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrMemberNotFound
+		}
+		r.log.Error("failed to get workspace member", logger.ErrorField(err))
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *workspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]*models.WorkspaceMember, error) {
+	var members []*models.WorkspaceMember
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at").
+		Find(&members).Error
+	if err != nil {
+		r.log.Error("failed to list workspace members",
+			logger.ErrorField(err),
+			logger.String("workspaceID", workspaceID))
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID, userID string, role models.WorkspaceRole) error {
+	result := r.db.WithContext(ctx).Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		r.log.Error("failed to update workspace member",
+			logger.ErrorField(result.Error),
+			logger.String("workspaceID", workspaceID))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrMemberNotFound
+	}
+	return nil
+}
+
+// RemoveMember removes a member. The links they created stay in the
+// workspace.
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	result := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Delete(&models.WorkspaceMember{})
+	if result.Error != nil {
+		r.log.Error("failed to remove workspace member",
+			logger.ErrorField(result.Error),
+			logger.String("workspaceID", workspaceID))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrMemberNotFound
+	}
+	return nil
+}
+
+func (r *workspaceRepository) CreateInvitation(ctx context.Context, invitation *models.WorkspaceInvitation) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var members int64
+		if err := tx.Model(&models.WorkspaceMember{}).
+			Joins("JOIN users ON users.id = workspace_members.user_id").
+			Where("workspace_members.workspace_id = ? AND LOWER(users.email) = ?", invitation.WorkspaceID, invitation.Email).
+			Count(&members).Error; err != nil {
+			return err
+		}
+		if members > 0 {
+			return models.ErrAlreadyMember
+		}
+
+		if err := tx.Where("workspace_id = ? AND email = ? AND accepted_at IS NULL", invitation.WorkspaceID, invitation.Email).
+			Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(invitation).Error
+	})
+	if err != nil && err != models.ErrAlreadyMember {
+		r.log.Error("failed to create workspace invitation",
+			logger.ErrorField(err),
+			logger.String("workspaceID", invitation.WorkspaceID))
+	}
+	return err
+}
+
+func (r *workspaceRepository) GetInvitationByToken(ctx context.Context, tokenHash string, now time.Time) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND accepted_at IS NULL AND expires_at > ?", tokenHash, now).
+		First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrInvalidInvitation
+		}
+		r.log.Error("failed to get workspace invitation", logger.ErrorField(err))
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListInvitations returns the workspace's open invitations, expired ones
+// included so they can be sent again.
+func (r *workspaceRepository) ListInvitations(ctx context.Context, workspaceID string) ([]*models.WorkspaceInvitation, error) {
+	var invitations []*models.WorkspaceInvitation
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND accepted_at IS NULL", workspaceID).
+		Order("created_at").
+		Find(&invitations).Error
+	if err != nil {
+		r.log.Error("failed to list workspace invitations",
+			logger.ErrorField(err),
+			logger.String("workspaceID", workspaceID))
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *workspaceRepository) DeleteInvitation(ctx context.Context, workspaceID, id string) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND workspace_id = ? AND accepted_at IS NULL", id, workspaceID).
+		Delete(&models.WorkspaceInvitation{})
+	if result.Error != nil {
+		r.log.Error("failed to delete workspace invitation",
+			logger.ErrorField(result.Error),
+			logger.String("workspaceID", workspaceID))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (r *workspaceRepository) AcceptInvitation(ctx context.Context, invitation *models.WorkspaceInvitation, userID string) (*models.WorkspaceMember, error) {
+	member := &models.WorkspaceMember{
+		WorkspaceID: invitation.WorkspaceID,
+		UserID:      userID,
+		Role:        invitation.Role,
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claim the invitation first, so two requests can't both use it
+		result := tx.Model(&models.WorkspaceInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrInvalidInvitation
+		}
+
+		var existing int64
+		if err := tx.Model(&models.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id = ?", invitation.WorkspaceID, userID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return models.ErrAlreadyMember
+		}
+		return tx.Create(member).Error
+	})
+	if err != nil {
+		if err != models.ErrInvalidInvitation && err != models.ErrAlreadyMember {
+			r.log.Error("failed to accept workspace invitation",
+				logger.ErrorField(err),
+				logger.String("workspaceID", invitation.WorkspaceID))
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// deleteWorkspace removes a workspace with everything it owns. Links go back
+// to the members who created them; subscriptions and payments are kept for
+// accounting. It must run inside a transaction.
+func deleteWorkspace(tx *gorm.DB, id string) error {
+	// Links come back to their creators without the workspace's folders
+	// and tags
+	if err := tx.Unscoped().Model(&models.URL{}).
+		Where("workspace_id = ?", id).
+		Updates(map[string]interface{}{"workspace_id": nil, "folder_id": nil}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM url_tags WHERE tag_id IN (SELECT id FROM tags WHERE workspace_id = ?)", id).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.Tag{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.Folder{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.CreditUsage{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.CreditLedgerEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.Credit{}).Error; err != nil {
+		return err
+	}
+
+	// Members and invitations go with the workspace
+	result := tx.Where("id = ?", id).Delete(&models.Workspace{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrWorkspaceNotFound
+	}
+	return nil
+}
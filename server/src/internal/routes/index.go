@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/imraushankr/bervity/server/src/configs"
 	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
@@ -18,19 +19,49 @@ func SetupRoutes(
 	urlHandler *v1.URLHandler,
 	creditHandler *v1.CreditHandler,
 	subHandler *v1.SubscriptionHandler,
+	wellKnownHandler *v1.WellKnownHandler,
+	privacyHandler *v1.PrivacyHandler,
+	webhookHandler *v1.WebhookHandler,
+	planHandler *v1.PlanHandler,
+	promoHandler *v1.PromoCodeHandler,
+	referralHandler *v1.ReferralHandler,
+	invoiceHandler *v1.InvoiceHandler,
+	workspaceHandler *v1.WorkspaceHandler,
+	adminHandler *v1.AdminHandler,
+	auditHandler *v1.AuditHandler,
+	webhookEndpointHandler *v1.WebhookEndpointHandler,
+	exportHandler *v1.ExportHandler,
+	folderHandler *v1.FolderHandler,
+	tagHandler *v1.TagHandler,
 	authService *auth.Auth, 
 	urlRepo interfaces.URLRepository,
+	policy *middleware.VerificationPolicy,
 	cfg *configs.Config, 
 	log logger.Logger,
 ) {
+	routerv1.RegisterWellKnownRoutes(router, wellKnownHandler)
+
 	api := router.Group("/api")
 	{
 		v1Group := api.Group("/v1")
 		routerv1.RegisterAuthRoutes(v1Group, authHandler, authService, cfg, log)
 		routerv1.RegisterUserRoutes(v1Group, userHandler, authService, cfg, log)
-		routerv1.RegisterURLRoutes(v1Group, urlHandler, authService, urlRepo, cfg, log)
-		routerv1.RegisterCreditRoutes(v1Group, creditHandler, authService, cfg, log)
-		routerv1.RegisterSubscriptionRoutes(v1Group, subHandler, authService, cfg, log)
-		routerv1.RegisterSystemRoutes(v1Group, healthHandler)
+		routerv1.RegisterReferralRoutes(v1Group, referralHandler, authService, cfg, log)
+		routerv1.RegisterPrivacyRoutes(v1Group, privacyHandler, authService, cfg, log)
+		routerv1.RegisterURLRoutes(v1Group, urlHandler, authService, urlRepo, policy, cfg, log)
+		routerv1.RegisterCreditRoutes(v1Group, creditHandler, authService, policy, cfg, log)
+		routerv1.RegisterSubscriptionRoutes(v1Group, subHandler, authService, policy, cfg, log)
+		routerv1.RegisterInvoiceRoutes(v1Group, invoiceHandler, authService, cfg, log)
+		routerv1.RegisterWorkspaceRoutes(v1Group, workspaceHandler, urlHandler, creditHandler, subHandler, authService, policy, cfg, log)
+		routerv1.RegisterPlanRoutes(v1Group, planHandler, authService, cfg, log)
+		routerv1.RegisterPromoCodeRoutes(v1Group, promoHandler, authService, cfg, log)
+		routerv1.RegisterAdminRoutes(v1Group, adminHandler, authService, cfg, log)
+		routerv1.RegisterAuditRoutes(v1Group, auditHandler, authService, cfg, log)
+		routerv1.RegisterWebhookRoutes(v1Group, webhookHandler)
+		routerv1.RegisterWebhookEndpointRoutes(v1Group, webhookEndpointHandler, authService, cfg, log)
+		routerv1.RegisterExportRoutes(v1Group, exportHandler, authService, cfg, log)
+		routerv1.RegisterFolderRoutes(v1Group, folderHandler, authService, cfg, log)
+		routerv1.RegisterTagRoutes(v1Group, tagHandler, authService, cfg, log)
+		routerv1.RegisterSystemRoutes(v1Group, healthHandler, authService, cfg, log)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterAdminRoutes(router *gin.RouterGroup, adminHandler *v1.AdminHandler, authService *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	adminRoutes := router.Group("/admin")
+	{
+		adminRoutes.Use(middleware.JWTAuth(authService, cfg, log))
+		adminRoutes.Use(middleware.RoleAuth(models.RoleAdmin))
+
+		adminRoutes.GET("/users", adminHandler.SearchUsers)
+		adminRoutes.PUT("/users/:id/status", adminHandler.SetUserStatus)
+		adminRoutes.PUT("/users/:id/role", adminHandler.SetUserRole)
+		adminRoutes.POST("/users/:id/credits/grants", adminHandler.GrantCredits)
+		adminRoutes.POST("/users/:id/credits/adjustments", adminHandler.AdjustCredits)
+		adminRoutes.PUT("/users/:id/subscription", adminHandler.OverrideSubscription)
+		adminRoutes.POST("/urls/:id/takedown", adminHandler.TakeDownURL)
+		adminRoutes.DELETE("/urls/:id/takedown", adminHandler.RestoreURL)
+	}
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterAuditRoutes(router *gin.RouterGroup, h *v1.AuditHandler, authService *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	own := router.Group("/users/me/audit")
+	{
+		own.Use(middleware.JWTAuth(authService, cfg, log))
+		own.GET("", h.GetMyEvents)
+	}
+
+	all := router.Group("/admin/audit")
+	{
+		all.Use(middleware.JWTAuth(authService, cfg, log))
+		all.Use(middleware.RoleAuth(models.RoleAdmin))
+		all.GET("", h.ListEvents)
+	}
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/imraushankr/bervity/server/src/configs"
 	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
@@ -10,6 +12,11 @@ import (
 )
 
 func RegisterAuthRoutes(r *gin.RouterGroup, h *v1.AuthHandler, auth *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	resendRequests, resendWindow := cfg.Verification.ResendRequests, cfg.Verification.ResendWindow
+	if resendRequests <= 0 || resendWindow <= 0 {
+		resendRequests, resendWindow = 5, time.Hour
+	}
+
 	authGroup := r.Group("/auth")
 	{
 		// Public endpoints
@@ -17,8 +24,18 @@ func RegisterAuthRoutes(r *gin.RouterGroup, h *v1.AuthHandler, auth *auth.Auth,
 		authGroup.POST("/signin", h.Login)
 		authGroup.POST("/signout", h.Logout)
 		authGroup.GET("/verify-email", h.VerifyEmail)
+		authGroup.POST("/resend-verification",
+			middleware.RateLimit(resendRequests, resendWindow),
+			h.ResendVerification,
+		)
 		authGroup.POST("/forgot-password", h.InitiatePasswordReset)
 		authGroup.PATCH("/reset-password/:token", h.CompletePasswordReset)
+		authGroup.POST("/magic-link",
+			middleware.RateLimit(resendRequests, resendWindow),
+			h.RequestMagicLink,
+		)
+		authGroup.GET("/magic-link/verify", h.ConfirmMagicLink)
+		authGroup.POST("/magic-link/verify", h.ConsumeMagicLink)
 
 		// Protected endpoints
 		protected := authGroup.Group("")
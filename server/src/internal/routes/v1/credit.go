@@ -9,13 +9,14 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
 )
 
-func RegisterCreditRoutes(router *gin.RouterGroup, creditHandler *v1.CreditHandler, authService *auth.Auth, cfg *configs.Config, log logger.Logger) {
+func RegisterCreditRoutes(router *gin.RouterGroup, creditHandler *v1.CreditHandler, authService *auth.Auth, policy *middleware.VerificationPolicy, cfg *configs.Config, log logger.Logger) {
 	creditRoutes := router.Group("/credits")
 	{
 		creditRoutes.Use(middleware.JWTAuth(authService, cfg, log))
 
 		creditRoutes.GET("/balance", creditHandler.GetBalance)
-		creditRoutes.POST("/apply-promo", creditHandler.ApplyPromoCode)
+		creditRoutes.POST("/apply-promo", policy.Require(middleware.ActionPromoCode), creditHandler.ApplyPromoCode)
 		creditRoutes.GET("/usage", creditHandler.GetUsage)
+		creditRoutes.GET("/ledger", creditHandler.GetLedger)
 	}
 }
\ No newline at end of file
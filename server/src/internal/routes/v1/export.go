@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// RegisterExportRoutes sets up the link and click exports, streamed on
+// request or delivered on a schedule.
+func RegisterExportRoutes(
+	router *gin.RouterGroup,
+	h *v1.ExportHandler,
+	authService *auth.Auth,
+	cfg *configs.Config,
+	log logger.Logger,
+) {
+	urls := router.Group("/urls")
+	{
+		urls.Use(middleware.JWTAuth(authService, cfg, log))
+
+		urls.GET("/export", h.ExportLinks)
+		urls.GET("/:id/analytics/export", h.ExportClicks)
+	}
+
+	schedules := router.Group("/exports/schedules")
+	{
+		schedules.Use(middleware.JWTAuth(authService, cfg, log))
+
+		schedules.POST("", h.CreateSchedule)
+		schedules.GET("", h.ListSchedules)
+		schedules.DELETE("/:id", h.DeleteSchedule)
+	}
+}
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// RegisterFolderRoutes sets up folders and moving links between them
+func RegisterFolderRoutes(
+	router *gin.RouterGroup,
+	h *v1.FolderHandler,
+	authService *auth.Auth,
+	cfg *configs.Config,
+	log logger.Logger,
+) {
+	folders := router.Group("/folders")
+	{
+		folders.Use(middleware.JWTAuth(authService, cfg, log))
+
+		folders.POST("", h.CreateFolder)
+		folders.GET("", h.ListFolders)
+		folders.PUT("/:id", h.UpdateFolder)
+		folders.DELETE("/:id", h.DeleteFolder)
+	}
+
+	urls := router.Group("/urls")
+	{
+		urls.Use(middleware.JWTAuth(authService, cfg, log))
+
+		urls.PUT("/:id/folder", h.MoveURL)
+	}
+}
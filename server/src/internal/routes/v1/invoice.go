@@ -0,0 +1,20 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterInvoiceRoutes(r *gin.RouterGroup, h *v1.InvoiceHandler, auth *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	invoices := r.Group("/subscriptions/invoices")
+	invoices.Use(middleware.JWTAuth(auth, cfg, log))
+	{
+		invoices.GET("", h.ListInvoices)
+		invoices.GET("/:id", h.GetInvoice)
+		invoices.GET("/:id/pdf", h.DownloadInvoice)
+	}
+}
@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterPlanRoutes(router *gin.RouterGroup, planHandler *v1.PlanHandler, authService *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	planRoutes := router.Group("/admin/plans")
+	{
+		planRoutes.Use(middleware.JWTAuth(authService, cfg, log))
+		planRoutes.Use(middleware.RoleAuth(models.RoleAdmin))
+
+		planRoutes.GET("", planHandler.ListPlans)
+		planRoutes.POST("", planHandler.CreatePlan)
+		planRoutes.GET("/:code", planHandler.GetPlanVersions)
+		planRoutes.PUT("/:code", planHandler.UpdatePlan)
+		planRoutes.DELETE("/:code", planHandler.ArchivePlan)
+	}
+}
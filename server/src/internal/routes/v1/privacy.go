@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterPrivacyRoutes(r *gin.RouterGroup, h *v1.PrivacyHandler, auth *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	me := r.Group("/users/me")
+	me.Use(middleware.JWTAuth(auth, cfg, log))
+	{
+		// Personal data export
+		me.POST("/export", h.RequestExport)
+		me.GET("/export/:id", h.GetExport)
+
+		// Account management
+		me.DELETE("", h.DeleteAccount)
+	}
+}
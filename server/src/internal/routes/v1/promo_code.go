@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterPromoCodeRoutes(router *gin.RouterGroup, promoHandler *v1.PromoCodeHandler, authService *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	promoRoutes := router.Group("/admin/promo-codes")
+	{
+		promoRoutes.Use(middleware.JWTAuth(authService, cfg, log))
+		promoRoutes.Use(middleware.RoleAuth(models.RoleAdmin))
+
+		promoRoutes.GET("", promoHandler.ListPromoCodes)
+		promoRoutes.POST("", promoHandler.CreatePromoCode)
+		promoRoutes.GET("/:id", promoHandler.GetPromoCode)
+		promoRoutes.GET("/:id/redemptions", promoHandler.GetRedemptions)
+		promoRoutes.PUT("/:id", promoHandler.UpdatePromoCode)
+		promoRoutes.DELETE("/:id", promoHandler.DeletePromoCode)
+	}
+}
@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterReferralRoutes(r *gin.RouterGroup, h *v1.ReferralHandler, auth *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	referrals := r.Group("/users/me/referrals")
+	referrals.Use(middleware.JWTAuth(auth, cfg, log))
+	{
+		referrals.GET("", h.GetReferrals)
+	}
+}
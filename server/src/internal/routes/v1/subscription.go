@@ -9,16 +9,20 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
 )
 
-func RegisterSubscriptionRoutes(router *gin.RouterGroup, subHandler *v1.SubscriptionHandler, authService *auth.Auth, cfg *configs.Config, log logger.Logger) {
+func RegisterSubscriptionRoutes(router *gin.RouterGroup, subHandler *v1.SubscriptionHandler, authService *auth.Auth, policy *middleware.VerificationPolicy, cfg *configs.Config, log logger.Logger) {
 	subRoutes := router.Group("/subscriptions")
 	{
 		subRoutes.Use(middleware.JWTAuth(authService, cfg, log))
 
-		subRoutes.POST("", subHandler.CreateSubscription)
+		subRoutes.POST("", policy.Require(middleware.ActionSubscription), subHandler.CreateSubscription)
 		subRoutes.GET("", subHandler.GetSubscription)
 		subRoutes.PUT("", subHandler.UpdateSubscription)
+		subRoutes.GET("/preview-change", subHandler.PreviewPlanChange)
 		subRoutes.DELETE("", subHandler.CancelSubscription)
 		subRoutes.GET("/plans", subHandler.GetPlans)
 		subRoutes.GET("/payments", subHandler.GetPaymentHistory)
+		subRoutes.POST("/trial", policy.Require(middleware.ActionSubscription), subHandler.StartTrial)
+		subRoutes.GET("/trial", subHandler.GetTrial)
+		subRoutes.PUT("/trial/payment-method", subHandler.SetTrialPaymentMethod)
 	}
 }
\ No newline at end of file
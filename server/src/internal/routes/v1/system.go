@@ -2,22 +2,32 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
 	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
 	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
 )
 
-func RegisterSystemRoutes(r *gin.RouterGroup, h *v1.HealthHandler) {
+func RegisterSystemRoutes(r *gin.RouterGroup, h *v1.HealthHandler, authService *auth.Auth, cfg *configs.Config, log logger.Logger) {
 	sys := r.Group("/system")
 	{
 		// Health checks
 		sys.GET("/health", h.HealthCheck)
-		sys.GET("/status", h.GetStatus)
 
 		// Metrics and monitoring
 		sys.GET("/metrics", middleware.PrometheusMetricsMiddleware(), middleware.PrometheusHandler())
 		sys.GET("/stats", h.GetStatistics)
 
-		// Configuration (protected)
-		sys.GET("/config", h.GetConfig)
+		// Status and configuration (admins only)
+		admin := sys.Group("")
+		admin.Use(middleware.JWTAuth(authService, cfg, log))
+		admin.Use(middleware.RoleAuth(models.RoleAdmin))
+		{
+			admin.GET("/status", h.GetStatus)
+			admin.GET("/config", h.GetConfig)
+			admin.GET("/config/diff", h.GetConfigDiff)
+		}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// RegisterTagRoutes sets up tags and tagging links
+func RegisterTagRoutes(
+	router *gin.RouterGroup,
+	h *v1.TagHandler,
+	authService *auth.Auth,
+	cfg *configs.Config,
+	log logger.Logger,
+) {
+	tags := router.Group("/tags")
+	{
+		tags.Use(middleware.JWTAuth(authService, cfg, log))
+
+		tags.GET("", h.ListTags)
+		tags.DELETE("/:id", h.DeleteTag)
+	}
+
+	urls := router.Group("/urls")
+	{
+		urls.Use(middleware.JWTAuth(authService, cfg, log))
+
+		urls.PUT("/:id/tags", h.SetURLTags)
+	}
+}
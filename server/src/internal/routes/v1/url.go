@@ -15,12 +15,15 @@ func RegisterURLRoutes(
 	urlHandler *v1.URLHandler,
 	authService *auth.Auth,
 	urlRepo interfaces.URLRepository,
+	policy *middleware.VerificationPolicy,
 	cfg *configs.Config,
 	log logger.Logger,
 ) {
 	// Public routes (no auth required)
 	router.POST("/urls",
+		middleware.OptionalJWTAuth(authService, cfg, log),
 		middleware.AnonymousURLLimit(urlRepo, log, cfg.App.AnonURLLimit),
+		policy.Require(middleware.ActionCreateURL),
 		urlHandler.CreateURL,
 	)
 	// Add the API prefix to the redirect route
@@ -31,9 +34,13 @@ func RegisterURLRoutes(
 	authRoutes.Use(middleware.JWTAuth(authService, cfg, log))
 	{
 		authRoutes.GET("", urlHandler.GetUserURLs)
+		authRoutes.POST("/bulk", policy.Require(middleware.ActionBulkURL), urlHandler.CreateURLs)
+		authRoutes.GET("/bulk/:id", urlHandler.GetImport)
 		authRoutes.GET("/:id", urlHandler.GetURL)
-		authRoutes.PUT("/:id", urlHandler.UpdateURL)
+		authRoutes.PUT("/:id", policy.Require(middleware.ActionCustomCode), urlHandler.UpdateURL)
 		authRoutes.DELETE("/:id", urlHandler.DeleteURL)
 		authRoutes.GET("/:id/analytics", urlHandler.GetAnalytics)
+		authRoutes.GET("/:id/revisions", urlHandler.GetRevisions)
+		authRoutes.POST("/:id/revisions/:number/restore", policy.Require(middleware.ActionCustomCode), urlHandler.RestoreRevision)
 	}
 }
\ No newline at end of file
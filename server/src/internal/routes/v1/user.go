@@ -10,17 +10,19 @@ import (
 )
 
 func RegisterUserRoutes(r *gin.RouterGroup, h *v1.UserHandler, auth *auth.Auth, cfg *configs.Config, log logger.Logger) {
+	// Public endpoints (opened from email links)
+	r.GET("/users/email/confirm", h.ShowEmailChange)
+	r.POST("/users/email/confirm", h.ConfirmEmailChange)
+
 	users := r.Group("/users")
 	users.Use(middleware.JWTAuth(auth, cfg, log))
 	{
 		// Profile management
 		users.GET("/me", h.GetProfile)
 		users.PUT("/me", h.UpdateProfile)
+		users.PUT("/me/email", h.RequestEmailChange)
 
 		// Avatar
 		users.POST("/avatar", h.UploadAvatar)
-
-		// Account management
-		users.DELETE("/me", h.DeleteAccount)
 	}
 }
@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+)
+
+func RegisterWebhookRoutes(r *gin.RouterGroup, h *v1.WebhookHandler) {
+	webhooks := r.Group("/webhooks")
+	{
+		// Authenticated by the provider signature, not a JWT
+		webhooks.POST("/payments", h.HandlePayment)
+	}
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// RegisterWebhookEndpointRoutes sets up the endpoints users register to
+// receive events from us.
+func RegisterWebhookEndpointRoutes(
+	router *gin.RouterGroup,
+	h *v1.WebhookEndpointHandler,
+	authService *auth.Auth,
+	cfg *configs.Config,
+	log logger.Logger,
+) {
+	endpoints := router.Group("/webhooks/endpoints")
+	{
+		endpoints.Use(middleware.JWTAuth(authService, cfg, log))
+
+		endpoints.POST("", h.CreateEndpoint)
+		endpoints.GET("", h.ListEndpoints)
+		endpoints.GET("/:id", h.GetEndpoint)
+		endpoints.PUT("/:id", h.UpdateEndpoint)
+		endpoints.DELETE("/:id", h.DeleteEndpoint)
+		endpoints.POST("/:id/rotate-secret", h.RotateSecret)
+		endpoints.GET("/:id/deliveries", h.ListDeliveries)
+		endpoints.POST("/:id/deliveries/:deliveryId/redeliver", h.Redeliver)
+	}
+}
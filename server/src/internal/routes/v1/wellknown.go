@@ -0,0 +1,13 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+)
+
+func RegisterWellKnownRoutes(r *gin.Engine, h *v1.WellKnownHandler) {
+	wellKnown := r.Group("/.well-known")
+	{
+		wellKnown.GET("/jwks.json", h.JWKS)
+	}
+}
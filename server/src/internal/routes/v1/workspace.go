@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/imraushankr/bervity/server/src/configs"
+	v1 "github.com/imraushankr/bervity/server/src/internal/handlers/v1"
+	"github.com/imraushankr/bervity/server/src/internal/middleware"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+func RegisterWorkspaceRoutes(
+	router *gin.RouterGroup,
+	workspaceHandler *v1.WorkspaceHandler,
+	urlHandler *v1.URLHandler,
+	creditHandler *v1.CreditHandler,
+	subHandler *v1.SubscriptionHandler,
+	authService *auth.Auth,
+	policy *middleware.VerificationPolicy,
+	cfg *configs.Config,
+	log logger.Logger,
+) {
+	workspaceRoutes := router.Group("/workspaces")
+	{
+		workspaceRoutes.Use(middleware.JWTAuth(authService, cfg, log))
+
+		workspaceRoutes.POST("", workspaceHandler.CreateWorkspace)
+		workspaceRoutes.GET("", workspaceHandler.ListWorkspaces)
+		workspaceRoutes.POST("/invitations/accept", workspaceHandler.AcceptInvitation)
+		workspaceRoutes.GET("/:id", workspaceHandler.GetWorkspace)
+		workspaceRoutes.PUT("/:id", workspaceHandler.UpdateWorkspace)
+		workspaceRoutes.DELETE("/:id", workspaceHandler.DeleteWorkspace)
+
+		workspaceRoutes.GET("/:id/members", workspaceHandler.ListMembers)
+		workspaceRoutes.PUT("/:id/members/:userId", workspaceHandler.UpdateMemberRole)
+		workspaceRoutes.DELETE("/:id/members/:userId", workspaceHandler.RemoveMember)
+		workspaceRoutes.POST("/:id/invitations", workspaceHandler.InviteMember)
+		workspaceRoutes.GET("/:id/invitations", workspaceHandler.ListInvitations)
+		workspaceRoutes.DELETE("/:id/invitations/:invitationId", workspaceHandler.RevokeInvitation)
+
+		workspaceRoutes.GET("/:id/urls", urlHandler.GetWorkspaceURLs)
+		workspaceRoutes.GET("/:id/credits", creditHandler.GetWorkspaceBalance)
+		workspaceRoutes.GET("/:id/credits/ledger", creditHandler.GetWorkspaceLedger)
+
+		workspaceRoutes.POST("/:id/subscription", policy.Require(middleware.ActionSubscription), subHandler.CreateWorkspaceSubscription)
+		workspaceRoutes.GET("/:id/subscription", subHandler.GetWorkspaceSubscription)
+		workspaceRoutes.PUT("/:id/subscription", subHandler.UpdateWorkspaceSubscription)
+		workspaceRoutes.GET("/:id/subscription/preview-change", subHandler.PreviewWorkspacePlanChange)
+		workspaceRoutes.DELETE("/:id/subscription", subHandler.CancelWorkspaceSubscription)
+	}
+}
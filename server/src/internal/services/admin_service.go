@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+type adminService struct {
+	adminRepo  interfaces.AdminRepository
+	planRepo   interfaces.PlanRepository
+	creditRepo interfaces.CreditRepository
+	baseURL    string
+	log        logger.Logger
+}
+
+func NewAdminService(
+	adminRepo interfaces.AdminRepository,
+	planRepo interfaces.PlanRepository,
+	creditRepo interfaces.CreditRepository,
+	baseURL string,
+	log logger.Logger,
+) interfaces.AdminService {
+	return &adminService{
+		adminRepo:  adminRepo,
+		planRepo:   planRepo,
+		creditRepo: creditRepo,
+		baseURL:    baseURL,
+		log:        log,
+	}
+}
+
+func (s *adminService) SearchUsers(ctx context.Context, filter *models.UserSearchFilter) ([]*models.User, *pagination.Page, error) {
+	return s.adminRepo.SearchUsers(ctx, filter)
+}
+
+// SetUserStatus suspends or reactivates an account. Suspended users can't sign
+// in, and their tokens stop working at once.
+func (s *adminService) SetUserStatus(ctx context.Context, adminID, userID string, req *models.SetUserStatusRequest) (*models.User, error) {
+	if userID == adminID {
+		return nil, models.ErrCannotModifySelf
+	}
+
+	action := models.AdminSuspendUser
+	if req.Active {
+		action = models.AdminReactivateUser
+	}
+	user, err := s.adminRepo.SetUserSuspended(ctx, userID, !req.Active, adminEvent(ctx, adminID, action, models.AuditTargetUser, userID, req.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("User status changed by admin",
+		logger.String("admin_id", adminID),
+		logger.String("user_id", userID),
+		logger.String("action", string(action)))
+	return user, nil
+}
+
+// SetUserRole changes a user's role. It takes effect on the user's next
+// request, since the stored role is checked rather than the token's.
+func (s *adminService) SetUserRole(ctx context.Context, adminID, userID string, req *models.SetUserRoleRequest) (*models.User, error) {
+	if userID == adminID {
+		return nil, models.ErrCannotModifySelf
+	}
+
+	user, err := s.adminRepo.SetUserRole(ctx, userID, req.Role, adminEvent(ctx, adminID, models.AdminChangeRole, models.AuditTargetUser, userID, req.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("User role changed by admin",
+		logger.String("admin_id", adminID),
+		logger.String("user_id", userID),
+		logger.String("role", string(req.Role)))
+	return user, nil
+}
+
+func (s *adminService) GrantCredits(ctx context.Context, adminID, userID string, req *models.GrantCreditsRequest) (*models.CreditBalanceResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, models.ErrInvalidInput
+	}
+
+	creditType := req.Type
+	if creditType == "" {
+		creditType = models.CreditTypePromo
+	}
+	credit := &models.Credit{
+		UserID:      userID,
+		Type:        creditType,
+		Amount:      req.Amount,
+		ExpiresAt:   req.ExpiresAt,
+		Description: req.Reason,
+	}
+	if err := s.adminRepo.GrantCredits(ctx, credit, adminEvent(ctx, adminID, models.AdminGrantCredits, models.AuditTargetUser, userID, req.Reason)); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Credits granted by admin",
+		logger.String("admin_id", adminID),
+		logger.String("user_id", userID),
+		logger.Int("amount", req.Amount))
+	return s.creditRepo.GetUserCreditBalance(ctx, userID)
+}
+
+// AdjustCredits corrects a user's balance. Added credits never expire;
+// removed credits are debited like spending, soonest-expiring first.
+func (s *adminService) AdjustCredits(ctx context.Context, adminID, userID string, req *models.AdjustCreditsRequest) (*models.CreditBalanceResponse, error) {
+	event := adminEvent(ctx, adminID, models.AdminAdjustCredits, models.AuditTargetUser, userID, req.Reason)
+
+	var err error
+	if req.Amount > 0 {
+		err = s.adminRepo.GrantCredits(ctx, &models.Credit{
+			UserID:      userID,
+			Type:        models.CreditTypePromo,
+			Amount:      req.Amount,
+			Description: req.Reason,
+		}, event)
+	} else {
+		err = s.adminRepo.DeductCredits(ctx, userID, -req.Amount, event)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Credits adjusted by admin",
+		logger.String("admin_id", adminID),
+		logger.String("user_id", userID),
+		logger.Int("amount", req.Amount))
+	return s.creditRepo.GetUserCreditBalance(ctx, userID)
+}
+
+// OverrideSubscription puts a user on a plan without charging them. The
+// payment provider isn't contacted.
+func (s *adminService) OverrideSubscription(ctx context.Context, adminID, userID string, req *models.OverrideSubscriptionRequest) (*models.Subscription, error) {
+	if !req.ExpiresAt.After(time.Now()) {
+		return nil, models.ErrInvalidInput
+	}
+
+	code := normalizePlanCode(req.Plan)
+	if code == models.PlanFree {
+		return nil, models.ErrInvalidPlan
+	}
+	plan, err := s.planRepo.GetLatest(ctx, code)
+	if errors.Is(err, models.ErrPlanNotFound) {
+		return nil, models.ErrInvalidPlan
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.adminRepo.OverrideSubscription(ctx, userID, plan, req.ExpiresAt, adminEvent(ctx, adminID, models.AdminOverrideSubscription, models.AuditTargetUser, userID, req.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Subscription overridden by admin",
+		logger.String("admin_id", adminID),
+		logger.String("user_id", userID),
+		logger.String("plan", string(code)))
+	return sub, nil
+}
+
+func (s *adminService) TakeDownURL(ctx context.Context, adminID, urlID string, req *models.TakeDownURLRequest) (*models.URLResponse, error) {
+	url, err := s.adminRepo.TakeDownURL(ctx, urlID, adminEvent(ctx, adminID, models.AdminTakeDownURL, models.AuditTargetURL, urlID, req.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("URL taken down by admin",
+		logger.String("admin_id", adminID),
+		logger.String("url_id", urlID))
+	return url.ToResponse(s.baseURL), nil
+}
+
+func (s *adminService) RestoreURL(ctx context.Context, adminID, urlID string, req *models.RestoreURLRequest) (*models.URLResponse, error) {
+	url, err := s.adminRepo.RestoreURL(ctx, urlID, adminEvent(ctx, adminID, models.AdminRestoreURL, models.AuditTargetURL, urlID, req.Reason))
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("URL restored by admin",
+		logger.String("admin_id", adminID),
+		logger.String("url_id", urlID))
+	return url.ToResponse(s.baseURL), nil
+}
+
+// adminEvent starts the audit event for an admin action
+func adminEvent(ctx context.Context, adminID string, action models.AuditAction, targetType, targetID, reason string) *models.AuditEvent {
+	event := models.NewAuditEvent(ctx, models.AuditActorAdmin, adminID, action, targetType, targetID)
+	event.Reason = reason
+	return event
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+)
+
+type auditService struct {
+	repo interfaces.AuditRepository
+	cfg  *configs.AuditConfig
+	log  logger.Logger
+}
+
+func NewAuditService(repo interfaces.AuditRepository, cfg *configs.AuditConfig, log logger.Logger) interfaces.AuditService {
+	return &auditService{
+		repo: repo,
+		cfg:  cfg,
+		log:  log,
+	}
+}
+
+func (s *auditService) Record(ctx context.Context, event *models.AuditEvent) {
+	if err := s.repo.Create(ctx, event); err != nil {
+		s.log.Error("Failed to record audit event",
+			logger.ErrorField(err),
+			logger.String("action", string(event.Action)),
+			logger.String("actor_id", event.ActorID),
+			logger.String("target_id", event.TargetID))
+	}
+}
+
+// ListUserEvents returns the events a user performed or that target their
+// account. For actions taken by an admin, the admin and where they acted
+// from are left out.
+func (s *auditService) ListUserEvents(ctx context.Context, userID string, filter *models.AuditEventFilter) ([]*models.AuditEvent, *pagination.Page, error) {
+	filter.Subject = userID
+
+	events, page, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, event := range events {
+		if event.ActorType == models.AuditActorAdmin && event.ActorID != userID {
+			event.ActorID = ""
+			event.IPAddress = ""
+			event.UserAgent = ""
+			event.RequestID = ""
+		}
+	}
+	return events, page, nil
+}
+
+func (s *auditService) ListEvents(ctx context.Context, filter *models.AuditEventFilter) ([]*models.AuditEvent, *pagination.Page, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// PurgeExpired deletes events older than their retention. Admin actions are
+// kept for the admin retention instead.
+func (s *auditService) PurgeExpired(ctx context.Context) error {
+	now := time.Now()
+	for _, admin := range []bool{false, true} {
+		retention := s.cfg.Retention
+		if admin {
+			retention = s.cfg.AdminRetention
+		}
+		if retention <= 0 {
+			continue
+		}
+
+		deleted, err := s.repo.DeleteBefore(ctx, now.Add(-retention), admin)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			s.log.Info("Purged expired audit events",
+				logger.Int("count", int(deleted)),
+				logger.Any("admin", admin))
+		}
+	}
+	return nil
+}
+
+// RunRetentionWorker calls PurgeExpired every purge interval until ctx is done.
+func (s *auditService) RunRetentionWorker(ctx context.Context) {
+	interval := s.cfg.PurgeInterval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeExpired(ctx); err != nil {
+			s.log.Error("Audit retention run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// userEvent starts the audit event for something userID did. Without a user
+// the actor is anonymous.
+func userEvent(ctx context.Context, userID string, action models.AuditAction, targetType, targetID string) *models.AuditEvent {
+	actorType := models.AuditActorUser
+	if userID == "" {
+		actorType = models.AuditActorAnonymous
+	}
+	return models.NewAuditEvent(ctx, actorType, userID, action, targetType, targetID)
+}
+
+// creditGrantEvent starts the audit event for credit given to a user or
+// workspace outside the admin API.
+func creditGrantEvent(ctx context.Context, actorID string, credit *models.Credit) *models.AuditEvent {
+	targetType, targetID := models.AuditTargetUser, credit.UserID
+	if credit.WorkspaceID != nil {
+		targetType, targetID = models.AuditTargetWorkspace, *credit.WorkspaceID
+	}
+	event := userEvent(ctx, actorID, models.AuditCreditGrant, targetType, targetID)
+	event.SetChanges(nil, map[string]interface{}{
+		"credit_id":  credit.ID,
+		"type":       credit.Type,
+		"amount":     credit.Amount,
+		"expires_at": credit.ExpiresAt,
+	})
+	return event
+}
@@ -14,31 +14,41 @@ import (
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
 )
 
+const verificationTokenTTL = 24 * time.Hour
+
 type authService struct {
-	repo  interfaces.AuthRepository
-	auth  *auth.Auth
-	email *email.EmailService
-	cfg   *configs.Config
-	log   logger.Logger
+	repo      interfaces.AuthRepository
+	auth      *auth.Auth
+	email     *email.EmailService
+	referrals interfaces.ReferralService
+	audit     interfaces.AuditService
+	cfg       *configs.Config
+	log       logger.Logger
 }
 
 func NewAuthService(
 	repo interfaces.AuthRepository,
 	auth *auth.Auth,
 	email *email.EmailService,
+	referrals interfaces.ReferralService,
+	audit interfaces.AuditService,
 	cfg *configs.Config,
 	log logger.Logger,
 ) interfaces.AuthService {
 	return &authService{
-		repo:  repo,
-		auth:  auth,
-		email: email,
-		cfg:   cfg,
-		log:   log,
+		repo:      repo,
+		auth:      auth,
+		email:     email,
+		referrals: referrals,
+		audit:     audit,
+		cfg:       cfg,
+		log:       log,
 	}
 }
 
-func (s *authService) Register(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
+// Register creates an unverified account. ip is the address the signup came
+// from, used to catch referrals to oneself.
+func (s *authService) Register(ctx context.Context, req *models.RegisterRequest, ip string) (*models.User, error) {
 	existingUser, err := s.repo.FindUserByIdentifier(ctx, req.Email)
 	if err == nil && existingUser != nil {
 		return nil, models.ErrEmailAlreadyExists
@@ -67,19 +77,15 @@ func (s *authService) Register(ctx context.Context, req *models.RegisterRequest)
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	token, err := s.auth.GenerateVerificationToken(user.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	// A referral problem shouldn't cost the user their signup
+	if err := s.referrals.RecordSignup(ctx, user, req.ReferralCode, ip); err != nil {
+		s.log.Error("Failed to record referral",
+			logger.ErrorField(err),
+			logger.String("user_id", user.ID))
 	}
 
-	expiresAt := time.Now().Add(24 * time.Hour)
-	if err := s.repo.SaveVerificationToken(ctx, user.Email, token, expiresAt); err != nil {
-		return nil, fmt.Errorf("failed to save verification token: %w", err)
-	}
-
-	verificationLink := fmt.Sprintf("%s/api/v1/auth/verify-email?token=%s", s.cfg.App.BaseURL, token)
-	if err := s.email.SendVerificationEmail(user.Email, verificationLink); err != nil {
-		return nil, fmt.Errorf("failed to send verification email: %w", err)
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		return nil, err
 	}
 
 	return user, nil
@@ -89,29 +95,43 @@ func (s *authService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	user, err := s.repo.FindUserByIdentifier(ctx, req.UserID)
 	if err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
+			s.recordLogin(ctx, "", loginPassword, models.ErrUserNotFound)
 			return nil, models.ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
-	if !user.IsVerified {
+	if !user.IsVerified && !s.cfg.Verification.AllowUnverifiedLogin {
+		s.recordLogin(ctx, user.ID, loginPassword, models.ErrUserNotVerified)
 		return nil, models.ErrUserNotVerified
 	}
 
 	if err := auth.IsPasswordCorrect(user.Password, req.Password); err != nil {
+		s.recordLogin(ctx, user.ID, loginPassword, models.ErrInvalidCredentials)
 		return nil, models.ErrInvalidCredentials
 	}
 
+	if user.SuspendedAt != nil {
+		s.recordLogin(ctx, user.ID, loginPassword, models.ErrAccountSuspended)
+		return nil, models.ErrAccountSuspended
+	}
+
+	if err := s.restorePendingDeletion(ctx, user); err != nil {
+		return nil, err
+	}
+
 	tokens, err := s.auth.GenerateTokens(user.ID, string(user.Role))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
+	s.recordLogin(ctx, user.ID, loginPassword, nil)
 
 	return &models.LoginResponse{
-		User:        *user,
-		AccessToken: tokens.AccessToken,
-		TokenType:   "Bearer",
-		ExpiresIn:   int(s.cfg.JWT.AccessTokenExpiry.Seconds()),
+		User:         *user,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.cfg.JWT.AccessTokenExpiry.Seconds()),
 	}, nil
 }
 
@@ -121,12 +141,84 @@ func (s *authService) Logout(ctx context.Context, userID string) error {
 }
 
 func (s *authService) VerifyEmail(ctx context.Context, token string) error {
-	if err := s.repo.VerifyUser(ctx, token); err != nil {
+	user, err := s.repo.VerifyUser(ctx, token)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidVerificationToken) {
+			return models.ErrInvalidVerificationToken
+		}
 		return fmt.Errorf("failed to verify email: %w", err)
 	}
+
+	s.completeReferral(ctx, user.ID)
+	return nil
+}
+
+// completeReferral pays out the user's referral now that their email is
+// verified. Failures are logged rather than failing the verification.
+func (s *authService) completeReferral(ctx context.Context, userID string) {
+	if err := s.referrals.CompleteReferral(ctx, userID); err != nil {
+		s.log.Error("Failed to complete referral",
+			logger.ErrorField(err),
+			logger.String("user_id", userID))
+	}
+}
+
+// ResendVerification issues a fresh verification link. It stays silent for
+// unknown or already verified addresses so it cannot be used to probe accounts.
+func (s *authService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.repo.FindUserByIdentifier(ctx, email)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if user.IsVerified {
+		return nil
+	}
+
+	if user.VerificationExpiresAt != nil && user.VerificationPurpose != models.VerificationPurposeEmailChange {
+		issuedAt := user.VerificationExpiresAt.Add(-verificationTokenTTL)
+		if time.Since(issuedAt) < s.resendCooldown() {
+			s.log.Debug("Verification resend throttled", logger.String("user_id", user.ID))
+			return nil
+		}
+	}
+
+	err = s.sendVerificationEmail(ctx, user)
+	if errors.Is(err, models.ErrEmailChangePending) {
+		s.log.Debug("Verification resend skipped for pending email change", logger.String("user_id", user.ID))
+		return nil
+	}
+	return err
+}
+
+func (s *authService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := s.auth.GenerateVerificationToken(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(verificationTokenTTL)
+	if err := s.repo.SaveVerificationToken(ctx, user.Email, token, expiresAt); err != nil {
+		return fmt.Errorf("failed to save verification token: %w", err)
+	}
+
+	verificationLink := fmt.Sprintf("%s/api/v1/auth/verify-email?token=%s", s.cfg.App.BaseURL, token)
+	if err := s.email.SendVerificationEmail(user.Email, verificationLink); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
 	return nil
 }
 
+func (s *authService) resendCooldown() time.Duration {
+	if s.cfg.Verification.ResendCooldown > 0 {
+		return s.cfg.Verification.ResendCooldown
+	}
+	return time.Minute
+}
+
 func (s *authService) InitiatePasswordReset(ctx context.Context, email string) error {
 	user, err := s.repo.FindUserByIdentifier(ctx, email)
 	if err != nil {
@@ -156,9 +248,128 @@ func (s *authService) CompletePasswordReset(ctx context.Context, token, newPassw
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	if err := s.repo.ResetPassword(ctx, token, hashedPassword); err != nil {
+	userID, err := s.repo.ResetPassword(ctx, token, hashedPassword)
+	if err != nil {
 		return fmt.Errorf("failed to reset password: %w", err)
 	}
+
+	s.audit.Record(ctx, userEvent(ctx, userID, models.AuditPasswordReset, models.AuditTargetUser, userID))
+	return nil
+}
+
+func (s *authService) RequestMagicLink(ctx context.Context, email, ip string) error {
+	user, err := s.repo.FindUserByIdentifier(ctx, email)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil // Don't reveal if user exists
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if !user.IsActive {
+		return nil
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate magic link token: %w", err)
+	}
+
+	expiry := s.magicLinkExpiry()
+	if err := s.repo.CreateMagicLinkToken(ctx, &models.MagicLinkToken{
+		UserID:      user.ID,
+		TokenHash:   auth.HashToken(token),
+		RequestedIP: ip,
+		ExpiresAt:   time.Now().Add(expiry),
+	}); err != nil {
+		return fmt.Errorf("failed to save magic link token: %w", err)
+	}
+
+	magicLink := fmt.Sprintf("%s/api/v1/auth/magic-link/verify?token=%s", s.cfg.App.BaseURL, token)
+	return s.email.SendMagicLinkEmail(user.Email, magicLink, expiry)
+}
+
+func (s *authService) LoginWithMagicLink(ctx context.Context, token string) (*models.LoginResponse, error) {
+	if token == "" {
+		return nil, models.ErrInvalidMagicLink
+	}
+
+	user, err := s.repo.ConsumeMagicLinkToken(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidMagicLink) || errors.Is(err, models.ErrUserNotFound) {
+			return nil, models.ErrInvalidMagicLink
+		}
+		return nil, fmt.Errorf("failed to consume magic link: %w", err)
+	}
+
+	if user.SuspendedAt != nil {
+		s.recordLogin(ctx, user.ID, loginMagicLink, models.ErrAccountSuspended)
+		return nil, models.ErrAccountSuspended
+	}
+
+	// Using the link verifies the email address
+	s.completeReferral(ctx, user.ID)
+
+	if err := s.restorePendingDeletion(ctx, user); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.auth.GenerateTokens(user.ID, string(user.Role))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+	s.recordLogin(ctx, user.ID, loginMagicLink, nil)
+
+	return &models.LoginResponse{
+		User:         *user,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.cfg.JWT.AccessTokenExpiry.Seconds()),
+	}, nil
+}
+
+// Sign-in methods recorded with login events
+const (
+	loginPassword  = "password"
+	loginMagicLink = "magic_link"
+)
+
+// recordLogin adds a sign-in attempt to the audit log. userID is the account
+// signed in to, when known; failure says why an attempt was refused. Failed
+// attempts have no actor since the caller hasn't proven who they are.
+func (s *authService) recordLogin(ctx context.Context, userID, method string, failure error) {
+	var event *models.AuditEvent
+	if failure == nil {
+		event = userEvent(ctx, userID, models.AuditLogin, models.AuditTargetUser, userID)
+	} else {
+		event = userEvent(ctx, "", models.AuditLoginFailed, models.AuditTargetUser, userID)
+		event.Reason = failure.Error()
+	}
+	event.SetMetadata(map[string]string{"method": method})
+	s.audit.Record(ctx, event)
+}
+
+func (s *authService) magicLinkExpiry() time.Duration {
+	if s.cfg.JWT.MagicLinkExpiry > 0 {
+		return s.cfg.JWT.MagicLinkExpiry
+	}
+	return 15 * time.Minute
+}
+
+// restorePendingDeletion cancels a scheduled account deletion when its owner
+// signs in during the grace period.
+func (s *authService) restorePendingDeletion(ctx context.Context, user *models.User) error {
+	if user.DeletionScheduledAt == nil {
+		return nil
+	}
+	if err := s.repo.RestoreUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to restore account: %w", err)
+	}
+	user.IsActive = true
+	user.DeletedAt = nil
+	user.DeletionScheduledAt = nil
+	s.log.Info("Account deletion cancelled by sign-in", logger.String("user_id", user.ID))
 	return nil
 }
 
@@ -168,7 +379,17 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*m
 		return nil, models.ErrInvalidToken
 	}
 
-	tokens, err := s.auth.GenerateTokens(claims.UserId, claims.Role)
+	// Accounts scheduled for deletion must sign in again to be restored
+	user, err := s.repo.FindUserByID(ctx, claims.UserId)
+	if err != nil || user.DeletionScheduledAt != nil {
+		return nil, models.ErrInvalidToken
+	}
+	if user.SuspendedAt != nil {
+		return nil, models.ErrAccountSuspended
+	}
+
+	// The role is read again so that role changes apply on the next refresh
+	tokens, err := s.auth.GenerateTokens(user.ID, string(user.Role))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new tokens: %w", err)
 	}
@@ -198,5 +419,10 @@ func (s *authService) ChangePassword(ctx context.Context, userID string, req *mo
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	return s.repo.UpdatePassword(ctx, userID, hashedPassword)
+	if err := s.repo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, userEvent(ctx, userID, models.AuditPasswordChange, models.AuditTargetUser, userID))
+	return nil
 }
@@ -35,23 +35,57 @@ package services
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/events"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 )
 
 type creditService struct {
-	creditRepo interfaces.CreditRepository
-	log        logger.Logger
-	authLimit  int // 15 for authenticated users
+	creditRepo  interfaces.CreditRepository
+	promoRepo   interfaces.PromoCodeRepository
+	subRepo     interfaces.SubscriptionRepository
+	permissions interfaces.PermissionService
+	audit       interfaces.AuditService
+	bus         *events.Bus
+	log         logger.Logger
+	authLimit   int // 15 for authenticated users
+
+	expiryInterval time.Duration
+	lowThreshold   int
 }
 
-func NewCreditService(creditRepo interfaces.CreditRepository, log logger.Logger, authLimit int) interfaces.CreditService {
-	return &creditService{
-		creditRepo: creditRepo,
-		log:        log,
-		authLimit:  authLimit,
+func NewCreditService(
+	creditRepo interfaces.CreditRepository,
+	promoRepo interfaces.PromoCodeRepository,
+	subRepo interfaces.SubscriptionRepository,
+	permissions interfaces.PermissionService,
+	audit interfaces.AuditService,
+	bus *events.Bus,
+	log logger.Logger,
+	authLimit int,
+	expiryInterval time.Duration,
+	lowThreshold int,
+) interfaces.CreditService {
+	s := &creditService{
+		creditRepo:     creditRepo,
+		promoRepo:      promoRepo,
+		subRepo:        subRepo,
+		permissions:    permissions,
+		audit:          audit,
+		bus:            bus,
+		log:            log,
+		authLimit:      authLimit,
+		expiryInterval: expiryInterval,
+		lowThreshold:   lowThreshold,
 	}
+	// Personal links are the only thing that spends a user's credits
+	bus.Subscribe(events.URLCreated, s.checkLowBalance)
+	return s
 }
 
 func (s *creditService) GetCreditBalance(ctx context.Context, userID string) (*models.CreditBalanceResponse, error) {
@@ -69,51 +103,159 @@ func (s *creditService) GetCreditBalance(ctx context.Context, userID string) (*m
 	return balance, nil
 }
 
+// ApplyPromoCode redeems a credits promo code. The code must be live, allowed
+// for the user's current plan and within its redemption limits.
 func (s *creditService) ApplyPromoCode(ctx context.Context, userID, code string) (*models.Credit, error) {
-	// Validate promo code (in a real app, you'd check against a database of valid codes)
-	if code == "" || len(code) < 5 {
+	if code == "" {
 		return nil, models.ErrPromoCodeInvalid
 	}
 
-	// Check if user already used this promo code
-	credits, err := s.creditRepo.GetUserCredits(ctx, userID)
+	promo, err := findPromoCode(ctx, s.promoRepo, code)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, c := range credits {
-		if c.Type == models.CreditTypePromo && c.Description == "Promo code: "+code {
-			return nil, models.ErrPromoCodeAlreadyUsed
-		}
+	plan := models.PlanFree
+	if sub, err := s.subRepo.GetUserSubscription(ctx, userID); err == nil {
+		plan = sub.Plan
+	} else if !errors.Is(err, models.ErrSubscriptionNotActive) {
+		return nil, err
+	}
+
+	if err := checkPromoCode(promo, models.PromoCodeCredits, plan, time.Now()); err != nil {
+		return nil, err
 	}
 
 	// Create credit record
 	credit := &models.Credit{
 		UserID:      userID,
 		Type:        models.CreditTypePromo,
-		Amount:      10, // Example fixed amount
-		Remaining:   10,
-		Description: "Promo code: " + code,
+		Amount:      promo.Credits,
+		Remaining:   promo.Credits,
+		Description: "Promo code: " + promo.Code,
 	}
 
-	if err := s.creditRepo.AddCredits(ctx, credit); err != nil {
-		s.log.Error("failed to apply promo code",
-			logger.ErrorField(err),
-			logger.String("userID", userID),
-			logger.String("code", code))
+	if err := s.promoRepo.Redeem(ctx, promo, &models.PromoCodeRedemption{UserID: userID}, credit); err != nil {
+		if err != models.ErrPromoCodeExhausted && err != models.ErrPromoCodeAlreadyUsed {
+			s.log.Error("failed to apply promo code",
+				logger.ErrorField(err),
+				logger.String("userID", userID),
+				logger.String("code", promo.Code))
+		}
 		return nil, err
 	}
 
+	event := creditGrantEvent(ctx, userID, credit)
+	event.SetMetadata(map[string]string{"source": "promo_code", "promo_code": promo.Code})
+	s.audit.Record(ctx, event)
+
 	return credit, nil
 }
 
-func (s *creditService) GetCreditUsage(ctx context.Context, userID string) ([]*models.CreditUsage, error) {
-	usages, err := s.creditRepo.GetCreditUsage(ctx, userID)
+func (s *creditService) GetCreditUsage(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditUsage, *pagination.Page, error) {
+	usages, page, err := s.creditRepo.GetCreditUsage(ctx, userID, p)
 	if err != nil {
 		s.log.Error("failed to get credit usage",
 			logger.ErrorField(err),
 			logger.String("userID", userID))
+		return nil, nil, err
+	}
+	return usages, page, nil
+}
+
+func (s *creditService) GetCreditLedger(ctx context.Context, userID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error) {
+	return s.creditRepo.GetLedger(ctx, userID, p)
+}
+
+// GetWorkspaceCreditBalance returns the credits the workspace's links are
+// paid from. Workspaces have no free allowance.
+func (s *creditService) GetWorkspaceCreditBalance(ctx context.Context, workspaceID, userID string) (*models.CreditBalanceResponse, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewBilling); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.creditRepo.GetWorkspaceCreditBalance(ctx, workspaceID)
+	if err != nil {
+		s.log.Error("failed to get workspace credit balance",
+			logger.ErrorField(err),
+			logger.String("workspaceID", workspaceID))
 		return nil, err
 	}
-	return usages, nil
-}
\ No newline at end of file
+	return balance, nil
+}
+
+func (s *creditService) GetWorkspaceCreditLedger(ctx context.Context, workspaceID, userID string, p pagination.Params) ([]*models.CreditLedgerEntry, *pagination.Page, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewBilling); err != nil {
+		return nil, nil, err
+	}
+	return s.creditRepo.GetWorkspaceLedger(ctx, workspaceID, p)
+}
+
+// ExpireCredits writes off whatever is left of credits past their expiry.
+func (s *creditService) ExpireCredits(ctx context.Context) error {
+	expired, err := s.creditRepo.ExpireCredits(ctx, time.Now())
+	if expired > 0 {
+		s.log.Info("Expired credits", logger.Int("count", expired))
+	}
+	return err
+}
+
+// RunExpiryWorker calls ExpireCredits every expiry interval until ctx is done.
+func (s *creditService) RunExpiryWorker(ctx context.Context) {
+	interval := s.expiryInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ExpireCredits(ctx); err != nil {
+			s.log.Error("Credit expiry run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// creditsLowData is the payload of credits.low
+type creditsLowData struct {
+	Remaining    int `json:"remaining_credits"`
+	TotalCredits int `json:"total_credits"`
+	Threshold    int `json:"threshold"`
+}
+
+// checkLowBalance publishes credits.low when a new link takes the user's
+// remaining credits down to the threshold. It fires once per dip: the mark
+// is cleared when a later check finds the balance back above it.
+func (s *creditService) checkLowBalance(ctx context.Context, event *events.Event) {
+	data, ok := event.Data.(*urlEventData)
+	if !ok || event.UserID == "" || data.URL.WorkspaceID != nil {
+		return
+	}
+
+	balance, err := s.creditRepo.GetUserCreditBalance(ctx, event.UserID)
+	if err != nil || balance.TotalCredits == 0 {
+		return
+	}
+
+	if balance.Remaining > s.lowThreshold {
+		s.creditRepo.ClearCreditsLow(ctx, event.UserID)
+		return
+	}
+
+	marked, err := s.creditRepo.MarkCreditsLow(ctx, event.UserID, time.Now())
+	if err != nil || !marked {
+		return
+	}
+	s.bus.Publish(ctx, events.New(events.CreditsLow, event.UserID, &creditsLowData{
+		Remaining:    balance.Remaining,
+		TotalCredits: balance.TotalCredits,
+		Threshold:    s.lowThreshold,
+	}))
+}
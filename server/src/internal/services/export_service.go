@@ -0,0 +1,426 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/email"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/export"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/storage"
+)
+
+const (
+	maxExportSchedules    = 10
+	exportScheduleBatch   = 20
+	scheduledExportFolder = "exports/scheduled"
+	// Long enough for a large export to be written and delivered
+	exportScheduleLease = 30 * time.Minute
+)
+
+type exportService struct {
+	urlRepo     interfaces.URLRepository
+	repo        interfaces.ExportScheduleRepository
+	userRepo    interfaces.UserRepository
+	permissions interfaces.PermissionService
+	storage     storage.Storage
+	email       *email.EmailService
+	cfg         *configs.ExportsConfig
+	log         logger.Logger
+	baseURL     string
+}
+
+func NewExportService(
+	urlRepo interfaces.URLRepository,
+	repo interfaces.ExportScheduleRepository,
+	userRepo interfaces.UserRepository,
+	permissions interfaces.PermissionService,
+	storage storage.Storage,
+	email *email.EmailService,
+	cfg *configs.ExportsConfig,
+	log logger.Logger,
+	baseURL string,
+) interfaces.ExportService {
+	return &exportService{
+		urlRepo:     urlRepo,
+		repo:        repo,
+		userRepo:    userRepo,
+		permissions: permissions,
+		storage:     storage,
+		email:       email,
+		cfg:         cfg,
+		log:         log,
+		baseURL:     baseURL,
+	}
+}
+
+// Export streams the links or clicks q selects to w. Everything is checked
+// before the first byte is written, so an error that comes back with
+// nothing written can still be reported to the caller.
+func (s *exportService) Export(ctx context.Context, userID string, q *models.URLExportQuery, w io.Writer) error {
+	format, err := export.ParseFormat(q.Format)
+	if err != nil {
+		return models.ErrUnknownExportFormat
+	}
+	if !q.Kind.Valid() {
+		return models.ErrInvalidInput
+	}
+	columns, err := q.Kind.CheckColumns(q.Columns)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, userID, q); err != nil {
+		return err
+	}
+
+	_, err = s.write(ctx, userID, q, format, columns, w)
+	return err
+}
+
+// authorize checks the user may read what q selects: a link's clicks need
+// view access to the link, and a workspace's links need view access to the
+// workspace.
+func (s *exportService) authorize(ctx context.Context, userID string, q *models.URLExportQuery) error {
+	if q.URLID != "" {
+		u, err := s.urlRepo.GetByID(ctx, q.URLID)
+		if err != nil {
+			return err
+		}
+		return s.permissions.CanAccessURL(ctx, userID, u, models.PermissionViewLinks)
+	}
+	if q.WorkspaceID != "" {
+		_, err := s.permissions.Require(ctx, q.WorkspaceID, userID, models.PermissionViewLinks)
+		return err
+	}
+	return nil
+}
+
+// write streams the export to w and returns how many rows it wrote
+func (s *exportService) write(ctx context.Context, userID string, q *models.URLExportQuery, format export.Format, columns []string, w io.Writer) (int, error) {
+	out := export.NewWriter(format, w, columns)
+	rows := 0
+
+	var err error
+	if q.Kind == models.URLExportClicks {
+		err = s.urlRepo.StreamClicks(ctx, userID, q, func(click *models.URLClick) error {
+			rows++
+			return out.Write(clickRecord(click))
+		})
+	} else {
+		err = s.urlRepo.StreamURLs(ctx, userID, q, func(u *models.URL) error {
+			rows++
+			return out.Write(linkRecord(u, s.baseURL))
+		})
+	}
+	if err != nil {
+		return rows, err
+	}
+	return rows, out.Close()
+}
+
+func linkRecord(u *models.URL, baseURL string) export.Record {
+	return export.Record{
+		"id":           u.ID,
+		"short_code":   u.ShortCode,
+		"short_url":    baseURL + "/" + u.ShortCode,
+		"original_url": u.OriginalURL,
+		"title":        u.Title,
+		"description":  u.Description,
+		"clicks":       u.Clicks,
+		"is_active":    u.IsActive,
+		"workspace_id": u.WorkspaceID,
+		"expires_at":   u.ExpiresAt,
+		"created_at":   u.CreatedAt,
+		"updated_at":   u.UpdatedAt,
+	}
+}
+
+func clickRecord(c *models.URLClick) export.Record {
+	return export.Record{
+		"id":         c.ID,
+		"url_id":     c.URLID,
+		"ip_address": c.IPAddress,
+		"referrer":   c.Referrer,
+		"user_agent": c.UserAgent,
+		"country":    c.Country,
+		"city":       c.City,
+		"device":     c.Device,
+		"os":         c.OS,
+		"browser":    c.Browser,
+		"created_at": c.CreatedAt,
+	}
+}
+
+func (s *exportService) CreateSchedule(ctx context.Context, userID string, req *models.CreateExportScheduleRequest) (*models.ExportSchedule, error) {
+	format, err := export.ParseFormat(req.Format)
+	if err != nil {
+		return nil, models.ErrUnknownExportFormat
+	}
+	columns, err := req.Kind.CheckColumns(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if req.URLID != "" && req.Kind != models.URLExportClicks {
+		return nil, models.ErrInvalidInput
+	}
+
+	q := &models.URLExportQuery{Kind: req.Kind, URLID: req.URLID, WorkspaceID: req.WorkspaceID}
+	if err := s.authorize(ctx, userID, q); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountSchedules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxExportSchedules {
+		return nil, models.ErrExportScheduleLimit
+	}
+
+	schedule := &models.ExportSchedule{
+		UserID:    userID,
+		Name:      req.Name,
+		Kind:      req.Kind,
+		Format:    string(format),
+		Columns:   columns,
+		Frequency: req.Frequency,
+		Delivery:  req.Delivery,
+		// The first export runs straight away
+		NextRunAt: time.Now(),
+	}
+	if schedule.Name == "" {
+		schedule.Name = fmt.Sprintf("%s %s export", req.Frequency, req.Kind)
+	}
+	if req.URLID != "" {
+		schedule.URLID = &req.URLID
+	}
+	if req.WorkspaceID != "" {
+		schedule.WorkspaceID = &req.WorkspaceID
+	}
+
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Export schedule created",
+		logger.String("scheduleID", schedule.ID),
+		logger.String("userID", userID))
+	return schedule, nil
+}
+
+func (s *exportService) ListSchedules(ctx context.Context, userID string) ([]*models.ExportSchedule, error) {
+	return s.repo.ListSchedules(ctx, userID)
+}
+
+// DeleteSchedule removes the schedule and the last file it stored
+func (s *exportService) DeleteSchedule(ctx context.Context, userID, id string) error {
+	schedule, err := s.repo.GetSchedule(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSchedule(ctx, id, userID); err != nil {
+		return err
+	}
+	s.removeFile(ctx, schedule.ID, schedule.LastFileKey)
+	return nil
+}
+
+// RunDueSchedules runs the exports that are due. Each schedule is claimed
+// first, so several instances can run the worker at once.
+func (s *exportService) RunDueSchedules(ctx context.Context) error {
+	now := time.Now()
+	ids, err := s.repo.GetDueScheduleIDs(ctx, now, exportScheduleBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		schedule, ok, err := s.repo.ClaimSchedule(ctx, id, now, time.Now().Add(exportScheduleLease))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		s.run(ctx, schedule)
+	}
+	return nil
+}
+
+// run delivers one export and sets the schedule's next run. A failed click
+// export leaves its window open, so the next run picks those clicks up.
+func (s *exportService) run(ctx context.Context, schedule *models.ExportSchedule) {
+	runAt := time.Now()
+	period := schedule.Frequency.Period()
+
+	q := &models.URLExportQuery{
+		Kind:    schedule.Kind,
+		Format:  schedule.Format,
+		Columns: schedule.Columns,
+	}
+	if schedule.URLID != nil {
+		q.URLID = *schedule.URLID
+	}
+	if schedule.WorkspaceID != nil {
+		q.WorkspaceID = *schedule.WorkspaceID
+	}
+	if schedule.Kind == models.URLExportClicks {
+		from := runAt.Add(-period)
+		if schedule.CoveredUntil != nil {
+			from = *schedule.CoveredUntil
+		}
+		q.From, q.To = &from, &runAt
+	}
+
+	err := s.authorize(ctx, schedule.UserID, q)
+	if err == nil {
+		err = s.deliver(ctx, schedule, q, runAt)
+	}
+
+	schedule.LastRunAt = &runAt
+	schedule.LockedUntil = nil
+	for !schedule.NextRunAt.After(runAt) {
+		schedule.NextRunAt = schedule.NextRunAt.Add(period)
+	}
+	if err != nil {
+		s.log.Warn("Scheduled export failed",
+			logger.ErrorField(err),
+			logger.String("scheduleID", schedule.ID))
+		schedule.LastError = err.Error()
+	} else {
+		schedule.LastError = ""
+		if q.To != nil {
+			schedule.CoveredUntil = q.To
+		}
+	}
+
+	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
+		s.log.Error("Failed to save export schedule",
+			logger.ErrorField(err),
+			logger.String("scheduleID", schedule.ID))
+	}
+}
+
+func (s *exportService) deliver(ctx context.Context, schedule *models.ExportSchedule, q *models.URLExportQuery, runAt time.Time) error {
+	format, err := export.ParseFormat(schedule.Format)
+	if err != nil {
+		return models.ErrUnknownExportFormat
+	}
+	columns, err := schedule.Kind.CheckColumns(schedule.Columns)
+	if err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("brevity-%s-%s.%s", schedule.Kind, runAt.UTC().Format("20060102-150405"), format.Extension())
+
+	if schedule.Delivery == models.ExportToStorage {
+		return s.store(ctx, schedule, q, format, columns, filename)
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, schedule.UserID)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	rows, err := s.write(ctx, schedule.UserID, q, format, columns, &limitedWriter{w: &buf, remaining: s.cfg.MaxEmailSize})
+	if err != nil {
+		return err
+	}
+	return s.email.SendScheduledExportEmail(user.Email, schedule.Name, rows, email.Attachment{
+		Filename:    filename,
+		ContentType: format.ContentType(),
+		Data:        buf.Bytes(),
+	})
+}
+
+// store streams the export into storage and replaces the schedule's
+// previous file with it
+func (s *exportService) store(ctx context.Context, schedule *models.ExportSchedule, q *models.URLExportQuery, format export.Format, columns []string, filename string) error {
+	// The random suffix keeps the file URL unguessable.
+	suffix, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	filename = fmt.Sprintf("%s-%s-%s", schedule.ID, suffix, filename)
+
+	pr, pw := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		_, err := s.write(ctx, schedule.UserID, q, format, columns, pw)
+		pw.CloseWithError(err)
+		written <- err
+	}()
+
+	url, key, err := s.storage.SaveFile(ctx, pr, scheduledExportFolder, filename)
+	// Unblocks the writer if the upload stopped reading early
+	pr.CloseWithError(io.ErrClosedPipe)
+	if writeErr := <-written; err == nil && writeErr != nil {
+		err = writeErr
+	}
+	if err != nil {
+		s.removeFile(ctx, schedule.ID, key)
+		return err
+	}
+
+	s.removeFile(ctx, schedule.ID, schedule.LastFileKey)
+	schedule.LastFileURL = url
+	schedule.LastFileKey = key
+	return nil
+}
+
+func (s *exportService) removeFile(ctx context.Context, scheduleID, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.storage.DeleteFile(ctx, key); err != nil {
+		s.log.Warn("Failed to delete scheduled export file",
+			logger.ErrorField(err),
+			logger.String("scheduleID", scheduleID))
+	}
+}
+
+// limitedWriter fails with ErrExportTooLarge once more than remaining bytes
+// have been written
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, models.ErrExportTooLarge
+	}
+	l.remaining -= int64(len(p))
+	return l.w.Write(p)
+}
+
+// RunScheduleWorker calls RunDueSchedules every worker interval until ctx is
+// done.
+func (s *exportService) RunScheduleWorker(ctx context.Context) {
+	interval := s.cfg.WorkerInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDueSchedules(ctx); err != nil {
+			s.log.Error("Scheduled export run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+type folderService struct {
+	repo        interfaces.FolderRepository
+	urlRepo     interfaces.URLRepository
+	permissions interfaces.PermissionService
+	log         logger.Logger
+	baseURL     string
+}
+
+func NewFolderService(
+	repo interfaces.FolderRepository,
+	urlRepo interfaces.URLRepository,
+	permissions interfaces.PermissionService,
+	log logger.Logger,
+	baseURL string,
+) interfaces.FolderService {
+	return &folderService{
+		repo:        repo,
+		urlRepo:     urlRepo,
+		permissions: permissions,
+		log:         log,
+		baseURL:     baseURL,
+	}
+}
+
+func (s *folderService) CreateFolder(ctx context.Context, userID string, req *models.CreateFolderRequest) (*models.Folder, error) {
+	folder := &models.Folder{
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+	}
+	if folder.Name == "" {
+		return nil, models.ErrInvalidInput
+	}
+	if req.WorkspaceID != "" {
+		if _, err := s.permissions.Require(ctx, req.WorkspaceID, userID, models.PermissionEditLinks); err != nil {
+			return nil, err
+		}
+		folder.WorkspaceID = &req.WorkspaceID
+	}
+
+	if err := s.repo.CreateFolder(ctx, folder); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Folder created",
+		logger.String("folderID", folder.ID),
+		logger.String("userID", userID))
+	return folder, nil
+}
+
+func (s *folderService) ListFolders(ctx context.Context, userID, workspaceID string) ([]*models.Folder, error) {
+	if workspaceID != "" {
+		if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewLinks); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.ListFolders(ctx, userID, workspaceID)
+}
+
+func (s *folderService) UpdateFolder(ctx context.Context, userID, id string, req *models.UpdateFolderRequest) (*models.Folder, error) {
+	folder, err := s.repo.GetFolder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeScope(ctx, s.permissions, userID, folder.UserID, folder.WorkspaceID, models.ErrFolderNotFound); err != nil {
+		return nil, err
+	}
+
+	folder.Name = strings.TrimSpace(req.Name)
+	if folder.Name == "" {
+		return nil, models.ErrInvalidInput
+	}
+	if err := s.repo.UpdateFolder(ctx, folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// DeleteFolder deletes the folder but not its links
+func (s *folderService) DeleteFolder(ctx context.Context, userID, id string) error {
+	folder, err := s.repo.GetFolder(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeScope(ctx, s.permissions, userID, folder.UserID, folder.WorkspaceID, models.ErrFolderNotFound); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteFolder(ctx, id); err != nil {
+		return err
+	}
+
+	s.log.Info("Folder deleted",
+		logger.String("folderID", id),
+		logger.String("userID", userID))
+	return nil
+}
+
+// MoveURL puts a link in a folder from its own scope, or takes it out of
+// its folder
+func (s *folderService) MoveURL(ctx context.Context, userID, urlID string, req *models.MoveURLRequest) (*models.URLResponse, error) {
+	u, err := s.urlRepo.GetByID(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	if err := canOrganizeURL(ctx, s.permissions, userID, u); err != nil {
+		return nil, err
+	}
+
+	var folderID *string
+	if req.FolderID != "" {
+		folder, err := s.repo.GetFolder(ctx, req.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		if !inURLScope(u, folder.UserID, folder.WorkspaceID) {
+			return nil, models.ErrFolderNotFound
+		}
+		folderID = &folder.ID
+	}
+
+	if err := s.repo.SetURLFolder(ctx, u.ID, folderID); err != nil {
+		return nil, err
+	}
+	u.FolderID = folderID
+	return u.ToResponse(s.baseURL), nil
+}
+
+// authorizeScope checks the user may change a folder or tag that ownerID
+// made, in workspaceID when it is set. Others' folders and tags are
+// reported as notFound.
+func authorizeScope(ctx context.Context, permissions interfaces.PermissionService, userID, ownerID string, workspaceID *string, notFound error) error {
+	if workspaceID != nil {
+		_, err := permissions.Require(ctx, *workspaceID, userID, models.PermissionEditLinks)
+		if err == models.ErrWorkspaceNotFound {
+			return notFound
+		}
+		return err
+	}
+	if ownerID != userID {
+		return notFound
+	}
+	return nil
+}
+
+// canOrganizeURL checks the user may file and tag the link. Links made
+// anonymously have no scope to keep folders and tags in.
+func canOrganizeURL(ctx context.Context, permissions interfaces.PermissionService, userID string, u *models.URL) error {
+	if u.WorkspaceID == nil && u.UserID == nil {
+		return models.ErrForbidden
+	}
+	return permissions.CanAccessURL(ctx, userID, u, models.PermissionEditLinks)
+}
+
+// inURLScope reports whether a folder or tag belongs where the link does
+func inURLScope(u *models.URL, ownerID string, workspaceID *string) bool {
+	if u.WorkspaceID != nil {
+		return workspaceID != nil && *workspaceID == *u.WorkspaceID
+	}
+	return workspaceID == nil && u.UserID != nil && *u.UserID == ownerID
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/email"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/invoice"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/storage"
+)
+
+const (
+	invoiceFolder = "invoices"
+	// invoiceBatchSize bounds the work done in one worker run
+	invoiceBatchSize = 50
+	// invoiceEmailAttempts is how often sending an invoice is tried before
+	// it is left for the user to download
+	invoiceEmailAttempts = 3
+)
+
+type invoiceService struct {
+	repo     interfaces.InvoiceRepository
+	userRepo interfaces.UserRepository
+	storage  storage.Storage
+	email    *email.EmailService
+	cfg      *configs.InvoiceConfig
+	log      logger.Logger
+}
+
+func NewInvoiceService(
+	repo interfaces.InvoiceRepository,
+	userRepo interfaces.UserRepository,
+	storage storage.Storage,
+	email *email.EmailService,
+	cfg *configs.InvoiceConfig,
+	log logger.Logger,
+) interfaces.InvoiceService {
+	return &invoiceService{
+		repo:     repo,
+		userRepo: userRepo,
+		storage:  storage,
+		email:    email,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+func (s *invoiceService) ListInvoices(ctx context.Context, userID string, p pagination.Params) ([]*models.Invoice, *pagination.Page, error) {
+	return s.repo.ListByUser(ctx, userID, p)
+}
+
+func (s *invoiceService) GetInvoice(ctx context.Context, userID, id string) (*models.Invoice, error) {
+	return s.repo.GetByID(ctx, userID, id)
+}
+
+// RenderPDF renders the invoice from its stored record rather than fetching
+// the stored file, so downloads work with any storage backend.
+func (s *invoiceService) RenderPDF(ctx context.Context, userID, id string) ([]byte, string, error) {
+	inv, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdf, err := invoice.RenderPDF(inv)
+	if err != nil {
+		return nil, "", err
+	}
+	return pdf, inv.Number + ".pdf", nil
+}
+
+func (s *invoiceService) IssueInvoices(ctx context.Context) error {
+	payments, err := s.repo.ListUninvoicedPayments(ctx, invoiceBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, payment := range payments {
+		if err := s.issue(ctx, payment); err != nil {
+			s.log.Error("Failed to issue invoice",
+				logger.ErrorField(err),
+				logger.String("payment_id", payment.ID))
+		}
+	}
+
+	invoices, err := s.repo.ListUndelivered(ctx, invoiceEmailAttempts, invoiceBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, inv := range invoices {
+		s.deliver(ctx, inv)
+	}
+	return nil
+}
+
+func (s *invoiceService) RunInvoiceWorker(ctx context.Context) {
+	interval := s.cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.IssueInvoices(ctx); err != nil {
+			s.log.Error("Invoice run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// issue creates the invoice for a payment. The seller and billing details
+// are copied from the current configuration and profile.
+func (s *invoiceService) issue(ctx context.Context, payment *models.Payment) error {
+	user, err := s.userRepo.FindUserByID(ctx, payment.UserID)
+	if err != nil {
+		if !errors.Is(err, models.ErrUserNotFound) {
+			return err
+		}
+		// Deleted accounts still get an invoice for the bookkeeping, just
+		// without billing details
+		user = &models.User{}
+	}
+
+	issuedAt := payment.CreatedAt
+	if payment.PaidAt != nil {
+		issuedAt = *payment.PaidAt
+	}
+
+	subtotal, tax := models.SplitTax(payment.Amount, s.cfg.TaxRate)
+	inv := &models.Invoice{
+		UserID:         payment.UserID,
+		PaymentID:      payment.ID,
+		SubscriptionID: payment.SubscriptionID,
+		Currency:       payment.Currency,
+		Subtotal:       subtotal,
+		TaxAmount:      tax,
+		Total:          payment.Amount,
+		SellerName:     s.sellerName(),
+		SellerAddress:  s.cfg.SellerAddress,
+		SellerTaxID:    s.cfg.SellerTaxID,
+		BillingEmail:   user.Email,
+		BillingTo:      user.BillingAddress,
+		IssuedAt:       issuedAt.UTC(),
+		LineItems: []*models.InvoiceLineItem{{
+			Description: lineItemDescription(payment),
+			Quantity:    1,
+			UnitAmount:  subtotal,
+			Amount:      subtotal,
+		}},
+	}
+	if tax > 0 {
+		inv.TaxRate = s.cfg.TaxRate
+		inv.TaxLabel = s.cfg.TaxLabel
+	}
+	if inv.Currency == "" {
+		inv.Currency = "usd"
+	}
+
+	prefix := s.cfg.Prefix
+	if prefix == "" {
+		prefix = "INV"
+	}
+	if err := s.repo.Create(ctx, inv, prefix); err != nil {
+		return err
+	}
+
+	s.log.Info("Invoice issued",
+		logger.String("invoice", inv.Number),
+		logger.String("payment_id", payment.ID),
+		logger.String("user_id", inv.UserID))
+	return nil
+}
+
+// deliver stores the invoice PDF and emails it to the user. Whatever fails
+// is tried again on the next run; email stops after invoiceEmailAttempts.
+func (s *invoiceService) deliver(ctx context.Context, inv *models.Invoice) {
+	pdf, err := invoice.RenderPDF(inv)
+	if err != nil {
+		s.log.Error("Failed to render invoice",
+			logger.ErrorField(err),
+			logger.String("invoice", inv.Number))
+		return
+	}
+
+	if inv.PDFKey == "" {
+		// The random suffix keeps the file URL unguessable.
+		suffix, err := auth.GenerateOpaqueToken()
+		if err != nil {
+			s.log.Error("Failed to store invoice", logger.ErrorField(err))
+			return
+		}
+		url, key, err := s.storage.SaveFile(ctx, bytes.NewReader(pdf), invoiceFolder, fmt.Sprintf("%s-%s.pdf", inv.Number, suffix))
+		if err != nil {
+			s.log.Error("Failed to store invoice",
+				logger.ErrorField(err),
+				logger.String("invoice", inv.Number))
+			return
+		}
+		stored, err := s.repo.SetPDF(ctx, inv.ID, url, key)
+		if err != nil || !stored {
+			// Another run got there first; drop our copy
+			if err := s.storage.DeleteFile(ctx, key); err != nil {
+				s.log.Warn("Failed to delete duplicate invoice PDF",
+					logger.ErrorField(err),
+					logger.String("invoice", inv.Number))
+			}
+			return
+		}
+	}
+
+	if inv.EmailedAt != nil || inv.EmailAttempts >= invoiceEmailAttempts {
+		return
+	}
+
+	claimed, err := s.repo.ClaimEmail(ctx, inv)
+	if err != nil || !claimed {
+		return
+	}
+
+	err = s.email.SendInvoiceEmail(
+		inv.BillingEmail,
+		inv.Number,
+		invoice.FormatAmount(inv.Total, inv.Currency),
+		inv.IssuedAt,
+		email.Attachment{Filename: inv.Number + ".pdf", ContentType: "application/pdf", Data: pdf},
+	)
+	if err != nil {
+		s.log.Warn("Failed to email invoice",
+			logger.ErrorField(err),
+			logger.String("invoice", inv.Number),
+			logger.Int("attempt", inv.EmailAttempts))
+		return
+	}
+	_ = s.repo.MarkEmailed(ctx, inv.ID, time.Now())
+}
+
+func (s *invoiceService) sellerName() string {
+	if s.cfg.SellerName == "" {
+		return "Brevity"
+	}
+	return s.cfg.SellerName
+}
+
+// lineItemDescription turns a payment description such as "pro subscription
+// renewal" into "Brevity pro subscription renewal".
+func lineItemDescription(payment *models.Payment) string {
+	description := strings.TrimSpace(payment.Description)
+	if description == "" {
+		return "Brevity subscription"
+	}
+	return "Brevity " + description
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/payment"
+)
+
+const webhookRetryBatch = 50
+
+// webhookPendingLease is how long an event may stay pending before the retry
+// worker assumes whoever was processing it died and picks it up.
+const webhookPendingLease = 10 * time.Minute
+
+type paymentWebhookService struct {
+	eventRepo  interfaces.PaymentEventRepository
+	subRepo    interfaces.SubscriptionRepository
+	planRepo   interfaces.PlanRepository
+	creditRepo interfaces.CreditRepository
+	gateway    payment.PaymentGateway
+	cfg        *configs.PaymentConfig
+	log        logger.Logger
+}
+
+func NewPaymentWebhookService(
+	eventRepo interfaces.PaymentEventRepository,
+	subRepo interfaces.SubscriptionRepository,
+	planRepo interfaces.PlanRepository,
+	creditRepo interfaces.CreditRepository,
+	gateway payment.PaymentGateway,
+	cfg *configs.PaymentConfig,
+	log logger.Logger,
+) interfaces.PaymentWebhookService {
+	return &paymentWebhookService{
+		eventRepo:  eventRepo,
+		subRepo:    subRepo,
+		planRepo:   planRepo,
+		creditRepo: creditRepo,
+		gateway:    gateway,
+		cfg:        cfg,
+		log:        log,
+	}
+}
+
+// HandleWebhook verifies and stores an incoming event, then processes it.
+// Events are keyed by the provider's ID, so redeliveries are acknowledged
+// without being applied twice. Processing failures are not returned to the
+// provider; the event is retried by the worker instead. An error is returned
+// only when the event's status can't be saved, so the provider redelivers it.
+func (s *paymentWebhookService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return models.ErrInvalidWebhookSignature
+		}
+		s.log.Warn("Rejected malformed payment webhook", logger.ErrorField(err))
+		return models.ErrInvalidInput
+	}
+
+	record := &models.PaymentEvent{
+		ID:      event.ID,
+		Type:    string(event.Type),
+		Payload: string(payload),
+		Status:  models.PaymentEventPending,
+	}
+	created, err := s.eventRepo.CreateIfNotExists(ctx, record)
+	if err != nil {
+		return err
+	}
+	if !created {
+		s.log.Info("Ignoring duplicate payment webhook",
+			logger.String("event_id", event.ID),
+			logger.String("type", string(event.Type)))
+		return nil
+	}
+
+	return s.process(ctx, record, event)
+}
+
+// RetryFailedEvents reprocesses failed events whose backoff has elapsed, and
+// events left pending past the lease, such as after a crash mid-processing.
+func (s *paymentWebhookService) RetryFailedEvents(ctx context.Context) error {
+	now := time.Now()
+	events, err := s.eventRepo.GetRetryable(ctx, now, now.Add(-webhookPendingLease), webhookRetryBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range events {
+		event, err := s.gateway.DecodeEvent([]byte(record.Payload))
+		if err != nil {
+			// A stored payload that no longer decodes will never succeed
+			record.Attempts = s.maxAttempts()
+			err = s.fail(ctx, record, err)
+		} else {
+			err = s.process(ctx, record, event)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *paymentWebhookService) RunRetryWorker(ctx context.Context) {
+	interval := s.cfg.WebhookRetryInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.RetryFailedEvents(ctx); err != nil {
+			s.log.Error("Payment webhook retry run failed", logger.ErrorField(err))
+		}
+	}
+}
+
+// process applies the event and saves the outcome. Only a failure to save
+// it is returned; a failure to apply it is recorded for a retry.
+func (s *paymentWebhookService) process(ctx context.Context, record *models.PaymentEvent, event *payment.Event) error {
+	if err := s.apply(ctx, event); err != nil {
+		return s.fail(ctx, record, err)
+	}
+
+	now := time.Now()
+	record.Status = models.PaymentEventProcessed
+	record.Attempts++
+	record.LastError = ""
+	record.NextAttemptAt = nil
+	record.ProcessedAt = &now
+	return s.saveEvent(ctx, record)
+}
+
+// fail records a processing error and schedules a retry with quadratic
+// backoff, or dead-letters the event once it has used all its attempts.
+func (s *paymentWebhookService) fail(ctx context.Context, record *models.PaymentEvent, cause error) error {
+	record.Attempts++
+	record.LastError = cause.Error()
+
+	if record.Attempts >= s.maxAttempts() {
+		record.Status = models.PaymentEventDeadLetter
+		record.NextAttemptAt = nil
+		s.log.Error("Payment webhook moved to dead letter",
+			logger.ErrorField(cause),
+			logger.String("event_id", record.ID),
+			logger.String("type", record.Type),
+			logger.Int("attempts", record.Attempts))
+	} else {
+		next := time.Now().Add(time.Duration(record.Attempts*record.Attempts) * time.Minute)
+		record.Status = models.PaymentEventFailed
+		record.NextAttemptAt = &next
+		s.log.Warn("Payment webhook processing failed",
+			logger.ErrorField(cause),
+			logger.String("event_id", record.ID),
+			logger.String("type", record.Type),
+			logger.Int("attempts", record.Attempts))
+	}
+
+	return s.saveEvent(ctx, record)
+}
+
+func (s *paymentWebhookService) saveEvent(ctx context.Context, record *models.PaymentEvent) error {
+	if err := s.eventRepo.Update(ctx, record); err != nil {
+		s.log.Error("Failed to save payment webhook status",
+			logger.ErrorField(err),
+			logger.String("event_id", record.ID),
+			logger.String("status", string(record.Status)))
+		return err
+	}
+	return nil
+}
+
+func (s *paymentWebhookService) maxAttempts() int {
+	if s.cfg.WebhookMaxAttempts <= 0 {
+		return 5
+	}
+	return s.cfg.WebhookMaxAttempts
+}
+
+func (s *paymentWebhookService) apply(ctx context.Context, event *payment.Event) error {
+	switch event.Type {
+	case payment.EventInvoicePaid:
+		return s.invoicePaid(ctx, event)
+	case payment.EventInvoicePaymentFailed:
+		return s.invoicePaymentFailed(ctx, event)
+	case payment.EventSubscriptionUpdated, payment.EventSubscriptionDeleted:
+		return s.syncSubscription(ctx, event)
+	case payment.EventChargeRefunded:
+		return s.chargeRefunded(ctx, event.Charge)
+	case payment.EventDisputeCreated, payment.EventDisputeClosed:
+		return s.dispute(ctx, event.Dispute)
+	default:
+		// Events we don't act on are still recorded as processed
+		return nil
+	}
+}
+
+// invoicePaid records a renewal payment and extends the subscription to the
+// end of the paid period. The renewal scheduler may have recorded the same
+// invoice already, in which case only the period is synced. The payment is
+// recorded even when a newer event has already been applied, but the status
+// is left alone.
+func (s *paymentWebhookService) invoicePaid(ctx context.Context, event *payment.Event) error {
+	inv := event.Invoice
+	if inv.SubscriptionID == "" {
+		return nil
+	}
+
+	sub, err := s.subRepo.GetSubscriptionByStripeID(ctx, inv.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("subscription %s: %w", inv.SubscriptionID, err)
+	}
+
+	// Only invoices for a whole period grant the plan's credits. Proration
+	// invoices from upgrades are credited by the upgrade itself.
+	var recorded bool
+	switch inv.BillingReason {
+	case payment.BillingReasonCycle, payment.BillingReasonCreate:
+		recorded, err = recordRenewal(ctx, s.subRepo, s.planRepo, s.creditRepo, sub, inv, s.log)
+	default:
+		recorded, err = s.recordInvoicePayment(ctx, sub, inv)
+	}
+	if err != nil {
+		return err
+	}
+	if recorded {
+		s.log.Info("Recorded subscription payment from webhook",
+			logger.String("subscription_id", sub.ID),
+			logger.String("invoice_id", inv.ID),
+			logger.String("billing_reason", inv.BillingReason))
+	}
+
+	if inv.PeriodEnd.After(sub.ExpiresAt) {
+		sub.ExpiresAt = inv.PeriodEnd
+		sub.RenewsAt = &inv.PeriodEnd
+	}
+	if !s.outOfOrder(sub, event) {
+		sub.Status = models.SubscriptionStatusActive
+		sub.GracePeriodEndsAt = nil
+		sub.IsActive = true
+		sub.ProviderEventAt = &event.Created
+	}
+	return s.subRepo.UpdateSubscription(ctx, sub)
+}
+
+// recordInvoicePayment stores a paid invoice that doesn't start a new period,
+// without granting credits.
+func (s *paymentWebhookService) recordInvoicePayment(ctx context.Context, sub *models.Subscription, inv *payment.Invoice) (bool, error) {
+	paidAt := inv.PaidAt
+	if paidAt == nil {
+		paidAt = timeNowPtr()
+	}
+	return s.subRepo.RecordPaidInvoice(ctx, &models.Payment{
+		UserID:          sub.UserID,
+		SubscriptionID:  sub.ID,
+		Amount:          inv.AmountPaid,
+		Currency:        inv.Currency,
+		StripeID:        inv.ID,
+		PaymentIntentID: inv.PaymentIntentID,
+		Status:          models.PaymentStatusPaid,
+		Description:     string(sub.Plan) + " subscription change",
+		PaidAt:          paidAt,
+	})
+}
+
+func (s *paymentWebhookService) invoicePaymentFailed(ctx context.Context, event *payment.Event) error {
+	inv := event.Invoice
+	if inv.SubscriptionID == "" {
+		return nil
+	}
+
+	sub, err := s.subRepo.GetSubscriptionByStripeID(ctx, inv.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("subscription %s: %w", inv.SubscriptionID, err)
+	}
+
+	if _, err := s.subRepo.GetPaymentByStripeID(ctx, inv.ID); errors.Is(err, models.ErrPaymentNotFound) {
+		if err := s.subRepo.CreatePayment(ctx, &models.Payment{
+			UserID:          sub.UserID,
+			SubscriptionID:  sub.ID,
+			Amount:          inv.AmountDue,
+			Currency:        inv.Currency,
+			StripeID:        inv.ID,
+			PaymentIntentID: inv.PaymentIntentID,
+			Status:          models.PaymentStatusFailed,
+			Description:     string(sub.Plan) + " subscription renewal",
+		}); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if s.outOfOrder(sub, event) {
+		return nil
+	}
+
+	// The provider retries the charge; access continues until the period ends
+	sub.Status = models.SubscriptionStatusPastDue
+	sub.ProviderEventAt = &event.Created
+	return s.subRepo.UpdateSubscription(ctx, sub)
+}
+
+func (s *paymentWebhookService) syncSubscription(ctx context.Context, event *payment.Event) error {
+	gwSub := event.Subscription
+	deleted := event.Type == payment.EventSubscriptionDeleted
+
+	sub, err := s.subRepo.GetSubscriptionByStripeID(ctx, gwSub.ID)
+	if err != nil {
+		return fmt.Errorf("subscription %s: %w", gwSub.ID, err)
+	}
+	if s.outOfOrder(sub, event) {
+		return nil
+	}
+	sub.ProviderEventAt = &event.Created
+
+	sub.Status = string(gwSub.Status)
+	if !gwSub.CurrentPeriodEnd.IsZero() {
+		sub.ExpiresAt = gwSub.CurrentPeriodEnd
+		if gwSub.CancelAtPeriodEnd {
+			sub.RenewsAt = nil
+		} else {
+			sub.RenewsAt = &gwSub.CurrentPeriodEnd
+		}
+	}
+
+	if deleted || gwSub.Status == payment.StatusCanceled || gwSub.Status == payment.StatusUnpaid {
+		if deleted {
+			sub.Status = models.SubscriptionStatusCanceled
+		}
+		sub.IsActive = false
+		sub.RenewsAt = nil
+		if sub.CancelledAt == nil {
+			sub.CancelledAt = timeNowPtr()
+		}
+	}
+
+	return s.subRepo.UpdateSubscription(ctx, sub)
+}
+
+// outOfOrder reports whether event is older than the last provider event
+// applied to sub. Event times are in whole seconds, so an event from the same
+// second is applied, except over a canceled subscription: cancellation is
+// final at the provider, and nothing from the same second should undo it.
+func (s *paymentWebhookService) outOfOrder(sub *models.Subscription, event *payment.Event) bool {
+	last := sub.ProviderEventAt
+	if last == nil {
+		return false
+	}
+	stale := event.Created.Before(*last) ||
+		(event.Created.Equal(*last) && sub.Status == models.SubscriptionStatusCanceled && event.Type != payment.EventSubscriptionDeleted)
+	if stale {
+		s.log.Info("Skipping out-of-order payment webhook",
+			logger.String("event_id", event.ID),
+			logger.String("type", string(event.Type)),
+			logger.String("subscription_id", sub.ID),
+			logger.Time("created", event.Created),
+			logger.Time("last_applied", *last))
+	}
+	return stale
+}
+
+func (s *paymentWebhookService) chargeRefunded(ctx context.Context, ch *payment.Charge) error {
+	p, err := s.findPayment(ctx, ch.PaymentIntentID, ch.InvoiceID)
+	if err != nil {
+		return err
+	}
+
+	if ch.AmountRefunded >= ch.Amount {
+		p.Status = models.PaymentStatusRefunded
+	} else {
+		p.Status = models.PaymentStatusPartiallyRefunded
+	}
+	return s.subRepo.UpdatePayment(ctx, p)
+}
+
+// dispute tracks a chargeback on a payment. A won dispute only puts the
+// payment back to paid if nothing else, such as a refund, changed it while
+// the dispute was open.
+func (s *paymentWebhookService) dispute(ctx context.Context, dp *payment.Dispute) error {
+	p, err := s.findPayment(ctx, dp.PaymentIntentID, "")
+	if err != nil {
+		return err
+	}
+
+	switch dp.Status {
+	case "won":
+		if p.Status != models.PaymentStatusDisputed {
+			return nil
+		}
+		p.Status = models.PaymentStatusPaid
+	case "lost":
+		p.Status = models.PaymentStatusDisputeLost
+	default:
+		p.Status = models.PaymentStatusDisputed
+	}
+	return s.subRepo.UpdatePayment(ctx, p)
+}
+
+// findPayment looks a payment up by payment intent, falling back to the
+// invoice it was recorded under.
+func (s *paymentWebhookService) findPayment(ctx context.Context, intentID, invoiceID string) (*models.Payment, error) {
+	if intentID != "" {
+		p, err := s.subRepo.GetPaymentByIntentID(ctx, intentID)
+		if err == nil || !errors.Is(err, models.ErrPaymentNotFound) || invoiceID == "" {
+			return p, err
+		}
+	}
+	if invoiceID != "" {
+		return s.subRepo.GetPaymentByStripeID(ctx, invoiceID)
+	}
+	return nil, models.ErrPaymentNotFound
+}
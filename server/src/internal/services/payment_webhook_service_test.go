@@ -0,0 +1,600 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/payment"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// webhookFixture builds a provider event payload in Stripe's format
+func webhookFixture(id string, eventType payment.EventType, created time.Time, object map[string]interface{}) []byte {
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":      id,
+		"type":    eventType,
+		"created": created.Unix(),
+		"data":    map[string]interface{}{"object": object},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return payload
+}
+
+// invoiceFixture builds a renewal invoice event
+func invoiceFixture(id string, eventType payment.EventType, created, periodEnd time.Time) []byte {
+	return billedInvoiceFixture(id, eventType, created, periodEnd, payment.BillingReasonCycle)
+}
+
+func billedInvoiceFixture(id string, eventType payment.EventType, created, periodEnd time.Time, reason string) []byte {
+	return webhookFixture(id, eventType, created, map[string]interface{}{
+		"id":             "in_1",
+		"subscription":   "sub_1",
+		"payment_intent": "pi_1",
+		"amount_due":     1500,
+		"amount_paid":    1500,
+		"currency":       "usd",
+		"billing_reason": reason,
+		"lines": map[string]interface{}{"data": []interface{}{
+			map[string]interface{}{"period": map[string]interface{}{"start": created.Unix(), "end": periodEnd.Unix()}},
+		}},
+	})
+}
+
+func subscriptionFixture(id string, eventType payment.EventType, created time.Time, status payment.SubscriptionStatus, periodEnd time.Time) []byte {
+	return webhookFixture(id, eventType, created, map[string]interface{}{
+		"id":                 "sub_1",
+		"status":             status,
+		"current_period_end": periodEnd.Unix(),
+	})
+}
+
+func refundFixture(id string, created time.Time, refunded int) []byte {
+	return webhookFixture(id, payment.EventChargeRefunded, created, map[string]interface{}{
+		"id":              "ch_1",
+		"payment_intent":  "pi_1",
+		"amount":          1500,
+		"amount_refunded": refunded,
+	})
+}
+
+func disputeFixture(id string, eventType payment.EventType, created time.Time, status string) []byte {
+	return webhookFixture(id, eventType, created, map[string]interface{}{
+		"id":             "dp_1",
+		"payment_intent": "pi_1",
+		"amount":         1500,
+		"status":         status,
+	})
+}
+
+type webhookTest struct {
+	t       *testing.T
+	service *paymentWebhookService
+	events  *fakePaymentEventRepo
+	subs    *fakeSubscriptionRepo
+	credits *fakeCreditRepo
+}
+
+func newWebhookTest(t *testing.T) *webhookTest {
+	events := &fakePaymentEventRepo{events: map[string]*models.PaymentEvent{}}
+	subs := &fakeSubscriptionRepo{subs: map[string]*models.Subscription{}}
+	credits := &fakeCreditRepo{}
+	service := NewPaymentWebhookService(
+		events,
+		subs,
+		fakePlanRepo{},
+		credits,
+		payment.NewFakeGateway(testWebhookSecret),
+		&configs.PaymentConfig{WebhookMaxAttempts: 3},
+		nopLogger{},
+	).(*paymentWebhookService)
+	return &webhookTest{t: t, service: service, events: events, subs: subs, credits: credits}
+}
+
+// send signs payload and delivers it as the provider would
+func (w *webhookTest) send(payload []byte) error {
+	w.t.Helper()
+	return w.service.HandleWebhook(context.Background(), payload, payment.SignWebhookPayload(payload, testWebhookSecret, time.Now()))
+}
+
+func (w *webhookTest) mustSend(payload []byte) {
+	w.t.Helper()
+	if err := w.send(payload); err != nil {
+		w.t.Fatalf("HandleWebhook: %v", err)
+	}
+}
+
+func (w *webhookTest) event(id string) *models.PaymentEvent {
+	w.t.Helper()
+	event, ok := w.events.events[id]
+	if !ok {
+		w.t.Fatalf("event %s not stored", id)
+	}
+	return event
+}
+
+func (w *webhookTest) addSubscription(status string, expiresAt time.Time) {
+	renews := expiresAt
+	w.subs.subs["sub_1"] = &models.Subscription{
+		ID:        "s1",
+		UserID:    "u1",
+		Plan:      models.PlanPro,
+		StripeID:  "sub_1",
+		Status:    status,
+		IsActive:  true,
+		StartsAt:  expiresAt.AddDate(0, -1, 0),
+		ExpiresAt: expiresAt,
+		RenewsAt:  &renews,
+	}
+}
+
+func (w *webhookTest) addPayment(status string) {
+	w.subs.payments = append(w.subs.payments, &models.Payment{
+		ID:              "p1",
+		UserID:          "u1",
+		SubscriptionID:  "s1",
+		Amount:          1500,
+		StripeID:        "in_1",
+		PaymentIntentID: "pi_1",
+		Status:          status,
+	})
+}
+
+func TestHandleWebhookRejectsBadSignature(t *testing.T) {
+	w := newWebhookTest(t)
+	payload := refundFixture("evt_1", time.Now(), 1500)
+
+	err := w.service.HandleWebhook(context.Background(), payload, payment.SignWebhookPayload(payload, "whsec_other", time.Now()))
+	if !errors.Is(err, models.ErrInvalidWebhookSignature) {
+		t.Fatalf("err = %v, want ErrInvalidWebhookSignature", err)
+	}
+	if len(w.events.events) != 0 {
+		t.Errorf("stored %d events for a rejected webhook", len(w.events.events))
+	}
+}
+
+func TestHandleWebhookIgnoresReplayedEvent(t *testing.T) {
+	w := newWebhookTest(t)
+	now := time.Now().Truncate(time.Second)
+	w.addSubscription(models.SubscriptionStatusPastDue, now)
+
+	payload := invoiceFixture("evt_1", payment.EventInvoicePaid, now, now.AddDate(0, 1, 0))
+	w.mustSend(payload)
+
+	// Whatever the replay would change must stay as the first delivery left it
+	w.subs.subs["sub_1"].Status = models.SubscriptionStatusPastDue
+	w.mustSend(payload)
+
+	if got := w.subs.subs["sub_1"].Status; got != models.SubscriptionStatusPastDue {
+		t.Errorf("replay changed status to %s", got)
+	}
+	if len(w.subs.payments) != 1 {
+		t.Errorf("recorded %d payments, want 1", len(w.subs.payments))
+	}
+	if event := w.event("evt_1"); event.Status != models.PaymentEventProcessed || event.Attempts != 1 {
+		t.Errorf("event is %s after %d attempts", event.Status, event.Attempts)
+	}
+}
+
+func TestHandleWebhookTransitions(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	periodEnd := now.AddDate(0, 1, 0)
+
+	t.Run("invoice paid", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addSubscription(models.SubscriptionStatusPastDue, now)
+		grace := now.Add(time.Hour)
+		w.subs.subs["sub_1"].GracePeriodEndsAt = &grace
+
+		w.mustSend(invoiceFixture("evt_1", payment.EventInvoicePaid, now, periodEnd))
+
+		sub := w.subs.subs["sub_1"]
+		if sub.Status != models.SubscriptionStatusActive || !sub.IsActive || sub.GracePeriodEndsAt != nil {
+			t.Errorf("subscription is %s, active %v, grace %v", sub.Status, sub.IsActive, sub.GracePeriodEndsAt)
+		}
+		if !sub.ExpiresAt.Equal(periodEnd) || sub.RenewsAt == nil || !sub.RenewsAt.Equal(periodEnd) {
+			t.Errorf("period ends %v, renews %v, want %v", sub.ExpiresAt, sub.RenewsAt, periodEnd)
+		}
+		if len(w.subs.payments) != 1 || w.subs.payments[0].Status != models.PaymentStatusPaid {
+			t.Errorf("payments = %+v", w.subs.payments)
+		}
+		if len(w.credits.granted) != 1 || w.credits.granted[0].Amount != testPlanCredits {
+			t.Errorf("credits granted = %+v", w.credits.granted)
+		}
+	})
+
+	t.Run("proration invoice paid", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addSubscription(models.SubscriptionStatusActive, periodEnd)
+
+		w.mustSend(billedInvoiceFixture("evt_1", payment.EventInvoicePaid, now, periodEnd, payment.BillingReasonUpdate))
+
+		if len(w.subs.payments) != 1 || w.subs.payments[0].Status != models.PaymentStatusPaid {
+			t.Errorf("payments = %+v", w.subs.payments)
+		}
+		if len(w.credits.granted) != 0 {
+			t.Errorf("proration invoice granted credits %+v", w.credits.granted)
+		}
+		if sub := w.subs.subs["sub_1"]; !sub.ExpiresAt.Equal(periodEnd) {
+			t.Errorf("period ends %v, want %v", sub.ExpiresAt, periodEnd)
+		}
+	})
+
+	t.Run("invoice payment failed", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addSubscription(models.SubscriptionStatusActive, now)
+
+		w.mustSend(invoiceFixture("evt_1", payment.EventInvoicePaymentFailed, now, periodEnd))
+
+		sub := w.subs.subs["sub_1"]
+		if sub.Status != models.SubscriptionStatusPastDue || !sub.IsActive {
+			t.Errorf("subscription is %s, active %v", sub.Status, sub.IsActive)
+		}
+		if len(w.subs.payments) != 1 || w.subs.payments[0].Status != models.PaymentStatusFailed {
+			t.Errorf("payments = %+v", w.subs.payments)
+		}
+	})
+
+	t.Run("full refund", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addPayment(models.PaymentStatusPaid)
+		w.mustSend(refundFixture("evt_1", now, 1500))
+		if got := w.subs.payments[0].Status; got != models.PaymentStatusRefunded {
+			t.Errorf("payment is %s", got)
+		}
+	})
+
+	t.Run("partial refund", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addPayment(models.PaymentStatusPaid)
+		w.mustSend(refundFixture("evt_1", now, 500))
+		if got := w.subs.payments[0].Status; got != models.PaymentStatusPartiallyRefunded {
+			t.Errorf("payment is %s", got)
+		}
+	})
+
+	t.Run("dispute", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			initial   string
+			eventType payment.EventType
+			status    string
+			want      string
+		}{
+			{"opened", models.PaymentStatusPaid, payment.EventDisputeCreated, "needs_response", models.PaymentStatusDisputed},
+			{"won", models.PaymentStatusDisputed, payment.EventDisputeClosed, "won", models.PaymentStatusPaid},
+			{"lost", models.PaymentStatusDisputed, payment.EventDisputeClosed, "lost", models.PaymentStatusDisputeLost},
+			{"won after refund", models.PaymentStatusRefunded, payment.EventDisputeClosed, "won", models.PaymentStatusRefunded},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := newWebhookTest(t)
+				w.addPayment(tt.initial)
+				w.mustSend(disputeFixture("evt_1", tt.eventType, now, tt.status))
+				if got := w.subs.payments[0].Status; got != tt.want {
+					t.Errorf("payment is %s, want %s", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("subscription deleted", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addSubscription(models.SubscriptionStatusActive, periodEnd)
+
+		w.mustSend(subscriptionFixture("evt_1", payment.EventSubscriptionDeleted, now, payment.StatusCanceled, periodEnd))
+
+		sub := w.subs.subs["sub_1"]
+		if sub.Status != models.SubscriptionStatusCanceled || sub.IsActive {
+			t.Errorf("subscription is %s, active %v", sub.Status, sub.IsActive)
+		}
+		if sub.CancelledAt == nil || sub.RenewsAt != nil {
+			t.Errorf("cancelled at %v, renews at %v", sub.CancelledAt, sub.RenewsAt)
+		}
+	})
+}
+
+func TestHandleWebhookSkipsOutOfOrderSubscriptionEvents(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	periodEnd := now.AddDate(0, 1, 0)
+
+	t.Run("update after delete", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addSubscription(models.SubscriptionStatusActive, periodEnd)
+
+		w.mustSend(subscriptionFixture("evt_2", payment.EventSubscriptionDeleted, now, payment.StatusCanceled, periodEnd))
+		w.mustSend(subscriptionFixture("evt_1", payment.EventSubscriptionUpdated, now.Add(-time.Minute), payment.StatusActive, periodEnd))
+		// Same second as the deletion
+		w.mustSend(subscriptionFixture("evt_3", payment.EventSubscriptionUpdated, now, payment.StatusActive, periodEnd))
+
+		sub := w.subs.subs["sub_1"]
+		if sub.Status != models.SubscriptionStatusCanceled || sub.IsActive {
+			t.Errorf("late update left subscription %s, active %v", sub.Status, sub.IsActive)
+		}
+		for _, id := range []string{"evt_1", "evt_3"} {
+			if event := w.event(id); event.Status != models.PaymentEventProcessed {
+				t.Errorf("skipped event %s is %s", id, event.Status)
+			}
+		}
+	})
+
+	t.Run("retried older update", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addSubscription(models.SubscriptionStatusActive, now)
+		later := periodEnd.AddDate(0, 1, 0)
+
+		w.mustSend(subscriptionFixture("evt_2", payment.EventSubscriptionUpdated, now, payment.StatusActive, later))
+		w.mustSend(subscriptionFixture("evt_1", payment.EventSubscriptionUpdated, now.Add(-time.Minute), payment.StatusPastDue, periodEnd))
+
+		sub := w.subs.subs["sub_1"]
+		if sub.Status != models.SubscriptionStatusActive || !sub.ExpiresAt.Equal(later) {
+			t.Errorf("older update regressed subscription to %s, expiring %v", sub.Status, sub.ExpiresAt)
+		}
+	})
+
+	t.Run("late invoice after delete", func(t *testing.T) {
+		w := newWebhookTest(t)
+		w.addSubscription(models.SubscriptionStatusActive, now)
+
+		w.mustSend(subscriptionFixture("evt_2", payment.EventSubscriptionDeleted, now, payment.StatusCanceled, now))
+		w.mustSend(invoiceFixture("evt_1", payment.EventInvoicePaid, now.Add(-time.Minute), periodEnd))
+
+		sub := w.subs.subs["sub_1"]
+		if sub.Status != models.SubscriptionStatusCanceled || sub.IsActive {
+			t.Errorf("late invoice reactivated subscription: %s, active %v", sub.Status, sub.IsActive)
+		}
+		if len(w.subs.payments) != 1 {
+			t.Errorf("late invoice's payment not recorded")
+		}
+	})
+}
+
+func TestHandleWebhookRetriesThenDeadLetters(t *testing.T) {
+	w := newWebhookTest(t)
+	ctx := context.Background()
+
+	// No payment matches the refund, so applying it fails every time
+	if err := w.send(refundFixture("evt_1", time.Now(), 1500)); err != nil {
+		t.Fatalf("a failed apply must not be returned to the provider: %v", err)
+	}
+
+	event := w.event("evt_1")
+	if event.Status != models.PaymentEventFailed || event.Attempts != 1 || event.LastError == "" {
+		t.Fatalf("event is %s after %d attempts, error %q", event.Status, event.Attempts, event.LastError)
+	}
+	if event.NextAttemptAt == nil || time.Until(*event.NextAttemptAt) < 50*time.Second {
+		t.Fatalf("first retry at %v, want about a minute from now", event.NextAttemptAt)
+	}
+
+	// Nothing is retried before its backoff has elapsed
+	if err := w.service.RetryFailedEvents(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.event("evt_1").Attempts; got != 1 {
+		t.Fatalf("retried early, attempts = %d", got)
+	}
+
+	var backoffs []time.Duration
+	for attempt := 2; attempt <= 3; attempt++ {
+		due := time.Now().Add(-time.Second)
+		w.events.events["evt_1"].NextAttemptAt = &due
+		if err := w.service.RetryFailedEvents(ctx); err != nil {
+			t.Fatal(err)
+		}
+		event = w.event("evt_1")
+		if event.Attempts != attempt {
+			t.Fatalf("attempts = %d, want %d", event.Attempts, attempt)
+		}
+		if event.NextAttemptAt != nil {
+			backoffs = append(backoffs, time.Until(*event.NextAttemptAt))
+		}
+	}
+
+	if event.Status != models.PaymentEventDeadLetter || event.NextAttemptAt != nil {
+		t.Fatalf("event is %s, next attempt %v, want dead_letter", event.Status, event.NextAttemptAt)
+	}
+	if len(backoffs) != 1 || backoffs[0] < 3*time.Minute {
+		t.Errorf("second retry backoffs = %v, want about 4m", backoffs)
+	}
+}
+
+func TestHandleWebhookRecoversStuckPendingEvent(t *testing.T) {
+	w := newWebhookTest(t)
+	w.addPayment(models.PaymentStatusPaid)
+	payload := refundFixture("evt_1", time.Now(), 1500)
+
+	// The event is stored, but its outcome can't be saved
+	w.events.failUpdates = true
+	if err := w.send(payload); err == nil {
+		t.Fatal("a status that couldn't be saved must be returned so the provider redelivers")
+	}
+	if got := w.event("evt_1").Status; got != models.PaymentEventPending {
+		t.Fatalf("event is %s, want pending", got)
+	}
+
+	// The redelivery is a duplicate of the stored event
+	w.events.failUpdates = false
+	w.subs.payments[0].Status = models.PaymentStatusPaid
+	w.mustSend(payload)
+	if got := w.event("evt_1").Status; got != models.PaymentEventPending {
+		t.Fatalf("duplicate delivery changed the event to %s", got)
+	}
+
+	// Within the lease the event is assumed to still be in progress
+	if err := w.service.RetryFailedEvents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.event("evt_1").Status; got != models.PaymentEventPending {
+		t.Fatalf("event picked up within its lease: %s", got)
+	}
+
+	w.events.events["evt_1"].UpdatedAt = time.Now().Add(-webhookPendingLease - time.Minute)
+	if err := w.service.RetryFailedEvents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.event("evt_1").Status; got != models.PaymentEventProcessed {
+		t.Fatalf("stuck event is %s, want processed", got)
+	}
+	if got := w.subs.payments[0].Status; got != models.PaymentStatusRefunded {
+		t.Errorf("payment is %s, want refunded", got)
+	}
+}
+
+// fakePaymentEventRepo keeps events in memory. With failUpdates set, status
+// writes fail as if the database were unavailable.
+type fakePaymentEventRepo struct {
+	events      map[string]*models.PaymentEvent
+	failUpdates bool
+}
+
+func (r *fakePaymentEventRepo) CreateIfNotExists(ctx context.Context, event *models.PaymentEvent) (bool, error) {
+	if _, ok := r.events[event.ID]; ok {
+		return false, nil
+	}
+	event.CreatedAt = time.Now()
+	event.UpdatedAt = event.CreatedAt
+	stored := *event
+	r.events[event.ID] = &stored
+	return true, nil
+}
+
+func (r *fakePaymentEventRepo) Update(ctx context.Context, event *models.PaymentEvent) error {
+	if r.failUpdates {
+		return errors.New("database is unavailable")
+	}
+	event.UpdatedAt = time.Now()
+	stored := *event
+	r.events[event.ID] = &stored
+	return nil
+}
+
+func (r *fakePaymentEventRepo) GetRetryable(ctx context.Context, now, staleBefore time.Time, limit int) ([]*models.PaymentEvent, error) {
+	var events []*models.PaymentEvent
+	for _, event := range r.events {
+		failed := event.Status == models.PaymentEventFailed && !event.NextAttemptAt.After(now)
+		stuck := event.Status == models.PaymentEventPending && !event.UpdatedAt.After(staleBefore)
+		if (failed || stuck) && len(events) < limit {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+// fakeSubscriptionRepo implements the subscription and payment lookups the
+// webhook service uses. Other methods panic.
+type fakeSubscriptionRepo struct {
+	interfaces.SubscriptionRepository
+	subs     map[string]*models.Subscription // by provider ID
+	payments []*models.Payment
+}
+
+func (r *fakeSubscriptionRepo) GetSubscriptionByStripeID(ctx context.Context, stripeID string) (*models.Subscription, error) {
+	sub, ok := r.subs[stripeID]
+	if !ok {
+		return nil, models.ErrSubscriptionNotFound
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+func (r *fakeSubscriptionRepo) UpdateSubscription(ctx context.Context, sub *models.Subscription) error {
+	copied := *sub
+	r.subs[sub.StripeID] = &copied
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) findPayment(match func(*models.Payment) bool) (*models.Payment, error) {
+	for _, p := range r.payments {
+		if match(p) {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrPaymentNotFound
+}
+
+func (r *fakeSubscriptionRepo) GetPaymentByStripeID(ctx context.Context, stripeID string) (*models.Payment, error) {
+	return r.findPayment(func(p *models.Payment) bool { return p.StripeID == stripeID })
+}
+
+func (r *fakeSubscriptionRepo) GetPaymentByIntentID(ctx context.Context, intentID string) (*models.Payment, error) {
+	return r.findPayment(func(p *models.Payment) bool { return p.PaymentIntentID == intentID })
+}
+
+func (r *fakeSubscriptionRepo) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	copied := *payment
+	r.payments = append(r.payments, &copied)
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) UpdatePayment(ctx context.Context, payment *models.Payment) error {
+	for i, p := range r.payments {
+		if p.ID == payment.ID {
+			copied := *payment
+			r.payments[i] = &copied
+			return nil
+		}
+	}
+	return models.ErrPaymentNotFound
+}
+
+func (r *fakeSubscriptionRepo) RecordPaidInvoice(ctx context.Context, payment *models.Payment) (bool, error) {
+	for _, p := range r.payments {
+		if p.StripeID != payment.StripeID {
+			continue
+		}
+		if p.Status != models.PaymentStatusFailed {
+			return false, nil
+		}
+		p.Status = models.PaymentStatusPaid
+		return true, nil
+	}
+	return true, r.CreatePayment(ctx, payment)
+}
+
+// fakePlanRepo returns monthly plans that grant testPlanCredits per period.
+type fakePlanRepo struct {
+	interfaces.PlanRepository
+}
+
+const testPlanCredits = 100
+
+func (fakePlanRepo) GetLatest(ctx context.Context, code models.SubscriptionPlan) (*models.Plan, error) {
+	return &models.Plan{Code: code, Interval: models.IntervalMonthly, Credits: testPlanCredits}, nil
+}
+
+// fakeCreditRepo records the credits granted.
+type fakeCreditRepo struct {
+	interfaces.CreditRepository
+	granted []*models.Credit
+}
+
+func (r *fakeCreditRepo) AddCredits(ctx context.Context, credit *models.Credit) error {
+	r.granted = append(r.granted, credit)
+	return nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...logger.Field)        {}
+func (nopLogger) Info(string, ...logger.Field)         {}
+func (nopLogger) Warn(string, ...logger.Field)         {}
+func (nopLogger) Error(string, ...logger.Field)        {}
+func (nopLogger) Fatal(string, ...logger.Field)        {}
+func (nopLogger) Debugf(string, ...interface{})        {}
+func (nopLogger) Infof(string, ...interface{})         {}
+func (nopLogger) Warnf(string, ...interface{})         {}
+func (nopLogger) Errorf(string, ...interface{})        {}
+func (nopLogger) Fatalf(string, ...interface{})        {}
+func (nopLogger) Sync() error                          { return nil }
+func (l nopLogger) With(...logger.Field) logger.Logger { return l }
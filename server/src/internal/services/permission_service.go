@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+type permissionService struct {
+	workspaceRepo interfaces.WorkspaceRepository
+	log           logger.Logger
+}
+
+func NewPermissionService(workspaceRepo interfaces.WorkspaceRepository, log logger.Logger) interfaces.PermissionService {
+	return &permissionService{
+		workspaceRepo: workspaceRepo,
+		log:           log,
+	}
+}
+
+func (s *permissionService) Require(ctx context.Context, workspaceID, userID string, perm models.Permission) (*models.WorkspaceMember, error) {
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		if err == models.ErrMemberNotFound {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	if !member.Role.Can(perm) {
+		s.log.Warn("workspace permission denied",
+			logger.String("userID", userID),
+			logger.String("workspaceID", workspaceID),
+			logger.String("role", string(member.Role)),
+			logger.String("permission", string(perm)))
+		return nil, models.ErrForbidden
+	}
+	return member, nil
+}
+
+func (s *permissionService) CanAccessURL(ctx context.Context, userID string, url *models.URL, perm models.Permission) error {
+	if url.WorkspaceID != nil {
+		_, err := s.Require(ctx, *url.WorkspaceID, userID, perm)
+		if err == models.ErrWorkspaceNotFound {
+			return models.ErrForbidden
+		}
+		return err
+	}
+
+	// Links created anonymously have no owner to check against
+	if url.UserID != nil && *url.UserID != userID {
+		s.log.Warn("unauthorized URL access attempt",
+			logger.String("requestingUserID", userID),
+			logger.String("urlOwnerID", *url.UserID),
+			logger.String("urlID", url.ID),
+			logger.String("permission", string(perm)))
+		return models.ErrForbidden
+	}
+	return nil
+}
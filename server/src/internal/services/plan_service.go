@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+type planService struct {
+	planRepo interfaces.PlanRepository
+	log      logger.Logger
+}
+
+func NewPlanService(planRepo interfaces.PlanRepository, log logger.Logger) interfaces.PlanService {
+	return &planService{planRepo: planRepo, log: log}
+}
+
+// ListPlans returns the latest version of every plan, including archived ones.
+func (s *planService) ListPlans(ctx context.Context) ([]*models.Plan, error) {
+	return s.planRepo.ListLatest(ctx, false)
+}
+
+func (s *planService) GetPlanVersions(ctx context.Context, code models.SubscriptionPlan) ([]*models.Plan, error) {
+	plans, err := s.planRepo.ListVersions(ctx, normalizePlanCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, models.ErrPlanNotFound
+	}
+	return plans, nil
+}
+
+// CreatePlan publishes a new plan. An archived plan can be brought back under
+// the same code; its earlier versions are kept for existing subscribers.
+func (s *planService) CreatePlan(ctx context.Context, req *models.CreatePlanRequest) (*models.Plan, error) {
+	code := normalizePlanCode(req.Code)
+	if code == models.PlanFree {
+		return nil, models.ErrInvalidPlan
+	}
+
+	if existing, err := s.planRepo.GetLatest(ctx, code); err == nil && existing.IsActive {
+		return nil, models.ErrPlanExists
+	} else if err != nil && !errors.Is(err, models.ErrPlanNotFound) {
+		return nil, err
+	}
+
+	plan := &models.Plan{
+		Code:            code,
+		Name:            req.Name,
+		Description:     req.Description,
+		Price:           req.Price,
+		Currency:        normalizeCurrency(req.Currency),
+		Interval:        req.Interval,
+		Credits:         req.Credits,
+		URLLimit:        req.URLLimit,
+		Features:        req.Features,
+		FeatureFlags:    req.FeatureFlags,
+		ProviderPriceID: req.ProviderPriceID,
+	}
+	if err := s.planRepo.CreateVersion(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Plan created",
+		logger.String("code", string(plan.Code)),
+		logger.Int("version", plan.Version))
+	return plan, nil
+}
+
+// UpdatePlan applies req to the latest version of a plan. Cosmetic changes
+// are made in place. Changing the billing terms publishes a new version for
+// new subscribers, while existing subscribers keep the version they are on.
+func (s *planService) UpdatePlan(ctx context.Context, code models.SubscriptionPlan, req *models.UpdatePlanRequest) (*models.Plan, error) {
+	latest, err := s.planRepo.GetLatest(ctx, normalizePlanCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	plan := *latest
+	if req.Name != nil {
+		plan.Name = *req.Name
+	}
+	if req.Description != nil {
+		plan.Description = *req.Description
+	}
+	if req.Features != nil {
+		plan.Features = req.Features
+	}
+
+	termsChanged := false
+	if req.Price != nil && *req.Price != plan.Price {
+		plan.Price = *req.Price
+		termsChanged = true
+	}
+	if req.Currency != nil && normalizeCurrency(*req.Currency) != plan.Currency {
+		plan.Currency = normalizeCurrency(*req.Currency)
+		termsChanged = true
+	}
+	if req.Interval != nil && *req.Interval != plan.Interval {
+		plan.Interval = *req.Interval
+		termsChanged = true
+	}
+	if req.Credits != nil && *req.Credits != plan.Credits {
+		plan.Credits = *req.Credits
+		termsChanged = true
+	}
+	if req.URLLimit != nil && *req.URLLimit != plan.URLLimit {
+		plan.URLLimit = *req.URLLimit
+		termsChanged = true
+	}
+	if req.FeatureFlags != nil && !maps.Equal(req.FeatureFlags, plan.FeatureFlags) {
+		plan.FeatureFlags = req.FeatureFlags
+		termsChanged = true
+	}
+	if req.ProviderPriceID != nil && *req.ProviderPriceID != plan.ProviderPriceID {
+		plan.ProviderPriceID = *req.ProviderPriceID
+		termsChanged = true
+	}
+
+	if !termsChanged {
+		if err := s.planRepo.Update(ctx, &plan); err != nil {
+			return nil, err
+		}
+		return &plan, nil
+	}
+
+	// Publishing new terms also brings an archived plan back
+	plan.ID = ""
+	plan.CreatedAt = time.Time{}
+	plan.UpdatedAt = time.Time{}
+	if err := s.planRepo.CreateVersion(ctx, &plan); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Plan version published",
+		logger.String("code", string(plan.Code)),
+		logger.Int("version", plan.Version))
+	return &plan, nil
+}
+
+// ArchivePlan stops offering a plan to new subscribers. Existing
+// subscriptions keep renewing on their version.
+func (s *planService) ArchivePlan(ctx context.Context, code models.SubscriptionPlan) error {
+	plan, err := s.planRepo.GetLatest(ctx, normalizePlanCode(code))
+	if err != nil {
+		return err
+	}
+	if !plan.IsActive {
+		return nil
+	}
+
+	plan.IsActive = false
+	if err := s.planRepo.Update(ctx, plan); err != nil {
+		return err
+	}
+
+	s.log.Info("Plan archived", logger.String("code", string(plan.Code)))
+	return nil
+}
+
+func normalizePlanCode(code models.SubscriptionPlan) models.SubscriptionPlan {
+	return models.SubscriptionPlan(strings.ToLower(strings.TrimSpace(string(code))))
+}
+
+func normalizeCurrency(currency string) string {
+	if currency == "" {
+		return "usd"
+	}
+	return strings.ToLower(currency)
+}
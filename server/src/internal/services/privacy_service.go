@@ -0,0 +1,337 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/storage"
+)
+
+const exportFolder = "exports"
+
+type privacyService struct {
+	repo    interfaces.PrivacyRepository
+	storage storage.Storage
+	cfg     *configs.PrivacyConfig
+	log     logger.Logger
+}
+
+func NewPrivacyService(
+	repo interfaces.PrivacyRepository,
+	storage storage.Storage,
+	cfg *configs.PrivacyConfig,
+	log logger.Logger,
+) interfaces.PrivacyService {
+	return &privacyService{
+		repo:    repo,
+		storage: storage,
+		cfg:     cfg,
+		log:     log,
+	}
+}
+
+// RequestExport queues a data export. The archive is built in the background;
+// clients poll GetExport until it is completed.
+func (s *privacyService) RequestExport(ctx context.Context, userID string) (*models.DataExport, error) {
+	active, err := s.repo.HasActiveExport(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing exports: %w", err)
+	}
+	if active {
+		return nil, models.ErrExportInProgress
+	}
+
+	export := &models.DataExport{
+		UserID: userID,
+		Status: models.DataExportPending,
+	}
+	if err := s.repo.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+
+	go s.buildExport(context.Background(), *export)
+
+	return export, nil
+}
+
+func (s *privacyService) GetExport(ctx context.Context, id, userID string) (*models.DataExport, error) {
+	return s.repo.GetExport(ctx, id, userID)
+}
+
+// DeleteAccount starts the grace period. The account is purged by the worker
+// once it ends; signing in before then restores it.
+func (s *privacyService) DeleteAccount(ctx context.Context, userID string) (*models.AccountDeletionResponse, error) {
+	purgeAt := time.Now().Add(s.gracePeriod())
+	if err := s.repo.ScheduleDeletion(ctx, userID, purgeAt); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Account deletion scheduled",
+		logger.String("user_id", userID),
+		logger.String("purge_at", purgeAt.Format(time.RFC3339)))
+
+	return &models.AccountDeletionResponse{DeletionScheduledAt: purgeAt}, nil
+}
+
+// PurgeDueAccounts runs phase two of account deletion for every account whose
+// grace period has ended, and removes expired export archives.
+func (s *privacyService) PurgeDueAccounts(ctx context.Context) error {
+	now := time.Now()
+
+	exports, err := s.repo.GetExpiredExports(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to load expired exports: %w", err)
+	}
+	for _, export := range exports {
+		s.removeExport(ctx, export)
+	}
+
+	userIDs, err := s.repo.GetUsersDueForPurge(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to load accounts due for purge: %w", err)
+	}
+	for _, userID := range userIDs {
+		// Archives hold a copy of the personal data, so they go first
+		exports, err := s.repo.GetUserExports(ctx, userID)
+		if err != nil {
+			s.log.Error("Failed to load exports for purge", logger.ErrorField(err), logger.String("user_id", userID))
+			continue
+		}
+		for _, export := range exports {
+			s.removeExport(ctx, export)
+		}
+		keys, err := s.repo.GetScheduledExportFileKeys(ctx, userID)
+		if err != nil {
+			s.log.Error("Failed to load scheduled exports for purge", logger.ErrorField(err), logger.String("user_id", userID))
+			continue
+		}
+		for _, key := range keys {
+			if err := s.storage.DeleteFile(ctx, key); err != nil {
+				s.log.Warn("Failed to delete scheduled export file",
+					logger.ErrorField(err),
+					logger.String("user_id", userID))
+			}
+		}
+
+		if err := s.repo.PurgeUser(ctx, userID); err != nil {
+			continue
+		}
+		s.log.Info("Account purged", logger.String("user_id", userID))
+	}
+
+	return nil
+}
+
+// RunPurgeWorker calls PurgeDueAccounts every purge interval until ctx is done.
+func (s *privacyService) RunPurgeWorker(ctx context.Context) {
+	interval := s.cfg.PurgeInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeDueAccounts(ctx); err != nil {
+			s.log.Error("Account purge run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *privacyService) buildExport(ctx context.Context, export models.DataExport) {
+	export.Status = models.DataExportProcessing
+	if err := s.repo.UpdateExport(ctx, &export); err != nil {
+		return
+	}
+
+	url, key, err := s.writeArchive(ctx, &export)
+	if err != nil {
+		s.log.Error("Data export failed",
+			logger.ErrorField(err),
+			logger.String("export_id", export.ID),
+			logger.String("user_id", export.UserID))
+		export.Status = models.DataExportFailed
+		export.Error = "Export could not be generated, please try again"
+		_ = s.repo.UpdateExport(ctx, &export)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.exportExpiry())
+	export.Status = models.DataExportCompleted
+	export.FileKey = key
+	export.DownloadURL = url
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	if err := s.repo.UpdateExport(ctx, &export); err != nil {
+		return
+	}
+
+	s.log.Info("Data export completed",
+		logger.String("export_id", export.ID),
+		logger.String("user_id", export.UserID))
+}
+
+func (s *privacyService) writeArchive(ctx context.Context, export *models.DataExport) (string, string, error) {
+	data, err := s.repo.CollectUserData(ctx, export.UserID)
+	if err != nil {
+		return "", "", err
+	}
+	data.User.Sanitize()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	files := []struct {
+		name  string
+		write func(*zip.Writer, string) error
+	}{
+		{"profile.json", jsonFile(data.User)},
+		{"links.csv", csvFile(linkRows(data.URLs))},
+		{"clicks.csv", csvFile(clickRows(data.Clicks))},
+		{"credits.json", jsonFile(map[string]interface{}{
+			"credits":     data.Credits,
+			"usage":       data.CreditUsages,
+			"ledger":      data.CreditLedger,
+			"redemptions": data.Redemptions,
+			"referrals":   data.Referrals,
+		})},
+		{"payments.json", jsonFile(map[string]interface{}{
+			"subscriptions": data.Subscriptions,
+			"payments":      data.Payments,
+			"invoices":      data.Invoices,
+			"trials":        data.Trials,
+		})},
+		{"workspaces.json", jsonFile(data.Workspaces)},
+	}
+	for _, f := range files {
+		if err := f.write(zw, f.name); err != nil {
+			return "", "", fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	// The random suffix keeps the download URL unguessable.
+	suffix, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	filename := fmt.Sprintf("brevity-export-%s-%s.zip", export.ID, suffix)
+
+	return s.storage.SaveFile(ctx, &buf, exportFolder, filename)
+}
+
+func (s *privacyService) removeExport(ctx context.Context, export *models.DataExport) {
+	if export.FileKey != "" {
+		if err := s.storage.DeleteFile(ctx, export.FileKey); err != nil {
+			s.log.Warn("Failed to delete export archive",
+				logger.ErrorField(err),
+				logger.String("export_id", export.ID))
+		}
+	}
+	if err := s.repo.DeleteExport(ctx, export.ID); err != nil {
+		s.log.Warn("Failed to delete export record",
+			logger.ErrorField(err),
+			logger.String("export_id", export.ID))
+	}
+}
+
+func (s *privacyService) gracePeriod() time.Duration {
+	if s.cfg.DeletionGracePeriod > 0 {
+		return s.cfg.DeletionGracePeriod
+	}
+	return 30 * 24 * time.Hour
+}
+
+func (s *privacyService) exportExpiry() time.Duration {
+	if s.cfg.ExportExpiry > 0 {
+		return s.cfg.ExportExpiry
+	}
+	return 7 * 24 * time.Hour
+}
+
+func jsonFile(v interface{}) func(*zip.Writer, string) error {
+	return func(zw *zip.Writer, name string) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+func csvFile(rows [][]string) func(*zip.Writer, string) error {
+	return func(zw *zip.Writer, name string) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+}
+
+func linkRows(urls []*models.URL) [][]string {
+	rows := [][]string{{
+		"id", "short_code", "original_url", "title", "description", "clicks",
+		"is_active", "expires_at", "created_by_ip", "created_at", "deleted_at",
+	}}
+	for _, u := range urls {
+		deletedAt := ""
+		if u.DeletedAt.Valid {
+			deletedAt = u.DeletedAt.Time.Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			u.ID, u.ShortCode, u.OriginalURL, u.Title, u.Description, strconv.Itoa(u.Clicks),
+			strconv.FormatBool(u.IsActive), formatOptionalTime(u.ExpiresAt), u.CreatedByIP,
+			u.CreatedAt.Format(time.RFC3339), deletedAt,
+		})
+	}
+	return rows
+}
+
+func clickRows(clicks []*models.URLClick) [][]string {
+	rows := [][]string{{
+		"id", "url_id", "ip_address", "referrer", "user_agent", "country",
+		"city", "device", "os", "browser", "created_at",
+	}}
+	for _, c := range clicks {
+		rows = append(rows, []string{
+			c.ID, c.URLID, c.IPAddress, c.Referrer, c.UserAgent, c.Country,
+			c.City, c.Device, c.OS, c.Browser, c.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return rows
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/payment"
+)
+
+type promoCodeService struct {
+	promoRepo interfaces.PromoCodeRepository
+	planRepo  interfaces.PlanRepository
+	gateway   payment.PaymentGateway
+	log       logger.Logger
+}
+
+func NewPromoCodeService(
+	promoRepo interfaces.PromoCodeRepository,
+	planRepo interfaces.PlanRepository,
+	gateway payment.PaymentGateway,
+	log logger.Logger,
+) interfaces.PromoCodeService {
+	return &promoCodeService{
+		promoRepo: promoRepo,
+		planRepo:  planRepo,
+		gateway:   gateway,
+		log:       log,
+	}
+}
+
+func (s *promoCodeService) ListPromoCodes(ctx context.Context) ([]*models.PromoCode, error) {
+	return s.promoRepo.List(ctx)
+}
+
+func (s *promoCodeService) GetPromoCode(ctx context.Context, id string) (*models.PromoCode, error) {
+	return s.promoRepo.GetByID(ctx, id)
+}
+
+func (s *promoCodeService) GetRedemptions(ctx context.Context, id string, p pagination.Params) ([]*models.PromoCodeRedemption, *pagination.Page, error) {
+	if _, err := s.promoRepo.GetByID(ctx, id); err != nil {
+		return nil, nil, err
+	}
+	return s.promoRepo.ListRedemptions(ctx, id, p)
+}
+
+// CreatePromoCode stores a new code. Discount codes are also created as a
+// coupon with the payment provider so subscriptions can be charged with it.
+func (s *promoCodeService) CreatePromoCode(ctx context.Context, req *models.CreatePromoCodeRequest) (*models.PromoCode, error) {
+	promo := &models.PromoCode{
+		Code:           normalizePromoCode(req.Code),
+		Type:           req.Type,
+		Description:    req.Description,
+		MaxRedemptions: req.MaxRedemptions,
+		PerUserLimit:   req.PerUserLimit,
+		Plans:          req.Plans,
+		StartsAt:       req.StartsAt,
+		ExpiresAt:      req.ExpiresAt,
+		IsActive:       true,
+	}
+	if promo.PerUserLimit == 0 {
+		promo.PerUserLimit = 1
+	}
+
+	switch req.Type {
+	case models.PromoCodeCredits:
+		if req.Credits <= 0 || req.PercentOff != 0 {
+			return nil, models.ErrInvalidInput
+		}
+		promo.Credits = req.Credits
+	case models.PromoCodeDiscount:
+		if req.PercentOff <= 0 || req.Credits != 0 {
+			return nil, models.ErrInvalidInput
+		}
+		promo.PercentOff = req.PercentOff
+		promo.Duration = req.Duration
+		if promo.Duration == "" {
+			promo.Duration = payment.CouponOnce
+		}
+	default:
+		return nil, models.ErrInvalidInput
+	}
+
+	if err := s.validateTerms(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.promoRepo.GetByCode(ctx, promo.Code); err == nil {
+		return nil, models.ErrPromoCodeExists
+	} else if !errors.Is(err, models.ErrPromoCodeNotFound) {
+		return nil, err
+	}
+
+	if err := s.promoRepo.Create(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	if promo.Type == models.PromoCodeDiscount {
+		coupon, err := s.gateway.CreateCoupon(ctx, payment.CouponParams{
+			ID:         "promo_" + promo.ID,
+			Name:       promo.Code,
+			PercentOff: promo.PercentOff,
+			Duration:   promo.Duration,
+		})
+		if err != nil {
+			s.log.Error("failed to create provider coupon",
+				logger.ErrorField(err),
+				logger.String("code", promo.Code))
+			_ = s.promoRepo.Delete(ctx, promo.ID)
+			return nil, err
+		}
+
+		promo.ProviderCouponID = coupon.ID
+		if err := s.promoRepo.Update(ctx, promo); err != nil {
+			return nil, err
+		}
+	}
+
+	s.log.Info("Promo code created",
+		logger.String("code", promo.Code),
+		logger.String("type", string(promo.Type)))
+	return promo, nil
+}
+
+func (s *promoCodeService) UpdatePromoCode(ctx context.Context, id string, req *models.UpdatePromoCodeRequest) (*models.PromoCode, error) {
+	promo, err := s.promoRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		promo.Description = *req.Description
+	}
+	if req.Credits != nil {
+		if promo.Type != models.PromoCodeCredits {
+			return nil, models.ErrInvalidInput
+		}
+		promo.Credits = *req.Credits
+	}
+	if req.MaxRedemptions != nil {
+		promo.MaxRedemptions = *req.MaxRedemptions
+	}
+	if req.PerUserLimit != nil {
+		promo.PerUserLimit = *req.PerUserLimit
+	}
+	if req.Plans != nil {
+		promo.Plans = req.Plans
+	}
+	if req.StartsAt != nil {
+		promo.StartsAt = req.StartsAt
+	}
+	if req.ExpiresAt != nil {
+		promo.ExpiresAt = req.ExpiresAt
+	}
+	if req.IsActive != nil {
+		promo.IsActive = *req.IsActive
+	}
+
+	if err := s.validateTerms(ctx, promo); err != nil {
+		return nil, err
+	}
+	if err := s.promoRepo.Update(ctx, promo); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// DeletePromoCode removes a code that was never used. Codes with redemptions
+// are deactivated instead so their history is kept.
+func (s *promoCodeService) DeletePromoCode(ctx context.Context, id string) error {
+	promo, err := s.promoRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if promo.RedemptionCount == 0 {
+		return s.promoRepo.Delete(ctx, id)
+	}
+
+	promo.IsActive = false
+	return s.promoRepo.Update(ctx, promo)
+}
+
+// validateTerms checks the validity window and that every restricted plan
+// exists. "free" is allowed so credit codes can target free accounts.
+func (s *promoCodeService) validateTerms(ctx context.Context, promo *models.PromoCode) error {
+	if promo.StartsAt != nil && promo.ExpiresAt != nil && !promo.ExpiresAt.After(*promo.StartsAt) {
+		return models.ErrInvalidInput
+	}
+
+	for i, plan := range promo.Plans {
+		plan = normalizePlanCode(plan)
+		promo.Plans[i] = plan
+		if plan == models.PlanFree {
+			continue
+		}
+		if _, err := s.planRepo.GetLatest(ctx, plan); errors.Is(err, models.ErrPlanNotFound) {
+			return models.ErrInvalidPlan
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findPromoCode looks up a code as a user typed it. Unknown codes are reported
+// as invalid rather than not found.
+func findPromoCode(ctx context.Context, promoRepo interfaces.PromoCodeRepository, code string) (*models.PromoCode, error) {
+	promo, err := promoRepo.GetByCode(ctx, normalizePromoCode(code))
+	if errors.Is(err, models.ErrPromoCodeNotFound) {
+		return nil, models.ErrPromoCodeInvalid
+	}
+	return promo, err
+}
+
+// checkPromoCode reports whether promo can be redeemed as kind by a user on
+// plan. The redemption limits are enforced again when it is redeemed.
+func checkPromoCode(promo *models.PromoCode, kind models.PromoCodeType, plan models.SubscriptionPlan, now time.Time) error {
+	switch {
+	case !promo.IsActive, promo.StartsAt != nil && now.Before(*promo.StartsAt):
+		return models.ErrPromoCodeInvalid
+	case promo.ExpiresAt != nil && !now.Before(*promo.ExpiresAt):
+		return models.ErrPromoCodeExpired
+	case promo.Type != kind, !promo.AllowsPlan(plan):
+		return models.ErrPromoCodeNotApplicable
+	case promo.MaxRedemptions > 0 && promo.RedemptionCount >= promo.MaxRedemptions:
+		return models.ErrPromoCodeExhausted
+	}
+	return nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// referralCodeAlphabet leaves out characters that are easy to misread
+const referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+type referralService struct {
+	referralRepo interfaces.ReferralRepository
+	userRepo     interfaces.UserRepository
+	cfg          *configs.ReferralConfig
+	log          logger.Logger
+}
+
+func NewReferralService(
+	referralRepo interfaces.ReferralRepository,
+	userRepo interfaces.UserRepository,
+	cfg *configs.ReferralConfig,
+	log logger.Logger,
+) interfaces.ReferralService {
+	return &referralService{
+		referralRepo: referralRepo,
+		userRepo:     userRepo,
+		cfg:          cfg,
+		log:          log,
+	}
+}
+
+// GetReferrals returns the user's referral code, creating it on first use,
+// along with everyone they referred.
+func (s *referralService) GetReferrals(ctx context.Context, userID string) (*models.ReferralSummaryResponse, error) {
+	code, err := s.ensureCode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.referralRepo.ListByReferrer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.ReferralSummaryResponse{
+		Code:       code.Code,
+		MonthlyCap: s.cfg.MonthlyCap,
+		Referrals:  entries,
+	}
+	for _, entry := range entries {
+		switch entry.Status {
+		case models.ReferralPending:
+			summary.Pending++
+		case models.ReferralCompleted, models.ReferralCapped:
+			summary.Completed++
+		}
+		summary.CreditsEarned += entry.CreditsEarned
+	}
+	return summary, nil
+}
+
+// RecordSignup runs the abuse checks that can be decided at signup. A
+// referral that fails them is still recorded, as rejected, so the referrer
+// can see why it didn't pay out.
+func (s *referralService) RecordSignup(ctx context.Context, referee *models.User, code, ip string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil
+	}
+
+	owner, err := s.referralRepo.GetCodeByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, models.ErrReferralNotFound) {
+			s.log.Debug("Unknown referral code at signup", logger.String("code", code))
+			return nil
+		}
+		return err
+	}
+
+	referrer, err := s.userRepo.FindUserByID(ctx, owner.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	referral := &models.Referral{
+		ReferrerID: referrer.ID,
+		RefereeID:  referee.ID,
+		Code:       code,
+		Status:     models.ReferralPending,
+		SignupIP:   ip,
+	}
+
+	if referrer.ID == referee.ID || normalizeEmail(referrer.Email) == normalizeEmail(referee.Email) {
+		referral.Status = models.ReferralRejected
+		referral.RejectReason = models.ReferralRejectSelf
+	} else if ip != "" {
+		seen, err := s.referralRepo.IPSeen(ctx, referrer.ID, ip)
+		if err != nil {
+			return err
+		}
+		if seen {
+			referral.Status = models.ReferralRejected
+			referral.RejectReason = models.ReferralRejectSameIP
+		}
+	}
+
+	if err := s.referralRepo.Create(ctx, referral); err != nil {
+		return err
+	}
+
+	if referral.Status == models.ReferralRejected {
+		s.log.Info("Referral rejected",
+			logger.String("referrer_id", referrer.ID),
+			logger.String("referee_id", referee.ID),
+			logger.String("reason", referral.RejectReason))
+	}
+	return nil
+}
+
+func (s *referralService) CompleteReferral(ctx context.Context, refereeID string) error {
+	referral, err := s.referralRepo.GetByReferee(ctx, refereeID)
+	if err != nil {
+		if errors.Is(err, models.ErrReferralNotFound) {
+			return nil
+		}
+		return err
+	}
+	if referral.Status != models.ReferralPending {
+		return nil
+	}
+
+	referrerCredit := &models.Credit{
+		UserID:      referral.ReferrerID,
+		Type:        models.CreditTypeReferral,
+		Amount:      s.cfg.ReferrerCredits,
+		Description: "Referral reward",
+	}
+	refereeCredit := &models.Credit{
+		UserID:      referral.RefereeID,
+		Type:        models.CreditTypeReferral,
+		Amount:      s.cfg.RefereeCredits,
+		Description: "Referral sign-up bonus",
+	}
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	err = s.referralRepo.Complete(ctx, referral, referrerCredit, refereeCredit, monthStart, s.cfg.MonthlyCap)
+	if err != nil {
+		if errors.Is(err, models.ErrReferralNotPending) {
+			return nil
+		}
+		return err
+	}
+
+	s.log.Info("Referral completed",
+		logger.String("referral_id", referral.ID),
+		logger.String("status", string(referral.Status)),
+		logger.Int("referrer_credits", referral.ReferrerCredits),
+		logger.Int("referee_credits", referral.RefereeCredits))
+	return nil
+}
+
+func (s *referralService) ensureCode(ctx context.Context, userID string) (*models.ReferralCode, error) {
+	code, err := s.referralRepo.GetCode(ctx, userID)
+	if err == nil {
+		return code, nil
+	}
+	if !errors.Is(err, models.ErrReferralNotFound) {
+		return nil, err
+	}
+
+	// 32^8 codes make a collision unlikely; try a few before giving up
+	for attempt := 0; attempt < 3; attempt++ {
+		value, err := generateReferralCode(8)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.referralRepo.GetCodeByCode(ctx, value); err == nil {
+			continue
+		} else if !errors.Is(err, models.ErrReferralNotFound) {
+			return nil, err
+		}
+
+		code = &models.ReferralCode{UserID: userID, Code: value}
+		if err := s.referralRepo.CreateCode(ctx, code); err != nil {
+			return nil, err
+		}
+		return code, nil
+	}
+	return nil, errors.New("failed to generate a unique referral code")
+}
+
+func generateReferralCode(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = referralCodeAlphabet[int(b[i])%len(referralCodeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// normalizeEmail folds the variations of one mailbox together: case and
+// "+tag" suffixes on the local part.
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return email
+	}
+	local, _, _ = strings.Cut(local, "+")
+	return local + "@" + domain
+}
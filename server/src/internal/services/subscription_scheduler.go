@@ -0,0 +1,487 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/email"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/invoice"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/payment"
+)
+
+const (
+	renewalBatch = 100
+	// renewalLease bounds how long a crashed instance can hold a subscription
+	renewalLease = 5 * time.Minute
+)
+
+type subscriptionScheduler struct {
+	subRepo    interfaces.SubscriptionRepository
+	planRepo   interfaces.PlanRepository
+	creditRepo interfaces.CreditRepository
+	userRepo   interfaces.UserRepository
+	trialRepo  interfaces.TrialRepository
+	gateway    payment.PaymentGateway
+	email      *email.EmailService
+	cfg        *configs.PaymentConfig
+	trialCfg   *configs.TrialConfig
+	log        logger.Logger
+}
+
+func NewSubscriptionScheduler(
+	subRepo interfaces.SubscriptionRepository,
+	planRepo interfaces.PlanRepository,
+	creditRepo interfaces.CreditRepository,
+	userRepo interfaces.UserRepository,
+	trialRepo interfaces.TrialRepository,
+	gateway payment.PaymentGateway,
+	email *email.EmailService,
+	cfg *configs.PaymentConfig,
+	trialCfg *configs.TrialConfig,
+	log logger.Logger,
+) interfaces.SubscriptionScheduler {
+	return &subscriptionScheduler{
+		subRepo:    subRepo,
+		planRepo:   planRepo,
+		creditRepo: creditRepo,
+		userRepo:   userRepo,
+		trialRepo:  trialRepo,
+		gateway:    gateway,
+		email:      email,
+		cfg:        cfg,
+		trialCfg:   trialCfg,
+		log:        log,
+	}
+}
+
+// ProcessDue renews, retries or expires every subscription that is due and
+// ends trials that have run out. Each subscription is claimed with a lease
+// first, so several server instances can run the scheduler without charging
+// anyone twice.
+func (s *subscriptionScheduler) ProcessDue(ctx context.Context) error {
+	now := time.Now()
+	if err := s.remindTrials(ctx, now); err != nil {
+		s.log.Error("Trial reminders failed", logger.ErrorField(err))
+	}
+
+	ids, err := s.subRepo.GetDueSubscriptionIDs(ctx, now, renewalBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		sub, claimed, err := s.subRepo.ClaimSubscription(ctx, id, now, now.Add(renewalLease))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.process(ctx, sub, now); err != nil {
+			s.log.Error("Subscription renewal failed",
+				logger.ErrorField(err),
+				logger.String("subscription_id", sub.ID))
+		}
+	}
+	return nil
+}
+
+func (s *subscriptionScheduler) RunScheduler(ctx context.Context) {
+	interval := s.cfg.RenewalInterval
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessDue(ctx); err != nil {
+			s.log.Error("Subscription scheduler run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *subscriptionScheduler) process(ctx context.Context, sub *models.Subscription, now time.Time) error {
+	if sub.Status == models.SubscriptionStatusTrialing {
+		return s.endTrial(ctx, sub, now)
+	}
+
+	// Subscriptions that won't renew simply run out
+	if sub.RenewsAt == nil || sub.StripeID == "" {
+		return s.expire(ctx, sub, now)
+	}
+	if sub.GracePeriodEndsAt != nil && !now.Before(*sub.GracePeriodEndsAt) {
+		return s.downgrade(ctx, sub, now)
+	}
+
+	gwSub, err := s.gateway.RenewSubscription(ctx, sub.StripeID)
+	if err != nil {
+		var gwErr *payment.Error
+		if errors.As(err, &gwErr) && gwErr.Declined() {
+			return s.pastDue(ctx, sub, now)
+		}
+		// Provider problems are retried on the next run once the lease expires
+		return err
+	}
+
+	switch {
+	case gwSub.Status == payment.StatusCanceled:
+		return s.expire(ctx, sub, now)
+	case !gwSub.IsPaid():
+		return s.pastDue(ctx, sub, now)
+	case !gwSub.CurrentPeriodEnd.After(sub.ExpiresAt):
+		// The provider hasn't started the next period yet; check again later
+		next := now.Add(s.retryInterval())
+		sub.RenewsAt = &next
+		return s.save(ctx, sub)
+	}
+
+	if _, err := recordRenewal(ctx, s.subRepo, s.planRepo, s.creditRepo, sub, gwSub.LatestInvoice, s.log); err != nil {
+		return err
+	}
+
+	sub.ExpiresAt = gwSub.CurrentPeriodEnd
+	sub.RenewsAt = &gwSub.CurrentPeriodEnd
+	sub.Status = models.SubscriptionStatusActive
+	sub.GracePeriodEndsAt = nil
+	s.log.Info("Subscription renewed",
+		logger.String("subscription_id", sub.ID),
+		logger.String("plan", string(sub.Plan)))
+	return s.save(ctx, sub)
+}
+
+// pastDue starts or continues the grace period after a failed charge. The
+// plan stays active while the charge is retried, and the user is reminded
+// after every failed attempt.
+func (s *subscriptionScheduler) pastDue(ctx context.Context, sub *models.Subscription, now time.Time) error {
+	if sub.GracePeriodEndsAt == nil {
+		ends := now.Add(s.gracePeriod())
+		sub.GracePeriodEndsAt = &ends
+	}
+
+	next := now.Add(s.retryInterval())
+	if next.After(*sub.GracePeriodEndsAt) {
+		next = *sub.GracePeriodEndsAt
+	}
+	sub.RenewsAt = &next
+	sub.Status = models.SubscriptionStatusPastDue
+
+	if err := s.save(ctx, sub); err != nil {
+		return err
+	}
+
+	s.log.Warn("Subscription renewal declined",
+		logger.String("subscription_id", sub.ID),
+		logger.String("grace_period_ends_at", sub.GracePeriodEndsAt.Format(time.RFC3339)))
+	s.notify(ctx, sub.UserID, func(to string) error {
+		return s.email.SendPaymentFailedEmail(to, string(sub.Plan), *sub.GracePeriodEndsAt)
+	})
+	return nil
+}
+
+// downgrade ends a subscription whose grace period ran out. Without an active
+// subscription the account falls back to PlanFree limits.
+func (s *subscriptionScheduler) downgrade(ctx context.Context, sub *models.Subscription, now time.Time) error {
+	if _, err := s.gateway.CancelSubscription(ctx, sub.StripeID, false); err != nil {
+		s.log.Error("Failed to cancel unpaid gateway subscription",
+			logger.ErrorField(err),
+			logger.String("subscription_id", sub.ID))
+	}
+
+	sub.Status = models.SubscriptionStatusUnpaid
+	if err := s.deactivate(ctx, sub, now); err != nil {
+		return err
+	}
+
+	s.log.Info("Subscription downgraded to free plan",
+		logger.String("subscription_id", sub.ID),
+		logger.String("plan", string(sub.Plan)))
+	s.notify(ctx, sub.UserID, func(to string) error {
+		return s.email.SendSubscriptionDowngradedEmail(to, string(sub.Plan))
+	})
+	return nil
+}
+
+// endTrial converts a finished trial to a paid subscription when a payment
+// method is on file, and reverts it to the free plan otherwise.
+func (s *subscriptionScheduler) endTrial(ctx context.Context, sub *models.Subscription, now time.Time) error {
+	trial, err := s.trialRepo.GetBySubscription(ctx, sub.ID)
+	if err != nil {
+		return err
+	}
+
+	if trial.HasPaymentMethod() {
+		converted, err := s.convertTrial(ctx, sub, trial)
+		if err != nil || converted {
+			return err
+		}
+	}
+	return s.revertTrial(ctx, sub, trial, now)
+}
+
+// convertTrial charges the first period of the trial's plan. It returns false
+// if the payment was declined, in which case the trial should be reverted.
+func (s *subscriptionScheduler) convertTrial(ctx context.Context, sub *models.Subscription, trial *models.Trial) (bool, error) {
+	plan, err := subscriptionPlan(ctx, s.planRepo, sub)
+	if err != nil {
+		return false, err
+	}
+	user, err := s.userRepo.FindUserByID(ctx, sub.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	gwSub, err := s.gateway.CreateSubscription(ctx, payment.SubscriptionParams{
+		CustomerID:      user.PaymentCustomerID,
+		PriceID:         planPriceID(s.cfg.Prices, plan),
+		PaymentMethodID: trial.PaymentMethodID,
+		Amount:          plan.Price,
+		Currency:        plan.Currency,
+		Interval:        gatewayInterval(plan),
+		Metadata:        map[string]string{"user_id": sub.UserID, "plan": string(plan.Code), "plan_id": plan.ID},
+	})
+	if err != nil {
+		var gwErr *payment.Error
+		if errors.As(err, &gwErr) && gwErr.Declined() {
+			s.log.Info("Trial conversion declined",
+				logger.String("subscription_id", sub.ID),
+				logger.String("decline_code", gwErr.DeclineCode))
+			return false, nil
+		}
+		// Provider problems are retried on the next run once the lease expires
+		return false, err
+	}
+	if !gwSub.IsPaid() {
+		s.log.Info("Trial conversion charge not confirmed",
+			logger.String("subscription_id", sub.ID),
+			logger.String("status", string(gwSub.Status)))
+		abandonGatewaySubscription(ctx, s.gateway, gwSub, s.log)
+		return false, nil
+	}
+
+	sub.StripeID = gwSub.ID
+	sub.Status = models.SubscriptionStatusActive
+	sub.StartsAt = gwSub.CurrentPeriodStart
+	sub.ExpiresAt = gwSub.CurrentPeriodEnd
+	sub.RenewsAt = &gwSub.CurrentPeriodEnd
+	if err := s.save(ctx, sub); err != nil {
+		abandonGatewaySubscription(ctx, s.gateway, gwSub, s.log)
+		return false, err
+	}
+
+	inv := gwSub.LatestInvoice
+	paidAt := inv.PaidAt
+	if paidAt == nil {
+		paidAt = timeNowPtr()
+	}
+	recorded, err := s.subRepo.RecordPaidInvoice(ctx, &models.Payment{
+		UserID:          sub.UserID,
+		SubscriptionID:  sub.ID,
+		Amount:          inv.AmountPaid,
+		Currency:        inv.Currency,
+		StripeID:        inv.ID,
+		PaymentIntentID: inv.PaymentIntentID,
+		Status:          models.PaymentStatusPaid,
+		Description:     string(plan.Code) + " subscription",
+		PaidAt:          paidAt,
+	})
+	if err != nil {
+		// The charge went through, so keep the subscription and flag it
+		s.log.Error("Failed to record trial conversion payment",
+			logger.ErrorField(err),
+			logger.String("subscription_id", sub.ID),
+			logger.String("invoice_id", inv.ID))
+	}
+	if recorded || err != nil {
+		if err := grantPlanCredits(ctx, s.creditRepo, sub, plan); err != nil {
+			s.log.Error("Failed to add plan credits",
+				logger.ErrorField(err),
+				logger.String("subscription_id", sub.ID))
+		}
+	}
+
+	trial.Status = models.TrialConverted
+	trial.EndedAt = timeNowPtr()
+	if err := s.trialRepo.End(ctx, trial); err != nil {
+		return true, err
+	}
+
+	s.log.Info("Trial converted",
+		logger.String("subscription_id", sub.ID),
+		logger.String("plan", string(sub.Plan)))
+	return true, nil
+}
+
+// revertTrial ends a trial without charging. The account falls back to the
+// free plan and the trial credits expire with the trial.
+func (s *subscriptionScheduler) revertTrial(ctx context.Context, sub *models.Subscription, trial *models.Trial, now time.Time) error {
+	if err := s.expire(ctx, sub, now); err != nil {
+		return err
+	}
+
+	trial.Status = models.TrialReverted
+	trial.EndedAt = &now
+	if err := s.trialRepo.End(ctx, trial); err != nil {
+		return err
+	}
+
+	s.log.Info("Trial reverted to free plan",
+		logger.String("subscription_id", sub.ID),
+		logger.String("plan", string(sub.Plan)))
+	s.notify(ctx, sub.UserID, func(to string) error {
+		return s.email.SendTrialEndedEmail(to, string(sub.Plan))
+	})
+	return nil
+}
+
+// remindTrials emails users whose trial ends within the reminder window.
+// Each reminder is claimed before sending, so it goes out at most once.
+func (s *subscriptionScheduler) remindTrials(ctx context.Context, now time.Time) error {
+	trials, err := s.trialRepo.ListReminderDue(ctx, now.Add(s.trialReminderBefore()), renewalBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, trial := range trials {
+		claimed, err := s.trialRepo.MarkReminded(ctx, trial.ID, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		plan, err := s.planRepo.GetByID(ctx, trial.PlanID)
+		if err != nil {
+			s.log.Error("Failed to load trial plan",
+				logger.ErrorField(err),
+				logger.String("trial_id", trial.ID))
+			continue
+		}
+		price := invoice.FormatAmount(plan.Price, plan.Currency)
+		s.notify(ctx, trial.UserID, func(to string) error {
+			return s.email.SendTrialEndingEmail(to, string(trial.Plan), trial.EndsAt, price, trial.HasPaymentMethod())
+		})
+	}
+	return nil
+}
+
+func (s *subscriptionScheduler) expire(ctx context.Context, sub *models.Subscription, now time.Time) error {
+	sub.Status = models.SubscriptionStatusCanceled
+	return s.deactivate(ctx, sub, now)
+}
+
+func (s *subscriptionScheduler) deactivate(ctx context.Context, sub *models.Subscription, now time.Time) error {
+	sub.IsActive = false
+	sub.RenewsAt = nil
+	sub.GracePeriodEndsAt = nil
+	if sub.CancelledAt == nil {
+		sub.CancelledAt = &now
+	}
+	return s.save(ctx, sub)
+}
+
+// save persists the subscription and releases the scheduler lease.
+func (s *subscriptionScheduler) save(ctx context.Context, sub *models.Subscription) error {
+	sub.LockedUntil = nil
+	return s.subRepo.UpdateSubscription(ctx, sub)
+}
+
+func (s *subscriptionScheduler) notify(ctx context.Context, userID string, send func(to string) error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return
+	}
+	if err := send(user.Email); err != nil {
+		s.log.Error("Failed to send billing email",
+			logger.ErrorField(err),
+			logger.String("user_id", userID))
+	}
+}
+
+func (s *subscriptionScheduler) retryInterval() time.Duration {
+	if s.cfg.RenewalRetryInterval <= 0 {
+		return 24 * time.Hour
+	}
+	return s.cfg.RenewalRetryInterval
+}
+
+func (s *subscriptionScheduler) trialReminderBefore() time.Duration {
+	if s.trialCfg.ReminderBefore <= 0 {
+		return 3 * 24 * time.Hour
+	}
+	return s.trialCfg.ReminderBefore
+}
+
+func (s *subscriptionScheduler) gracePeriod() time.Duration {
+	if s.cfg.GracePeriod <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return s.cfg.GracePeriod
+}
+
+// recordRenewal stores a paid renewal invoice and grants the new period's
+// credits. Both the scheduler and the payment webhook can see the same
+// invoice; only the first to record it grants credits. A downgrade scheduled
+// for the renewal is applied to sub first, so credits match the new plan.
+func recordRenewal(
+	ctx context.Context,
+	subRepo interfaces.SubscriptionRepository,
+	planRepo interfaces.PlanRepository,
+	creditRepo interfaces.CreditRepository,
+	sub *models.Subscription,
+	inv *payment.Invoice,
+	log logger.Logger,
+) (bool, error) {
+	if sub.PendingPlan != "" && inv.PeriodEnd.After(sub.ExpiresAt) {
+		sub.Plan = sub.PendingPlan
+		sub.PlanID = sub.PendingPlanID
+		sub.PendingPlan = ""
+		sub.PendingPlanID = ""
+	}
+
+	paidAt := inv.PaidAt
+	if paidAt == nil {
+		paidAt = timeNowPtr()
+	}
+
+	recorded, err := subRepo.RecordPaidInvoice(ctx, &models.Payment{
+		UserID:          sub.UserID,
+		SubscriptionID:  sub.ID,
+		Amount:          inv.AmountPaid,
+		Currency:        inv.Currency,
+		StripeID:        inv.ID,
+		PaymentIntentID: inv.PaymentIntentID,
+		Status:          models.PaymentStatusPaid,
+		Description:     string(sub.Plan) + " subscription renewal",
+		PaidAt:          paidAt,
+	})
+	if err != nil || !recorded {
+		return recorded, err
+	}
+
+	plan, err := subscriptionPlan(ctx, planRepo, sub)
+	if err == nil {
+		err = grantPlanCredits(ctx, creditRepo, sub, plan)
+	}
+	if err != nil {
+		log.Error("failed to add plan credits",
+			logger.ErrorField(err),
+			logger.String("userID", sub.UserID),
+			logger.String("invoice_id", inv.ID))
+	}
+	return true, nil
+}
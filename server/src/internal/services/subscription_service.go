@@ -2,197 +2,914 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"time"
-	
+
 	"github.com/imraushankr/bervity/server/src/configs"
 	"github.com/imraushankr/bervity/server/src/internal/models"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/payment"
 )
 
 type subscriptionService struct {
-	subRepo    interfaces.SubscriptionRepository
-	creditRepo interfaces.CreditRepository
-	log        logger.Logger
-	cfg        *configs.Config
+	subRepo     interfaces.SubscriptionRepository
+	planRepo    interfaces.PlanRepository
+	promoRepo   interfaces.PromoCodeRepository
+	creditRepo  interfaces.CreditRepository
+	userRepo    interfaces.UserRepository
+	trialRepo   interfaces.TrialRepository
+	permissions interfaces.PermissionService
+	audit       interfaces.AuditService
+	gateway     payment.PaymentGateway
+	log         logger.Logger
+	cfg         *configs.Config
 }
 
 func NewSubscriptionService(
 	subRepo interfaces.SubscriptionRepository,
+	planRepo interfaces.PlanRepository,
+	promoRepo interfaces.PromoCodeRepository,
 	creditRepo interfaces.CreditRepository,
+	userRepo interfaces.UserRepository,
+	trialRepo interfaces.TrialRepository,
+	permissions interfaces.PermissionService,
+	audit interfaces.AuditService,
+	gateway payment.PaymentGateway,
 	log logger.Logger,
 	cfg *configs.Config,
 ) interfaces.SubscriptionService {
 	return &subscriptionService{
-		subRepo:    subRepo,
-		creditRepo: creditRepo,
-		log:        log,
-		cfg:        cfg,
+		subRepo:     subRepo,
+		planRepo:    planRepo,
+		promoRepo:   promoRepo,
+		creditRepo:  creditRepo,
+		userRepo:    userRepo,
+		trialRepo:   trialRepo,
+		permissions: permissions,
+		audit:       audit,
+		gateway:     gateway,
+		log:         log,
+		cfg:         cfg,
 	}
 }
 
 func (s *subscriptionService) CreateSubscription(ctx context.Context, userID string, req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
-	// Validate plan
-	if !s.isValidPlan(req.Plan) {
-		return nil, models.ErrInvalidPlan
+	return s.createSubscription(ctx, userID, nil, req)
+}
+
+// CreateWorkspaceSubscription subscribes a workspace to a plan, charged to
+// the member who subscribes. The plan's credits go to the workspace.
+func (s *subscriptionService) CreateWorkspaceSubscription(ctx context.Context, workspaceID, userID string, req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageBilling); err != nil {
+		return nil, err
+	}
+	return s.createSubscription(ctx, userID, &workspaceID, req)
+}
+
+// createSubscription charges userID for a new subscription, owned by the
+// workspace when workspaceID is set and by the user otherwise.
+func (s *subscriptionService) createSubscription(ctx context.Context, userID string, workspaceID *string, req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
+	// New subscriptions get the plan's current terms
+	plan, err := s.activePlan(ctx, req.Plan)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.activeSubscription(ctx, userID, workspaceID); err == nil {
+		return nil, models.ErrActiveSubscriptionExists
+	} else if !errors.Is(err, models.ErrSubscriptionNotActive) {
+		return nil, err
+	}
+
+	customerID, err := s.ensureCustomer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.gateway.AttachPaymentMethod(ctx, customerID, req.Token); err != nil {
+		return nil, s.paymentError("failed to attach payment method", userID, err)
 	}
 
+	// A coupon is claimed up front so it can't be used past its limits by
+	// concurrent sign-ups, and released again if the subscription fails
+	redemption, coupon, err := s.reserveCoupon(ctx, userID, req.Coupon, plan)
+	if err != nil {
+		return nil, err
+	}
+	completed := false
+	defer func() {
+		if redemption != nil && !completed {
+			_ = s.promoRepo.Release(ctx, redemption)
+		}
+	}()
+
+	metadata := map[string]string{"user_id": userID, "plan": string(plan.Code), "plan_id": plan.ID}
+	if workspaceID != nil {
+		metadata["workspace_id"] = *workspaceID
+	}
+
+	// The gateway only returns once the first invoice has been charged
+	gwSub, err := s.gateway.CreateSubscription(ctx, payment.SubscriptionParams{
+		CustomerID:      customerID,
+		PriceID:         s.planPriceID(plan),
+		PaymentMethodID: req.Token,
+		Coupon:          coupon,
+		Amount:          plan.Price,
+		Currency:        plan.Currency,
+		Interval:        gatewayInterval(plan),
+		Metadata:        metadata,
+	})
+	if err != nil {
+		return nil, s.paymentError("failed to create gateway subscription", userID, err)
+	}
+	if !gwSub.IsPaid() {
+		s.log.Warn("subscription charge not confirmed",
+			logger.String("userID", userID),
+			logger.String("status", string(gwSub.Status)))
+		s.abandonGatewaySubscription(ctx, gwSub)
+		return nil, models.ErrPaymentFailed
+	}
+
+	invoice := gwSub.LatestInvoice
+
 	// Create subscription
 	subscription := &models.Subscription{
-		UserID:    userID,
-		Plan:      req.Plan,
-		IsActive:  true,
-		StartsAt:  time.Now(),
-		ExpiresAt: time.Now().AddDate(0, 1, 0), // 1 month
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Plan:        plan.Code,
+		PlanID:      plan.ID,
+		StripeID:    gwSub.ID,
+		Status:      models.SubscriptionStatusActive,
+		IsActive:    true,
+		StartsAt:    gwSub.CurrentPeriodStart,
+		ExpiresAt:   gwSub.CurrentPeriodEnd,
+		RenewsAt:    &gwSub.CurrentPeriodEnd,
 	}
 
 	if err := s.subRepo.CreateSubscription(ctx, subscription); err != nil {
 		s.log.Error("failed to create subscription",
 			logger.ErrorField(err),
 			logger.String("userID", userID))
+		s.abandonGatewaySubscription(ctx, gwSub)
 		return nil, err
 	}
 
 	// Record payment
+	paidAt := invoice.PaidAt
+	if paidAt == nil {
+		paidAt = timeNowPtr()
+	}
 	payment := &models.Payment{
 		UserID:         userID,
 		SubscriptionID: subscription.ID,
-		Amount:         s.getPlanPrice(req.Plan),
-		Currency:       "usd",
-		Status:         "paid",
+		Amount:         invoice.AmountPaid,
+		Currency:       invoice.Currency,
+		StripeID:       invoice.ID,
+		PaymentIntentID: invoice.PaymentIntentID,
+		Status:         models.PaymentStatusPaid,
 		Description:    string(req.Plan) + " subscription",
-		PaidAt:         timeNowPtr(),
+		PaidAt:         paidAt,
 	}
 
-	if err := s.subRepo.CreatePayment(ctx, payment); err != nil {
+	recorded, err := s.subRepo.RecordPaidInvoice(ctx, payment)
+	if err != nil {
+		// The charge went through, so keep the subscription and flag it
 		s.log.Error("failed to record payment",
 			logger.ErrorField(err),
-			logger.String("userID", userID))
-		return nil, models.ErrPaymentFailed
+			logger.String("userID", userID),
+			logger.String("invoice_id", invoice.ID))
 	}
 
-	// Add credits based on plan
-	if err := s.addPlanCredits(ctx, userID, req.Plan); err != nil {
-		s.log.Error("failed to add plan credits",
-			logger.ErrorField(err),
-			logger.String("userID", userID))
+	s.recordSubscription(ctx, userID, models.AuditSubscriptionCreate, subscription, nil, "")
+
+	// Add credits based on plan, unless the invoice webhook already did
+	if recorded || err != nil {
+		if err := grantPlanCredits(ctx, s.creditRepo, subscription, plan); err != nil {
+			s.log.Error("failed to add plan credits",
+				logger.ErrorField(err),
+				logger.String("userID", userID))
+		}
+	}
+
+	completed = true
+	if redemption != nil {
+		redemption.SubscriptionID = subscription.ID
+		if err := s.promoRepo.UpdateRedemption(ctx, redemption); err != nil {
+			s.log.Error("failed to link coupon redemption",
+				logger.ErrorField(err),
+				logger.String("userID", userID))
+		}
 	}
 
 	return subscription, nil
 }
 
+// reserveCoupon redeems a discount promo code for a new subscription and
+// returns the provider coupon to charge with. An empty code reserves nothing.
+func (s *subscriptionService) reserveCoupon(ctx context.Context, userID, code string, plan *models.Plan) (*models.PromoCodeRedemption, string, error) {
+	if code == "" {
+		return nil, "", nil
+	}
+
+	promo, err := findPromoCode(ctx, s.promoRepo, code)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkPromoCode(promo, models.PromoCodeDiscount, plan.Code, time.Now()); err != nil {
+		return nil, "", err
+	}
+
+	redemption := &models.PromoCodeRedemption{UserID: userID}
+	if err := s.promoRepo.Redeem(ctx, promo, redemption, nil); err != nil {
+		return nil, "", err
+	}
+	return redemption, promo.ProviderCouponID, nil
+}
+
 func (s *subscriptionService) GetUserSubscription(ctx context.Context, userID string) (*models.Subscription, error) {
 	return s.subRepo.GetUserSubscription(ctx, userID)
 }
 
-func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID string, req *models.UpdateSubscriptionRequest) (*models.Subscription, error) {
-	// Validate new plan
-	if !s.isValidPlan(req.Plan) {
-		return nil, models.ErrInvalidPlan
+func (s *subscriptionService) GetWorkspaceSubscription(ctx context.Context, workspaceID, userID string) (*models.Subscription, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewBilling); err != nil {
+		return nil, err
+	}
+	return s.subRepo.GetWorkspaceSubscription(ctx, workspaceID)
+}
+
+// activeSubscription returns the workspace's subscription when workspaceID
+// is set, and the user's personal one otherwise.
+func (s *subscriptionService) activeSubscription(ctx context.Context, userID string, workspaceID *string) (*models.Subscription, error) {
+	if workspaceID != nil {
+		return s.subRepo.GetWorkspaceSubscription(ctx, *workspaceID)
+	}
+	return s.subRepo.GetUserSubscription(ctx, userID)
+}
+
+// PreviewPlanChange prices a plan change without applying it.
+func (s *subscriptionService) PreviewPlanChange(ctx context.Context, userID string, code models.SubscriptionPlan) (*models.PlanChangePreview, error) {
+	sub, err := s.subRepo.GetUserSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
+	return s.previewSubscriptionChange(ctx, sub, code)
+}
 
+func (s *subscriptionService) PreviewWorkspacePlanChange(ctx context.Context, workspaceID, userID string, code models.SubscriptionPlan) (*models.PlanChangePreview, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewBilling); err != nil {
+		return nil, err
+	}
+
+	sub, err := s.subRepo.GetWorkspaceSubscription(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.previewSubscriptionChange(ctx, sub, code)
+}
+
+func (s *subscriptionService) previewSubscriptionChange(ctx context.Context, sub *models.Subscription, code models.SubscriptionPlan) (*models.PlanChangePreview, error) {
+	target, err := s.activePlan(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == models.SubscriptionStatusTrialing {
+		return nil, models.ErrSubscriptionTrialing
+	}
+
+	current, err := subscriptionPlan(ctx, s.planRepo, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.previewPlanChange(sub, current, target, time.Now())
+}
+
+// UpdateSubscription changes the plan. Upgrades are charged the prorated
+// difference and credited right away; downgrades are scheduled for the end of
+// the current period. Choosing the current plan again cancels a scheduled
+// downgrade. Prices are compared against the subscriber's own plan version,
+// so grandfathered terms are honoured until they switch.
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, userID string, req *models.UpdateSubscriptionRequest) (*models.Subscription, error) {
 	// Get current subscription
 	sub, err := s.subRepo.GetUserSubscription(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	return s.changePlan(ctx, userID, sub, req.Plan)
+}
+
+// UpdateWorkspaceSubscription changes a workspace's plan. The gateway charges
+// upgrades to the member who subscribed.
+func (s *subscriptionService) UpdateWorkspaceSubscription(ctx context.Context, workspaceID, userID string, req *models.UpdateSubscriptionRequest) (*models.Subscription, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageBilling); err != nil {
+		return nil, err
+	}
+
+	sub, err := s.subRepo.GetWorkspaceSubscription(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.changePlan(ctx, userID, sub, req.Plan)
+}
+
+// changePlan applies a plan change made by userID and records it in the
+// audit log.
+func (s *subscriptionService) changePlan(ctx context.Context, userID string, sub *models.Subscription, code models.SubscriptionPlan) (*models.Subscription, error) {
+	before := subscriptionAuditFields(sub)
+	changed, err := s.applyPlanChange(ctx, sub, code)
+	if err != nil {
+		return nil, err
+	}
+	s.recordSubscription(ctx, userID, models.AuditSubscriptionChange, changed, before, "")
+	return changed, nil
+}
+
+func (s *subscriptionService) applyPlanChange(ctx context.Context, sub *models.Subscription, code models.SubscriptionPlan) (*models.Subscription, error) {
+	if sub.Status == models.SubscriptionStatusTrialing {
+		return nil, models.ErrSubscriptionTrialing
+	}
 
-	// Update plan
-	sub.Plan = req.Plan
+	current, err := subscriptionPlan(ctx, s.planRepo, sub)
+	if err != nil {
+		return nil, err
+	}
+
+	// The current plan may since have been retired, so this is checked
+	// before the catalog lookup
+	if code == sub.Plan && sub.PendingPlan != "" {
+		return s.cancelPendingDowngrade(ctx, sub, current)
+	}
+
+	target, err := s.activePlan(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	preview, err := s.previewPlanChange(sub, current, target, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if preview.Type == models.PlanChangeDowngrade {
+		return s.scheduleDowngrade(ctx, sub, target)
+	}
+	return s.upgrade(ctx, sub, target, preview, now)
+}
+
+func (s *subscriptionService) previewPlanChange(sub *models.Subscription, current, target *models.Plan, now time.Time) (*models.PlanChangePreview, error) {
+	if target.Code == sub.Plan {
+		return nil, models.ErrPlanUnchanged
+	}
+	if target.Interval != current.Interval || target.Currency != current.Currency {
+		return nil, models.ErrPlanIntervalMismatch
+	}
+
+	preview := &models.PlanChangePreview{
+		CurrentPlan: sub.Plan,
+		NewPlan:     target.Code,
+		Currency:    target.Currency,
+		PeriodEnd:   sub.ExpiresAt,
+	}
+
+	if target.Price < current.Price {
+		preview.Type = models.PlanChangeDowngrade
+		preview.EffectiveAt = sub.ExpiresAt
+		return preview, nil
+	}
+
+	preview.Type = models.PlanChangeUpgrade
+	preview.EffectiveAt = now
+	preview.AmountDue = payment.ProratedAmount(current.Price, target.Price, periodStart(sub, current), sub.ExpiresAt, now)
+	preview.CreditDelta = target.Credits - current.Credits
+	return preview, nil
+}
+
+func (s *subscriptionService) upgrade(ctx context.Context, sub *models.Subscription, target *models.Plan, preview *models.PlanChangePreview, now time.Time) (*models.Subscription, error) {
+	if sub.StripeID == "" {
+		// Nothing on file to charge the difference to
+		return nil, models.ErrPaymentFailed
+	}
+
+	gwSub, err := s.gateway.UpdateSubscription(ctx, sub.StripeID, payment.SubscriptionUpdateParams{
+		PriceID:       s.planPriceID(target),
+		Amount:        target.Price,
+		Prorate:       true,
+		ProrationDate: now,
+		Metadata:      map[string]string{"user_id": sub.UserID, "plan": string(target.Code), "plan_id": target.ID},
+	})
+	if err != nil {
+		return nil, s.paymentError("failed to upgrade gateway subscription", sub.UserID, err)
+	}
+
+	sub.Plan = target.Code
+	sub.PlanID = target.ID
+	sub.PendingPlan = ""
+	sub.PendingPlanID = ""
 	if err := s.subRepo.UpdateSubscription(ctx, sub); err != nil {
 		s.log.Error("failed to update subscription",
 			logger.ErrorField(err),
-			logger.String("userID", userID))
+			logger.String("userID", sub.UserID))
 		return nil, err
 	}
 
+	if inv := gwSub.LatestInvoice; preview.AmountDue > 0 && inv != nil && inv.Status == payment.InvoicePaid {
+		if _, err := s.subRepo.RecordPaidInvoice(ctx, &models.Payment{
+			UserID:          sub.UserID,
+			SubscriptionID:  sub.ID,
+			Amount:          inv.AmountPaid,
+			Currency:        inv.Currency,
+			StripeID:        inv.ID,
+			PaymentIntentID: inv.PaymentIntentID,
+			Status:          models.PaymentStatusPaid,
+			Description:     "upgrade to " + string(preview.NewPlan) + " (prorated)",
+			PaidAt:          inv.PaidAt,
+		}); err != nil {
+			s.log.Error("failed to record upgrade payment",
+				logger.ErrorField(err),
+				logger.String("userID", sub.UserID),
+				logger.String("invoice_id", inv.ID))
+		}
+	}
+
+	if preview.CreditDelta > 0 {
+		credit := &models.Credit{
+			UserID:      sub.UserID,
+			WorkspaceID: sub.WorkspaceID,
+			Type:        models.CreditTypePaid,
+			Amount:      preview.CreditDelta,
+			Remaining:   preview.CreditDelta,
+			Description: string(preview.NewPlan) + " upgrade credits",
+		}
+		if err := s.creditRepo.AddCredits(ctx, credit); err != nil {
+			s.log.Error("failed to add upgrade credits",
+				logger.ErrorField(err),
+				logger.String("userID", sub.UserID))
+		} else {
+			event := creditGrantEvent(ctx, "", credit)
+			event.ActorType = models.AuditActorSystem
+			event.SetMetadata(map[string]string{"source": "upgrade", "subscription_id": sub.ID})
+			s.audit.Record(ctx, event)
+		}
+	}
+
+	return sub, nil
+}
+
+// scheduleDowngrade moves the gateway to the cheaper price from the next
+// period and keeps the current plan until then. The renewal applies it.
+func (s *subscriptionService) scheduleDowngrade(ctx context.Context, sub *models.Subscription, target *models.Plan) (*models.Subscription, error) {
+	if sub.StripeID != "" {
+		if _, err := s.gateway.UpdateSubscription(ctx, sub.StripeID, payment.SubscriptionUpdateParams{
+			PriceID:  s.planPriceID(target),
+			Amount:   target.Price,
+			Metadata: map[string]string{"user_id": sub.UserID, "plan": string(target.Code), "plan_id": target.ID},
+		}); err != nil {
+			return nil, s.paymentError("failed to schedule gateway downgrade", sub.UserID, err)
+		}
+	}
+
+	sub.PendingPlan = target.Code
+	sub.PendingPlanID = target.ID
+	if err := s.subRepo.UpdateSubscription(ctx, sub); err != nil {
+		s.log.Error("failed to schedule downgrade",
+			logger.ErrorField(err),
+			logger.String("userID", sub.UserID))
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *subscriptionService) cancelPendingDowngrade(ctx context.Context, sub *models.Subscription, current *models.Plan) (*models.Subscription, error) {
+	if sub.StripeID != "" {
+		if _, err := s.gateway.UpdateSubscription(ctx, sub.StripeID, payment.SubscriptionUpdateParams{
+			PriceID:  s.planPriceID(current),
+			Amount:   current.Price,
+			Metadata: map[string]string{"user_id": sub.UserID, "plan": string(current.Code), "plan_id": current.ID},
+		}); err != nil {
+			return nil, s.paymentError("failed to restore gateway plan", sub.UserID, err)
+		}
+	}
+
+	sub.PendingPlan = ""
+	sub.PendingPlanID = ""
+	if err := s.subRepo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
 	return sub, nil
 }
 
 func (s *subscriptionService) CancelSubscription(ctx context.Context, userID string, req *models.CancelSubscriptionRequest) error {
-	return s.subRepo.CancelSubscription(ctx, userID)
+	sub, err := s.subRepo.GetUserSubscription(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cancelGatewaySubscription(ctx, sub); err != nil {
+		return err
+	}
+	if err := s.subRepo.CancelSubscription(ctx, userID); err != nil {
+		return err
+	}
+	s.recordCancel(ctx, userID, sub, req)
+
+	// Canceling during a trial ends it; the trial credits run out on their own
+	if sub.Status == models.SubscriptionStatusTrialing {
+		if trial, err := s.trialRepo.GetBySubscription(ctx, sub.ID); err == nil {
+			trial.Status = models.TrialCanceled
+			trial.EndedAt = timeNowPtr()
+			_ = s.trialRepo.End(ctx, trial)
+		}
+	}
+	return nil
+}
+
+func (s *subscriptionService) CancelWorkspaceSubscription(ctx context.Context, workspaceID, userID string, req *models.CancelSubscriptionRequest) error {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageBilling); err != nil {
+		return err
+	}
+
+	sub, err := s.subRepo.GetWorkspaceSubscription(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cancelGatewaySubscription(ctx, sub); err != nil {
+		return err
+	}
+	if err := s.subRepo.CancelWorkspaceSubscription(ctx, workspaceID); err != nil {
+		return err
+	}
+	s.recordCancel(ctx, userID, sub, req)
+	return nil
+}
+
+func (s *subscriptionService) cancelGatewaySubscription(ctx context.Context, sub *models.Subscription) error {
+	if sub.StripeID == "" {
+		return nil
+	}
+	if _, err := s.gateway.CancelSubscription(ctx, sub.StripeID, false); err != nil {
+		s.log.Error("failed to cancel gateway subscription",
+			logger.ErrorField(err),
+			logger.String("userID", sub.UserID))
+		return err
+	}
+	return nil
+}
+
+// StartTrial puts a verified user who has never subscribed on a paid plan
+// for the configured trial length, with the plan's credits valid until the
+// trial ends. A payment method can be given now or added later; with one on
+// file the trial converts to a paid subscription when it ends.
+func (s *subscriptionService) StartTrial(ctx context.Context, userID string, req *models.StartTrialRequest) (*models.Trial, error) {
+	plan, err := s.activePlan(ctx, req.Plan)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Price == 0 {
+		return nil, models.ErrInvalidPlan
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsVerified {
+		return nil, models.ErrUserNotVerified
+	}
+
+	if _, err := s.subRepo.GetUserSubscription(ctx, userID); err == nil {
+		return nil, models.ErrActiveSubscriptionExists
+	} else if !errors.Is(err, models.ErrSubscriptionNotActive) {
+		return nil, err
+	}
+	if _, err := s.trialRepo.GetByUser(ctx, userID); err == nil {
+		return nil, models.ErrTrialUnavailable
+	} else if !errors.Is(err, models.ErrTrialNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	endsAt := now.Add(s.trialLength())
+	trial := &models.Trial{
+		UserID:    userID,
+		Plan:      plan.Code,
+		PlanID:    plan.ID,
+		Status:    models.TrialActive,
+		StartedAt: now,
+		EndsAt:    endsAt,
+	}
+	if req.Token != "" {
+		if err := s.attachTrialPaymentMethod(ctx, trial, req.Token); err != nil {
+			return nil, err
+		}
+	}
+
+	// The scheduler picks the subscription up when the trial ends
+	sub := &models.Subscription{
+		UserID:    userID,
+		Plan:      plan.Code,
+		PlanID:    plan.ID,
+		Status:    models.SubscriptionStatusTrialing,
+		IsActive:  true,
+		StartsAt:  now,
+		ExpiresAt: endsAt,
+		RenewsAt:  &endsAt,
+	}
+
+	var credit *models.Credit
+	if plan.Credits > 0 {
+		credit = &models.Credit{
+			UserID:      userID,
+			Type:        models.CreditTypeTrial,
+			Amount:      plan.Credits,
+			ExpiresAt:   &endsAt,
+			Description: string(plan.Code) + " trial credits",
+		}
+	}
+
+	if err := s.trialRepo.Start(ctx, trial, sub, credit); err != nil {
+		return nil, err
+	}
+
+	s.recordSubscription(ctx, userID, models.AuditTrialStart, sub, nil, "")
+
+	s.log.Info("Trial started",
+		logger.String("userID", userID),
+		logger.String("plan", string(plan.Code)),
+		logger.String("ends_at", endsAt.Format(time.RFC3339)))
+	return trial, nil
+}
+
+func (s *subscriptionService) GetTrial(ctx context.Context, userID string) (*models.Trial, error) {
+	return s.trialRepo.GetByUser(ctx, userID)
+}
+
+// SetTrialPaymentMethod puts a payment method on file for an active trial so
+// it converts to paid when it ends.
+func (s *subscriptionService) SetTrialPaymentMethod(ctx context.Context, userID, token string) (*models.Trial, error) {
+	if token == "" {
+		return nil, models.ErrInvalidInput
+	}
+
+	trial, err := s.trialRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if trial.Status != models.TrialActive {
+		return nil, models.ErrSubscriptionNotActive
+	}
+
+	if err := s.attachTrialPaymentMethod(ctx, trial, token); err != nil {
+		return nil, err
+	}
+	if err := s.trialRepo.SetPaymentMethod(ctx, trial); err != nil {
+		return nil, err
+	}
+	return trial, nil
+}
+
+// attachTrialPaymentMethod saves the card with the provider and copies it
+// onto the trial. The fingerprint is checked when the trial is stored.
+func (s *subscriptionService) attachTrialPaymentMethod(ctx context.Context, trial *models.Trial, token string) error {
+	customerID, err := s.ensureCustomer(ctx, trial.UserID)
+	if err != nil {
+		return err
+	}
+
+	pm, err := s.gateway.AttachPaymentMethod(ctx, customerID, token)
+	if err != nil {
+		return s.paymentError("failed to attach payment method", trial.UserID, err)
+	}
+
+	trial.PaymentMethodID = pm.ID
+	trial.PaymentMethodBrand = pm.Brand
+	trial.PaymentMethodLast4 = pm.Last4
+	trial.PaymentFingerprint = nil
+	if pm.Fingerprint != "" {
+		fingerprint := pm.Fingerprint
+		trial.PaymentFingerprint = &fingerprint
+	}
+	return nil
+}
+
+func (s *subscriptionService) trialLength() time.Duration {
+	if s.cfg.Trial.Length <= 0 {
+		return 14 * 24 * time.Hour
+	}
+	return s.cfg.Trial.Length
 }
 
 func (s *subscriptionService) GetSubscriptionPlans(ctx context.Context) ([]*models.SubscriptionPlanResponse, error) {
-	return []*models.SubscriptionPlanResponse{
-		{
-			ID:          "basic",
-			Name:        "Basic",
-			Description: "Basic plan with limited features",
-			Price:       999, // $9.99 in cents
-			Currency:    "usd",
-			URLsAllowed: 100,
-			Features:    []string{"100 URLs/month", "Basic analytics"},
-		},
-		{
-			ID:          "pro",
-			Name:        "Pro",
-			Description: "Professional plan with advanced features",
-			Price:       1999, // $19.99 in cents
-			Currency:    "usd",
-			URLsAllowed: 500,
-			Features:    []string{"500 URLs/month", "Advanced analytics", "Priority support"},
-		},
-		{
-			ID:          "enterprise",
-			Name:        "Enterprise",
-			Description: "Enterprise plan with unlimited features",
-			Price:       4999, // $49.99 in cents
-			Currency:    "usd",
-			URLsAllowed: 0, // 0 means unlimited
-			Features:    []string{"Unlimited URLs", "All features", "24/7 support"},
-		},
-	}, nil
-}
-
-func (s *subscriptionService) GetPaymentHistory(ctx context.Context, userID string) ([]*models.Payment, error) {
-	return s.subRepo.GetUserPayments(ctx, userID)
-}
-
-func (s *subscriptionService) isValidPlan(plan models.SubscriptionPlan) bool {
-	switch plan {
-	case models.PlanBasic, models.PlanPro, models.PlanEnterprise:
-		return true
-	default:
-		return false
-	}
-}
-
-func (s *subscriptionService) getPlanPrice(plan models.SubscriptionPlan) int {
-	switch plan {
-	case models.PlanBasic:
-		return 999
-	case models.PlanPro:
-		return 1999
-	case models.PlanEnterprise:
-		return 4999
-	default:
-		return 0
+	plans, err := s.planRepo.ListLatest(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*models.SubscriptionPlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		response = append(response, &models.SubscriptionPlanResponse{
+			ID:          string(plan.Code),
+			Name:        plan.Name,
+			Description: plan.Description,
+			Price:       plan.Price,
+			Currency:    plan.Currency,
+			Interval:    string(plan.Interval),
+			Credits:     plan.Credits,
+			URLsAllowed: plan.URLLimit, // 0 means unlimited
+			Features:    plan.Features,
+		})
 	}
+	return response, nil
 }
 
-func (s *subscriptionService) addPlanCredits(ctx context.Context, userID string, plan models.SubscriptionPlan) error {
-	var credits int
-	switch plan {
+func (s *subscriptionService) GetPaymentHistory(ctx context.Context, userID string, p pagination.Params) ([]*models.Payment, *pagination.Page, error) {
+	return s.subRepo.GetUserPayments(ctx, userID, p)
+}
+
+// activePlan returns the terms new subscribers get for a plan code.
+func (s *subscriptionService) activePlan(ctx context.Context, code models.SubscriptionPlan) (*models.Plan, error) {
+	plan, err := s.planRepo.GetLatest(ctx, code)
+	if errors.Is(err, models.ErrPlanNotFound) || (err == nil && !plan.IsActive) {
+		return nil, models.ErrInvalidPlan
+	}
+	return plan, err
+}
+
+// planPriceID is the provider price for a plan version. Plans without one
+// fall back to the configured price for their code.
+func (s *subscriptionService) planPriceID(plan *models.Plan) string {
+	return planPriceID(s.cfg.Payment.Prices, plan)
+}
+
+func planPriceID(prices configs.PlanPrices, plan *models.Plan) string {
+	if plan.ProviderPriceID != "" {
+		return plan.ProviderPriceID
+	}
+
+	var id string
+	switch plan.Code {
 	case models.PlanBasic:
-		credits = 100
+		id = prices.Basic
 	case models.PlanPro:
-		credits = 500
+		id = prices.Pro
 	case models.PlanEnterprise:
-		credits = 10000 // Essentially unlimited
-	default:
+		id = prices.Enterprise
+	}
+	if id == "" {
+		id = "price_" + string(plan.Code)
+	}
+	return id
+}
+
+func gatewayInterval(plan *models.Plan) string {
+	if plan.Interval == models.IntervalYearly {
+		return "year"
+	}
+	return "month"
+}
+
+// subscriptionPlan returns the plan version a subscription is billed on.
+// Subscriptions from before the catalog fall back to the plan's latest
+// version.
+func subscriptionPlan(ctx context.Context, planRepo interfaces.PlanRepository, sub *models.Subscription) (*models.Plan, error) {
+	if sub.PlanID != "" {
+		return planRepo.GetByID(ctx, sub.PlanID)
+	}
+	return planRepo.GetLatest(ctx, sub.Plan)
+}
+
+// periodStart is when the subscription's current billing period began.
+func periodStart(sub *models.Subscription, plan *models.Plan) time.Time {
+	start := plan.PeriodBefore(sub.ExpiresAt)
+	if sub.StartsAt.After(start) {
+		return sub.StartsAt
+	}
+	return start
+}
+
+// subscriptionAuditFields are the subscription fields recorded in the audit log
+func subscriptionAuditFields(sub *models.Subscription) map[string]interface{} {
+	return map[string]interface{}{
+		"plan":         sub.Plan,
+		"pending_plan": sub.PendingPlan,
+		"status":       sub.Status,
+		"is_active":    sub.IsActive,
+		"expires_at":   sub.ExpiresAt,
+	}
+}
+
+// recordSubscription adds a change userID made to sub to the audit log.
+// before is the subscription's audit fields ahead of the change, nil for a
+// new subscription.
+func (s *subscriptionService) recordSubscription(ctx context.Context, userID string, action models.AuditAction, sub *models.Subscription, before map[string]interface{}, reason string) {
+	event := userEvent(ctx, userID, action, models.AuditTargetSubscription, sub.ID)
+	event.Reason = reason
+	event.SetChanges(before, subscriptionAuditFields(sub))
+	if sub.WorkspaceID != nil {
+		event.SetMetadata(map[string]string{"workspace_id": *sub.WorkspaceID})
+	}
+	s.audit.Record(ctx, event)
+}
+
+func (s *subscriptionService) recordCancel(ctx context.Context, userID string, sub *models.Subscription, req *models.CancelSubscriptionRequest) {
+	before := subscriptionAuditFields(sub)
+	canceled := *sub
+	canceled.IsActive = false
+	s.recordSubscription(ctx, userID, models.AuditSubscriptionCancel, &canceled, before, req.Reason)
+}
+
+// grantPlanCredits adds one billing period's worth of paid credits to
+// whoever owns the subscription.
+func grantPlanCredits(ctx context.Context, creditRepo interfaces.CreditRepository, sub *models.Subscription, plan *models.Plan) error {
+	if plan.Credits == 0 {
 		return nil
 	}
 
 	credit := &models.Credit{
-		UserID:      userID,
+		UserID:      sub.UserID,
+		WorkspaceID: sub.WorkspaceID,
 		Type:        models.CreditTypePaid,
-		Amount:      credits,
-		Remaining:   credits,
-		Description: string(plan) + " subscription credits",
+		Amount:      plan.Credits,
+		Remaining:   plan.Credits,
+		Description: string(plan.Code) + " subscription credits",
+	}
+
+	return creditRepo.AddCredits(ctx, credit)
+}
+
+// ensureCustomer returns the user's payment provider customer, creating it on
+// first use.
+func (s *subscriptionService) ensureCustomer(ctx context.Context, userID string) (string, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.PaymentCustomerID != "" {
+		return user.PaymentCustomerID, nil
+	}
+
+	customer, err := s.gateway.CreateCustomer(ctx, payment.CustomerParams{
+		Email:    user.Email,
+		Name:     user.FirstName + " " + user.LastName,
+		Metadata: map[string]string{"user_id": user.ID},
+	})
+	if err != nil {
+		return "", s.paymentError("failed to create payment customer", userID, err)
+	}
+
+	if err := s.userRepo.SetPaymentCustomerID(ctx, userID, customer.ID); err != nil {
+		return "", err
+	}
+	return customer.ID, nil
+}
+
+// abandonGatewaySubscription undoes a gateway subscription that could not be
+// completed locally, refunding anything that was charged.
+func (s *subscriptionService) abandonGatewaySubscription(ctx context.Context, gwSub *payment.Subscription) {
+	abandonGatewaySubscription(ctx, s.gateway, gwSub, s.log)
+}
+
+func abandonGatewaySubscription(ctx context.Context, gateway payment.PaymentGateway, gwSub *payment.Subscription, log logger.Logger) {
+	if _, err := gateway.CancelSubscription(ctx, gwSub.ID, false); err != nil {
+		log.Error("failed to cancel abandoned gateway subscription",
+			logger.ErrorField(err),
+			logger.String("subscription_id", gwSub.ID))
+	}
+
+	invoice := gwSub.LatestInvoice
+	if invoice == nil || invoice.AmountPaid == 0 || invoice.PaymentIntentID == "" {
+		return
+	}
+	if _, err := gateway.Refund(ctx, payment.RefundParams{
+		PaymentIntentID: invoice.PaymentIntentID,
+		Reason:          "requested_by_customer",
+	}); err != nil {
+		log.Error("failed to refund abandoned subscription",
+			logger.ErrorField(err),
+			logger.String("subscription_id", gwSub.ID),
+			logger.String("invoice_id", invoice.ID))
+	}
+}
+
+// paymentError maps declined payments to ErrPaymentFailed and keeps other
+// provider failures as internal errors.
+func (s *subscriptionService) paymentError(msg, userID string, err error) error {
+	var perr *payment.Error
+	if errors.As(err, &perr) && (perr.Declined() || perr.StatusCode == http.StatusPaymentRequired) {
+		s.log.Info("payment declined",
+			logger.String("userID", userID),
+			logger.String("code", perr.Code),
+			logger.String("decline_code", perr.DeclineCode))
+		return models.ErrPaymentFailed
 	}
 
-	return s.creditRepo.AddCredits(ctx, credit)
+	s.log.Error(msg, logger.ErrorField(err), logger.String("userID", userID))
+	return fmt.Errorf("%s: %w", msg, err)
 }
 
 func timeNowPtr() *time.Time {
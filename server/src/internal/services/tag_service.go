@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+type tagService struct {
+	repo        interfaces.TagRepository
+	urlRepo     interfaces.URLRepository
+	permissions interfaces.PermissionService
+	log         logger.Logger
+	baseURL     string
+}
+
+func NewTagService(
+	repo interfaces.TagRepository,
+	urlRepo interfaces.URLRepository,
+	permissions interfaces.PermissionService,
+	log logger.Logger,
+	baseURL string,
+) interfaces.TagService {
+	return &tagService{
+		repo:        repo,
+		urlRepo:     urlRepo,
+		permissions: permissions,
+		log:         log,
+		baseURL:     baseURL,
+	}
+}
+
+func (s *tagService) ListTags(ctx context.Context, userID, workspaceID string) ([]*models.Tag, error) {
+	if workspaceID != "" {
+		if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewLinks); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.ListTags(ctx, userID, workspaceID)
+}
+
+// DeleteTag deletes the tag and takes it off its links
+func (s *tagService) DeleteTag(ctx context.Context, userID, id string) error {
+	tag, err := s.repo.GetTag(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeScope(ctx, s.permissions, userID, tag.UserID, tag.WorkspaceID, models.ErrTagNotFound); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTag(ctx, id); err != nil {
+		return err
+	}
+
+	s.log.Info("Tag deleted",
+		logger.String("tagID", id),
+		logger.String("userID", userID))
+	return nil
+}
+
+// SetURLTags replaces the link's tags. Names are matched ignoring case and
+// surrounding spaces; an empty list removes every tag.
+func (s *tagService) SetURLTags(ctx context.Context, userID, urlID string, req *models.SetURLTagsRequest) (*models.URLResponse, error) {
+	u, err := s.urlRepo.GetByID(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	if err := canOrganizeURL(ctx, s.permissions, userID, u); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(req.Tags))
+	seen := make(map[string]bool, len(req.Tags))
+	for _, tag := range req.Tags {
+		name := models.TagName(tag)
+		if name == "" {
+			return nil, models.ErrInvalidInput
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if err := s.repo.SetURLTags(ctx, u, userID, names); err != nil {
+		return nil, err
+	}
+	return u.ToResponse(s.baseURL), nil
+}
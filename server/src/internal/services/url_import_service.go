@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/events"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+const (
+	importBatchSize = 10
+	// Long enough for the largest import to be created in one transaction
+	importLease = 10 * time.Minute
+)
+
+type urlImportService struct {
+	repo         interfaces.URLImportRepository
+	permissions  interfaces.PermissionService
+	audit        interfaces.AuditService
+	bus          *events.Bus
+	cfg          *configs.BulkConfig
+	log          logger.Logger
+	baseURL      string
+	authURLLimit int
+
+	// wake starts the import worker early when an import is queued
+	wake chan struct{}
+}
+
+func NewURLImportService(
+	repo interfaces.URLImportRepository,
+	permissions interfaces.PermissionService,
+	audit interfaces.AuditService,
+	bus *events.Bus,
+	cfg *configs.BulkConfig,
+	log logger.Logger,
+	baseURL string,
+	authURLLimit int,
+) interfaces.URLImportService {
+	return &urlImportService{
+		repo:         repo,
+		permissions:  permissions,
+		audit:        audit,
+		bus:          bus,
+		cfg:          cfg,
+		log:          log,
+		baseURL:      baseURL,
+		authURLLimit: authURLLimit,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+func (s *urlImportService) CreateURLs(ctx context.Context, userID string, req *models.CreateBulkURLRequest) (*models.URLImport, error) {
+	if len(req.URLs) > s.cfg.MaxRows {
+		return nil, models.ErrTooManyRows
+	}
+	return s.submit(ctx, userID, req.WorkspaceID, "json", req.URLs)
+}
+
+// ImportCSV reads links from a CSV file. The first line names the columns:
+// original_url is required, and custom_code, title, description and
+// expires_at are optional. Other columns are ignored.
+func (s *urlImportService) ImportCSV(ctx context.Context, userID, workspaceID string, r io.Reader) (*models.URLImport, error) {
+	rows, err := parseURLCSV(r, s.cfg.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, models.ErrInvalidCSV
+	}
+	return s.submit(ctx, userID, workspaceID, "csv", rows)
+}
+
+// submit creates small batches straight away and queues the rest for the
+// import worker. A batch that can't be paid for in full creates nothing.
+func (s *urlImportService) submit(ctx context.Context, userID, workspaceID, source string, rows []*models.BulkURLRow) (*models.URLImport, error) {
+	imp := &models.URLImport{
+		UserID:    userID,
+		Source:    source,
+		TotalRows: len(rows),
+		Rows:      rows,
+	}
+	if workspaceID != "" {
+		if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionEditLinks); err != nil {
+			return nil, err
+		}
+		imp.WorkspaceID = &workspaceID
+	}
+
+	if len(rows) <= s.cfg.SyncLimit {
+		now := time.Now()
+		imp.StartedAt = &now
+		imp.CreatedAt, imp.UpdatedAt = now, now
+
+		batch := s.newBatch(imp)
+		if err := s.repo.CreateBatch(ctx, batch, nil); err != nil {
+			return nil, err
+		}
+		imp.Finish(models.URLImportCompleted, batch.Results(), time.Now())
+		s.created(ctx, batch, imp)
+		return imp, nil
+	}
+
+	imp.Status = models.URLImportPending
+	if err := s.repo.CreateImport(ctx, imp); err != nil {
+		return nil, err
+	}
+	s.notify()
+
+	s.log.Info("URL import queued",
+		logger.String("importID", imp.ID),
+		logger.String("userID", userID),
+		logger.Int("rows", imp.TotalRows))
+	return imp, nil
+}
+
+func (s *urlImportService) GetImport(ctx context.Context, userID, id string) (*models.URLImport, error) {
+	return s.repo.GetImport(ctx, id, userID)
+}
+
+func (s *urlImportService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessImports runs the queued imports. Each one is claimed first, so
+// several instances can run the worker at once.
+func (s *urlImportService) ProcessImports(ctx context.Context) error {
+	now := time.Now()
+	ids, err := s.repo.GetPendingImportIDs(ctx, now, importBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		imp, ok, err := s.repo.ClaimImport(ctx, id, now, time.Now().Add(importLease))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := s.process(ctx, imp); err != nil {
+			s.log.Error("URL import failed",
+				logger.ErrorField(err),
+				logger.String("importID", imp.ID))
+		}
+	}
+	return nil
+}
+
+// process creates an import's links. The import fails as a whole if the
+// user lost access to its workspace or can't pay for every valid row; other
+// errors leave it to be retried once its lease runs out.
+func (s *urlImportService) process(ctx context.Context, imp *models.URLImport) error {
+	batch := s.newBatch(imp)
+
+	if imp.WorkspaceID != nil {
+		if _, err := s.permissions.Require(ctx, *imp.WorkspaceID, imp.UserID, models.PermissionEditLinks); err != nil {
+			if err != models.ErrForbidden && err != models.ErrWorkspaceNotFound {
+				return err
+			}
+			return s.fail(ctx, imp, batch, models.BulkErrImportFailed, err)
+		}
+	}
+
+	err := s.repo.CreateBatch(ctx, batch, imp)
+	if err == models.ErrInsufficientCredits {
+		return s.fail(ctx, imp, batch, models.BulkErrInsufficientCredit, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.created(ctx, batch, imp)
+	s.log.Info("URL import completed",
+		logger.String("importID", imp.ID),
+		logger.Int("created", imp.CreatedCount),
+		logger.Int("failed", imp.FailedCount))
+	return nil
+}
+
+// fail finishes the import without creating anything. Rows that were valid
+// are reported with code.
+func (s *urlImportService) fail(ctx context.Context, imp *models.URLImport, batch *models.URLBatch, code string, cause error) error {
+	for _, entry := range batch.Valid() {
+		entry.Error = code
+		entry.Message = cause.Error()
+	}
+
+	imp.Finish(models.URLImportFailed, batch.Results(), time.Now())
+	imp.Error = cause.Error()
+	return s.repo.UpdateImport(ctx, imp)
+}
+
+// created records and announces each link the batch created
+func (s *urlImportService) created(ctx context.Context, batch *models.URLBatch, imp *models.URLImport) {
+	for _, entry := range batch.Valid() {
+		u := entry.URL
+
+		event := userEvent(ctx, batch.UserID, models.AuditURLCreate, models.AuditTargetURL, u.ID)
+		event.SetChanges(nil, urlAuditFields(u))
+		metadata := map[string]string{"source": "bulk_" + imp.Source}
+		if imp.ID != "" {
+			metadata["import_id"] = imp.ID
+		}
+		if batch.WorkspaceID != nil {
+			metadata["workspace_id"] = *batch.WorkspaceID
+		}
+		event.SetMetadata(metadata)
+		s.audit.Record(ctx, event)
+
+		s.bus.Publish(ctx, events.New(events.URLCreated, batch.UserID, &urlEventData{
+			URL: u.ToResponse(s.baseURL),
+		}))
+	}
+}
+
+// newBatch checks each row by CreateURL's rules and gives the valid ones a
+// short code. A custom code may only be used once per batch; random codes
+// never repeat one already in it.
+func (s *urlImportService) newBatch(imp *models.URLImport) *models.URLBatch {
+	batch := &models.URLBatch{
+		UserID:      imp.UserID,
+		WorkspaceID: imp.WorkspaceID,
+		FreeLimit:   s.authURLLimit,
+		BaseURL:     s.baseURL,
+		Entries:     make([]*models.URLBatchEntry, len(imp.Rows)),
+		NewCode:     func() string { return generateShortCode(6) },
+	}
+
+	codes := make(map[string]bool)
+	for i, row := range imp.Rows {
+		entry := &models.URLBatchEntry{Row: i + 1}
+		batch.Entries[i] = entry
+
+		expiresAt, code, message := checkBulkRow(row)
+		if code == "" && row.CustomCode != "" && codes[row.CustomCode] {
+			code, message = models.BulkErrDuplicateCode, "custom_code is used by an earlier row"
+		}
+		if code != "" {
+			entry.Error, entry.Message = code, message
+			continue
+		}
+
+		userID := imp.UserID
+		entry.URL = &models.URL{
+			OriginalURL: row.OriginalURL,
+			ShortCode:   row.CustomCode,
+			UserID:      &userID,
+			WorkspaceID: imp.WorkspaceID,
+			Title:       row.Title,
+			Description: row.Description,
+			ExpiresAt:   expiresAt,
+			IsActive:    true,
+		}
+		if row.CustomCode != "" {
+			entry.Custom = true
+			codes[row.CustomCode] = true
+		}
+	}
+
+	// Random codes are picked once every custom code is known
+	for _, entry := range batch.Valid() {
+		if entry.Custom {
+			continue
+		}
+		code := batch.NewCode()
+		for codes[code] {
+			code = batch.NewCode()
+		}
+		codes[code] = true
+		entry.URL.ShortCode = code
+	}
+	return batch
+}
+
+// checkBulkRow returns the row's expiry, or the error code and message for
+// the first problem found
+func checkBulkRow(row *models.BulkURLRow) (*time.Time, string, string) {
+	if row == nil {
+		return nil, models.BulkErrInvalidRow, "row is empty"
+	}
+	if _, err := url.ParseRequestURI(row.OriginalURL); err != nil {
+		return nil, models.BulkErrInvalidURL, "original_url is not a valid URL"
+	}
+	if row.CustomCode != "" && !validCustomCode(row.CustomCode) {
+		return nil, models.BulkErrInvalidCustomCode, "custom_code must be 3 to 10 letters or digits"
+	}
+
+	var expiresAt *time.Time
+	if row.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, row.ExpiresAt)
+		if err != nil {
+			return nil, models.BulkErrInvalidExpiry, "expires_at must be an RFC 3339 time"
+		}
+		expiresAt = &t
+	}
+
+	if err := row.Validate(); err != nil {
+		return nil, models.BulkErrInvalidRow, err.Error()
+	}
+	return expiresAt, "", ""
+}
+
+// parseURLCSV reads up to maxRows links from a CSV file with a header line
+func parseURLCSV(r io.Reader, maxRows int) ([]*models.BulkURLRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, models.ErrInvalidCSV
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["original_url"]; !ok {
+		return nil, models.ErrInvalidCSV
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []*models.BulkURLRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, models.ErrInvalidCSV
+		}
+		if len(rows) == maxRows {
+			return nil, models.ErrTooManyRows
+		}
+
+		rows = append(rows, &models.BulkURLRow{
+			OriginalURL: field(record, "original_url"),
+			CustomCode:  field(record, "custom_code"),
+			Title:       field(record, "title"),
+			Description: field(record, "description"),
+			ExpiresAt:   field(record, "expires_at"),
+		})
+	}
+}
+
+// RunImportWorker calls ProcessImports every worker interval, and as soon as
+// an import is queued, until ctx is done.
+func (s *urlImportService) RunImportWorker(ctx context.Context) {
+	interval := s.cfg.WorkerInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessImports(ctx); err != nil {
+			s.log.Error("URL import run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
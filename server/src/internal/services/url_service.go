@@ -4,42 +4,58 @@ import (
 	"context"
 	"errors"
 	"net/url"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/events"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
 	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 )
 
 type urlService struct {
 	urlRepo       interfaces.URLRepository
 	creditRepo    interfaces.CreditRepository
 	analyticsRepo interfaces.AnalyticsRepository
+	permissions   interfaces.PermissionService
+	audit         interfaces.AuditService
+	bus           *events.Bus
 	logger        logger.Logger
 	baseURL       string
 	anonURLLimit  int // 5 for anonymous users
 	authURLLimit  int // 15 for authenticated users
+
+	expiryInterval time.Duration
 }
 
 func NewURLService(
 	urlRepo interfaces.URLRepository,
 	creditRepo interfaces.CreditRepository,
 	analyticsRepo interfaces.AnalyticsRepository,
+	permissions interfaces.PermissionService,
+	audit interfaces.AuditService,
+	bus *events.Bus,
 	logger logger.Logger,
 	baseURL string,
 	anonURLLimit int,
 	authURLLimit int,
+	expiryInterval time.Duration,
 ) interfaces.URLService {
 	return &urlService{
-		urlRepo:       urlRepo,
-		creditRepo:    creditRepo,
-		analyticsRepo: analyticsRepo,
-		logger:        logger,
-		baseURL:       baseURL,
-		anonURLLimit:  anonURLLimit,
-		authURLLimit:  authURLLimit,
+		urlRepo:        urlRepo,
+		creditRepo:     creditRepo,
+		analyticsRepo:  analyticsRepo,
+		permissions:    permissions,
+		audit:          audit,
+		bus:            bus,
+		logger:         logger,
+		baseURL:        baseURL,
+		anonURLLimit:   anonURLLimit,
+		authURLLimit:   authURLLimit,
+		expiryInterval: expiryInterval,
 	}
 }
 
@@ -56,7 +72,7 @@ func (s *urlService) CreateURL(ctx context.Context, req *models.CreateURLRequest
 	if shortCode == "" {
 		shortCode = generateShortCode(6)
 	} else {
-		if len(shortCode) < 3 || len(shortCode) > 10 || !isAlphanumeric(shortCode) {
+		if !validCustomCode(shortCode) {
 			s.logger.Debug("invalid custom code format",
 				logger.String("code", shortCode))
 			return nil, models.ErrInvalidInput
@@ -76,8 +92,34 @@ func (s *urlService) CreateURL(ctx context.Context, req *models.CreateURLRequest
 		return nil, err
 	}
 
+	// Workspace links are always paid for from the workspace's credits
+	var workspaceID *string
+	if req.WorkspaceID != "" {
+		if userID == "" {
+			return nil, models.ErrUnauthorized
+		}
+		if _, err := s.permissions.Require(ctx, req.WorkspaceID, userID, models.PermissionEditLinks); err != nil {
+			return nil, err
+		}
+
+		balance, err := s.creditRepo.GetWorkspaceCreditBalance(ctx, req.WorkspaceID)
+		if err != nil {
+			s.logger.Error("failed to get workspace credit balance",
+				logger.ErrorField(err),
+				logger.String("workspaceID", req.WorkspaceID))
+			return nil, err
+		}
+		if !balance.CanCreate {
+			s.logger.Warn("insufficient workspace credits",
+				logger.String("workspaceID", req.WorkspaceID),
+				logger.Any("balance", balance))
+			return nil, models.ErrInsufficientCredits
+		}
+		workspaceID = &req.WorkspaceID
+	}
+
 	// Check URL creation limits
-	if userID != "" {
+	if userID != "" && workspaceID == nil {
 		freeCount, err := s.creditRepo.GetFreeURLCount(ctx, userID)
 		if err != nil {
 			s.logger.Error("failed to get free URL count",
@@ -113,6 +155,7 @@ func (s *urlService) CreateURL(ctx context.Context, req *models.CreateURLRequest
 		OriginalURL: req.OriginalURL,
 		ShortCode:   shortCode,
 		UserID:      userIDPtr,
+		WorkspaceID: workspaceID,
 		CreatedByIP: ip,
 		Title:       req.Title,
 		Description: req.Description,
@@ -127,8 +170,24 @@ func (s *urlService) CreateURL(ctx context.Context, req *models.CreateURLRequest
 		return nil, err
 	}
 
-	// Record the URL creation for authenticated users
-	if userID != "" {
+	if workspaceID != nil {
+		// The balance checked above may be spent by now; the URL is removed
+		// if the credits can't be taken
+		err = s.creditRepo.UseWorkspaceCredits(ctx, *workspaceID, userID, 1, "url_creation", newURL.ID)
+		if err != nil {
+			s.logger.Error("failed to deduct workspace credits",
+				logger.ErrorField(err),
+				logger.String("workspaceID", *workspaceID),
+				logger.String("urlID", newURL.ID))
+			if delErr := s.urlRepo.Delete(ctx, newURL.ID); delErr != nil {
+				s.logger.Error("failed to rollback URL creation",
+					logger.ErrorField(delErr),
+					logger.String("urlID", newURL.ID))
+			}
+			return nil, err
+		}
+	} else if userID != "" {
+		// Record the URL creation for authenticated users
 		freeCount, _ := s.creditRepo.GetFreeURLCount(ctx, userID)
 		if freeCount < s.authURLLimit {
 			if err := s.creditRepo.RecordFreeURLCreation(ctx, userID, newURL.ID); err != nil {
@@ -144,12 +203,9 @@ func (s *urlService) CreateURL(ctx context.Context, req *models.CreateURLRequest
 				return nil, err
 			}
 		} else {
-			err = s.creditRepo.UseCredits(ctx, &models.CreditUsage{
-				UserID:    userID,
-				URLID:     newURL.ID,
-				Amount:    1,
-				Operation: "url_creation",
-			})
+			// The balance checked above may be spent by now; UseCredits
+			// checks it again under lock and the URL is removed if it fails
+			err = s.creditRepo.UseCredits(ctx, userID, 1, "url_creation", newURL.ID)
 			if err != nil {
 				s.logger.Error("failed to deduct credits",
 					logger.ErrorField(err),
@@ -165,6 +221,14 @@ func (s *urlService) CreateURL(ctx context.Context, req *models.CreateURLRequest
 		}
 	}
 
+	event := userEvent(ctx, userID, models.AuditURLCreate, models.AuditTargetURL, newURL.ID)
+	event.SetChanges(nil, urlAuditFields(newURL))
+	if workspaceID != nil {
+		event.SetMetadata(map[string]string{"workspace_id": *workspaceID})
+	}
+	s.audit.Record(ctx, event)
+	s.publish(ctx, events.URLCreated, newURL, nil)
+
 	s.logger.Info("URL created successfully",
 		logger.String("urlID", newURL.ID),
 		logger.String("shortCode", newURL.ShortCode))
@@ -183,13 +247,13 @@ func (s *urlService) GetURL(ctx context.Context, shortCode string) (*models.URL,
 	return url, nil
 }
 
-func (s *urlService) GetUserURLs(ctx context.Context, userID string, limit, offset int) ([]*models.URLResponse, error) {
-	urls, err := s.urlRepo.GetByUser(ctx, userID, limit, offset)
+func (s *urlService) GetUserURLs(ctx context.Context, userID string, f *models.URLFilter) ([]*models.URLResponse, *pagination.Page, error) {
+	urls, page, err := s.urlRepo.GetByUser(ctx, userID, f)
 	if err != nil {
 		s.logger.Error("failed to get user URLs",
 			logger.ErrorField(err),
 			logger.String("userID", userID))
-		return nil, err
+		return nil, nil, err
 	}
 
 	responses := make([]*models.URLResponse, len(urls))
@@ -197,34 +261,175 @@ func (s *urlService) GetUserURLs(ctx context.Context, userID string, limit, offs
 		responses[i] = u.ToResponse(s.baseURL)
 	}
 
-	return responses, nil
+	return responses, page, nil
 }
 
-func (s *urlService) UpdateURL(ctx context.Context, url *models.URL) (*models.URLResponse, error) {
-	existingURL, err := s.urlRepo.GetByID(ctx, url.ID)
+func (s *urlService) GetWorkspaceURLs(ctx context.Context, workspaceID, userID string, f *models.URLFilter) ([]*models.URLResponse, *pagination.Page, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewLinks); err != nil {
+		return nil, nil, err
+	}
+
+	urls, page, err := s.urlRepo.GetByWorkspace(ctx, workspaceID, f)
 	if err != nil {
-		s.logger.Error("failed to get existing URL",
+		s.logger.Error("failed to get workspace URLs",
 			logger.ErrorField(err),
-			logger.String("urlID", url.ID))
+			logger.String("workspaceID", workspaceID))
+		return nil, nil, err
+	}
+
+	responses := make([]*models.URLResponse, len(urls))
+	for i, u := range urls {
+		responses[i] = u.ToResponse(s.baseURL)
+	}
+
+	return responses, page, nil
+}
+
+// UpdateURL changes the fields set in req and records the result as a new
+// revision of the link. A changed short code keeps the old one as an alias.
+func (s *urlService) UpdateURL(ctx context.Context, id, userID string, req *models.UpdateURLRequest) (*models.URLResponse, error) {
+	existingURL, err := s.editableURL(ctx, id, userID, models.PermissionEditLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.OriginalURL != nil {
+		if _, err := url.ParseRequestURI(*req.OriginalURL); err != nil {
+			s.logger.Debug("invalid URL format",
+				logger.String("url", *req.OriginalURL),
+				logger.ErrorField(err))
+			return nil, models.ErrInvalidInput
+		}
+	}
+	if req.ShortCode != nil && !validCustomCode(*req.ShortCode) {
+		s.logger.Debug("invalid custom code format",
+			logger.String("code", *req.ShortCode))
+		return nil, models.ErrInvalidInput
+	}
+
+	before := models.NewURLRevision(existingURL)
+	if req.OriginalURL != nil {
+		existingURL.OriginalURL = *req.OriginalURL
+	}
+	if req.ShortCode != nil {
+		existingURL.ShortCode = *req.ShortCode
+	}
+	if req.Title != nil {
+		existingURL.Title = *req.Title
+	}
+	if req.Description != nil {
+		existingURL.Description = *req.Description
+	}
+	if req.ClearExpiry {
+		existingURL.ExpiresAt = nil
+	} else if req.ExpiresAt != nil {
+		existingURL.ExpiresAt = req.ExpiresAt
+	}
+	if req.IsActive != nil {
+		existingURL.IsActive = *req.IsActive
+	}
+
+	return s.saveRevision(ctx, userID, existingURL, before, models.AuditURLUpdate, nil)
+}
+
+// GetURLRevisions returns a page of the link's change history, newest first
+func (s *urlService) GetURLRevisions(ctx context.Context, id, userID string, p pagination.Params) ([]*models.URLRevision, *pagination.Page, error) {
+	if _, err := s.editableURL(ctx, id, userID, models.PermissionViewLinks); err != nil {
+		return nil, nil, err
+	}
+	return s.urlRepo.ListRevisions(ctx, id, p)
+}
+
+// RestoreURLRevision puts the link back the way revision number left it.
+// The restore is itself recorded as a new revision.
+func (s *urlService) RestoreURLRevision(ctx context.Context, id, userID string, number int) (*models.URLResponse, error) {
+	existingURL, err := s.editableURL(ctx, id, userID, models.PermissionEditLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.urlRepo.GetRevision(ctx, id, number)
+	if err != nil {
 		return nil, err
 	}
 
-	existingURL.Title = url.Title
-	existingURL.Description = url.Description
-	existingURL.ExpiresAt = url.ExpiresAt
-	existingURL.IsActive = url.IsActive
+	before := models.NewURLRevision(existingURL)
+	existingURL.OriginalURL = revision.OriginalURL
+	existingURL.ShortCode = revision.ShortCode
+	existingURL.Title = revision.Title
+	existingURL.Description = revision.Description
+	existingURL.ExpiresAt = revision.ExpiresAt
+	existingURL.IsActive = revision.IsActive
 
-	if err := s.urlRepo.Update(ctx, existingURL); err != nil {
-		s.logger.Error("failed to update URL",
+	return s.saveRevision(ctx, userID, existingURL, before, models.AuditURLRestore, &number)
+}
+
+// editableURL loads a link the user may act on with perm. Links created
+// anonymously have no owner, so no one can edit them or see their history.
+func (s *urlService) editableURL(ctx context.Context, id, userID string, perm models.Permission) (*models.URL, error) {
+	existingURL, err := s.urlRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get existing URL",
 			logger.ErrorField(err),
-			logger.Any("url", existingURL))
+			logger.String("urlID", id))
+		return nil, err
+	}
+
+	if existingURL.UserID == nil && existingURL.WorkspaceID == nil {
+		return nil, models.ErrForbidden
+	}
+	if err := s.permissions.CanAccessURL(ctx, userID, existingURL, perm); err != nil {
+		return nil, err
+	}
+	return existingURL, nil
+}
+
+// saveRevision stores the edited link u, whose state beforehand was before,
+// as its next revision, then audits the change and announces it. Nothing is
+// stored if the edit changes nothing.
+func (s *urlService) saveRevision(ctx context.Context, userID string, u *models.URL, before *models.URLRevision, action models.AuditAction, restoredFrom *int) (*models.URLResponse, error) {
+	rev := models.NewURLRevision(u)
+	rev.Changes = revisionChanges(before, rev)
+	if len(rev.Changes) == 0 {
+		return u.ToResponse(s.baseURL), nil
+	}
+	rev.UserID = &userID
+	rev.RestoredFrom = restoredFrom
+	before.UserID = u.UserID
+	before.Changes = []string{}
+
+	u.Domain = models.URLDomain(u.OriginalURL)
+	if !sameTime(before.ExpiresAt, u.ExpiresAt) {
+		// A new expiry is announced again when it passes
+		u.ExpiredNotifiedAt = nil
+	}
+
+	if err := s.urlRepo.UpdateWithRevision(ctx, u, before, rev); err != nil {
+		if err != models.ErrShortCodeTaken {
+			s.logger.Error("failed to update URL",
+				logger.ErrorField(err),
+				logger.Any("url", u))
+		}
 		return nil, err
 	}
 
+	beforeFields := revisionAuditFields(before)
+	after := urlAuditFields(u)
+	event := userEvent(ctx, userID, action, models.AuditTargetURL, u.ID)
+	event.SetChanges(beforeFields, after)
+	metadata := map[string]interface{}{"revision": rev.Number}
+	if restoredFrom != nil {
+		metadata["restored_from"] = *restoredFrom
+	}
+	event.SetMetadata(metadata)
+	s.audit.Record(ctx, event)
+	s.publish(ctx, events.URLUpdated, u, previousFields(beforeFields, after))
+
 	s.logger.Info("URL updated successfully",
-		logger.String("urlID", existingURL.ID))
+		logger.String("urlID", u.ID),
+		logger.Int("revision", rev.Number))
 
-	return existingURL.ToResponse(s.baseURL), nil
+	return u.ToResponse(s.baseURL), nil
 }
 
 func (s *urlService) DeleteURL(ctx context.Context, id, userID string) error {
@@ -236,12 +441,8 @@ func (s *urlService) DeleteURL(ctx context.Context, id, userID string) error {
 		return err
 	}
 
-	if url.UserID != nil && *url.UserID != userID {
-		s.logger.Warn("unauthorized URL deletion attempt",
-			logger.String("requestingUserID", userID),
-			logger.String("urlOwnerID", *url.UserID),
-			logger.String("urlID", id))
-		return models.ErrForbidden
+	if err := s.permissions.CanAccessURL(ctx, userID, url, models.PermissionEditLinks); err != nil {
+		return err
 	}
 
 	if err := s.urlRepo.Delete(ctx, id); err != nil {
@@ -251,12 +452,73 @@ func (s *urlService) DeleteURL(ctx context.Context, id, userID string) error {
 		return err
 	}
 
+	event := userEvent(ctx, userID, models.AuditURLDelete, models.AuditTargetURL, id)
+	event.SetChanges(urlAuditFields(url), nil)
+	s.audit.Record(ctx, event)
+	s.publish(ctx, events.URLDeleted, url, nil)
+
 	s.logger.Info("URL deleted successfully",
 		logger.String("urlID", id))
 
 	return nil
 }
 
+// urlAuditFields are the link fields recorded in the audit log
+func urlAuditFields(u *models.URL) map[string]interface{} {
+	return map[string]interface{}{
+		"original_url": u.OriginalURL,
+		"short_code":   u.ShortCode,
+		"title":        u.Title,
+		"description":  u.Description,
+		"expires_at":   u.ExpiresAt,
+		"is_active":    u.IsActive,
+	}
+}
+
+// revisionAuditFields are the audited fields of a link as a revision
+// recorded them
+func revisionAuditFields(r *models.URLRevision) map[string]interface{} {
+	return urlAuditFields(&models.URL{
+		OriginalURL: r.OriginalURL,
+		ShortCode:   r.ShortCode,
+		Title:       r.Title,
+		Description: r.Description,
+		ExpiresAt:   r.ExpiresAt,
+		IsActive:    r.IsActive,
+	})
+}
+
+// revisionChanges names the fields that differ between two revisions
+func revisionChanges(before, after *models.URLRevision) []string {
+	changes := []string{}
+	if before.OriginalURL != after.OriginalURL {
+		changes = append(changes, "original_url")
+	}
+	if before.ShortCode != after.ShortCode {
+		changes = append(changes, "short_code")
+	}
+	if before.Title != after.Title {
+		changes = append(changes, "title")
+	}
+	if before.Description != after.Description {
+		changes = append(changes, "description")
+	}
+	if !sameTime(before.ExpiresAt, after.ExpiresAt) {
+		changes = append(changes, "expires_at")
+	}
+	if before.IsActive != after.IsActive {
+		changes = append(changes, "is_active")
+	}
+	return changes
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
 func (s *urlService) RedirectURL(ctx context.Context, shortCode string, clickData *models.URLClick) (string, error) {
 	url, err := s.urlRepo.GetByShortCode(ctx, shortCode)
 	if err != nil {
@@ -266,6 +528,10 @@ func (s *urlService) RedirectURL(ctx context.Context, shortCode string, clickDat
 		return "", err
 	}
 
+	if url.TakenDownAt != nil {
+		return "", models.ErrURLTakenDown
+	}
+
 	if clickData != nil {
 		clickData.URLID = url.ID
 		clickData.ID = uuid.New().String()
@@ -275,6 +541,18 @@ func (s *urlService) RedirectURL(ctx context.Context, shortCode string, clickDat
 			s.logger.Error("failed to record URL click",
 				logger.ErrorField(err),
 				logger.Any("clickData", clickData))
+		} else if url.UserID != nil {
+			s.bus.Publish(ctx, events.New(events.ClickRecorded, *url.UserID, &clickEventData{
+				URLID:     url.ID,
+				ShortCode: url.ShortCode,
+				Referrer:  clickData.Referrer,
+				Country:   clickData.Country,
+				City:      clickData.City,
+				Device:    clickData.Device,
+				OS:        clickData.OS,
+				Browser:   clickData.Browser,
+				ClickedAt: clickData.CreatedAt,
+			}))
 		}
 	}
 
@@ -287,34 +565,126 @@ func (s *urlService) RedirectURL(ctx context.Context, shortCode string, clickDat
 	return url.OriginalURL, nil
 }
 
-func (s *urlService) GetURLAnalytics(ctx context.Context, urlID, userID string, from, to time.Time) ([]*models.URLClick, error) {
+// urlEventData is the payload of the url.* events. Previous holds the old
+// values of the fields an update changed.
+type urlEventData struct {
+	URL      *models.URLResponse    `json:"url"`
+	Previous map[string]interface{} `json:"previous,omitempty"`
+}
+
+// clickEventData is the payload of click.recorded. The visitor's IP address
+// and user agent are not sent.
+type clickEventData struct {
+	URLID     string    `json:"url_id"`
+	ShortCode string    `json:"short_code"`
+	Referrer  string    `json:"referrer,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	City      string    `json:"city,omitempty"`
+	Device    string    `json:"device,omitempty"`
+	OS        string    `json:"os,omitempty"`
+	Browser   string    `json:"browser,omitempty"`
+	ClickedAt time.Time `json:"clicked_at"`
+}
+
+// publish sends a url.* event to the link's creator. Anonymous links have
+// no one to tell.
+func (s *urlService) publish(ctx context.Context, eventType events.Type, u *models.URL, previous map[string]interface{}) {
+	if u.UserID == nil {
+		return
+	}
+	s.bus.Publish(ctx, events.New(eventType, *u.UserID, &urlEventData{
+		URL:      u.ToResponse(s.baseURL),
+		Previous: previous,
+	}))
+}
+
+// previousFields returns the before values of the fields that differ
+func previousFields(before, after map[string]interface{}) map[string]interface{} {
+	previous := map[string]interface{}{}
+	for key, value := range before {
+		if !reflect.DeepEqual(value, after[key]) {
+			previous[key] = value
+		}
+	}
+	return previous
+}
+
+// NotifyExpired publishes url.expired for links whose expiry has passed
+// since the last run. Each link is marked first, so it is announced once
+// even with several instances running.
+func (s *urlService) NotifyExpired(ctx context.Context) error {
+	const batchSize = 100
+
+	for {
+		now := time.Now()
+		urls, err := s.urlRepo.GetNewlyExpired(ctx, now, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range urls {
+			marked, err := s.urlRepo.MarkExpiredNotified(ctx, u.ID, now)
+			if err != nil {
+				return err
+			}
+			if marked {
+				s.publish(ctx, events.URLExpired, u, nil)
+			}
+		}
+
+		if len(urls) < batchSize {
+			return nil
+		}
+	}
+}
+
+// RunExpiryWorker calls NotifyExpired every expiry interval until ctx is done.
+func (s *urlService) RunExpiryWorker(ctx context.Context) {
+	interval := s.expiryInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.NotifyExpired(ctx); err != nil {
+			s.logger.Error("URL expiry run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *urlService) GetURLAnalytics(ctx context.Context, urlID, userID string, from, to time.Time, p pagination.Params) ([]*models.URLClick, *pagination.Page, error) {
 	url, err := s.urlRepo.GetByID(ctx, urlID)
 	if err != nil {
 		s.logger.Error("failed to get URL for analytics",
 			logger.ErrorField(err),
 			logger.String("urlID", urlID))
-		return nil, err
+		return nil, nil, err
 	}
 
-	if url.UserID != nil && *url.UserID != userID {
-		s.logger.Warn("unauthorized analytics access attempt",
-			logger.String("requestingUserID", userID),
-			logger.String("urlOwnerID", *url.UserID),
-			logger.String("urlID", urlID))
-		return nil, models.ErrForbidden
+	if err := s.permissions.CanAccessURL(ctx, userID, url, models.PermissionViewLinks); err != nil {
+		return nil, nil, err
 	}
 
-	clicks, err := s.urlRepo.GetClicksAnalytics(ctx, urlID, from, to)
+	clicks, page, err := s.urlRepo.GetClicksAnalytics(ctx, urlID, from, to, p)
 	if err != nil {
 		s.logger.Error("failed to get URL analytics",
 			logger.ErrorField(err),
 			logger.String("urlID", urlID),
 			logger.Time("from", from),
 			logger.Time("to", to))
-		return nil, err
+		return nil, nil, err
 	}
 
-	return clicks, nil
+	return clicks, page, nil
 }
 
 func generateShortCode(length int) string {
@@ -326,6 +696,11 @@ func generateShortCode(length int) string {
 	return clean[:length]
 }
 
+// validCustomCode reports whether code can be chosen as a short code
+func validCustomCode(code string) bool {
+	return len(code) >= 3 && len(code) <= 10 && isAlphanumeric(code)
+}
+
 func isAlphanumeric(s string) bool {
 	for _, r := range s {
 		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
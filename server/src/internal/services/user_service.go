@@ -3,7 +3,10 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"mime/multipart"
+	"strings"
+	"time"
 
 	"github.com/imraushankr/bervity/server/src/configs"
 	"github.com/imraushankr/bervity/server/src/internal/models"
@@ -140,3 +143,68 @@ func (s *userService) UploadAvatar(ctx context.Context, userID string, file mult
 
 	return avatarURL, nil
 }
+
+func (s *userService) RequestEmailChange(ctx context.Context, userID string, req *models.ChangeEmailRequest) error {
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.IsPasswordCorrect(user.Password, req.Password); err != nil {
+		return models.ErrInvalidCredentials
+	}
+
+	if !user.IsVerified {
+		return models.ErrUserNotVerified
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return models.ErrEmailUnchanged
+	}
+
+	existing, err := s.repo.FindUserByIdentifier(ctx, newEmail)
+	if err == nil && existing != nil {
+		return models.ErrEmailAlreadyExists
+	}
+	if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+		return err
+	}
+
+	token, err := s.auth.GenerateVerificationToken(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if err := s.repo.SavePendingEmailChange(ctx, user.ID, newEmail, token, expiresAt); err != nil {
+		return fmt.Errorf("failed to save pending email change: %w", err)
+	}
+
+	confirmLink := fmt.Sprintf("%s/api/v1/users/email/confirm?token=%s", s.cfg.App.BaseURL, token)
+	if err := s.email.SendEmailChangeConfirmation(newEmail, confirmLink); err != nil {
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	if err := s.email.SendEmailChangeNotice(user.Email, newEmail); err != nil {
+		s.log.Warn("Failed to notify previous email address",
+			logger.NamedError("error", err),
+			logger.String("user_id", user.ID))
+	}
+
+	return nil
+}
+
+func (s *userService) ConfirmEmailChange(ctx context.Context, token string) (*models.User, error) {
+	if _, err := s.auth.VerifyVerificationToken(token); err != nil {
+		return nil, models.ErrInvalidVerificationToken
+	}
+
+	user, err := s.repo.ConfirmEmailChange(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Email address changed", logger.String("user_id", user.ID))
+	return user, nil
+}
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/events"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/webhook"
+)
+
+const (
+	maxWebhookEndpoints = 10
+	deliveryBatchSize   = 50
+	// Retries are never put off for longer than this, however many
+	// attempts were made
+	maxDeliveryBackoff = 12 * time.Hour
+)
+
+type webhookService struct {
+	repo   interfaces.WebhookRepository
+	sender *webhook.Sender
+	cfg    *configs.WebhooksConfig
+	log    logger.Logger
+
+	// wake starts the delivery worker early when new deliveries are queued
+	wake chan struct{}
+}
+
+// NewWebhookService subscribes to every event on bus and queues a delivery
+// for each of the user's endpoints that wants it.
+func NewWebhookService(repo interfaces.WebhookRepository, bus *events.Bus, cfg *configs.WebhooksConfig, log logger.Logger) interfaces.WebhookService {
+	s := &webhookService{
+		repo:   repo,
+		sender: webhook.NewSender(cfg.Timeout, cfg.AllowPrivateTargets),
+		cfg:    cfg,
+		log:    log,
+		wake:   make(chan struct{}, 1),
+	}
+	bus.SubscribeAll(s.enqueue)
+	return s
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, userID string, req *models.CreateWebhookEndpointRequest) (*models.WebhookEndpointWithSecret, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := validateEventTypes(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountEndpoints(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWebhookEndpoints {
+		return nil, models.ErrWebhookEndpointLimit
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		UserID:      userID,
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		Events:      eventTypes,
+		IsActive:    true,
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Webhook endpoint created",
+		logger.String("endpoint_id", endpoint.ID),
+		logger.String("user_id", userID))
+
+	return &models.WebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context, userID string) ([]*models.WebhookEndpoint, error) {
+	return s.repo.ListEndpoints(ctx, userID)
+}
+
+func (s *webhookService) GetEndpoint(ctx context.Context, userID, id string) (*models.WebhookEndpoint, error) {
+	return s.repo.GetEndpoint(ctx, id, userID)
+}
+
+// UpdateEndpoint applies the fields set in req. Turning an endpoint back on
+// clears the failures that disabled it.
+func (s *webhookService) UpdateEndpoint(ctx context.Context, userID, id string, req *models.UpdateWebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = *req.Description
+	}
+	if req.Events != nil {
+		eventTypes, err := validateEventTypes(*req.Events)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Events = eventTypes
+	}
+	if req.IsActive != nil {
+		if *req.IsActive && !endpoint.IsActive {
+			endpoint.ConsecutiveFailures = 0
+			endpoint.DisabledAt = nil
+			endpoint.DisabledReason = ""
+		}
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, userID, id string) error {
+	if err := s.repo.DeleteEndpoint(ctx, id, userID); err != nil {
+		if !errors.Is(err, models.ErrWebhookEndpointNotFound) {
+			s.log.Error("Failed to delete webhook endpoint",
+				logger.ErrorField(err),
+				logger.String("endpoint_id", id))
+		}
+		return err
+	}
+	return nil
+}
+
+// RotateSecret replaces the signing secret. Deliveries sent from now on,
+// including retries, are signed with the new one.
+func (s *webhookService) RotateSecret(ctx context.Context, userID, id string) (*models.WebhookEndpointWithSecret, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return &models.WebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, userID, endpointID string, status models.WebhookDeliveryStatus, p pagination.Params) ([]*models.WebhookDelivery, *pagination.Page, error) {
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed:
+	default:
+		return nil, nil, models.ErrInvalidInput
+	}
+	if _, err := s.repo.GetEndpoint(ctx, endpointID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	return s.repo.ListDeliveries(ctx, endpointID, status, p)
+}
+
+// Redeliver queues the same event again as a new delivery. The original
+// delivery is left as it was.
+func (s *webhookService) Redeliver(ctx context.Context, userID, endpointID, deliveryID string) (*models.WebhookDelivery, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, endpointID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.IsActive {
+		return nil, models.ErrWebhookEndpointDisabled
+	}
+
+	original, err := s.repo.GetDelivery(ctx, endpointID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		EndpointID:    endpointID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	s.notify()
+	return delivery, nil
+}
+
+// enqueue queues event for each of the user's active endpoints subscribed
+// to its type. It runs inside whatever published the event, so failures
+// are logged rather than returned.
+func (s *webhookService) enqueue(ctx context.Context, event *events.Event) {
+	if event.UserID == "" {
+		return
+	}
+
+	endpoints, err := s.repo.GetActiveEndpoints(ctx, event.UserID)
+	if err != nil || len(endpoints) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.log.Error("Failed to encode webhook event",
+			logger.ErrorField(err),
+			logger.String("event_id", event.ID),
+			logger.String("type", string(event.Type)))
+		return
+	}
+
+	queued := false
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribed(string(event.Type)) {
+			continue
+		}
+		delivery := &models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       event.ID,
+			EventType:     string(event.Type),
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		s.notify()
+	}
+}
+
+func (s *webhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// DeliverDue sends the deliveries that are due. Each one is claimed first,
+// so several instances can run the worker at once.
+func (s *webhookService) DeliverDue(ctx context.Context) error {
+	now := time.Now()
+	ids, err := s.repo.GetDueDeliveryIDs(ctx, now, deliveryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// Long enough for the request to time out and the result to be saved
+	lease := s.cfg.Timeout + time.Minute
+	for _, id := range ids {
+		delivery, ok, err := s.repo.ClaimDelivery(ctx, id, now, time.Now().Add(lease))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		s.deliver(ctx, delivery)
+	}
+	return nil
+}
+
+func (s *webhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	endpoint, err := s.repo.GetEndpointByID(ctx, delivery.EndpointID)
+	if err != nil {
+		return
+	}
+
+	delivery.LockedUntil = nil
+	if !endpoint.IsActive {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = models.ErrWebhookEndpointDisabled.Error()
+		s.repo.UpdateDelivery(ctx, delivery)
+		return
+	}
+
+	result, sendErr := s.sender.Send(ctx, &webhook.Request{
+		URL:        endpoint.URL,
+		Secret:     endpoint.Secret,
+		EventType:  delivery.EventType,
+		DeliveryID: delivery.ID,
+		Payload:    []byte(delivery.Payload),
+	})
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseCode = result.StatusCode
+	delivery.ResponseBody = result.Body
+	delivery.DurationMS = result.Duration.Milliseconds()
+
+	if sendErr == nil && result.Success() {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		if err := s.repo.UpdateDelivery(ctx, delivery); err == nil {
+			s.repo.ResetEndpointFailures(ctx, endpoint.ID)
+		}
+		return
+	}
+
+	if sendErr != nil {
+		delivery.LastError = sendErr.Error()
+	} else {
+		delivery.LastError = fmt.Sprintf("endpoint responded with status %d", result.StatusCode)
+	}
+
+	if delivery.Attempts < s.cfg.MaxAttempts {
+		next := now.Add(s.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		s.repo.UpdateDelivery(ctx, delivery)
+		return
+	}
+
+	delivery.Status = models.WebhookDeliveryFailed
+	delivery.NextAttemptAt = nil
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return
+	}
+
+	s.log.Warn("Webhook delivery failed",
+		logger.String("delivery_id", delivery.ID),
+		logger.String("endpoint_id", endpoint.ID),
+		logger.Int("attempts", delivery.Attempts),
+		logger.String("error", delivery.LastError))
+
+	disabled, err := s.repo.RecordEndpointFailure(ctx, endpoint.ID, s.cfg.DisableAfter, now)
+	if err == nil && disabled {
+		s.log.Warn("Webhook endpoint disabled after repeated failures",
+			logger.String("endpoint_id", endpoint.ID),
+			logger.String("user_id", endpoint.UserID))
+	}
+}
+
+// backoff is how long to wait after the given number of attempts: the retry
+// base, doubled for each attempt after the first.
+func (s *webhookService) backoff(attempts int) time.Duration {
+	wait := s.cfg.RetryBase
+	if wait <= 0 {
+		wait = 30 * time.Second
+	}
+	for i := 1; i < attempts && wait < maxDeliveryBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxDeliveryBackoff {
+		wait = maxDeliveryBackoff
+	}
+	return wait
+}
+
+// RunDeliveryWorker calls DeliverDue every delivery interval, and as soon as
+// new deliveries are queued, until ctx is done.
+func (s *webhookService) RunDeliveryWorker(ctx context.Context) {
+	interval := s.cfg.DeliveryInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DeliverDue(ctx); err != nil {
+			s.log.Error("Webhook delivery run failed", logger.ErrorField(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// validateEventTypes checks the requested event types and drops duplicates
+func validateEventTypes(requested []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	eventTypes := make([]string, 0, len(requested))
+	for _, t := range requested {
+		if !events.Type(t).Valid() {
+			return nil, models.ErrUnknownEventType
+		}
+		if !seen[t] {
+			seen[t] = true
+			eventTypes = append(eventTypes, t)
+		}
+	}
+	return eventTypes, nil
+}
+
+func newWebhookSecret() (string, error) {
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + token, nil
+}
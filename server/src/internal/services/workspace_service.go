@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imraushankr/bervity/server/src/configs"
+	"github.com/imraushankr/bervity/server/src/internal/models"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/auth"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/email"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/interfaces"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/logger"
+)
+
+// invitationExpiry is how long a workspace invitation stays valid
+const invitationExpiry = 7 * 24 * time.Hour
+
+type workspaceService struct {
+	workspaceRepo interfaces.WorkspaceRepository
+	userRepo      interfaces.UserRepository
+	permissions   interfaces.PermissionService
+	email         *email.EmailService
+	cfg           *configs.Config
+	log           logger.Logger
+}
+
+func NewWorkspaceService(
+	workspaceRepo interfaces.WorkspaceRepository,
+	userRepo interfaces.UserRepository,
+	permissions interfaces.PermissionService,
+	email *email.EmailService,
+	cfg *configs.Config,
+	log logger.Logger,
+) interfaces.WorkspaceService {
+	return &workspaceService{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		permissions:   permissions,
+		email:         email,
+		cfg:           cfg,
+		log:           log,
+	}
+}
+
+func (s *workspaceService) CreateWorkspace(ctx context.Context, userID string, req *models.CreateWorkspaceRequest) (*models.WorkspaceResponse, error) {
+	workspace := &models.Workspace{
+		Name:    strings.TrimSpace(req.Name),
+		OwnerID: userID,
+	}
+	if workspace.Name == "" {
+		return nil, models.ErrInvalidInput
+	}
+
+	if err := s.workspaceRepo.Create(ctx, workspace); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("workspace created",
+		logger.String("workspaceID", workspace.ID),
+		logger.String("userID", userID))
+	return &models.WorkspaceResponse{Workspace: *workspace, Role: models.WorkspaceOwner}, nil
+}
+
+func (s *workspaceService) ListWorkspaces(ctx context.Context, userID string) ([]*models.WorkspaceResponse, error) {
+	return s.workspaceRepo.ListForUser(ctx, userID)
+}
+
+func (s *workspaceService) GetWorkspace(ctx context.Context, workspaceID, userID string) (*models.WorkspaceResponse, error) {
+	member, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WorkspaceResponse{Workspace: *workspace, Role: member.Role}, nil
+}
+
+func (s *workspaceService) UpdateWorkspace(ctx context.Context, workspaceID, userID string, req *models.UpdateWorkspaceRequest) (*models.WorkspaceResponse, error) {
+	member, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageWorkspace)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace.Name = strings.TrimSpace(req.Name)
+	if workspace.Name == "" {
+		return nil, models.ErrInvalidInput
+	}
+	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
+		return nil, err
+	}
+	return &models.WorkspaceResponse{Workspace: *workspace, Role: member.Role}, nil
+}
+
+func (s *workspaceService) DeleteWorkspace(ctx context.Context, workspaceID, userID string) error {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageWorkspace); err != nil {
+		return err
+	}
+
+	if err := s.workspaceRepo.Delete(ctx, workspaceID); err != nil {
+		return err
+	}
+
+	s.log.Info("workspace deleted",
+		logger.String("workspaceID", workspaceID),
+		logger.String("userID", userID))
+	return nil
+}
+
+func (s *workspaceService) ListMembers(ctx context.Context, workspaceID, userID string) ([]*models.WorkspaceMember, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionViewLinks); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListMembers(ctx, workspaceID)
+}
+
+// UpdateMemberRole changes a member's role. Members can only manage those
+// ranked below them, and only grant roles below their own, so an admin can't
+// promote anyone to admin or demote another admin.
+func (s *workspaceService) UpdateMemberRole(ctx context.Context, workspaceID, userID, memberID string, req *models.UpdateMemberRoleRequest) (*models.WorkspaceMember, error) {
+	actor, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == models.WorkspaceOwner || req.Role == models.WorkspaceOwner {
+		return nil, models.ErrOwnerRoleChange
+	}
+	if !req.Role.Valid() {
+		return nil, models.ErrInvalidInput
+	}
+	if !actor.Role.Outranks(member.Role) || !actor.Role.Outranks(req.Role) {
+		return nil, models.ErrForbidden
+	}
+
+	if err := s.workspaceRepo.UpdateMemberRole(ctx, workspaceID, memberID, req.Role); err != nil {
+		return nil, err
+	}
+	member.Role = req.Role
+
+	s.log.Info("workspace member role changed",
+		logger.String("workspaceID", workspaceID),
+		logger.String("memberID", memberID),
+		logger.String("role", string(req.Role)))
+	return member, nil
+}
+
+func (s *workspaceService) RemoveMember(ctx context.Context, workspaceID, userID, memberID string) error {
+	if memberID == userID {
+		// Any member may leave, except the owner
+		member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
+		if err != nil {
+			if err == models.ErrMemberNotFound {
+				return models.ErrWorkspaceNotFound
+			}
+			return err
+		}
+		if member.Role == models.WorkspaceOwner {
+			return models.ErrOwnerRoleChange
+		}
+		return s.workspaceRepo.RemoveMember(ctx, workspaceID, userID)
+	}
+
+	actor, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageMembers)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.WorkspaceOwner {
+		return models.ErrOwnerRoleChange
+	}
+	if !actor.Role.Outranks(member.Role) {
+		return models.ErrForbidden
+	}
+
+	if err := s.workspaceRepo.RemoveMember(ctx, workspaceID, memberID); err != nil {
+		return err
+	}
+
+	s.log.Info("workspace member removed",
+		logger.String("workspaceID", workspaceID),
+		logger.String("memberID", memberID))
+	return nil
+}
+
+func (s *workspaceService) InviteMember(ctx context.Context, workspaceID, userID string, req *models.InviteMemberRequest) (*models.WorkspaceInvitation, error) {
+	actor, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Role.Valid() || req.Role == models.WorkspaceOwner {
+		return nil, models.ErrInvalidInput
+	}
+	if !actor.Role.Outranks(req.Role) {
+		return nil, models.ErrForbidden
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	inviter, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	invitation := &models.WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       strings.ToLower(strings.TrimSpace(req.Email)),
+		Role:        req.Role,
+		TokenHash:   auth.HashToken(token),
+		InvitedBy:   &userID,
+		ExpiresAt:   time.Now().Add(invitationExpiry),
+	}
+	if err := s.workspaceRepo.CreateInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	inviteLink := fmt.Sprintf("%s/api/v1/workspaces/invitations/accept?token=%s", s.cfg.App.BaseURL, token)
+	inviterName := strings.TrimSpace(inviter.FirstName + " " + inviter.LastName)
+	if err := s.email.SendWorkspaceInvitationEmail(invitation.Email, workspace.Name, inviterName, inviteLink, invitationExpiry); err != nil {
+		s.log.Warn("failed to send workspace invitation",
+			logger.ErrorField(err),
+			logger.String("workspaceID", workspaceID),
+			logger.String("invitationID", invitation.ID))
+	}
+
+	s.log.Info("workspace invitation sent",
+		logger.String("workspaceID", workspaceID),
+		logger.String("invitationID", invitation.ID))
+	return invitation, nil
+}
+
+func (s *workspaceService) ListInvitations(ctx context.Context, workspaceID, userID string) ([]*models.WorkspaceInvitation, error) {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageMembers); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListInvitations(ctx, workspaceID)
+}
+
+func (s *workspaceService) RevokeInvitation(ctx context.Context, workspaceID, userID, invitationID string) error {
+	if _, err := s.permissions.Require(ctx, workspaceID, userID, models.PermissionManageMembers); err != nil {
+		return err
+	}
+	return s.workspaceRepo.DeleteInvitation(ctx, workspaceID, invitationID)
+}
+
+// AcceptInvitation adds the signed-in user to the workspace. The invitation
+// must have been sent to their email address, so a forwarded link can't be
+// used by someone else.
+func (s *workspaceService) AcceptInvitation(ctx context.Context, userID, token string) (*models.WorkspaceMember, error) {
+	if token == "" {
+		return nil, models.ErrInvalidInvitation
+	}
+
+	invitation, err := s.workspaceRepo.GetInvitationByToken(ctx, auth.HashToken(token), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(user.Email), invitation.Email) {
+		return nil, models.ErrInvitationEmailMismatch
+	}
+
+	member, err := s.workspaceRepo.AcceptInvitation(ctx, invitation, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("workspace invitation accepted",
+		logger.String("workspaceID", invitation.WorkspaceID),
+		logger.String("userID", userID))
+	return member, nil
+}
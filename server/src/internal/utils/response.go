@@ -6,16 +6,18 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/imraushankr/bervity/server/src/internal/pkg/pagination"
 )
 
 // APIResponse represents a standardized API response structure
 type APIResponse struct {
-	Success   bool        `json:"success"`
-	Message   string      `json:"message,omitempty"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Timestamp string      `json:"timestamp"`
-	Path      string      `json:"path"`
+	Success   bool             `json:"success"`
+	Message   string           `json:"message,omitempty"`
+	Data      interface{}      `json:"data,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Meta      *pagination.Page `json:"meta,omitempty"`
+	Timestamp string           `json:"timestamp"`
+	Path      string           `json:"path"`
 }
 
 // ResponseOptions configures the API response
@@ -24,6 +26,7 @@ type ResponseOptions struct {
 	Message string
 	Data    interface{}
 	Error   error
+	Meta    *pagination.Page
 }
 
 // GetValidationErrors converts validator errors to a map
@@ -41,6 +44,7 @@ func Respond(c *gin.Context, statusCode int, opts ResponseOptions) {
 		Success:   opts.Success,
 		Message:   opts.Message,
 		Data:      opts.Data,
+		Meta:      opts.Meta,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Path:      c.Request.URL.Path,
 	}
@@ -82,14 +86,12 @@ func ValidationError(c *gin.Context, errors map[string]string) {
 	})
 }
 
-// PaginatedResponse sends a paginated response
-func PaginatedResponse(c *gin.Context, statusCode int, data interface{}, pagination interface{}) {
+// List sends a page of a list, with the page described in meta
+func List(c *gin.Context, statusCode int, message string, data interface{}, page *pagination.Page) {
 	Respond(c, statusCode, ResponseOptions{
 		Success: true,
-		Message: "Data retrieved successfully",
-		Data: gin.H{
-			"items":      data,
-			"pagination": pagination,
-		},
+		Message: message,
+		Data:    data,
+		Meta:    page,
 	})
 }
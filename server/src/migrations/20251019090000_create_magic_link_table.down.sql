@@ -0,0 +1,7 @@
+-- Brevity Migration: create_magic_link_table
+-- Generated: 2025-10-19T09:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP TABLE IF EXISTS magic_link_tokens;
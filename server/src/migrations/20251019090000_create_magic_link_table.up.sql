@@ -0,0 +1,19 @@
+-- Brevity Migration: create_magic_link_table
+-- Generated: 2025-10-19T09:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  magic_link_tokens (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    requested_ip VARCHAR(45),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_magic_link_tokens_user_id ON magic_link_tokens (user_id);
@@ -0,0 +1,9 @@
+-- Brevity Migration: add_email_change_to_users
+-- Generated: 2025-10-19T09:15:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE users DROP COLUMN pending_email;
+
+ALTER TABLE users DROP COLUMN verification_purpose;
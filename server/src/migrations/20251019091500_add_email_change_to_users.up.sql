@@ -0,0 +1,11 @@
+-- Brevity Migration: add_email_change_to_users
+-- Generated: 2025-10-19T09:15:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE users ADD COLUMN verification_purpose VARCHAR(20);
+
+ALTER TABLE users ADD COLUMN pending_email VARCHAR(255);
+
+UPDATE users SET verification_purpose = 'signup' WHERE verification_token IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Brevity Migration: add_account_privacy
+-- Generated: 2025-10-19T10:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP TABLE IF EXISTS data_exports;
+
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+
+ALTER TABLE users DROP COLUMN deletion_scheduled_at;
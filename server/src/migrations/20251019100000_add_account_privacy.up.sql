@@ -0,0 +1,30 @@
+-- Brevity Migration: add_account_privacy
+-- Generated: 2025-10-19T10:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP;
+
+CREATE INDEX idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+
+CREATE TABLE
+  data_exports (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (
+      status IN ('pending', 'processing', 'completed', 'failed')
+    ),
+    file_key TEXT,
+    download_url TEXT,
+    error TEXT,
+    expires_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_data_exports_user_id ON data_exports (user_id);
+
+CREATE INDEX idx_data_exports_expires_at ON data_exports (expires_at);
@@ -0,0 +1,9 @@
+-- Brevity Migration: add_payment_customer_to_users
+-- Generated: 2025-10-19T10:30:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_users_payment_customer_id;
+
+ALTER TABLE users DROP COLUMN payment_customer_id;
@@ -0,0 +1,9 @@
+-- Brevity Migration: add_payment_customer_to_users
+-- Generated: 2025-10-19T10:30:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE users ADD COLUMN payment_customer_id VARCHAR(255);
+
+CREATE INDEX idx_users_payment_customer_id ON users (payment_customer_id);
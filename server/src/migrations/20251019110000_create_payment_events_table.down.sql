@@ -0,0 +1,13 @@
+-- Brevity Migration: create_payment_events_table
+-- Generated: 2025-10-19T11:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_payments_payment_intent_id;
+
+ALTER TABLE payments DROP COLUMN payment_intent_id;
+
+ALTER TABLE subscriptions DROP COLUMN status;
+
+DROP TABLE IF EXISTS payment_events;
@@ -0,0 +1,29 @@
+-- Brevity Migration: create_payment_events_table
+-- Generated: 2025-10-19T11:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  payment_events (
+    id VARCHAR(255) PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (
+      status IN ('pending', 'processed', 'failed', 'dead_letter')
+    ),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP,
+    processed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_payment_events_status_next_attempt ON payment_events (status, next_attempt_at);
+
+ALTER TABLE subscriptions ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active';
+
+ALTER TABLE payments ADD COLUMN payment_intent_id VARCHAR(255);
+
+CREATE INDEX idx_payments_payment_intent_id ON payments (payment_intent_id);
@@ -0,0 +1,13 @@
+-- Brevity Migration: add_subscription_lifecycle
+-- Generated: 2025-10-19T11:30:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_payments_stripe_id_unique;
+
+DROP INDEX IF EXISTS idx_subscriptions_renewal;
+
+ALTER TABLE subscriptions DROP COLUMN locked_until;
+
+ALTER TABLE subscriptions DROP COLUMN grace_period_ends_at;
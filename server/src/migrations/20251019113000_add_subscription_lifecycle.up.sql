@@ -0,0 +1,16 @@
+-- Brevity Migration: add_subscription_lifecycle
+-- Generated: 2025-10-19T11:30:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE subscriptions ADD COLUMN grace_period_ends_at TIMESTAMP;
+
+ALTER TABLE subscriptions ADD COLUMN locked_until TIMESTAMP;
+
+CREATE INDEX idx_subscriptions_renewal ON subscriptions (is_active, renews_at, expires_at);
+
+CREATE UNIQUE INDEX idx_payments_stripe_id_unique ON payments (stripe_id)
+WHERE
+  stripe_id IS NOT NULL
+  AND stripe_id != '';
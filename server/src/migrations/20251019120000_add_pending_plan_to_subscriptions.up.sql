@@ -0,0 +1,7 @@
+-- Brevity Migration: add_pending_plan_to_subscriptions
+-- Generated: 2025-10-19T12:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE subscriptions ADD COLUMN pending_plan VARCHAR(20);
@@ -0,0 +1,78 @@
+-- Brevity Migration: create_plans_table
+-- Generated: 2025-10-19T12:30:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+-- SQLite can't alter a table in place, so subscriptions is rebuilt. Dropping
+-- the old table clears payments.subscription_id, so the links are restored
+-- afterwards.
+CREATE TEMPORARY TABLE payment_subscriptions AS
+SELECT
+  id,
+  subscription_id
+FROM
+  payments
+WHERE
+  subscription_id IS NOT NULL;
+
+CREATE TABLE
+  subscriptions_new (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    plan VARCHAR(20) NOT NULL CHECK (plan IN ('free', 'basic', 'pro', 'enterprise')),
+    stripe_id VARCHAR(255),
+    is_active BOOLEAN DEFAULT true,
+    starts_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    renews_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    grace_period_ends_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    pending_plan VARCHAR(20),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+INSERT INTO
+  subscriptions_new (id, user_id, plan, stripe_id, is_active, starts_at, expires_at, renews_at, cancelled_at, created_at, updated_at, status, grace_period_ends_at, locked_until, pending_plan)
+SELECT
+  id, user_id, plan, stripe_id, is_active, starts_at, expires_at, renews_at, cancelled_at, created_at, updated_at, status, grace_period_ends_at, locked_until, pending_plan
+FROM
+  subscriptions;
+
+DROP TABLE subscriptions;
+
+ALTER TABLE subscriptions_new RENAME TO subscriptions;
+
+CREATE INDEX idx_subscriptions_user_id ON subscriptions (user_id);
+
+CREATE INDEX idx_subscriptions_stripe_id ON subscriptions (stripe_id);
+
+CREATE INDEX idx_subscriptions_renewal ON subscriptions (is_active, renews_at, expires_at);
+
+UPDATE payments
+SET
+  subscription_id = (
+    SELECT
+      subscription_id
+    FROM
+      payment_subscriptions
+    WHERE
+      payment_subscriptions.id = payments.id
+  )
+WHERE
+  id IN (
+    SELECT
+      id
+    FROM
+      payment_subscriptions
+  );
+
+DROP TABLE payment_subscriptions;
+
+DROP INDEX IF EXISTS idx_plans_code_version;
+
+DROP TABLE IF EXISTS plans;
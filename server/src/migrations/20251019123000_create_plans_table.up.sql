@@ -0,0 +1,126 @@
+-- Brevity Migration: create_plans_table
+-- Generated: 2025-10-19T12:30:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  plans (
+    id VARCHAR(20) PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price INTEGER NOT NULL CHECK (price >= 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'usd',
+    interval VARCHAR(10) NOT NULL CHECK (interval IN ('monthly', 'yearly')),
+    credits INTEGER NOT NULL DEFAULT 0,
+    url_limit INTEGER NOT NULL DEFAULT 0,
+    features TEXT,
+    feature_flags TEXT,
+    provider_price_id VARCHAR(255),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE UNIQUE INDEX idx_plans_code_version ON plans (code, version);
+
+INSERT INTO
+  plans (id, code, version, name, description, price, currency, interval, credits, url_limit, features, feature_flags)
+VALUES
+  ('plan-basic-v1', 'basic', 1, 'Basic', 'Basic plan with limited features', 999, 'usd', 'monthly', 100, 100, '["100 URLs/month","Basic analytics"]', '{}'),
+  ('plan-pro-v1', 'pro', 1, 'Pro', 'Professional plan with advanced features', 1999, 'usd', 'monthly', 500, 500, '["500 URLs/month","Advanced analytics","Priority support"]', '{"advanced_analytics":true,"priority_support":true}'),
+  ('plan-enterprise-v1', 'enterprise', 1, 'Enterprise', 'Enterprise plan with unlimited features', 4999, 'usd', 'monthly', 10000, 0, '["Unlimited URLs","All features","24/7 support"]', '{"advanced_analytics":true,"priority_support":true}');
+
+-- Plan codes now come from the catalog, so the CHECK constraint on
+-- subscriptions.plan goes, and subscriptions keep the plan version they signed
+-- up on. SQLite can't drop a constraint in place, so the table is rebuilt.
+-- Dropping the old table clears payments.subscription_id, so the links are
+-- restored afterwards. Plans are archived rather than deleted, so the plan
+-- columns need no foreign keys.
+CREATE TEMPORARY TABLE payment_subscriptions AS
+SELECT
+  id,
+  subscription_id
+FROM
+  payments
+WHERE
+  subscription_id IS NOT NULL;
+
+CREATE TABLE
+  subscriptions_new (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    plan VARCHAR(20) NOT NULL,
+    stripe_id VARCHAR(255),
+    is_active BOOLEAN DEFAULT true,
+    starts_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    renews_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    grace_period_ends_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    pending_plan VARCHAR(20),
+    plan_id VARCHAR(20),
+    pending_plan_id VARCHAR(20),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+INSERT INTO
+  subscriptions_new (id, user_id, plan, stripe_id, is_active, starts_at, expires_at, renews_at, cancelled_at, created_at, updated_at, status, grace_period_ends_at, locked_until, pending_plan, plan_id, pending_plan_id)
+SELECT
+  id, user_id, plan, stripe_id, is_active, starts_at, expires_at, renews_at, cancelled_at, created_at, updated_at, status, grace_period_ends_at, locked_until, pending_plan,
+  (
+    SELECT
+      id
+    FROM
+      plans
+    WHERE
+      plans.code = subscriptions.plan
+      AND plans.version = 1
+  ),
+  (
+    SELECT
+      id
+    FROM
+      plans
+    WHERE
+      plans.code = subscriptions.pending_plan
+      AND plans.version = 1
+  )
+FROM
+  subscriptions;
+
+DROP TABLE subscriptions;
+
+ALTER TABLE subscriptions_new RENAME TO subscriptions;
+
+CREATE INDEX idx_subscriptions_user_id ON subscriptions (user_id);
+
+CREATE INDEX idx_subscriptions_stripe_id ON subscriptions (stripe_id);
+
+CREATE INDEX idx_subscriptions_renewal ON subscriptions (is_active, renews_at, expires_at);
+
+UPDATE payments
+SET
+  subscription_id = (
+    SELECT
+      subscription_id
+    FROM
+      payment_subscriptions
+    WHERE
+      payment_subscriptions.id = payments.id
+  )
+WHERE
+  id IN (
+    SELECT
+      id
+    FROM
+      payment_subscriptions
+  );
+
+DROP TABLE payment_subscriptions;
@@ -0,0 +1,13 @@
+-- Brevity Migration: create_promo_codes_table
+-- Generated: 2025-10-19T13:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_promo_code_redemptions_user_id;
+
+DROP INDEX IF EXISTS idx_promo_code_redemptions_code_user;
+
+DROP TABLE IF EXISTS promo_code_redemptions;
+
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,42 @@
+-- Brevity Migration: create_promo_codes_table
+-- Generated: 2025-10-19T13:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  promo_codes (
+    id VARCHAR(20) PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('credits', 'discount')),
+    description TEXT,
+    credits INTEGER NOT NULL DEFAULT 0 CHECK (credits >= 0),
+    percent_off INTEGER NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 100),
+    duration VARCHAR(20),
+    provider_coupon_id VARCHAR(255),
+    max_redemptions INTEGER NOT NULL DEFAULT 0,
+    per_user_limit INTEGER NOT NULL DEFAULT 1,
+    redemption_count INTEGER NOT NULL DEFAULT 0,
+    plans TEXT,
+    starts_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE TABLE
+  promo_code_redemptions (
+    id VARCHAR(20) PRIMARY KEY,
+    promo_code_id VARCHAR(20) NOT NULL,
+    user_id VARCHAR(20) NOT NULL,
+    credit_id VARCHAR(20),
+    subscription_id VARCHAR(20),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (promo_code_id) REFERENCES promo_codes (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_promo_code_redemptions_code_user ON promo_code_redemptions (promo_code_id, user_id);
+
+CREATE INDEX idx_promo_code_redemptions_user_id ON promo_code_redemptions (user_id);
@@ -0,0 +1,46 @@
+-- Brevity Migration: create_credit_ledger
+-- Generated: 2025-10-19T13:30:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_credits_user_expiry;
+
+DELETE FROM credit_usages
+WHERE
+  credit_id IS NULL;
+
+CREATE TABLE
+  credit_usages_old (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    credit_id VARCHAR(20) NOT NULL,
+    url_id VARCHAR(20),
+    amount INTEGER NOT NULL DEFAULT 1,
+    operation VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (credit_id) REFERENCES credits (id) ON DELETE CASCADE,
+    FOREIGN KEY (url_id) REFERENCES urls (id) ON DELETE SET NULL
+  );
+
+INSERT INTO
+  credit_usages_old (id, user_id, credit_id, url_id, amount, operation, created_at)
+SELECT
+  id, user_id, credit_id, url_id, amount, operation, created_at
+FROM
+  credit_usages;
+
+DROP TABLE credit_usages;
+
+ALTER TABLE credit_usages_old RENAME TO credit_usages;
+
+CREATE INDEX idx_credit_usages_user_id ON credit_usages (user_id);
+
+CREATE INDEX idx_credit_usages_credit_id ON credit_usages (credit_id);
+
+CREATE INDEX idx_credit_usages_url_id ON credit_usages (url_id);
+
+DROP TRIGGER IF EXISTS credit_ledger_entries_immutable;
+
+DROP TABLE IF EXISTS credit_ledger_entries;
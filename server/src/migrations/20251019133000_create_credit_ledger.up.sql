@@ -0,0 +1,108 @@
+-- Brevity Migration: create_credit_ledger
+-- Generated: 2025-10-19T13:30:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  credit_ledger_entries (
+    id VARCHAR(24) PRIMARY KEY,
+    transaction_id VARCHAR(24) NOT NULL,
+    user_id VARCHAR(20) NOT NULL,
+    credit_id VARCHAR(20),
+    account VARCHAR(20) NOT NULL CHECK (
+      account IN ('wallet', 'issued', 'consumed', 'expired')
+    ),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('grant', 'debit', 'expiry')),
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    url_id VARCHAR(20),
+    operation VARCHAR(50),
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_credit_ledger_entries_user_account ON credit_ledger_entries (user_id, account);
+
+CREATE INDEX idx_credit_ledger_entries_transaction_id ON credit_ledger_entries (transaction_id);
+
+CREATE INDEX idx_credit_ledger_entries_credit_id ON credit_ledger_entries (credit_id);
+
+-- Entries are append-only; corrections are made with new entries
+CREATE TRIGGER credit_ledger_entries_immutable BEFORE
+UPDATE ON credit_ledger_entries BEGIN
+SELECT
+  RAISE (ABORT, 'credit ledger entries are immutable');
+
+END;
+
+-- Open the ledger with each existing credit's grant and what was used of it
+INSERT INTO
+  credit_ledger_entries (id, transaction_id, user_id, credit_id, account, kind, amount, description, created_at)
+SELECT
+  'g' || id || 'w', 'g' || id, user_id, id, 'wallet', 'grant', amount, description, created_at
+FROM
+  credits
+WHERE
+  amount > 0;
+
+INSERT INTO
+  credit_ledger_entries (id, transaction_id, user_id, credit_id, account, kind, amount, description, created_at)
+SELECT
+  'g' || id || 'i', 'g' || id, user_id, id, 'issued', 'grant', -amount, description, created_at
+FROM
+  credits
+WHERE
+  amount > 0;
+
+INSERT INTO
+  credit_ledger_entries (id, transaction_id, user_id, credit_id, account, kind, amount, operation, created_at)
+SELECT
+  'd' || id || 'w', 'd' || id, user_id, id, 'wallet', 'debit', remaining - amount, 'opening_balance', updated_at
+FROM
+  credits
+WHERE
+  amount > remaining;
+
+INSERT INTO
+  credit_ledger_entries (id, transaction_id, user_id, credit_id, account, kind, amount, operation, created_at)
+SELECT
+  'd' || id || 'c', 'd' || id, user_id, id, 'consumed', 'debit', amount - remaining, 'opening_balance', updated_at
+FROM
+  credits
+WHERE
+  amount > remaining;
+
+-- Free URL creations aren't drawn from a credit, so credit_id must be nullable
+CREATE TABLE
+  credit_usages_new (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    credit_id VARCHAR(20),
+    url_id VARCHAR(20),
+    amount INTEGER NOT NULL DEFAULT 1,
+    operation VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (credit_id) REFERENCES credits (id) ON DELETE CASCADE,
+    FOREIGN KEY (url_id) REFERENCES urls (id) ON DELETE SET NULL
+  );
+
+INSERT INTO
+  credit_usages_new (id, user_id, credit_id, url_id, amount, operation, created_at)
+SELECT
+  id, user_id, NULLIF(credit_id, ''), url_id, amount, operation, created_at
+FROM
+  credit_usages;
+
+DROP TABLE credit_usages;
+
+ALTER TABLE credit_usages_new RENAME TO credit_usages;
+
+CREATE INDEX idx_credit_usages_user_id ON credit_usages (user_id);
+
+CREATE INDEX idx_credit_usages_credit_id ON credit_usages (credit_id);
+
+CREATE INDEX idx_credit_usages_url_id ON credit_usages (url_id);
+
+CREATE INDEX idx_credits_user_expiry ON credits (user_id, expires_at);
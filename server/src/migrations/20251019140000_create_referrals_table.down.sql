@@ -0,0 +1,13 @@
+-- Brevity Migration: create_referrals_table
+-- Generated: 2025-10-19T14:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_referrals_signup_ip;
+
+DROP INDEX IF EXISTS idx_referrals_referrer_status;
+
+DROP TABLE IF EXISTS referrals;
+
+DROP TABLE IF EXISTS referral_codes;
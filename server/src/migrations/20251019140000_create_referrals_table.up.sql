@@ -0,0 +1,37 @@
+-- Brevity Migration: create_referrals_table
+-- Generated: 2025-10-19T14:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  referral_codes (
+    user_id VARCHAR(20) PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE TABLE
+  referrals (
+    id VARCHAR(20) PRIMARY KEY,
+    referrer_id VARCHAR(20) NOT NULL,
+    referee_id VARCHAR(20) NOT NULL UNIQUE,
+    code VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (
+      status IN ('pending', 'completed', 'capped', 'rejected')
+    ),
+    reject_reason VARCHAR(50),
+    signup_ip VARCHAR(45),
+    referrer_credits INTEGER NOT NULL DEFAULT 0,
+    referee_credits INTEGER NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (referrer_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (referee_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_referrals_referrer_status ON referrals (referrer_id, status, completed_at);
+
+CREATE INDEX idx_referrals_signup_ip ON referrals (signup_ip);
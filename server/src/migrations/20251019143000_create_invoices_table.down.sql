@@ -0,0 +1,29 @@
+-- Brevity Migration: create_invoices_table
+-- Generated: 2025-10-19T14:30:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_invoice_line_items_invoice_id;
+
+DROP TABLE IF EXISTS invoice_line_items;
+
+DROP INDEX IF EXISTS idx_invoices_user_id;
+
+DROP TABLE IF EXISTS invoices;
+
+ALTER TABLE users DROP COLUMN billing_tax_id;
+
+ALTER TABLE users DROP COLUMN billing_country;
+
+ALTER TABLE users DROP COLUMN billing_postal_code;
+
+ALTER TABLE users DROP COLUMN billing_state;
+
+ALTER TABLE users DROP COLUMN billing_city;
+
+ALTER TABLE users DROP COLUMN billing_line2;
+
+ALTER TABLE users DROP COLUMN billing_line1;
+
+ALTER TABLE users DROP COLUMN billing_name;
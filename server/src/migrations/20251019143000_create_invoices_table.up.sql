@@ -0,0 +1,73 @@
+-- Brevity Migration: create_invoices_table
+-- Generated: 2025-10-19T14:30:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+ALTER TABLE users ADD COLUMN billing_name VARCHAR(100);
+
+ALTER TABLE users ADD COLUMN billing_line1 VARCHAR(200);
+
+ALTER TABLE users ADD COLUMN billing_line2 VARCHAR(200);
+
+ALTER TABLE users ADD COLUMN billing_city VARCHAR(100);
+
+ALTER TABLE users ADD COLUMN billing_state VARCHAR(100);
+
+ALTER TABLE users ADD COLUMN billing_postal_code VARCHAR(20);
+
+ALTER TABLE users ADD COLUMN billing_country VARCHAR(2);
+
+ALTER TABLE users ADD COLUMN billing_tax_id VARCHAR(50);
+
+CREATE TABLE
+  invoices (
+    id VARCHAR(20) PRIMARY KEY,
+    sequence INTEGER NOT NULL UNIQUE,
+    number VARCHAR(30) NOT NULL UNIQUE,
+    user_id VARCHAR(20) NOT NULL,
+    payment_id VARCHAR(20) NOT NULL UNIQUE,
+    subscription_id VARCHAR(20),
+    currency VARCHAR(3) NOT NULL,
+    subtotal INTEGER NOT NULL,
+    tax_rate REAL NOT NULL DEFAULT 0,
+    tax_label VARCHAR(20),
+    tax_amount INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL,
+    seller_name VARCHAR(100),
+    seller_address TEXT,
+    seller_tax_id VARCHAR(50),
+    billing_email VARCHAR(255),
+    billing_name VARCHAR(100),
+    billing_line1 VARCHAR(200),
+    billing_line2 VARCHAR(200),
+    billing_city VARCHAR(100),
+    billing_state VARCHAR(100),
+    billing_postal_code VARCHAR(20),
+    billing_country VARCHAR(2),
+    billing_tax_id VARCHAR(50),
+    pdf_url VARCHAR(500),
+    pdf_key VARCHAR(255),
+    emailed_at TIMESTAMP,
+    email_attempts INTEGER NOT NULL DEFAULT 0,
+    issued_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    FOREIGN KEY (payment_id) REFERENCES payments (id)
+  );
+
+CREATE INDEX idx_invoices_user_id ON invoices (user_id);
+
+CREATE TABLE
+  invoice_line_items (
+    id VARCHAR(20) PRIMARY KEY,
+    invoice_id VARCHAR(20) NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    unit_amount INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    FOREIGN KEY (invoice_id) REFERENCES invoices (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_invoice_line_items_invoice_id ON invoice_line_items (invoice_id);
@@ -0,0 +1,11 @@
+-- Brevity Migration: create_trials_table
+-- Generated: 2025-10-19T15:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_trials_status_ends_at;
+
+DROP INDEX IF EXISTS idx_trials_subscription_id;
+
+DROP TABLE IF EXISTS trials;
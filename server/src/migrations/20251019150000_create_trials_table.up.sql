@@ -0,0 +1,34 @@
+-- Brevity Migration: create_trials_table
+-- Generated: 2025-10-19T15:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  trials (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL UNIQUE,
+    subscription_id VARCHAR(20) NOT NULL,
+    plan VARCHAR(20) NOT NULL,
+    plan_id VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (
+      status IN ('active', 'converted', 'reverted', 'canceled')
+    ),
+    payment_method_id VARCHAR(255),
+    payment_fingerprint VARCHAR(255) UNIQUE,
+    payment_method_brand VARCHAR(20),
+    payment_method_last4 VARCHAR(4),
+    started_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reminder_sent_at TIMESTAMP,
+    ended_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (subscription_id) REFERENCES subscriptions (id) ON DELETE CASCADE,
+    FOREIGN KEY (plan_id) REFERENCES plans (id)
+  );
+
+CREATE INDEX idx_trials_subscription_id ON trials (subscription_id);
+
+CREATE INDEX idx_trials_status_ends_at ON trials (status, ends_at);
@@ -0,0 +1,37 @@
+-- Brevity Migration: create_workspaces
+-- Generated: 2025-10-19T15:30:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_subscriptions_workspace_id;
+
+DROP INDEX IF EXISTS idx_credit_ledger_entries_workspace_account;
+
+DROP INDEX IF EXISTS idx_credit_usages_workspace_id;
+
+DROP INDEX IF EXISTS idx_credits_workspace_expiry;
+
+DROP INDEX IF EXISTS idx_urls_workspace_id;
+
+ALTER TABLE subscriptions DROP COLUMN workspace_id;
+
+ALTER TABLE credit_ledger_entries DROP COLUMN workspace_id;
+
+ALTER TABLE credit_usages DROP COLUMN workspace_id;
+
+ALTER TABLE credits DROP COLUMN workspace_id;
+
+ALTER TABLE urls DROP COLUMN workspace_id;
+
+DROP INDEX IF EXISTS idx_workspace_invitations_pending;
+
+DROP TABLE IF EXISTS workspace_invitations;
+
+DROP INDEX IF EXISTS idx_workspace_members_user_id;
+
+DROP TABLE IF EXISTS workspace_members;
+
+DROP INDEX IF EXISTS idx_workspaces_owner_id;
+
+DROP TABLE IF EXISTS workspaces;
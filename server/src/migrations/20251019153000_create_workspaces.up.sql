@@ -0,0 +1,75 @@
+-- Brevity Migration: create_workspaces
+-- Generated: 2025-10-19T15:30:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  workspaces (
+    id VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_id VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_workspaces_owner_id ON workspaces (owner_id);
+
+CREATE TABLE
+  workspace_members (
+    workspace_id VARCHAR(20) NOT NULL,
+    user_id VARCHAR(20) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'editor', 'viewer')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, user_id),
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members (user_id);
+
+CREATE TABLE
+  workspace_invitations (
+    id VARCHAR(20) PRIMARY KEY,
+    workspace_id VARCHAR(20) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'editor', 'viewer')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by VARCHAR(20),
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users (id) ON DELETE SET NULL
+  );
+
+-- One open invitation per address; inviting again replaces it
+CREATE UNIQUE INDEX idx_workspace_invitations_pending ON workspace_invitations (workspace_id, email)
+WHERE
+  accepted_at IS NULL;
+
+-- Links, credits and subscriptions can belong to a workspace instead of a
+-- single user. user_id still records who created or paid for them. Deleting a
+-- workspace clears these in the same transaction, so the columns carry no
+-- foreign keys and can be dropped again by the down migration.
+ALTER TABLE urls ADD COLUMN workspace_id VARCHAR(20);
+
+ALTER TABLE credits ADD COLUMN workspace_id VARCHAR(20);
+
+ALTER TABLE credit_usages ADD COLUMN workspace_id VARCHAR(20);
+
+ALTER TABLE credit_ledger_entries ADD COLUMN workspace_id VARCHAR(20);
+
+ALTER TABLE subscriptions ADD COLUMN workspace_id VARCHAR(20);
+
+CREATE INDEX idx_urls_workspace_id ON urls (workspace_id);
+
+CREATE INDEX idx_credits_workspace_expiry ON credits (workspace_id, expires_at);
+
+CREATE INDEX idx_credit_usages_workspace_id ON credit_usages (workspace_id);
+
+CREATE INDEX idx_credit_ledger_entries_workspace_account ON credit_ledger_entries (workspace_id, account);
+
+CREATE INDEX idx_subscriptions_workspace_id ON subscriptions (workspace_id);
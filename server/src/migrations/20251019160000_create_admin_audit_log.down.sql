@@ -0,0 +1,23 @@
+-- Brevity Migration: create_admin_audit_log
+-- Generated: 2025-10-19T16:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE urls DROP COLUMN takedown_reason;
+
+ALTER TABLE urls DROP COLUMN taken_down_at;
+
+ALTER TABLE users DROP COLUMN suspended_at;
+
+DROP TRIGGER IF EXISTS admin_audit_log_no_delete;
+
+DROP TRIGGER IF EXISTS admin_audit_log_no_update;
+
+DROP INDEX IF EXISTS idx_admin_audit_log_created_at;
+
+DROP INDEX IF EXISTS idx_admin_audit_log_admin_id;
+
+DROP INDEX IF EXISTS idx_admin_audit_log_target;
+
+DROP TABLE IF EXISTS admin_audit_log;
@@ -0,0 +1,47 @@
+-- Brevity Migration: create_admin_audit_log
+-- Generated: 2025-10-19T16:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Every change made through the admin API. The admin and target IDs are kept
+-- as plain columns so entries outlive the accounts and links they mention.
+CREATE TABLE
+  admin_audit_log (
+    id VARCHAR(20) PRIMARY KEY,
+    admin_id VARCHAR(20) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    details TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log (target_type, target_id);
+
+CREATE INDEX idx_admin_audit_log_admin_id ON admin_audit_log (admin_id);
+
+CREATE INDEX idx_admin_audit_log_created_at ON admin_audit_log (created_at);
+
+CREATE TRIGGER admin_audit_log_no_update BEFORE
+UPDATE ON admin_audit_log BEGIN
+SELECT
+  RAISE (ABORT, 'admin audit log entries are immutable');
+
+END;
+
+CREATE TRIGGER admin_audit_log_no_delete BEFORE DELETE ON admin_audit_log BEGIN
+SELECT
+  RAISE (ABORT, 'admin audit log entries are immutable');
+
+END;
+
+-- Suspension is tracked apart from is_active so that signing in during a
+-- deletion grace period can't lift it
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+
+ALTER TABLE urls ADD COLUMN taken_down_at TIMESTAMP;
+
+ALTER TABLE urls ADD COLUMN takedown_reason TEXT;
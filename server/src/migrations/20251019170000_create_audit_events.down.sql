@@ -0,0 +1,76 @@
+-- Brevity Migration: create_audit_events
+-- Generated: 2025-10-19T17:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+CREATE TABLE
+  admin_audit_log (
+    id VARCHAR(20) PRIMARY KEY,
+    admin_id VARCHAR(20) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    details TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log (target_type, target_id);
+
+CREATE INDEX idx_admin_audit_log_admin_id ON admin_audit_log (admin_id);
+
+CREATE INDEX idx_admin_audit_log_created_at ON admin_audit_log (created_at);
+
+INSERT INTO
+  admin_audit_log (
+    id,
+    admin_id,
+    action,
+    target_type,
+    target_id,
+    reason,
+    details,
+    ip_address,
+    created_at
+  )
+SELECT
+  id,
+  actor_id,
+  substr(action, 7),
+  target_type,
+  target_id,
+  COALESCE(reason, ''),
+  metadata,
+  ip_address,
+  created_at
+FROM
+  audit_events
+WHERE
+  action LIKE 'admin.%';
+
+CREATE TRIGGER admin_audit_log_no_update BEFORE
+UPDATE ON admin_audit_log BEGIN
+SELECT
+  RAISE (ABORT, 'admin audit log entries are immutable');
+
+END;
+
+CREATE TRIGGER admin_audit_log_no_delete BEFORE DELETE ON admin_audit_log BEGIN
+SELECT
+  RAISE (ABORT, 'admin audit log entries are immutable');
+
+END;
+
+DROP TRIGGER IF EXISTS audit_events_no_update;
+
+DROP INDEX IF EXISTS idx_audit_events_created_at;
+
+DROP INDEX IF EXISTS idx_audit_events_action;
+
+DROP INDEX IF EXISTS idx_audit_events_target;
+
+DROP INDEX IF EXISTS idx_audit_events_actor_id;
+
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,82 @@
+-- Brevity Migration: create_audit_events
+-- Generated: 2025-10-19T17:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Who did what, from link edits and sign-ins to admin actions. Actor and
+-- target IDs are plain columns so events outlive the accounts and links they
+-- mention. before and after hold only the fields that changed.
+CREATE TABLE
+  audit_events (
+    id VARCHAR(20) PRIMARY KEY,
+    actor_id VARCHAR(20),
+    actor_type VARCHAR(20) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id VARCHAR(20),
+    reason TEXT,
+    before TEXT,
+    after TEXT,
+    metadata TEXT,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    request_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_audit_events_actor_id ON audit_events (actor_id, created_at);
+
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target_id, created_at);
+
+CREATE INDEX idx_audit_events_action ON audit_events (action);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+
+-- Events are never changed. They are only deleted by the retention worker.
+CREATE TRIGGER audit_events_no_update BEFORE
+UPDATE ON audit_events BEGIN
+SELECT
+  RAISE (ABORT, 'audit events are immutable');
+
+END;
+
+-- The admin audit log becomes the admin.* events
+INSERT INTO
+  audit_events (
+    id,
+    actor_id,
+    actor_type,
+    action,
+    target_type,
+    target_id,
+    reason,
+    metadata,
+    ip_address,
+    created_at
+  )
+SELECT
+  id,
+  admin_id,
+  'admin',
+  'admin.' || action,
+  target_type,
+  target_id,
+  reason,
+  CAST(details AS TEXT),
+  ip_address,
+  created_at
+FROM
+  admin_audit_log;
+
+DROP TRIGGER IF EXISTS admin_audit_log_no_delete;
+
+DROP TRIGGER IF EXISTS admin_audit_log_no_update;
+
+DROP INDEX IF EXISTS idx_admin_audit_log_created_at;
+
+DROP INDEX IF EXISTS idx_admin_audit_log_admin_id;
+
+DROP INDEX IF EXISTS idx_admin_audit_log_target;
+
+DROP TABLE IF EXISTS admin_audit_log;
@@ -0,0 +1,21 @@
+-- Brevity Migration: create_webhooks
+-- Generated: 2025-10-19T18:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE users DROP COLUMN credits_low_at;
+
+DROP INDEX IF EXISTS idx_urls_expiry_pending;
+
+ALTER TABLE urls DROP COLUMN expired_notified_at;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_endpoint_id;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP INDEX IF EXISTS idx_webhook_endpoints_user_id;
+
+DROP TABLE IF EXISTS webhook_endpoints;
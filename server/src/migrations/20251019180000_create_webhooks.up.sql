@@ -0,0 +1,71 @@
+-- Brevity Migration: create_webhooks
+-- Generated: 2025-10-19T18:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Endpoints users register to receive events. events is a JSON array of
+-- event types; an empty array means all of them.
+CREATE TABLE
+  webhook_endpoints (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT,
+    secret TEXT NOT NULL,
+    events TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP,
+    disabled_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_webhook_endpoints_user_id ON webhook_endpoints (user_id);
+
+-- One row per event per endpoint, kept as the delivery log. Pending rows
+-- are the retry queue; locked_until is the lease a worker holds while
+-- sending.
+CREATE TABLE
+  webhook_deliveries (
+    id VARCHAR(20) PRIMARY KEY,
+    endpoint_id VARCHAR(20) NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    response_code INTEGER,
+    response_body TEXT,
+    last_error TEXT,
+    duration_ms INTEGER,
+    redelivery_of VARCHAR(20),
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_webhook_deliveries_endpoint_id ON webhook_deliveries (endpoint_id, created_at);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+ALTER TABLE urls ADD COLUMN expired_notified_at TIMESTAMP;
+
+-- Links that expired before webhooks existed are not announced
+UPDATE urls
+SET
+  expired_notified_at = expires_at
+WHERE
+  expires_at IS NOT NULL
+  AND expires_at <= CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_urls_expiry_pending ON urls (expires_at)
+WHERE
+  expired_notified_at IS NULL;
+
+ALTER TABLE users ADD COLUMN credits_low_at TIMESTAMP;
@@ -0,0 +1,11 @@
+-- Brevity Migration: create_url_imports
+-- Generated: 2025-10-19T19:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_url_imports_due;
+
+DROP INDEX IF EXISTS idx_url_imports_user_id;
+
+DROP TABLE IF EXISTS url_imports;
@@ -0,0 +1,35 @@
+-- Brevity Migration: create_url_imports
+-- Generated: 2025-10-19T19:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Queued bulk link requests. rows holds the submitted links as JSON until
+-- the import has run; results holds the outcome of each row. locked_until
+-- is the lease a worker holds while processing.
+CREATE TABLE
+  url_imports (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    workspace_id VARCHAR(20),
+    status VARCHAR(20) NOT NULL,
+    source VARCHAR(10) NOT NULL,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    rows TEXT,
+    results TEXT,
+    locked_until TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_url_imports_user_id ON url_imports (user_id);
+
+CREATE INDEX idx_url_imports_due ON url_imports (status, locked_until)
+WHERE
+  status IN ('pending', 'processing');
@@ -0,0 +1,11 @@
+-- Brevity Migration: create_export_schedules
+-- Generated: 2025-10-19T20:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_export_schedules_due;
+
+DROP INDEX IF EXISTS idx_export_schedules_user_id;
+
+DROP TABLE IF EXISTS export_schedules;
@@ -0,0 +1,36 @@
+-- Brevity Migration: create_export_schedules
+-- Generated: 2025-10-19T20:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Recurring link and click exports. covered_until is the end of the last
+-- click window delivered, so the next run picks up where it stopped.
+-- locked_until is the lease a worker holds while running the export.
+CREATE TABLE
+  export_schedules (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    columns TEXT,
+    url_id VARCHAR(20),
+    workspace_id VARCHAR(20),
+    frequency VARCHAR(10) NOT NULL,
+    delivery VARCHAR(10) NOT NULL,
+    next_run_at TIMESTAMP NOT NULL,
+    last_run_at TIMESTAMP,
+    last_error TEXT,
+    last_file_url TEXT,
+    last_file_key TEXT,
+    covered_until TIMESTAMP,
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_export_schedules_user_id ON export_schedules (user_id);
+
+CREATE INDEX idx_export_schedules_due ON export_schedules (next_run_at, locked_until);
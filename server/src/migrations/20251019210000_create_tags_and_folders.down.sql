@@ -0,0 +1,43 @@
+-- Brevity Migration: create_tags_and_folders
+-- Generated: 2025-10-19T21:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP TRIGGER IF EXISTS urls_fts_before_delete;
+
+DROP TRIGGER IF EXISTS urls_fts_after_update;
+
+DROP TRIGGER IF EXISTS urls_fts_before_update;
+
+DROP TRIGGER IF EXISTS urls_fts_after_insert;
+
+DROP TABLE IF EXISTS urls_fts;
+
+DROP INDEX IF EXISTS idx_urls_domain;
+
+DROP INDEX IF EXISTS idx_urls_folder_id;
+
+ALTER TABLE urls DROP COLUMN domain;
+
+ALTER TABLE urls DROP COLUMN folder_id;
+
+DROP INDEX IF EXISTS idx_url_tags_tag_id;
+
+DROP TABLE IF EXISTS url_tags;
+
+DROP INDEX IF EXISTS idx_tags_scope_name;
+
+DROP INDEX IF EXISTS idx_tags_workspace_id;
+
+DROP INDEX IF EXISTS idx_tags_user_id;
+
+DROP TABLE IF EXISTS tags;
+
+DROP INDEX IF EXISTS idx_folders_scope_name;
+
+DROP INDEX IF EXISTS idx_folders_workspace_id;
+
+DROP INDEX IF EXISTS idx_folders_user_id;
+
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,172 @@
+-- Brevity Migration: create_tags_and_folders
+-- Generated: 2025-10-19T21:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Folders and tags are scoped like links: to the user who made them, or to
+-- a workspace. Names are unique within a scope.
+CREATE TABLE
+  folders (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    workspace_id VARCHAR(20),
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_folders_user_id ON folders (user_id);
+
+CREATE INDEX idx_folders_workspace_id ON folders (workspace_id);
+
+CREATE UNIQUE INDEX idx_folders_scope_name ON folders (COALESCE(workspace_id, user_id), name COLLATE NOCASE);
+
+CREATE TABLE
+  tags (
+    id VARCHAR(20) PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    workspace_id VARCHAR(20),
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_tags_user_id ON tags (user_id);
+
+CREATE INDEX idx_tags_workspace_id ON tags (workspace_id);
+
+CREATE UNIQUE INDEX idx_tags_scope_name ON tags (COALESCE(workspace_id, user_id), name);
+
+CREATE TABLE
+  url_tags (
+    url_id VARCHAR(20) NOT NULL,
+    tag_id VARCHAR(20) NOT NULL,
+    PRIMARY KEY (url_id, tag_id),
+    FOREIGN KEY (url_id) REFERENCES urls (id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_url_tags_tag_id ON url_tags (tag_id);
+
+-- folder_id has no foreign key so the column can be dropped again; links
+-- are taken out of a folder before it is deleted.
+ALTER TABLE urls ADD COLUMN folder_id VARCHAR(20);
+
+ALTER TABLE urls ADD COLUMN domain VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_urls_folder_id ON urls (folder_id);
+
+CREATE INDEX idx_urls_domain ON urls (domain);
+
+-- domain is the destination's host: cut the scheme, then the path, query
+-- and fragment, then any credentials and port.
+UPDATE urls
+SET
+  domain = lower(substr(original_url, instr(original_url, '://') + 3))
+WHERE
+  instr(original_url, '://') > 0;
+
+UPDATE urls
+SET
+  domain = substr(domain, 1, instr(domain, '/') - 1)
+WHERE
+  instr(domain, '/') > 0;
+
+UPDATE urls
+SET
+  domain = substr(domain, 1, instr(domain, '?') - 1)
+WHERE
+  instr(domain, '?') > 0;
+
+UPDATE urls
+SET
+  domain = substr(domain, 1, instr(domain, '#') - 1)
+WHERE
+  instr(domain, '#') > 0;
+
+UPDATE urls
+SET
+  domain = substr(domain, instr(domain, '@') + 1)
+WHERE
+  instr(domain, '@') > 0;
+
+UPDATE urls
+SET
+  domain = substr(domain, 1, instr(domain, ':') - 1)
+WHERE
+  instr(domain, ':') > 0;
+
+-- Full-text index over the link fields people search by. It is an
+-- external-content table: the text stays in urls and the index is keyed by
+-- the urls rowid, kept current by the triggers below. Only the indexed
+-- columns trigger a reindex, so counting clicks doesn't. FTS4 is used
+-- because it is compiled into the SQLite driver by default; FTS5 needs a
+-- build tag. A VACUUM can renumber rowids, so run
+-- INSERT INTO urls_fts (urls_fts) VALUES ('rebuild') after one.
+CREATE VIRTUAL TABLE urls_fts USING fts4(
+  content="urls",
+  title,
+  description,
+  original_url,
+  short_code,
+  tokenize=unicode61
+);
+
+CREATE TRIGGER urls_fts_after_insert AFTER INSERT ON urls BEGIN
+INSERT INTO
+  urls_fts (docid, title, description, original_url, short_code)
+VALUES
+  (
+    new.rowid,
+    new.title,
+    new.description,
+    new.original_url,
+    new.short_code
+  );
+
+END;
+
+CREATE TRIGGER urls_fts_before_update BEFORE
+UPDATE OF title,
+description,
+original_url,
+short_code ON urls BEGIN
+DELETE FROM urls_fts
+WHERE
+  docid = old.rowid;
+
+END;
+
+CREATE TRIGGER urls_fts_after_update AFTER
+UPDATE OF title,
+description,
+original_url,
+short_code ON urls BEGIN
+INSERT INTO
+  urls_fts (docid, title, description, original_url, short_code)
+VALUES
+  (
+    new.rowid,
+    new.title,
+    new.description,
+    new.original_url,
+    new.short_code
+  );
+
+END;
+
+CREATE TRIGGER urls_fts_before_delete BEFORE DELETE ON urls BEGIN
+DELETE FROM urls_fts
+WHERE
+  docid = old.rowid;
+
+END;
+
+INSERT INTO
+  urls_fts (urls_fts)
+VALUES
+  ('rebuild');
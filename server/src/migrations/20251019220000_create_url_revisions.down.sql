@@ -0,0 +1,13 @@
+-- Brevity Migration: create_url_revisions
+-- Generated: 2025-10-19T22:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+DROP INDEX IF EXISTS idx_url_aliases_url_id;
+
+DROP TABLE IF EXISTS url_aliases;
+
+DROP INDEX IF EXISTS idx_url_revisions_url_number;
+
+DROP TABLE IF EXISTS url_revisions;
@@ -0,0 +1,41 @@
+-- Brevity Migration: create_url_revisions
+-- Generated: 2025-10-19T22:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- Each edit of a link stores the full state it left the link in, numbered
+-- from 1 per link. changes lists the fields the edit touched and
+-- restored_from the revision it was restored from, if any.
+CREATE TABLE
+  url_revisions (
+    id VARCHAR(20) PRIMARY KEY,
+    url_id VARCHAR(20) NOT NULL,
+    number INTEGER NOT NULL,
+    user_id VARCHAR(20),
+    original_url TEXT NOT NULL,
+    short_code VARCHAR(10) NOT NULL,
+    title VARCHAR(255),
+    description TEXT,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    changes TEXT,
+    restored_from INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+  );
+
+CREATE UNIQUE INDEX idx_url_revisions_url_number ON url_revisions (url_id, number);
+
+-- Short codes a link used to have. They keep redirecting to the link and
+-- cannot be taken by another one.
+CREATE TABLE
+  url_aliases (
+    short_code VARCHAR(10) PRIMARY KEY,
+    url_id VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (url_id) REFERENCES urls (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_url_aliases_url_id ON url_aliases (url_id);
@@ -0,0 +1,7 @@
+-- Brevity Migration: add_provider_event_at_to_subscriptions
+-- Generated: 2025-10-19T23:00:00Z
+-- Direction: DOWN
+
+-- Add your SQL below this line
+
+ALTER TABLE subscriptions DROP COLUMN provider_event_at;
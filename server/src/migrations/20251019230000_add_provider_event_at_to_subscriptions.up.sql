@@ -0,0 +1,9 @@
+-- Brevity Migration: add_provider_event_at_to_subscriptions
+-- Generated: 2025-10-19T23:00:00Z
+-- Direction: UP
+
+-- Add your SQL below this line
+
+-- When the last payment provider event applied to the subscription was
+-- created. Webhooks can arrive out of order; older ones are skipped.
+ALTER TABLE subscriptions ADD COLUMN provider_event_at TIMESTAMP;